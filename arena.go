@@ -0,0 +1,137 @@
+package vibeGraphql
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+)
+
+// arenaChunkSize is the number of nodes of one type allocated at a time.
+const arenaChunkSize = 128
+
+// Arena allocates the fields, selection sets and values of parsed documents
+// in blocks and frees them all at once with Reset. Parsing many documents
+// into one arena, and resetting it instead of leaving the nodes to the
+// garbage collector, cuts heap churn for large queries.
+//
+//	arena := NewArena()
+//	doc := NewParser(NewLexer(query), WithArena(arena)).ParseDocument()
+//	// ... use doc ...
+//	arena.Reset() // doc must not be used any more
+//
+// An Arena is not safe for concurrent use.
+type Arena struct {
+	fields slab[Field]
+	sets   slab[SelectionSet]
+	values slab[Value]
+
+	// abandoned is set when a resolver that may still read the arena's
+	// nodes outlived its request, so the arena must not be reused.
+	abandoned atomic.Bool
+}
+
+// NewArena returns an empty Arena.
+func NewArena() *Arena {
+	return &Arena{}
+}
+
+// WithArena makes the parser allocate nodes in a. Nodes remain valid until
+// a.Reset is called.
+func WithArena(a *Arena) ParserOption {
+	return func(p *Parser) { p.arena = a }
+}
+
+// Reset frees every node allocated in a, which may then be reused.
+func (a *Arena) Reset() {
+	a.fields.reset()
+	a.sets.reset()
+	a.values.reset()
+}
+
+// slab hands out pointers into chunks of T.
+type slab[T any] struct {
+	chunks [][]T
+	chunk  int // index of the chunk in use
+	next   int // index of the next free node in that chunk
+}
+
+func (s *slab[T]) alloc() *T {
+	if s.chunk < len(s.chunks) && s.next == arenaChunkSize {
+		s.chunk++
+		s.next = 0
+	}
+	if s.chunk == len(s.chunks) {
+		s.chunks = append(s.chunks, make([]T, arenaChunkSize))
+	}
+	node := &s.chunks[s.chunk][s.next]
+	s.next++
+	return node
+}
+
+func (s *slab[T]) reset() {
+	for i := 0; i < len(s.chunks) && i <= s.chunk; i++ {
+		clear(s.chunks[i])
+	}
+	s.chunk, s.next = 0, 0
+}
+
+func (p *Parser) newField() *Field {
+	if p.arena == nil {
+		return &Field{}
+	}
+	return p.arena.fields.alloc()
+}
+
+func (p *Parser) newSelectionSet() *SelectionSet {
+	if p.arena == nil {
+		return &SelectionSet{}
+	}
+	return p.arena.sets.alloc()
+}
+
+func (p *Parser) newValue(v Value) *Value {
+	if p.arena == nil {
+		return &v
+	}
+	node := p.arena.values.alloc()
+	*node = v
+	return node
+}
+
+// WithArenaAllocation parses each request into an Arena that is reset and
+// reused once the response is written. Resolvers must then not keep the
+// document's nodes, such as ResolveInfo.Field, after they return. When a
+// field timeout abandons a resolver that may still read them, that request's
+// arena is not reused.
+func WithArenaAllocation() HandlerOption {
+	return func(h *handler) {
+		h.arenas = &sync.Pool{New: func() interface{} { return NewArena() }}
+	}
+}
+
+type arenaKey struct{}
+
+// getArena returns an arena for a request, or nil if the handler does not
+// use arenas.
+func (h *handler) getArena() *Arena {
+	if h.arenas == nil {
+		return nil
+	}
+	return h.arenas.Get().(*Arena)
+}
+
+// putArena frees the nodes of a finished request.
+func (h *handler) putArena(a *Arena) {
+	if a == nil || a.abandoned.Load() {
+		return
+	}
+	a.Reset()
+	h.arenas.Put(a)
+}
+
+// abandonArena marks the arena of the request in ctx as still in use.
+func abandonArena(ctx context.Context) {
+	if a, ok := ctx.Value(arenaKey{}).(*Arena); ok {
+		a.abandoned.Store(true)
+	}
+}
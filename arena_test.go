@@ -0,0 +1,106 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestArena(t *testing.T) {
+	arena := NewArena()
+	for i := 0; i < 3; i++ {
+		want := NewParser(NewLexer(benchmarkQuery)).ParseDocument()
+		got := NewParser(NewLexer(benchmarkQuery), WithArena(arena)).ParseDocument()
+		if !reflect.DeepEqual(got, want) {
+			t.Fatalf("documents parsed in an arena differ")
+		}
+		arena.Reset()
+	}
+
+	// Nodes come from the arena's chunks, which are reused after Reset.
+	p := NewParser(NewLexer(`{ a }`), WithArena(arena))
+	first := p.ParseDocument().Definitions[0].(*OperationDefinition).SelectionSet.Selections[0].(*Field)
+	if first != &arena.fields.chunks[0][0] {
+		t.Errorf("expected the field to be allocated in the arena")
+	}
+}
+
+func TestArenaGrows(t *testing.T) {
+	var sb strings.Builder
+	sb.WriteString("{")
+	for i := 0; i < 3*arenaChunkSize; i++ {
+		sb.WriteString(" f")
+	}
+	sb.WriteString(" }")
+	arena := NewArena()
+	doc := NewParser(NewLexer(sb.String()), WithArena(arena)).ParseDocument()
+	selections := doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections
+	if len(selections) != 3*arenaChunkSize || len(arena.fields.chunks) != 3 {
+		t.Fatalf("got %d fields in %d chunks", len(selections), len(arena.fields.chunks))
+	}
+	arena.Reset()
+	if arena.fields.chunks[2][0].Name != "" {
+		t.Errorf("expected Reset to clear every chunk")
+	}
+}
+
+func TestWithArenaAllocation(t *testing.T) {
+	release := make(chan struct{})
+	var slow sync.WaitGroup
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"Query": {"slow": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			defer slow.Done()
+			<-release
+			return nil, nil
+		}},
+	})
+	RegisterFieldTimeout("Query", "slow", 10*time.Millisecond)
+	defer delete(FieldTimeouts, "Query")
+	// Let the slow resolver finish before the registries are restored.
+	t.Cleanup(func() {
+		close(release)
+		slow.Wait()
+	})
+
+	h := newHandler([]HandlerOption{WithArenaAllocation()})
+	for i := 0; i < 3; i++ {
+		rr := httptest.NewRecorder()
+		h.serveJSON(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ hello }"}`)))
+		if got := rr.Body.String(); got != `{"data":{"hello":"world"}}`+"\n" {
+			t.Fatalf("unexpected response %s", got)
+		}
+	}
+
+	arena := h.arenas.Get().(*Arena)
+	h.arenas.Put(arena)
+	if arena.fields.chunk != 0 || arena.fields.next != 0 {
+		t.Errorf("expected a reset arena in the pool")
+	}
+
+	// An abandoned resolver keeps the arena from being reused.
+	a := NewArena()
+	h.arenas = &sync.Pool{New: func() interface{} { return a }}
+	slow.Add(1)
+	rr := httptest.NewRecorder()
+	h.serveJSON(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ slow }"}`)))
+	if !a.abandoned.Load() || a.fields.next == 0 {
+		t.Errorf("expected the arena of a timed-out request to be left alone")
+	}
+}
+
+func BenchmarkParseArena(b *testing.B) {
+	arena := NewArena()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, errs := parseRequestIn(benchmarkQuery, arena); len(errs) > 0 {
+			b.Fatal(errs)
+		}
+		arena.Reset()
+	}
+}
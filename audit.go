@@ -0,0 +1,72 @@
+package vibeGraphql
+
+import (
+	"context"
+	"time"
+)
+
+// AuditEntry records the execution of a mutation field.
+type AuditEntry struct {
+	// OperationName is the name of the mutation operation, if it has one.
+	OperationName string
+	// Field is the name of the executed mutation field.
+	Field string
+	// Arguments holds the field's arguments after redaction.
+	Arguments map[string]interface{}
+	// Caller identifies who sent the request, as returned by AuditLog.Identity.
+	Caller string
+	// Duration is the time taken to authorize and resolve the field.
+	Duration time.Duration
+	// Err is the error the field failed with, or nil if it succeeded.
+	Err error
+}
+
+// AuditLog configures the audit trail of mutations.
+type AuditLog struct {
+	// Hook receives an entry for every executed mutation field, including
+	// those denied by Schema.Authorize.
+	Hook func(ctx context.Context, entry AuditEntry)
+	// Identity returns the caller stored in the request context, such as
+	// the authenticated user's ID.
+	Identity func(ctx context.Context) string
+	// Redact, when set, is applied to each argument before it is recorded.
+	Redact RedactFunc
+}
+
+// WithAuditLog records every mutation field the handler executes.
+func WithAuditLog(audit AuditLog) HandlerOption {
+	return func(h *handler) {
+		h.audit = &audit
+	}
+}
+
+type auditLogKey struct{}
+
+func auditLogFrom(ctx context.Context) *AuditLog {
+	audit, _ := ctx.Value(auditLogKey{}).(*AuditLog)
+	if audit == nil || audit.Hook == nil {
+		return nil
+	}
+	return audit
+}
+
+// record passes an entry for the execution of field to the hook.
+func (a *AuditLog) record(ctx context.Context, op *OperationDefinition, field *Field, args map[string]interface{}, d time.Duration, err error) {
+	entry := AuditEntry{
+		OperationName: op.Name,
+		Field:         field.Name,
+		Arguments:     make(map[string]interface{}, len(args)),
+		Duration:      d,
+		Err:           err,
+	}
+	for name, value := range args {
+		if a.Redact != nil {
+			value = a.Redact(name, value)
+		}
+		entry.Arguments[name] = value
+	}
+	if a.Identity != nil {
+		entry.Caller = a.Identity(ctx)
+	}
+	a.Hook(ctx, entry)
+}
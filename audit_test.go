@@ -0,0 +1,73 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type auditUserKey struct{}
+
+func TestWithAuditLog(t *testing.T) {
+	MutationResolvers["auditRename"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "renamed", nil
+	}
+	MutationResolvers["auditFail"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, NewError("FAILED", "rename failed")
+	}
+	QueryResolvers["auditRead"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "read", nil
+	}
+	defer delete(MutationResolvers, "auditRename")
+	defer delete(MutationResolvers, "auditFail")
+	defer delete(QueryResolvers, "auditRead")
+
+	var entries []AuditEntry
+	h := NewHandler(WithAuditLog(AuditLog{
+		Hook: func(ctx context.Context, entry AuditEntry) { entries = append(entries, entry) },
+		Identity: func(ctx context.Context) string {
+			user, _ := ctx.Value(auditUserKey{}).(string)
+			return user
+		},
+		Redact: func(name string, value interface{}) interface{} {
+			if name == "secret" {
+				return "[REDACTED]"
+			}
+			return value
+		},
+	}))
+	send := func(query string) {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`))
+		req = req.WithContext(context.WithValue(req.Context(), auditUserKey{}, "alice"))
+		h.ServeHTTP(httptest.NewRecorder(), req)
+	}
+
+	send(`mutation Rename { auditRename(name: \"bob\", secret: \"s3cr3t\") auditFail }`)
+	send(`{ auditRead }`)
+	if len(entries) != 2 {
+		t.Fatalf("expected 2 audit entries, got %+v", entries)
+	}
+	first := entries[0]
+	if first.OperationName != "Rename" || first.Field != "auditRename" || first.Caller != "alice" || first.Err != nil {
+		t.Errorf("unexpected entry %+v", first)
+	}
+	if first.Arguments["name"] != "bob" || first.Arguments["secret"] != "[REDACTED]" {
+		t.Errorf("unexpected arguments %v", first.Arguments)
+	}
+	if entries[1].Field != "auditFail" || entries[1].Err == nil || entries[1].Err.Error() != "rename failed" {
+		t.Errorf("expected failure to be recorded, got %+v", entries[1])
+	}
+
+	// Denied mutations are audited too.
+	s := useTestSchema(t, `type Query { auditRead: String } type Mutation { auditRename(name: String, secret: String): String }`)
+	s.Authorize = func(ctx context.Context, typeName, fieldName string, args map[string]interface{}) error {
+		return errors.New("admins only")
+	}
+	entries = nil
+	send(`mutation { auditRename(name: \"eve\") }`)
+	if len(entries) != 1 || entries[0].Err == nil || !strings.Contains(entries[0].Err.Error(), "permission denied") {
+		t.Errorf("expected denied mutation to be audited, got %+v", entries)
+	}
+}
@@ -0,0 +1,29 @@
+package vibeGraphql
+
+import "context"
+
+// AuthorizeFunc decides whether a field may be resolved. Returning a non-nil
+// error denies access to the field; sibling fields still resolve.
+type AuthorizeFunc func(ctx context.Context, parentType, field string, args map[string]interface{}) error
+
+// AuthDirective returns a directive handler that denies access to the annotated
+// field when check returns an error. It is typically registered as @auth:
+//
+//	RegisterDirective("auth", AuthDirective(func(ctx context.Context, args map[string]interface{}) error { ... }))
+func AuthDirective(check func(ctx context.Context, args map[string]interface{}) error) DirectiveFunc {
+	return func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+		if err := check(ctx, args); err != nil {
+			return nil, permissionDenied(err)
+		}
+		return next(ctx)
+	}
+}
+
+// permissionDenied converts an authorization failure into a GraphQL error,
+// keeping errors that are already GraphQL errors unchanged.
+func permissionDenied(err error) *Error {
+	if gqlErr, ok := err.(*Error); ok {
+		return gqlErr
+	}
+	return ErrPermissionDenied()
+}
@@ -0,0 +1,78 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+)
+
+func TestSchemaAuthorize(t *testing.T) {
+	s := useTestSchema(t, `type Query { public: String private: String }`)
+	s.Authorize = func(ctx context.Context, parentType, field string, args map[string]interface{}) error {
+		if parentType == "Query" && field == "private" {
+			return errors.New("no access")
+		}
+		return nil
+	}
+	RegisterFieldResolver("Query", "public", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "open", nil
+	})
+	RegisterFieldResolver("Query", "private", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("resolver of a denied field must not run")
+		return "hidden", nil
+	})
+	t.Cleanup(func() { delete(FieldResolvers, "Query") })
+
+	doc := NewParser(NewLexer(`{ public private }`)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatalf("executeDocument error: %v", err)
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["public"] != "open" {
+		t.Errorf("expected sibling field to resolve, got %v", data["public"])
+	}
+	if v, ok := data["private"]; !ok || v != nil {
+		t.Errorf("expected denied field to be null, got %v", v)
+	}
+	errs := resp["errors"].([]*Error)
+	if len(errs) != 1 || errs[0].Message != "permission denied" || errs[0].Extensions["code"] != CodePermissionDenied {
+		t.Errorf("unexpected errors: %+v", errs)
+	}
+}
+
+func TestAuthDirective(t *testing.T) {
+	useTestSchema(t, `
+		directive @auth(role: String!) on FIELD_DEFINITION
+		type Query { admin: String @auth(role: "admin") }
+	`)
+	RegisterQueryResolver("admin", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "root", nil
+	})
+	type roleKey struct{}
+	RegisterDirective("auth", AuthDirective(func(ctx context.Context, args map[string]interface{}) error {
+		if ctx.Value(roleKey{}) != args["role"] {
+			return errors.New("wrong role")
+		}
+		return nil
+	}))
+	t.Cleanup(func() { delete(DirectiveHandlers, "auth") })
+
+	doc := NewParser(NewLexer(`{ admin }`)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatalf("executeDocument error: %v", err)
+	}
+	if errs, _ := resp["errors"].([]*Error); len(errs) != 1 {
+		t.Fatalf("expected a permission error, got %v", resp)
+	}
+
+	ctx := context.WithValue(context.Background(), roleKey{}, "admin")
+	resp, err = executeDocument(ctx, doc, nil)
+	if err != nil {
+		t.Fatalf("executeDocument error: %v", err)
+	}
+	if resp["data"].(map[string]interface{})["admin"] != "root" {
+		t.Errorf("expected admin to resolve, got %v", resp)
+	}
+}
@@ -0,0 +1,174 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+	"sync"
+	"testing"
+)
+
+// The benchmarks in this file, together with BenchmarkLexer, BenchmarkParse
+// and BenchmarkExecute, make up the performance regression suite. Compare a
+// change against testdata/benchmarks/baseline.txt with benchstat:
+//
+//	go test -run '^$' -bench . -count 6 > new.txt
+//	benchstat testdata/benchmarks/baseline.txt new.txt
+
+const benchmarkSDL = `
+	type Query { node: Node users(first: Int): [User] }
+	type Node { id: ID value: Int child: Node }
+	type User { id: ID name: String friends: [User] posts: [Post] }
+	type Post { id: ID title: String }
+`
+
+type benchNode struct {
+	ID    string     `json:"id"`
+	Value int        `json:"value"`
+	Child *benchNode `json:"child"`
+}
+
+type benchUser struct {
+	ID    string       `json:"id"`
+	Name  string       `json:"name"`
+	Posts []*benchPost `json:"posts"`
+}
+
+type benchPost struct {
+	ID    string `json:"id"`
+	Title string `json:"title"`
+}
+
+// deepQuery selects node { child { child ... } } nested depth levels deep.
+func deepQuery(depth int) string {
+	return "{ node { id value " + strings.Repeat("child { id value ", depth) + strings.Repeat("} ", depth) + "} }"
+}
+
+// useBenchmarkResolvers serves benchmarkSDL with a chain of depth nodes and
+// n users, whose friends are resolved by a registered field resolver.
+func useBenchmarkResolvers(b *testing.B, depth, n int) {
+	root := &benchNode{ID: "0"}
+	for node, i := root, 1; i <= depth; i++ {
+		node.Child = &benchNode{ID: fmt.Sprint(i), Value: i}
+		node = node.Child
+	}
+	users := make([]*benchUser, n)
+	for i := range users {
+		users[i] = &benchUser{ID: fmt.Sprint(i), Name: "user", Posts: []*benchPost{{ID: "1", Title: "a"}, {ID: "2", Title: "b"}}}
+	}
+	useResolvers(b, map[string]ResolverFunc{
+		"node":  func(source interface{}, args map[string]interface{}) (interface{}, error) { return root, nil },
+		"users": func(source interface{}, args map[string]interface{}) (interface{}, error) { return users, nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"benchUser": {"friends": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return users[:3], nil
+		}},
+	})
+	useTestSchema(b, benchmarkSDL)
+}
+
+func runBenchmarkQuery(b *testing.B, query string) {
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := executeRequest(context.Background(), query, nil)
+		if err != nil || result["errors"] != nil {
+			b.Fatal(err, result["errors"])
+		}
+	}
+}
+
+func BenchmarkValidate(b *testing.B) {
+	s, err := ParseSchema(benchmarkSDL)
+	if err != nil {
+		b.Fatal(err)
+	}
+	doc := parseQuery(`{ users(first: 10) { id name friends { id name posts { id title } } posts { id } } node { id child { id } } }`)
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if errs := Validate(s, doc); len(errs) > 0 {
+			b.Fatal(errs)
+		}
+	}
+}
+
+func BenchmarkExecuteDeep(b *testing.B) {
+	useBenchmarkResolvers(b, 50, 0)
+	runBenchmarkQuery(b, deepQuery(50))
+}
+
+func BenchmarkExecuteNested(b *testing.B) {
+	useBenchmarkResolvers(b, 0, 20)
+	runBenchmarkQuery(b, `{ users { id name friends { id name friends { id name posts { id title } } } } }`)
+}
+
+func BenchmarkExecuteList(b *testing.B) {
+	for _, concurrency := range []int{1, 8} {
+		b.Run(fmt.Sprintf("concurrency=%d", concurrency), func(b *testing.B) {
+			defer func(prev int) { ListConcurrency = prev }(ListConcurrency)
+			ListConcurrency = concurrency
+			useBenchmarkResolvers(b, 0, 500)
+			runBenchmarkQuery(b, `{ users { id name friends { id } posts { id title } } }`)
+		})
+	}
+}
+
+// fanOutConn is a SubscriptionConn that encodes every event it receives.
+type fanOutConn struct {
+	received *sync.WaitGroup
+}
+
+func (c fanOutConn) ReadMessage() (int, []byte, error) {
+	return 1, []byte(`{"query": "subscription { benchEvents }"}`), nil
+}
+func (c fanOutConn) WriteMessage(messageType int, data []byte) error { return nil }
+func (c fanOutConn) WriteJSON(v interface{}) error {
+	err := json.NewEncoder(io.Discard).Encode(v)
+	c.received.Done()
+	return err
+}
+
+func BenchmarkSubscriptionFanOut(b *testing.B) {
+	const subscribers = 100
+	var mu sync.Mutex
+	var channels []chan interface{}
+	var subscribed sync.WaitGroup
+	subscribed.Add(subscribers)
+	SubscriptionResolvers["benchEvents"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		mu.Lock()
+		channels = append(channels, ch)
+		mu.Unlock()
+		subscribed.Done()
+		return ch, nil
+	}
+	defer delete(SubscriptionResolvers, "benchEvents")
+
+	var received, done sync.WaitGroup
+	done.Add(subscribers)
+	for i := 0; i < subscribers; i++ {
+		go func() {
+			defer done.Done()
+			ServeSubscription(fanOutConn{received: &received})
+		}()
+	}
+	subscribed.Wait()
+	event := map[string]interface{}{"benchEvents": map[string]interface{}{"id": "1", "value": 42}}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		received.Add(subscribers)
+		for _, ch := range channels {
+			ch <- event
+		}
+		received.Wait()
+	}
+	b.StopTimer()
+	for _, ch := range channels {
+		close(ch)
+	}
+	done.Wait()
+}
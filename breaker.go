@@ -0,0 +1,146 @@
+package vibeGraphql
+
+import (
+	"context"
+	"math"
+	"sync"
+	"time"
+)
+
+// CircuitBreaker stops calling resolvers whose backend keeps failing, so that
+// one broken dependency cannot slow down or take out the whole API. After
+// FailureThreshold consecutive failures the circuit opens: calls fail at once
+// with a SERVICE_UNAVAILABLE error until OpenTimeout has passed. Then a single
+// trial call is let through, which closes the circuit if it succeeds and
+// opens it again if it fails.
+//
+// A CircuitBreaker may wrap any number of resolvers; each key has its own
+// circuit.
+type CircuitBreaker struct {
+	// FailureThreshold defaults to 5.
+	FailureThreshold int
+	// OpenTimeout defaults to 30 seconds.
+	OpenTimeout time.Duration
+	// Key returns the circuit a call belongs to, for example the name of
+	// the backend shared by several fields. It defaults to the field's
+	// "Type.field".
+	Key func(ctx context.Context) string
+	// IsFailure reports whether an error counts as a failure of the backend.
+	// By default every error does except GraphQL errors, such as a denied
+	// permission, and context cancellation.
+	IsFailure func(err error) bool
+
+	mu       sync.Mutex
+	circuits map[string]*circuit
+	now      func() time.Time
+}
+
+type circuit struct {
+	failures  int
+	openUntil time.Time // zero while the circuit is closed
+	trial     bool      // a trial call is in flight
+}
+
+// Wrap returns resolver guarded by the breaker:
+//
+//	payments := &CircuitBreaker{Key: func(context.Context) string { return "payments" }}
+//	RegisterFieldResolver("Order", "invoice", payments.Wrap(fetchInvoice))
+func (b *CircuitBreaker) Wrap(resolver ContextResolverFunc) ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		key := b.key(ctx)
+		if retryAfter, ok := b.allow(key); !ok {
+			err := NewError(CodeUnavailable, "Field \""+fieldCoordinate(ctx)+"\" is temporarily unavailable.")
+			err.Extensions["retryAfter"] = int(math.Ceil(retryAfter.Seconds()))
+			return nil, err
+		}
+		res, err := resolver(ctx, source, args)
+		b.done(key, err != nil && b.isFailure(err))
+		return res, err
+	}
+}
+
+func (b *CircuitBreaker) key(ctx context.Context) string {
+	if b.Key != nil {
+		return b.Key(ctx)
+	}
+	return fieldCoordinate(ctx)
+}
+
+// fieldCoordinate returns the "Type.field" being resolved in ctx.
+func fieldCoordinate(ctx context.Context) string {
+	if info := ResolveInfoFromContext(ctx); info != nil {
+		return info.ParentType + "." + info.FieldName
+	}
+	return ""
+}
+
+func (b *CircuitBreaker) isFailure(err error) bool {
+	if b.IsFailure != nil {
+		return b.IsFailure(err)
+	}
+	return retryable(err)
+}
+
+func (b *CircuitBreaker) clock() time.Time {
+	if b.now != nil {
+		return b.now()
+	}
+	return time.Now()
+}
+
+// allow reports whether a call on the circuit key may proceed, and if not,
+// how long until a trial call is let through.
+func (b *CircuitBreaker) allow(key string) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	c := b.circuits[key]
+	if c == nil || c.openUntil.IsZero() {
+		return 0, true
+	}
+	if wait := c.openUntil.Sub(b.clock()); wait > 0 {
+		return wait, false
+	}
+	if c.trial {
+		return b.openTimeout(), false
+	}
+	c.trial = true
+	return 0, true
+}
+
+// done records the outcome of a call on the circuit key.
+func (b *CircuitBreaker) done(key string, failed bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	if b.circuits == nil {
+		b.circuits = make(map[string]*circuit)
+	}
+	c := b.circuits[key]
+	if c == nil {
+		if !failed {
+			return
+		}
+		c = &circuit{}
+		b.circuits[key] = c
+	}
+	trial := c.trial
+	c.trial = false
+	if !failed {
+		delete(b.circuits, key)
+		return
+	}
+	c.failures++
+	threshold := b.FailureThreshold
+	if threshold <= 0 {
+		threshold = 5
+	}
+	if trial || c.failures >= threshold {
+		c.openUntil = b.clock().Add(b.openTimeout())
+	}
+}
+
+func (b *CircuitBreaker) openTimeout() time.Duration {
+	if b.OpenTimeout > 0 {
+		return b.OpenTimeout
+	}
+	return 30 * time.Second
+}
@@ -0,0 +1,84 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+func TestCircuitBreaker(t *testing.T) {
+	now := time.Unix(0, 0)
+	b := &CircuitBreaker{FailureThreshold: 2, OpenTimeout: 10 * time.Second, now: func() time.Time { return now }}
+	calls := 0
+	var fail error = errors.New("connection refused")
+	resolver := b.Wrap(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		if fail != nil {
+			return nil, fail
+		}
+		return "ok", nil
+	})
+	ctx := context.WithValue(context.Background(), resolveInfoKey{}, &ResolveInfo{ParentType: "Order", FieldName: "invoice"})
+	call := func() error {
+		_, err := resolver(ctx, nil, nil)
+		return err
+	}
+
+	call()
+	call()
+	err := call()
+	if calls != 2 {
+		t.Fatalf("expected the circuit to open after 2 failures, got %d calls", calls)
+	}
+	gqlErr, ok := err.(*Error)
+	if !ok || gqlErr.Extensions["code"] != CodeUnavailable || gqlErr.Extensions["retryAfter"] != 10 {
+		t.Fatalf("unexpected error %#v", err)
+	}
+	if gqlErr.Message != `Field "Order.invoice" is temporarily unavailable.` {
+		t.Errorf("unexpected message %q", gqlErr.Message)
+	}
+
+	// A failed trial call opens the circuit again.
+	now = now.Add(10 * time.Second)
+	call()
+	call()
+	if calls != 3 {
+		t.Fatalf("expected a single trial call, got %d calls", calls)
+	}
+
+	// A successful trial call closes it.
+	now = now.Add(10 * time.Second)
+	fail = nil
+	if err := call(); err != nil {
+		t.Fatal(err)
+	}
+	if err := call(); err != nil || calls != 5 {
+		t.Errorf("expected the circuit to close, got %v after %d calls", err, calls)
+	}
+
+	// GraphQL errors do not count as failures.
+	fail = ErrPermissionDenied()
+	for i := 0; i < 3; i++ {
+		call()
+	}
+	if calls != 8 {
+		t.Errorf("expected GraphQL errors not to open the circuit, got %d calls", calls)
+	}
+}
+
+func TestCircuitBreakerKey(t *testing.T) {
+	b := &CircuitBreaker{FailureThreshold: 1, Key: func(context.Context) string { return "payments" }}
+	down := func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New("down")
+	}
+	calls := 0
+	up := func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return "ok", nil
+	}
+	b.Wrap(down)(context.Background(), nil, nil)
+	if _, err := b.Wrap(up)(context.Background(), nil, nil); err == nil || calls != 0 {
+		t.Errorf("expected resolvers sharing a key to share the circuit, got %v", err)
+	}
+}
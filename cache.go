@@ -0,0 +1,268 @@
+package vibeGraphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// CacheScope says whether a cached response may be shared between users.
+type CacheScope string
+
+const (
+	CachePublic  CacheScope = "PUBLIC"
+	CachePrivate CacheScope = "PRIVATE"
+)
+
+// CachePolicy says how long a response may be cached and by whom.
+type CachePolicy struct {
+	MaxAge time.Duration
+	Scope  CacheScope
+}
+
+// CacheControl returns the value of the Cache-Control header for p.
+func (p CachePolicy) CacheControl() string {
+	if p.MaxAge <= 0 {
+		return "no-store"
+	}
+	scope := "public"
+	if p.Scope == CachePrivate {
+		scope = "private"
+	}
+	return "max-age=" + strconv.Itoa(int(p.MaxAge/time.Second)) + ", " + scope
+}
+
+// CachePolicyOf computes the cache policy of op from the @cacheControl hints
+// in s, following the rules of Apollo Server:
+//
+//	type Post @cacheControl(maxAge: 240) { ... }
+//	type Query { me: User @cacheControl(maxAge: 30, scope: PRIVATE) }
+//
+// A hint on a field takes precedence over one on the field's type. Root
+// fields and fields returning objects, interfaces or unions default to a max
+// age of zero, while other fields inherit the max age of their parent. The
+// policy's max age is the smallest of all selected fields, and its scope is
+// private if any field is. Only queries are cacheable.
+func CachePolicyOf(s *Schema, op *OperationDefinition) CachePolicy {
+	if s == nil || op == nil || op.Operation != "query" || op.SelectionSet == nil {
+		return CachePolicy{}
+	}
+	c := &cachePolicyCalc{schema: s, maxAge: -1, scope: CachePublic}
+	c.selectionSet(s.QueryType, op.SelectionSet, 0)
+	if c.maxAge < 0 {
+		c.maxAge = 0
+	}
+	return CachePolicy{MaxAge: time.Duration(c.maxAge) * time.Second, Scope: c.scope}
+}
+
+type cachePolicyCalc struct {
+	schema *Schema
+	maxAge int // in seconds; -1 until a field restricts it
+	scope  CacheScope
+	// fragments records the fragments already applied, with the type and
+	// max age they were applied with, as applying them again changes nothing.
+	fragments map[fragmentUse]bool
+}
+
+type fragmentUse struct {
+	fragment     *FragmentDefinition
+	typeName     string
+	parentMaxAge int
+}
+
+func (c *cachePolicyCalc) selectionSet(typeName string, ss *SelectionSet, parentMaxAge int) {
+	for _, sel := range ss.Selections {
+		field, ok := sel.(*Field)
+		if !ok {
+			c.fragment(typeName, sel, parentMaxAge)
+			continue
+		}
+		if strings.HasPrefix(field.Name, "__") {
+			continue
+		}
+		def := c.schema.Field(typeName, field.Name)
+		if def == nil {
+			continue
+		}
+		target := c.schema.Type(namedType(def.Type))
+		maxAge, scope := cacheHint(def.Directives)
+		if target != nil {
+			typeMaxAge, typeScope := cacheHint(target.Directives)
+			if maxAge < 0 {
+				maxAge = typeMaxAge
+			}
+			if scope == "" {
+				scope = typeScope
+			}
+		}
+		composite := target != nil && (target.kind() == KindObject || target.kind() == KindInterface || target.kind() == KindUnion)
+		if maxAge < 0 {
+			if composite || typeName == c.schema.QueryType {
+				maxAge = 0
+			} else {
+				maxAge = parentMaxAge
+			}
+		}
+		if c.maxAge < 0 || maxAge < c.maxAge {
+			c.maxAge = maxAge
+		}
+		if scope == CachePrivate {
+			c.scope = CachePrivate
+		}
+		if composite && field.SelectionSet != nil {
+			c.selectionSet(target.Name, field.SelectionSet, maxAge)
+		}
+	}
+}
+
+// fragment applies the fields of sel, if it is a fragment, to the policy.
+func (c *cachePolicyCalc) fragment(typeName string, sel Selection, parentMaxAge int) {
+	ss, typeCondition, _ := expandFragment(sel)
+	if ss == nil {
+		return
+	}
+	if typeCondition != "" {
+		typeName = typeCondition
+	}
+	if spread, ok := sel.(*FragmentSpread); ok {
+		use := fragmentUse{spread.Fragment, typeName, parentMaxAge}
+		if c.fragments[use] {
+			return
+		}
+		if c.fragments == nil {
+			c.fragments = make(map[fragmentUse]bool)
+		}
+		c.fragments[use] = true
+	}
+	c.selectionSet(typeName, ss, parentMaxAge)
+}
+
+// cacheHint returns the arguments of the @cacheControl directive among
+// directives. maxAge is -1 and scope is empty if they are not given.
+func cacheHint(directives []Directive) (maxAge int, scope CacheScope) {
+	maxAge = -1
+	for _, d := range directives {
+		if d.Name != "cacheControl" {
+			continue
+		}
+		args := buildArgumentValues(d.Arguments, nil)
+		if n, ok := args["maxAge"].(int); ok {
+			maxAge = n
+		}
+		if s, ok := args["scope"].(string); ok {
+			scope = CacheScope(s)
+		}
+	}
+	return maxAge, scope
+}
+
+// CacheStore stores cached responses. Implementations must be safe for
+// concurrent use; wrap a Redis client, for example, to share the cache
+// between servers.
+type CacheStore interface {
+	Get(ctx context.Context, key string) ([]byte, bool)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration)
+}
+
+// ResponseCache configures the full-response cache.
+type ResponseCache struct {
+	Store CacheStore
+	// SessionKey identifies the user a private response is cached for, such
+	// as a session or user ID. Private responses are not cached when it is
+	// nil or returns "".
+	SessionKey func(r *http.Request) string
+}
+
+// WithResponseCache caches the responses of queries according to their
+// CachePolicyOf and sets the Cache-Control header of every response. Responses
+// with errors are never cached.
+func WithResponseCache(cache ResponseCache) HandlerOption {
+	return func(h *handler) {
+		h.cache = &cache
+	}
+}
+
+// key returns the key the response to query is cached under, or "" if it
+// may not be cached.
+func (c *ResponseCache) key(r *http.Request, policy CachePolicy, query, operationName string, variables map[string]interface{}) string {
+	if c.Store == nil || policy.MaxAge <= 0 {
+		return ""
+	}
+	var session string
+	if policy.Scope == CachePrivate {
+		if c.SessionKey != nil {
+			session = c.SessionKey(r)
+		}
+		if session == "" {
+			return ""
+		}
+	}
+	vars, err := json.Marshal(variables)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256([]byte(string(policy.Scope) + "\x00" + session + "\x00" + query + "\x00" + operationName + "\x00" + string(vars)))
+	return hex.EncodeToString(sum[:])
+}
+
+// MemoryCache is an in-process CacheStore.
+type MemoryCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	entries    map[string]memoryCacheEntry
+	now        func() time.Time
+}
+
+type memoryCacheEntry struct {
+	value   []byte
+	expires time.Time
+}
+
+// NewMemoryCache returns a MemoryCache holding at most maxEntries responses.
+// A maxEntries of zero means no limit.
+func NewMemoryCache(maxEntries int) *MemoryCache {
+	return &MemoryCache{maxEntries: maxEntries, entries: make(map[string]memoryCacheEntry), now: time.Now}
+}
+
+// Get returns the unexpired value stored under key.
+func (c *MemoryCache) Get(ctx context.Context, key string) ([]byte, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	entry, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !c.now().Before(entry.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	return entry.value, true
+}
+
+// Set stores value under key for ttl. When the cache is full, expired
+// entries are dropped first, then arbitrary ones.
+func (c *MemoryCache) Set(ctx context.Context, key string, value []byte, ttl time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	now := c.now()
+	if _, exists := c.entries[key]; !exists && c.maxEntries > 0 && len(c.entries) >= c.maxEntries {
+		for k, entry := range c.entries {
+			if !now.Before(entry.expires) {
+				delete(c.entries, k)
+			}
+		}
+		for k := range c.entries {
+			if len(c.entries) < c.maxEntries {
+				break
+			}
+			delete(c.entries, k)
+		}
+	}
+	c.entries[key] = memoryCacheEntry{value: append([]byte(nil), value...), expires: now.Add(ttl)}
+}
@@ -0,0 +1,141 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+const cacheSDL = `
+	type Query {
+		posts: [Post] @cacheControl(maxAge: 60)
+		me: User @cacheControl(maxAge: 30, scope: PRIVATE)
+		news: Post
+		version: String @cacheControl(maxAge: 3600)
+		now: String
+	}
+	type Post @cacheControl(maxAge: 240) {
+		title: String
+		author: User
+		views: Int @cacheControl(maxAge: 10)
+	}
+	type User {
+		name: String
+	}
+	type Mutation { like: Int }
+`
+
+func TestCachePolicyOf(t *testing.T) {
+	s, err := ParseSchema(cacheSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ posts { title } }`, "max-age=60, public"},
+		{`{ posts { title views } }`, "max-age=10, public"},
+		{`{ news { title } }`, "max-age=240, public"},
+		{`{ posts { title author { name } } }`, "no-store"},
+		{`{ version }`, "max-age=3600, public"},
+		{`{ version now }`, "no-store"},
+		{`{ me { name } version }`, "max-age=30, private"},
+		{`{ __typename version }`, "max-age=3600, public"},
+		{`mutation { like }`, "no-store"},
+	}
+	for _, tt := range tests {
+		op := parseQuery(tt.query).Definitions[0].(*OperationDefinition)
+		if got := CachePolicyOf(s, op).CacheControl(); got != tt.want {
+			t.Errorf("%s: got %q, want %q", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestWithResponseCache(t *testing.T) {
+	useTestSchema(t, cacheSDL)
+	type user struct{ Name string }
+	calls := 0
+	QueryResolvers["version"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return "v1", nil
+	}
+	QueryResolvers["me"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return user{Name: "ann"}, nil
+	}
+	QueryResolvers["now"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, NewError("UNAVAILABLE", "clock unavailable")
+	}
+	defer delete(QueryResolvers, "version")
+	defer delete(QueryResolvers, "me")
+	defer delete(QueryResolvers, "now")
+
+	h := newHandler([]HandlerOption{WithResponseCache(ResponseCache{
+		Store:      NewMemoryCache(10),
+		SessionKey: func(r *http.Request) string { return r.Header.Get("X-Session") },
+	})})
+	send := func(session, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`))
+		if session != "" {
+			req.Header.Set("X-Session", session)
+		}
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, req)
+		return rr
+	}
+
+	first, second := send("", "{ version }"), send("", "{ version }")
+	if calls != 1 || first.Body.String() != second.Body.String() || !strings.Contains(second.Body.String(), `"version":"v1"`) {
+		t.Errorf("expected the second response to be cached, calls=%d body=%s", calls, second.Body.String())
+	}
+	if cc := second.Header().Get("Cache-Control"); cc != "max-age=3600, public" {
+		t.Errorf("unexpected Cache-Control %q", cc)
+	}
+
+	// Private responses are cached per session, and not without one.
+	calls = 0
+	send("", "{ me { name } }")
+	send("", "{ me { name } }")
+	send("a", "{ me { name } }")
+	send("a", "{ me { name } }")
+	send("b", "{ me { name } }")
+	if calls != 4 {
+		t.Errorf("expected 4 resolver calls, got %d", calls)
+	}
+
+	// Responses with errors are not cached.
+	rr := send("", "{ now }")
+	if cc := rr.Header().Get("Cache-Control"); cc != "no-store" {
+		t.Errorf("unexpected Cache-Control %q for an error response", cc)
+	}
+}
+
+func TestMemoryCache(t *testing.T) {
+	ctx := context.Background()
+	c := NewMemoryCache(2)
+	now := time.Unix(0, 0)
+	c.now = func() time.Time { return now }
+
+	c.Set(ctx, "a", []byte("1"), time.Second)
+	if v, ok := c.Get(ctx, "a"); !ok || string(v) != "1" {
+		t.Errorf("unexpected value %q %v", v, ok)
+	}
+	now = now.Add(time.Second)
+	if _, ok := c.Get(ctx, "a"); ok {
+		t.Error("expected entry to expire")
+	}
+
+	c.Set(ctx, "a", []byte("1"), time.Minute)
+	c.Set(ctx, "b", []byte("2"), time.Minute)
+	c.Set(ctx, "c", []byte("3"), time.Minute)
+	if len(c.entries) != 2 {
+		t.Errorf("expected cache to hold 2 entries, has %d", len(c.entries))
+	}
+	if v, ok := c.Get(ctx, "c"); !ok || string(v) != "3" {
+		t.Errorf("expected newest entry to be kept, got %q %v", v, ok)
+	}
+}
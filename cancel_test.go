@@ -0,0 +1,68 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestExecutionCancelledOnDisconnect(t *testing.T) {
+	ctx, disconnect := context.WithCancel(context.Background())
+	defer disconnect()
+	var ranAfter bool
+	useResolvers(t, map[string]ResolverFunc{
+		"after": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			ranAfter = true
+			return "late", nil
+		},
+	}, map[string]map[string]ContextResolverFunc{
+		"Query": {"slow": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			// The client disconnects while the resolver waits on a backend.
+			time.AfterFunc(10*time.Millisecond, disconnect)
+			select {
+			case <-ctx.Done():
+				return nil, ctx.Err()
+			case <-time.After(5 * time.Second):
+				return "done", nil
+			}
+		}},
+	})
+
+	rr := httptest.NewRecorder()
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ slow after }"}`)).WithContext(ctx)
+	begin := time.Now()
+	NewHandler().ServeHTTP(rr, req)
+	if elapsed := time.Since(begin); elapsed > time.Second {
+		t.Errorf("expected the resolver to be cancelled promptly, took %v", elapsed)
+	}
+	if ranAfter {
+		t.Error("expected no further resolvers to run after the disconnect")
+	}
+	if rr.Body.Len() != 0 {
+		t.Errorf("expected no response to a disconnected client, got %s", rr.Body.String())
+	}
+}
+
+func TestExecutionStopsBetweenFields(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	calls := 0
+	useResolvers(t, map[string]ResolverFunc{
+		"first": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			calls++
+			cancel()
+			return "1", nil
+		},
+		"second": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			calls++
+			return "2", nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	if _, err := executeRequest(ctx, `{ first second }`, nil); err != context.Canceled {
+		t.Errorf("expected context.Canceled, got %v", err)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single resolver call, got %d", calls)
+	}
+}
@@ -0,0 +1,213 @@
+// Package client is a small GraphQL-over-HTTP client for calling GraphQL
+// servers, including vibeGraphql servers, from Go services.
+package client
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Client sends GraphQL operations to a single endpoint.
+type Client struct {
+	url        string
+	httpClient *http.Client
+	header     http.Header
+}
+
+// Option configures a Client.
+type Option func(*Client)
+
+// WithHTTPClient sets the HTTP client (and thereby the transport) used for requests.
+func WithHTTPClient(hc *http.Client) Option {
+	return func(c *Client) { c.httpClient = hc }
+}
+
+// WithHeader adds a header sent with every request.
+func WithHeader(key, value string) Option {
+	return func(c *Client) { c.header.Add(key, value) }
+}
+
+// New creates a Client for the GraphQL endpoint at url.
+func New(url string, opts ...Option) *Client {
+	c := &Client{url: url, httpClient: http.DefaultClient, header: http.Header{}}
+	for _, opt := range opts {
+		opt(c)
+	}
+	return c
+}
+
+// Error is a single entry of a GraphQL response's "errors" array.
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Errors is returned by Do when the response contains GraphQL errors.
+type Errors []*Error
+
+func (errs Errors) Error() string {
+	msgs := make([]string, len(errs))
+	for i, e := range errs {
+		msgs[i] = e.Message
+	}
+	return "graphql: " + strings.Join(msgs, "; ")
+}
+
+// Upload is a file sent as a variable using the GraphQL multipart request spec.
+type Upload struct {
+	Filename string
+	File     io.Reader
+}
+
+// Do executes query with variables and decodes the "data" member into out.
+// If variables contain Upload values the request is sent as multipart/form-data.
+// When the server reports errors, Do decodes any partial data and returns Errors.
+func (c *Client) Do(ctx context.Context, query string, variables map[string]interface{}, out interface{}) error {
+	req, err := c.newRequest(ctx, query, variables)
+	if err != nil {
+		return err
+	}
+	resp, err := c.httpClient.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	var payload struct {
+		Data   json.RawMessage `json:"data"`
+		Errors Errors          `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return fmt.Errorf("graphql: invalid response (status %d): %v", resp.StatusCode, err)
+	}
+	if out != nil && len(payload.Data) > 0 && string(payload.Data) != "null" {
+		if err := json.Unmarshal(payload.Data, out); err != nil {
+			return fmt.Errorf("graphql: decoding data: %v", err)
+		}
+	}
+	if len(payload.Errors) > 0 {
+		return payload.Errors
+	}
+	return nil
+}
+
+func (c *Client) newRequest(ctx context.Context, query string, variables map[string]interface{}) (*http.Request, error) {
+	uploads := map[string]Upload{}
+	variables = extractUploads(variables, "variables", uploads).(map[string]interface{})
+	operations, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		return nil, err
+	}
+
+	var body io.Reader = bytes.NewReader(operations)
+	contentType := "application/json"
+	if len(uploads) > 0 {
+		buf := &bytes.Buffer{}
+		contentType, err = writeMultipart(buf, operations, uploads)
+		if err != nil {
+			return nil, err
+		}
+		body = buf
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, c.url, body)
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range c.header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", contentType)
+	req.Header.Set("Accept", "application/json")
+	return req, nil
+}
+
+// extractUploads replaces Upload values with nil, recording each one under its
+// dotted object path (e.g. "variables.files.0").
+func extractUploads(value interface{}, path string, uploads map[string]Upload) interface{} {
+	switch v := value.(type) {
+	case Upload:
+		uploads[path] = v
+		return nil
+	case *Upload:
+		uploads[path] = *v
+		return nil
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for key, elem := range v {
+			out[key] = extractUploads(elem, path+"."+key, uploads)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			out[i] = extractUploads(elem, path+"."+strconv.Itoa(i), uploads)
+		}
+		return out
+	case []Upload:
+		out := make([]interface{}, len(v))
+		for i, elem := range v {
+			uploads[path+"."+strconv.Itoa(i)] = elem
+		}
+		return out
+	case nil:
+		if path == "variables" {
+			return map[string]interface{}{}
+		}
+	}
+	return value
+}
+
+// writeMultipart encodes operations, the file map and the files themselves.
+func writeMultipart(buf *bytes.Buffer, operations []byte, uploads map[string]Upload) (string, error) {
+	paths := make([]string, 0, len(uploads))
+	for path := range uploads {
+		paths = append(paths, path)
+	}
+	sort.Strings(paths)
+	fileMap := make(map[string][]string, len(paths))
+	for i, path := range paths {
+		fileMap[strconv.Itoa(i)] = []string{path}
+	}
+	mapJSON, err := json.Marshal(fileMap)
+	if err != nil {
+		return "", err
+	}
+
+	mw := multipart.NewWriter(buf)
+	if err := mw.WriteField("operations", string(operations)); err != nil {
+		return "", err
+	}
+	if err := mw.WriteField("map", string(mapJSON)); err != nil {
+		return "", err
+	}
+	for i, path := range paths {
+		upload := uploads[path]
+		part, err := mw.CreateFormFile(strconv.Itoa(i), upload.Filename)
+		if err != nil {
+			return "", err
+		}
+		if _, err := io.Copy(part, upload.File); err != nil {
+			return "", err
+		}
+	}
+	if err := mw.Close(); err != nil {
+		return "", err
+	}
+	return mw.FormDataContentType(), nil
+}
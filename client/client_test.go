@@ -0,0 +1,87 @@
+package client
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+func TestClientDo(t *testing.T) {
+	graphql.RegisterQueryResolver("clientEcho", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return args["msg"], nil
+	})
+	var gotHeader string
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		gotHeader = r.Header.Get("Authorization")
+		graphql.GraphqlHandler(w, r)
+	}))
+	defer srv.Close()
+
+	c := New(srv.URL, WithHeader("Authorization", "Bearer t"), WithHTTPClient(srv.Client()))
+	var out struct {
+		ClientEcho string `json:"clientEcho"`
+	}
+	err := c.Do(context.Background(), `query ($m: String) { clientEcho(msg: $m) }`, map[string]interface{}{"m": "hi"}, &out)
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if out.ClientEcho != "hi" {
+		t.Errorf("expected echo 'hi', got %q", out.ClientEcho)
+	}
+	if gotHeader != "Bearer t" {
+		t.Errorf("expected Authorization header to be sent, got %q", gotHeader)
+	}
+}
+
+func TestClientErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"data":{"a":1},"errors":[{"message":"denied","path":["b"],"extensions":{"code":"PERMISSION_DENIED"}}]}`))
+	}))
+	defer srv.Close()
+
+	var out struct{ A int }
+	err := New(srv.URL).Do(context.Background(), `{ a b }`, nil, &out)
+	errs, ok := err.(Errors)
+	if !ok || len(errs) != 1 {
+		t.Fatalf("expected Errors, got %v", err)
+	}
+	if errs[0].Extensions["code"] != "PERMISSION_DENIED" || errs[0].Path[0] != "b" {
+		t.Errorf("unexpected error: %+v", errs[0])
+	}
+	if out.A != 1 {
+		t.Errorf("expected partial data to be decoded, got %+v", out)
+	}
+}
+
+func TestClientUpload(t *testing.T) {
+	graphql.RegisterMutationResolver("clientUpload", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		files := args["files"].([]graphql.Upload)
+		var names []string
+		for _, f := range files {
+			names = append(names, f.Filename)
+		}
+		return strings.Join(names, ","), nil
+	})
+	srv := httptest.NewServer(http.HandlerFunc(graphql.GraphqlUploadHandler))
+	defer srv.Close()
+
+	var out struct {
+		ClientUpload string `json:"clientUpload"`
+	}
+	err := New(srv.URL).Do(context.Background(), `mutation ($files: [Upload]) { clientUpload(files: $files) }`, map[string]interface{}{
+		"files": []Upload{
+			{Filename: "a.txt", File: strings.NewReader("aaa")},
+			{Filename: "b.txt", File: strings.NewReader("bbb")},
+		},
+	}, &out)
+	if err != nil {
+		t.Fatalf("Do error: %v", err)
+	}
+	if out.ClientUpload != "a.txt,b.txt" {
+		t.Errorf("unexpected upload result: %q", out.ClientUpload)
+	}
+}
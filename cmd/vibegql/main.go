@@ -0,0 +1,473 @@
+// Command vibegql runs, inspects and validates GraphQL operations.
+//
+// Usage:
+//
+//	vibegql exec     -url URL (-query QUERY | -file FILE) [-vars JSON]
+//	vibegql schema   -url URL
+//	vibegql validate -schema SCHEMA.graphql (-query QUERY | -file FILE)
+//	vibegql bench    -url URL (-query QUERY | -file FILE) [-vars JSON] [-n 100] [-c 10]
+//	vibegql codegen  (-schema SCHEMA.graphql | -introspection RESULT.json) [-package NAME] [-o FILE]
+//	vibegql mock     -schema SCHEMA.graphql [-addr :8080]
+//	vibegql fmt      [-w] [FILE...]
+//	vibegql lint     [-schema SCHEMA.graphql] [-max-depth 10] [-disable RULE,...] [FILE...]
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/Raezil/vibeGraphql/client"
+	"github.com/Raezil/vibeGraphql/codegen"
+	"github.com/Raezil/vibeGraphql/lint"
+)
+
+func main() {
+	os.Exit(run(os.Args[1:], os.Stdout, os.Stderr))
+}
+
+type command struct {
+	run   func(args []string, stdout, stderr io.Writer) error
+	usage string
+}
+
+var commands = map[string]command{
+	"exec":     {runExec, "execute a query against a running endpoint"},
+	"schema":   {runSchema, "print the SDL of a running endpoint via introspection"},
+	"validate": {runValidate, "validate a query against an SDL file"},
+	"bench":    {runBench, "benchmark an operation against a running endpoint"},
+	"codegen":  {runCodegen, "generate Go types and resolver stubs from a schema"},
+	"mock":     {runMock, "serve fake data for an SDL file"},
+	"fmt":      {runFmt, "reformat GraphQL documents in canonical style"},
+	"lint":     {runLint, "report deprecated fields, anonymous operations and other problems"},
+}
+
+// run executes the CLI and returns the process exit code.
+func run(args []string, stdout, stderr io.Writer) int {
+	if len(args) == 0 || commands[args[0]].run == nil {
+		printUsage(stderr)
+		return 2
+	}
+	if err := commands[args[0]].run(args[1:], stdout, stderr); err != nil {
+		fmt.Fprintf(stderr, "vibegql %s: %v\n", args[0], err)
+		return 1
+	}
+	return 0
+}
+
+func printUsage(w io.Writer) {
+	fmt.Fprintln(w, "usage: vibegql <command> [flags]")
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		fmt.Fprintf(w, "  %-9s %s\n", name, commands[name].usage)
+	}
+}
+
+// operationFlags are shared by the commands that send an operation.
+type operationFlags struct {
+	url   string
+	query string
+	file  string
+	vars  string
+}
+
+func (o *operationFlags) register(fs *flag.FlagSet, withURL bool) {
+	if withURL {
+		fs.StringVar(&o.url, "url", "", "GraphQL endpoint URL")
+	}
+	fs.StringVar(&o.query, "query", "", "query text")
+	fs.StringVar(&o.file, "file", "", "file containing the query")
+	fs.StringVar(&o.vars, "vars", "", "variables as a JSON object")
+}
+
+func (o *operationFlags) load() (string, map[string]interface{}, error) {
+	query := o.query
+	if o.file != "" {
+		data, err := os.ReadFile(o.file)
+		if err != nil {
+			return "", nil, err
+		}
+		query = string(data)
+	}
+	if query == "" {
+		return "", nil, fmt.Errorf("one of -query or -file is required")
+	}
+	var variables map[string]interface{}
+	if o.vars != "" {
+		if err := json.Unmarshal([]byte(o.vars), &variables); err != nil {
+			return "", nil, fmt.Errorf("invalid -vars: %v", err)
+		}
+	}
+	return query, variables, nil
+}
+
+func newFlagSet(name string, stderr io.Writer) *flag.FlagSet {
+	fs := flag.NewFlagSet(name, flag.ContinueOnError)
+	fs.SetOutput(stderr)
+	return fs
+}
+
+func runExec(args []string, stdout, stderr io.Writer) error {
+	var op operationFlags
+	fs := newFlagSet("exec", stderr)
+	op.register(fs, true)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if op.url == "" {
+		return fmt.Errorf("-url is required")
+	}
+	query, variables, err := op.load()
+	if err != nil {
+		return err
+	}
+	var data json.RawMessage
+	err = client.New(op.url).Do(context.Background(), query, variables, &data)
+	if len(data) > 0 {
+		out, _ := json.MarshalIndent(data, "", "  ")
+		fmt.Fprintln(stdout, string(out))
+	}
+	return err
+}
+
+func runSchema(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("schema", stderr)
+	url := fs.String("url", "", "GraphQL endpoint URL")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *url == "" {
+		return fmt.Errorf("-url is required")
+	}
+	var data json.RawMessage
+	if err := client.New(*url).Do(context.Background(), codegen.IntrospectionQuery, nil, &data); err != nil {
+		return err
+	}
+	schema, err := codegen.SchemaFromIntrospection(data)
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(stdout, graphql.PrintSchema(schema))
+	return nil
+}
+
+func loadSchemaFile(path string) (*graphql.Schema, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return graphql.ParseSchema(string(data))
+}
+
+func runValidate(args []string, stdout, stderr io.Writer) error {
+	var op operationFlags
+	fs := newFlagSet("validate", stderr)
+	schemaPath := fs.String("schema", "", "SDL schema file")
+	op.register(fs, false)
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return fmt.Errorf("-schema is required")
+	}
+	schema, err := loadSchemaFile(*schemaPath)
+	if err != nil {
+		return err
+	}
+	query, _, err := op.load()
+	if err != nil {
+		return err
+	}
+	parser := graphql.NewParser(graphql.NewLexer(query))
+	doc := parser.ParseDocument()
+	errs := parser.Errors()
+	if len(errs) == 0 {
+		errs = graphql.Validate(schema, doc)
+	}
+	for _, e := range errs {
+		fmt.Fprintln(stdout, e.Message)
+	}
+	if len(errs) > 0 {
+		return fmt.Errorf("%d validation error(s)", len(errs))
+	}
+	fmt.Fprintln(stdout, "ok")
+	return nil
+}
+
+func runBench(args []string, stdout, stderr io.Writer) error {
+	var op operationFlags
+	fs := newFlagSet("bench", stderr)
+	op.register(fs, true)
+	n := fs.Int("n", 100, "total number of requests")
+	c := fs.Int("c", 10, "number of concurrent workers")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if op.url == "" {
+		return fmt.Errorf("-url is required")
+	}
+	if *n <= 0 || *c <= 0 {
+		return fmt.Errorf("-n and -c must be positive")
+	}
+	query, variables, err := op.load()
+	if err != nil {
+		return err
+	}
+
+	cl := client.New(op.url)
+	jobs := make(chan struct{}, *n)
+	for i := 0; i < *n; i++ {
+		jobs <- struct{}{}
+	}
+	close(jobs)
+
+	var mu sync.Mutex
+	var latencies []time.Duration
+	failures := 0
+	var wg sync.WaitGroup
+	start := time.Now()
+	for w := 0; w < *c; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for range jobs {
+				t := time.Now()
+				err := cl.Do(context.Background(), query, variables, nil)
+				elapsed := time.Since(t)
+				mu.Lock()
+				latencies = append(latencies, elapsed)
+				if err != nil {
+					failures++
+				}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+	total := time.Since(start)
+
+	sort.Slice(latencies, func(i, j int) bool { return latencies[i] < latencies[j] })
+	fmt.Fprintf(stdout, "requests:   %d (%d failed)\n", len(latencies), failures)
+	fmt.Fprintf(stdout, "throughput: %.1f req/s\n", float64(len(latencies))/total.Seconds())
+	fmt.Fprintf(stdout, "p50:        %v\n", percentile(latencies, 0.50))
+	fmt.Fprintf(stdout, "p90:        %v\n", percentile(latencies, 0.90))
+	fmt.Fprintf(stdout, "p99:        %v\n", percentile(latencies, 0.99))
+	return nil
+}
+
+// percentile returns the p-th percentile of sorted durations.
+func percentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	idx := int(float64(len(sorted)-1) * p)
+	return sorted[idx]
+}
+
+func runCodegen(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("codegen", stderr)
+	schemaPath := fs.String("schema", "", "SDL schema file")
+	introspectionPath := fs.String("introspection", "", "introspection result JSON file")
+	pkg := fs.String("package", "generated", "package name of the generated file")
+	out := fs.String("o", "", "output file (default stdout)")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var schema *graphql.Schema
+	var err error
+	switch {
+	case *schemaPath != "":
+		schema, err = loadSchemaFile(*schemaPath)
+	case *introspectionPath != "":
+		var data []byte
+		data, err = os.ReadFile(*introspectionPath)
+		if err == nil {
+			schema, err = codegen.SchemaFromIntrospection(data)
+		}
+	default:
+		return fmt.Errorf("one of -schema or -introspection is required")
+	}
+	if err != nil {
+		return err
+	}
+	src, err := codegen.Generate(schema, codegen.Config{Package: *pkg})
+	if err != nil {
+		return err
+	}
+	if *out == "" {
+		_, err = stdout.Write(src)
+		return err
+	}
+	return os.WriteFile(*out, src, 0644)
+}
+
+func runMock(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("mock", stderr)
+	schemaPath := fs.String("schema", "", "SDL schema file")
+	addr := fs.String("addr", ":8080", "address to listen on")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if *schemaPath == "" {
+		return fmt.Errorf("-schema is required")
+	}
+	sdl, err := os.ReadFile(*schemaPath)
+	if err != nil {
+		return err
+	}
+	schema, err := graphql.MockSchema(string(sdl))
+	if err != nil {
+		return err
+	}
+	graphql.UseSchema(schema)
+	fmt.Fprintf(stdout, "serving mock data on %s/graphql\n", *addr)
+	return http.ListenAndServe(*addr, http.HandlerFunc(graphql.GraphqlHandler))
+}
+
+// runFmt formats each named file, or stdin when none are given. With -w the
+// files are rewritten in place instead of printed.
+func runFmt(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("fmt", stderr)
+	write := fs.Bool("w", false, "write the result back to the source files")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	if fs.NArg() == 0 {
+		if *write {
+			return fmt.Errorf("-w requires file arguments")
+		}
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		out, err := formatSource("<stdin>", string(src))
+		if err != nil {
+			return err
+		}
+		_, err = io.WriteString(stdout, out)
+		return err
+	}
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		out, err := formatSource(path, string(src))
+		if err != nil {
+			return err
+		}
+		if !*write {
+			if _, err := io.WriteString(stdout, out); err != nil {
+				return err
+			}
+			continue
+		}
+		if out == string(src) {
+			continue
+		}
+		if err := os.WriteFile(path, []byte(out), 0644); err != nil {
+			return err
+		}
+		fmt.Fprintln(stdout, path)
+	}
+	return nil
+}
+
+// formatSource formats src, reporting syntax errors as path:line:column.
+func formatSource(path, src string) (string, error) {
+	out, err := graphql.FormatQuery(src)
+	if err != nil {
+		return "", positioned(path, err)
+	}
+	return out, nil
+}
+
+// positioned prefixes err with path and, for a located *graphql.Error, the
+// line and column it occurred at.
+func positioned(path string, err error) error {
+	if gqlErr, ok := err.(*graphql.Error); ok && len(gqlErr.Locations) > 0 {
+		loc := gqlErr.Locations[0]
+		return fmt.Errorf("%s:%d:%d: %s", path, loc.Line, loc.Column, gqlErr.Message)
+	}
+	return fmt.Errorf("%s: %v", path, err)
+}
+
+// runLint lints each named file, or stdin when none are given, printing one
+// line per diagnostic. It fails if any diagnostic has error severity.
+func runLint(args []string, stdout, stderr io.Writer) error {
+	fs := newFlagSet("lint", stderr)
+	schemaPath := fs.String("schema", "", "SDL schema file, needed to report deprecated fields")
+	maxDepth := fs.Int("max-depth", lint.DefaultMaxDepth, "maximum nesting of selections, 0 for no limit")
+	disable := fs.String("disable", "", "comma-separated names of rules to skip")
+	if err := fs.Parse(args); err != nil {
+		return err
+	}
+	var schema *graphql.Schema
+	if *schemaPath != "" {
+		var err error
+		if schema, err = loadSchemaFile(*schemaPath); err != nil {
+			return err
+		}
+	}
+	skip := make(map[string]bool)
+	for _, name := range strings.Split(*disable, ",") {
+		skip[strings.TrimSpace(name)] = true
+	}
+	var rules []lint.Rule
+	for _, rule := range lint.DefaultRules() {
+		if rule.Name == "max-depth" {
+			rule = lint.MaxDepth(*maxDepth)
+		}
+		if !skip[rule.Name] {
+			rules = append(rules, rule)
+		}
+	}
+
+	type source struct{ path, text string }
+	var sources []source
+	if fs.NArg() == 0 {
+		src, err := io.ReadAll(os.Stdin)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{"<stdin>", string(src)})
+	}
+	for _, path := range fs.Args() {
+		src, err := os.ReadFile(path)
+		if err != nil {
+			return err
+		}
+		sources = append(sources, source{path, string(src)})
+	}
+	failed := 0
+	for _, src := range sources {
+		parser := graphql.NewParser(graphql.NewLexer(src.text))
+		doc := parser.ParseDocument()
+		if errs := parser.Errors(); len(errs) > 0 {
+			return positioned(src.path, errs[0])
+		}
+		for _, d := range lint.Lint(schema, doc, rules) {
+			fmt.Fprintf(stdout, "%s:%s\n", src.path, d)
+			if d.Severity == lint.Error {
+				failed++
+			}
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d error(s)", failed)
+	}
+	return nil
+}
@@ -0,0 +1,158 @@
+package main
+
+import (
+	"bytes"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+const testSDL = `type Query { hello(name: String): String }`
+
+func writeFile(t *testing.T, name, content string) string {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), name)
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+	return path
+}
+
+func TestRunUsage(t *testing.T) {
+	var stderr bytes.Buffer
+	if code := run(nil, &bytes.Buffer{}, &stderr); code != 2 {
+		t.Errorf("expected exit code 2, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), "validate") {
+		t.Errorf("expected usage to list commands, got %q", stderr.String())
+	}
+}
+
+func TestRunValidate(t *testing.T) {
+	schemaPath := writeFile(t, "schema.graphql", testSDL)
+	var stdout bytes.Buffer
+	if code := run([]string{"validate", "-schema", schemaPath, "-query", `{ hello(name: "x") }`}, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Errorf("expected valid query, got exit %d: %s", code, stdout.String())
+	}
+	stdout.Reset()
+	if code := run([]string{"validate", "-schema", schemaPath, "-query", `{ goodbye }`}, &stdout, &bytes.Buffer{}); code != 1 {
+		t.Errorf("expected exit code 1 for invalid query, got %d", code)
+	}
+	if !strings.Contains(stdout.String(), `Cannot query field "goodbye"`) {
+		t.Errorf("unexpected validation output: %q", stdout.String())
+	}
+}
+
+func TestRunExecSchemaAndBench(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphql.UseSchema(schema)
+	defer graphql.UseSchema(nil)
+	graphql.RegisterQueryResolver("hello", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hello " + args["name"].(string), nil
+	})
+	srv := httptest.NewServer(http.HandlerFunc(graphql.GraphqlHandler))
+	defer srv.Close()
+
+	var stdout bytes.Buffer
+	if code := run([]string{"exec", "-url", srv.URL, "-query", `query ($n: String) { hello(name: $n) }`, "-vars", `{"n":"cli"}`}, &stdout, os.Stderr); code != 0 {
+		t.Fatalf("exec failed with exit %d", code)
+	}
+	if !strings.Contains(stdout.String(), `"hello cli"`) {
+		t.Errorf("unexpected exec output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"schema", "-url", srv.URL}, &stdout, os.Stderr); code != 0 {
+		t.Fatalf("schema failed with exit %d", code)
+	}
+	if !strings.Contains(stdout.String(), "hello(name: String): String") {
+		t.Errorf("unexpected schema output: %s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"bench", "-url", srv.URL, "-query", `{ hello(name: "b") }`, "-n", "5", "-c", "2"}, &stdout, os.Stderr); code != 0 {
+		t.Fatalf("bench failed with exit %d", code)
+	}
+	if !strings.Contains(stdout.String(), "requests:   5 (0 failed)") {
+		t.Errorf("unexpected bench output: %s", stdout.String())
+	}
+}
+
+func TestRunCodegen(t *testing.T) {
+	schemaPath := writeFile(t, "schema.graphql", testSDL)
+	var stdout bytes.Buffer
+	if code := run([]string{"codegen", "-schema", schemaPath, "-package", "api"}, &stdout, os.Stderr); code != 0 {
+		t.Fatalf("codegen failed with exit %d", code)
+	}
+	if !strings.Contains(stdout.String(), "type QueryResolver interface") {
+		t.Errorf("unexpected codegen output: %s", stdout.String())
+	}
+}
+
+func TestRunFmt(t *testing.T) {
+	path := writeFile(t, "query.graphql", "query Q($id: ID!){user(id:$id){name ...F}}\nfragment F on User{email}")
+	want := "query Q($id: ID!) {\n  user(id: $id) {\n    name\n    ...F\n  }\n}\n\nfragment F on User {\n  email\n}\n"
+
+	var stdout bytes.Buffer
+	if code := run([]string{"fmt", path}, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if stdout.String() != want {
+		t.Errorf("unexpected output:\n%s", stdout.String())
+	}
+
+	stdout.Reset()
+	if code := run([]string{"fmt", "-w", path}, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Fatalf("expected exit code 0, got %d", code)
+	}
+	if got, _ := os.ReadFile(path); string(got) != want {
+		t.Errorf("file not rewritten:\n%s", got)
+	}
+	if stdout.String() != path+"\n" {
+		t.Errorf("expected the rewritten file to be listed, got %q", stdout.String())
+	}
+
+	bad := writeFile(t, "bad.graphql", "{\n  user(id: 1 }")
+	var stderr bytes.Buffer
+	if code := run([]string{"fmt", bad}, &bytes.Buffer{}, &stderr); code != 1 {
+		t.Errorf("expected exit code 1 for a syntax error, got %d", code)
+	}
+	if !strings.Contains(stderr.String(), bad+":2:14: Syntax Error") {
+		t.Errorf("expected a positioned error, got %q", stderr.String())
+	}
+}
+
+func TestRunLint(t *testing.T) {
+	schemaPath := writeFile(t, "schema.graphql", `type Query { hello(name: String): String, hi: String @deprecated }`)
+	path := writeFile(t, "query.graphql", "{ hi }\nquery Q($name: String) { hello }")
+
+	var stdout bytes.Buffer
+	if code := run([]string{"lint", "-schema", schemaPath, path}, &stdout, &bytes.Buffer{}); code != 1 {
+		t.Errorf("expected exit code 1 for an unused variable, got %d", code)
+	}
+	for _, want := range []string{
+		path + ":1:1: warning: Anonymous query should be given a name. (operation-name)",
+		path + ":1:3: warning: The field Query.hi is deprecated: No longer supported (deprecated-field)",
+		path + `:2:9: error: Variable "$name" is never used in operation "Q". (unused-variable)`,
+	} {
+		if !strings.Contains(stdout.String(), want) {
+			t.Errorf("expected %q in output:\n%s", want, stdout.String())
+		}
+	}
+
+	stdout.Reset()
+	if code := run([]string{"lint", "-disable", "unused-variable,operation-name", path}, &stdout, &bytes.Buffer{}); code != 0 {
+		t.Errorf("expected exit code 0 with the rules disabled, got %d: %s", code, stdout.String())
+	}
+	if stdout.Len() != 0 {
+		t.Errorf("expected no diagnostics, got %q", stdout.String())
+	}
+}
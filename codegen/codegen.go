@@ -0,0 +1,400 @@
+// Package codegen generates typed Go code from a GraphQL schema: structs for
+// object and input types, enums, argument structs for root fields and
+// resolver interfaces that bind into vibeGraphql's resolver registry.
+//
+// It is typically driven from a go:generate directive:
+//
+//	//go:generate vibegql codegen -schema schema.graphql -package api -o generated.go
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"go/format"
+	"sort"
+	"strings"
+	"unicode"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+// Config controls code generation.
+type Config struct {
+	// Package is the package name of the generated file.
+	Package string
+	// Scalars maps custom scalar names to Go types; unmapped scalars use interface{}.
+	Scalars map[string]string
+}
+
+var builtinScalarTypes = map[string]string{
+	"Int":     "int",
+	"Float":   "float64",
+	"String":  "string",
+	"Boolean": "bool",
+	"ID":      "string",
+}
+
+// Generate returns gofmt-ed Go source for the given schema.
+func Generate(schema *graphql.Schema, cfg Config) ([]byte, error) {
+	if cfg.Package == "" {
+		cfg.Package = "generated"
+	}
+	var body bytes.Buffer
+	g := &generator{schema: schema, cfg: cfg, out: &body}
+
+	roots := map[string]bool{schema.QueryType: true, schema.MutationType: true, schema.SubscriptionType: true}
+	names := make([]string, 0, len(schema.Types))
+	for name := range schema.Types {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	hasRoots := false
+	for _, name := range names {
+		td := schema.Types[name]
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		switch {
+		case roots[name]:
+			g.rootType(td)
+			hasRoots = true
+		case td.Kind == graphql.KindEnum:
+			g.enumType(td)
+		case td.Kind == graphql.KindInputObject:
+			g.inputType(td)
+		case td.Kind == graphql.KindObject || td.Kind == "" || td.Kind == graphql.KindInterface:
+			g.objectType(td)
+		}
+	}
+
+	var file bytes.Buffer
+	file.WriteString("// Code generated by vibegql codegen. DO NOT EDIT.\n\n")
+	fmt.Fprintf(&file, "package %s\n\n", cfg.Package)
+	if hasRoots {
+		file.WriteString("import (\n\t\"context\"\n\t\"encoding/json\"\n\n\tgraphql \"github.com/Raezil/vibeGraphql\"\n)\n\n")
+	}
+	file.Write(body.Bytes())
+	if hasRoots {
+		file.WriteString(decodeArgsFunc)
+	}
+	src, err := format.Source(file.Bytes())
+	if err != nil {
+		return nil, fmt.Errorf("codegen: formatting generated code: %v", err)
+	}
+	return src, nil
+}
+
+const decodeArgsFunc = `
+// decodeArgs converts resolver arguments into a typed argument struct.
+func decodeArgs(args map[string]interface{}, out interface{}) error {
+	raw, err := json.Marshal(args)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(raw, out)
+}
+`
+
+type generator struct {
+	schema *graphql.Schema
+	cfg    Config
+	out    *bytes.Buffer
+}
+
+func (g *generator) printf(format string, args ...interface{}) {
+	fmt.Fprintf(g.out, format, args...)
+}
+
+func (g *generator) objectType(td *graphql.TypeDefinition) {
+	g.printf("// %s is the Go representation of the GraphQL type %s.\n", goName(td.Name), td.Name)
+	g.printf("type %s struct {\n", goName(td.Name))
+	for _, f := range td.Fields {
+		g.printf("\t%s %s `json:\"%s\"`\n", goName(f.Name), g.goType(f.Type), f.Name)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) inputType(td *graphql.TypeDefinition) {
+	g.printf("// %s is the Go representation of the GraphQL input %s.\n", goName(td.Name), td.Name)
+	g.printf("type %s struct {\n", goName(td.Name))
+	for _, f := range td.InputFields {
+		g.printf("\t%s %s `json:\"%s\"`\n", goName(f.Name), g.goType(f.Type), f.Name)
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) enumType(td *graphql.TypeDefinition) {
+	name := goName(td.Name)
+	g.printf("// %s is the GraphQL enum %s.\n", name, td.Name)
+	g.printf("type %s string\n\n", name)
+	if len(td.EnumValues) == 0 {
+		return
+	}
+	g.printf("const (\n")
+	for _, v := range td.EnumValues {
+		g.printf("\t%s%s %s = %q\n", name, goName(strings.ToLower(v.Name)), name, v.Name)
+	}
+	g.printf(")\n\n")
+}
+
+// rootType emits argument structs, a resolver interface and a registration
+// function for a root operation type.
+func (g *generator) rootType(td *graphql.TypeDefinition) {
+	root := goName(td.Name)
+	for _, f := range td.Fields {
+		if len(f.ArgumentDefinitions) == 0 {
+			continue
+		}
+		g.printf("// %s%sArgs holds the arguments of %s.%s.\n", root, goName(f.Name), td.Name, f.Name)
+		g.printf("type %s%sArgs struct {\n", root, goName(f.Name))
+		for _, arg := range f.ArgumentDefinitions {
+			g.printf("\t%s %s `json:\"%s\"`\n", goName(arg.Name), g.goType(arg.Type), arg.Name)
+		}
+		g.printf("}\n\n")
+	}
+
+	g.printf("// %sResolver resolves the fields of the %s type.\n", root, td.Name)
+	g.printf("type %sResolver interface {\n", root)
+	for _, f := range td.Fields {
+		g.printf("\t%s(%s) (%s, error)\n", goName(f.Name), g.params(root, f), g.resultType(td.Name, f))
+	}
+	g.printf("}\n\n")
+
+	g.printf("// Register%sResolver binds r into the vibeGraphql resolver registry.\n", root)
+	g.printf("func Register%sResolver(r %sResolver) {\n", root, root)
+	for _, f := range td.Fields {
+		g.printf("\tgraphql.RegisterFieldResolver(%q, %q, func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {\n", td.Name, f.Name)
+		if len(f.ArgumentDefinitions) > 0 {
+			g.printf("\t\tvar typed %s%sArgs\n", root, goName(f.Name))
+			g.printf("\t\tif err := decodeArgs(args, &typed); err != nil {\n\t\t\treturn nil, err\n\t\t}\n")
+			g.printf("\t\treturn r.%s(ctx, typed)\n", goName(f.Name))
+		} else {
+			g.printf("\t\treturn r.%s(ctx)\n", goName(f.Name))
+		}
+		g.printf("\t})\n")
+	}
+	g.printf("}\n\n")
+}
+
+func (g *generator) params(root string, f *graphql.Field) string {
+	if len(f.ArgumentDefinitions) == 0 {
+		return "ctx context.Context"
+	}
+	return fmt.Sprintf("ctx context.Context, args %s%sArgs", root, goName(f.Name))
+}
+
+// resultType returns the Go result type of a root field. Subscription fields
+// return a channel of events.
+func (g *generator) resultType(typeName string, f *graphql.Field) string {
+	if typeName == g.schema.SubscriptionType {
+		return "<-chan interface{}"
+	}
+	return g.goType(f.Type)
+}
+
+// goType maps a GraphQL type reference to a Go type. Nullable scalars and
+// enums become pointers, object types are always pointers.
+func (g *generator) goType(t *graphql.Type) string {
+	if t == nil {
+		return "interface{}"
+	}
+	if t.IsList {
+		return "[]" + g.goType(t.Elem)
+	}
+	var base string
+	pointer := !t.NonNull
+	if scalar, ok := builtinScalarTypes[t.Name]; ok {
+		base = scalar
+	} else if custom, ok := g.cfg.Scalars[t.Name]; ok {
+		base = custom
+	} else if td := g.schema.Types[t.Name]; td != nil {
+		switch td.Kind {
+		case graphql.KindScalar, graphql.KindUnion:
+			return "interface{}"
+		case graphql.KindInterface:
+			return goName(t.Name)
+		case graphql.KindEnum, graphql.KindInputObject:
+			base = goName(t.Name)
+		default:
+			base = goName(t.Name)
+			pointer = true
+		}
+	} else {
+		return "interface{}"
+	}
+	if pointer {
+		return "*" + base
+	}
+	return base
+}
+
+// commonInitialisms are upper-cased entirely when they form a whole name segment.
+var commonInitialisms = map[string]string{"id": "ID", "url": "URL", "api": "API", "http": "HTTP", "json": "JSON", "uuid": "UUID"}
+
+// goName converts a GraphQL name into an exported Go identifier.
+func goName(name string) string {
+	if initialism, ok := commonInitialisms[strings.ToLower(name)]; ok {
+		return initialism
+	}
+	parts := strings.FieldsFunc(name, func(r rune) bool { return r == '_' })
+	var sb strings.Builder
+	for _, part := range parts {
+		runes := []rune(part)
+		runes[0] = unicode.ToUpper(runes[0])
+		sb.WriteString(string(runes))
+	}
+	if sb.Len() == 0 {
+		return "X"
+	}
+	out := sb.String()
+	if strings.HasSuffix(out, "Id") {
+		out = strings.TrimSuffix(out, "Id") + "ID"
+	}
+	return out
+}
+
+// SchemaFromIntrospection builds a Schema from the JSON result of a standard
+// introspection query. Both the full response ({"data": {"__schema": ...}})
+// and the bare data object are accepted.
+func SchemaFromIntrospection(data []byte) (*graphql.Schema, error) {
+	var payload struct {
+		Data   *introspectionData   `json:"data"`
+		Schema *introspectionSchema `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &payload); err != nil {
+		return nil, fmt.Errorf("codegen: invalid introspection JSON: %v", err)
+	}
+	is := payload.Schema
+	if payload.Data != nil {
+		is = payload.Data.Schema
+	}
+	if is == nil {
+		return nil, fmt.Errorf("codegen: introspection result has no __schema")
+	}
+
+	doc := &graphql.Document{}
+	for _, t := range is.Types {
+		if strings.HasPrefix(t.Name, "__") || builtinScalarTypes[t.Name] != "" {
+			continue
+		}
+		td := &graphql.TypeDefinition{Kind: t.Kind, Name: t.Name}
+		for _, f := range t.Fields {
+			field := &graphql.Field{Name: f.Name, Type: f.Type.toType()}
+			for _, arg := range f.Args {
+				field.ArgumentDefinitions = append(field.ArgumentDefinitions, &graphql.InputValueDefinition{Name: arg.Name, Type: arg.Type.toType()})
+			}
+			td.Fields = append(td.Fields, field)
+		}
+		for _, f := range t.InputFields {
+			td.InputFields = append(td.InputFields, &graphql.InputValueDefinition{Name: f.Name, Type: f.Type.toType()})
+		}
+		for _, v := range t.EnumValues {
+			td.EnumValues = append(td.EnumValues, &graphql.EnumValueDefinition{Name: v.Name})
+		}
+		for _, iface := range t.Interfaces {
+			td.Interfaces = append(td.Interfaces, iface.Name)
+		}
+		if t.Kind == graphql.KindUnion {
+			for _, member := range t.PossibleTypes {
+				td.Types = append(td.Types, member.Name)
+			}
+		}
+		doc.Definitions = append(doc.Definitions, td)
+	}
+	schema, err := graphql.NewSchema(doc)
+	if err != nil {
+		return nil, err
+	}
+	if is.QueryType != nil {
+		schema.QueryType = is.QueryType.Name
+	}
+	if is.MutationType != nil {
+		schema.MutationType = is.MutationType.Name
+	}
+	if is.SubscriptionType != nil {
+		schema.SubscriptionType = is.SubscriptionType.Name
+	}
+	return schema, nil
+}
+
+type introspectionData struct {
+	Schema *introspectionSchema `json:"__schema"`
+}
+
+type introspectionSchema struct {
+	QueryType        *introspectionTypeRef `json:"queryType"`
+	MutationType     *introspectionTypeRef `json:"mutationType"`
+	SubscriptionType *introspectionTypeRef `json:"subscriptionType"`
+	Types            []struct {
+		Kind   string `json:"kind"`
+		Name   string `json:"name"`
+		Fields []struct {
+			Name string                `json:"name"`
+			Type *introspectionTypeRef `json:"type"`
+			Args []struct {
+				Name string                `json:"name"`
+				Type *introspectionTypeRef `json:"type"`
+			} `json:"args"`
+		} `json:"fields"`
+		InputFields []struct {
+			Name string                `json:"name"`
+			Type *introspectionTypeRef `json:"type"`
+		} `json:"inputFields"`
+		EnumValues []struct {
+			Name string `json:"name"`
+		} `json:"enumValues"`
+		Interfaces    []introspectionTypeRef `json:"interfaces"`
+		PossibleTypes []introspectionTypeRef `json:"possibleTypes"`
+	} `json:"types"`
+}
+
+type introspectionTypeRef struct {
+	Kind   string                `json:"kind"`
+	Name   string                `json:"name"`
+	OfType *introspectionTypeRef `json:"ofType"`
+}
+
+func (r *introspectionTypeRef) toType() *graphql.Type {
+	if r == nil {
+		return nil
+	}
+	switch r.Kind {
+	case "NON_NULL":
+		t := r.OfType.toType()
+		if t != nil {
+			t.NonNull = true
+		}
+		return t
+	case "LIST":
+		return &graphql.Type{IsList: true, Elem: r.OfType.toType()}
+	}
+	return &graphql.Type{Name: r.Name}
+}
+
+// typeRefSelection selects a type reference up to four wrapper levels deep,
+// enough for types such as [[String!]!]!.
+const typeRefSelection = "{ kind name ofType { kind name ofType { kind name ofType { kind name ofType { kind name } } } } }"
+
+// IntrospectionQuery fetches everything SchemaFromIntrospection needs.
+const IntrospectionQuery = `query IntrospectionQuery {
+  __schema {
+    queryType { name }
+    mutationType { name }
+    subscriptionType { name }
+    types {
+      kind
+      name
+      fields(includeDeprecated: true) {
+        name
+        args { name type ` + typeRefSelection + ` }
+        type ` + typeRefSelection + `
+      }
+      inputFields { name type ` + typeRefSelection + ` }
+      enumValues(includeDeprecated: true) { name }
+      interfaces { name }
+      possibleTypes { name }
+    }
+  }
+}`
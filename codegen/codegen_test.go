@@ -0,0 +1,113 @@
+package codegen
+
+import (
+	"bytes"
+	"encoding/json"
+	"net/http/httptest"
+	"os"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+const testSDL = `
+	enum Role { ADMIN GUEST }
+	input UserFilter { role: Role, limit: Int }
+	type User { id: ID! name: String age: Int! friends: [User!] role: Role }
+	type Query {
+		user(id: ID!): User
+		users(filter: UserFilter): [User!]!
+		count: Int!
+	}
+	type Mutation { rename(id: ID!, name: String!): User }
+`
+
+func TestGenerate(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	src, err := Generate(schema, Config{Package: "api"})
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	code := string(src)
+	for _, want := range []string{
+		"package api",
+		"type User struct {",
+		"ID      string  `json:\"id\"`",
+		"Friends []*User `json:\"friends\"`",
+		"type Role string",
+		`RoleAdmin Role = "ADMIN"`,
+		"type UserFilter struct {",
+		"type QueryUserArgs struct {",
+		"User(ctx context.Context, args QueryUserArgs) (*User, error)",
+		"Count(ctx context.Context) (int, error)",
+		"func RegisterQueryResolver(r QueryResolver) {",
+		`graphql.RegisterFieldResolver("Mutation", "rename"`,
+	} {
+		if !strings.Contains(code, want) {
+			t.Errorf("generated code missing %q:\n%s", want, code)
+		}
+	}
+}
+
+// TestGenerate_Example checks that the generated code of the example
+// package, whose tests run the bindings, is up to date.
+func TestGenerate_Example(t *testing.T) {
+	sdl, err := os.ReadFile("internal/example/schema.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graphql.ParseSchema(string(sdl))
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	src, err := Generate(schema, Config{Package: "example"})
+	if err != nil {
+		t.Fatalf("Generate error: %v", err)
+	}
+	want, err := os.ReadFile("internal/example/generated.go")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(src, want) {
+		t.Errorf("internal/example/generated.go is stale; run go generate ./codegen/...:\n%s", src)
+	}
+	if !bytes.Contains(src, []byte(`graphql.RegisterFieldResolver("Subscription", "messageAdded", func(ctx context.Context,`)) {
+		t.Errorf("expected subscription resolvers to receive the request context:\n%s", src)
+	}
+}
+
+func TestSchemaFromIntrospection(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	graphql.UseSchema(schema)
+	defer graphql.UseSchema(nil)
+
+	query := IntrospectionQuery
+	body, _ := json.Marshal(map[string]interface{}{"query": query})
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+
+	rebuilt, err := SchemaFromIntrospection(w.Body.Bytes())
+	if err != nil {
+		t.Fatalf("SchemaFromIntrospection error: %v", err)
+	}
+	if rebuilt.QueryType != "Query" || rebuilt.MutationType != "Mutation" {
+		t.Errorf("unexpected root types: %q %q", rebuilt.QueryType, rebuilt.MutationType)
+	}
+	users := rebuilt.Field("Query", "users")
+	if users == nil || !users.Type.IsList || !users.Type.NonNull || users.Type.Elem.Name != "User" {
+		t.Fatalf("unexpected Query.users: %+v", users)
+	}
+	if _, err := Generate(rebuilt, Config{}); err != nil {
+		t.Errorf("Generate from introspection error: %v", err)
+	}
+	if _, err := SchemaFromIntrospection([]byte(`{}`)); err == nil {
+		t.Error("expected error without __schema")
+	}
+}
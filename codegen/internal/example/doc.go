@@ -0,0 +1,5 @@
+// Package example is generated from schema.graphql and exercises the
+// generated resolver bindings in tests.
+package example
+
+//go:generate go run ../../../cmd/vibegql codegen -schema schema.graphql -package example -o generated.go
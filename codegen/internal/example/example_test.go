@@ -0,0 +1,48 @@
+package example
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/Raezil/vibeGraphql/graphqltest"
+)
+
+type resolver struct{}
+
+func (resolver) Messages(ctx context.Context, args QueryMessagesArgs) ([]*Message, error) {
+	return []*Message{{ID: "1", Room: args.Room, Text: "hello"}}, nil
+}
+
+func (resolver) MessageAdded(ctx context.Context, args SubscriptionMessageAddedArgs) (<-chan interface{}, error) {
+	ch := make(chan interface{}, 1)
+	ch <- &Message{ID: "2", Room: args.Room, Text: "welcome"}
+	close(ch)
+	return ch, nil
+}
+
+func TestGeneratedResolvers(t *testing.T) {
+	sdl, err := os.ReadFile("schema.graphql")
+	if err != nil {
+		t.Fatal(err)
+	}
+	schema, err := graphql.ParseSchema(string(sdl))
+	if err != nil {
+		t.Fatal(err)
+	}
+	RegisterQueryResolver(resolver{})
+	RegisterSubscriptionResolver(resolver{})
+	defer delete(graphql.FieldResolvers, "Query")
+	defer delete(graphql.FieldResolvers, "Subscription")
+	srv := graphqltest.NewTestServer(schema)
+	defer srv.Close()
+
+	res := srv.ExecuteQuery(t, `{ messages(room: "general") { id text } }`, nil)
+	graphqltest.MustMatchJSON(t, res.Data, `{"messages": [{"id": "1", "text": "hello"}]}`)
+
+	sub := srv.Subscribe(t, `subscription { messageAdded(room: "general") { id text } }`, nil)
+	defer sub.Close()
+	graphqltest.MustMatchJSON(t, sub.MustNext(t, time.Second), `{"id": "2", "room": "general", "text": "welcome"}`)
+}
@@ -0,0 +1,87 @@
+// Package coderadapter serves vibeGraphql subscriptions over
+// github.com/coder/websocket (formerly nhooyr.io/websocket) instead of
+// gorilla/websocket.
+package coderadapter
+
+import (
+	"context"
+	"net/http"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+// Upgrader upgrades connections with websocket.Accept. Pass it to
+// graphql.WithUpgrader.
+type Upgrader struct {
+	// Options are passed to websocket.Accept. A nil value accepts only
+	// same-origin requests.
+	Options *websocket.AcceptOptions
+}
+
+// Upgrade accepts the WebSocket handshake of r. On failure, Accept has
+// already written an error response.
+func (u Upgrader) Upgrade(w http.ResponseWriter, r *http.Request) (graphql.WebSocketConn, error) {
+	c, err := websocket.Accept(w, r, u.Options)
+	if err != nil {
+		return nil, err
+	}
+	return &Conn{c: c, ctx: r.Context()}, nil
+}
+
+// Conn adapts a *websocket.Conn to graphql.WebSocketConn. Reads and writes
+// are bound to the context of the request it was upgraded from.
+type Conn struct {
+	c   *websocket.Conn
+	ctx context.Context
+}
+
+// NewConn wraps c, binding its reads and writes to ctx.
+func NewConn(ctx context.Context, c *websocket.Conn) *Conn {
+	return &Conn{c: c, ctx: ctx}
+}
+
+// ReadMessage reads the next data message.
+func (c *Conn) ReadMessage() (int, []byte, error) {
+	typ, p, err := c.c.Read(c.ctx)
+	return int(typ), p, err
+}
+
+// WriteMessage writes a data message. The message types of
+// coder/websocket are numbered like graphql.TextMessage.
+func (c *Conn) WriteMessage(messageType int, data []byte) error {
+	return c.c.Write(c.ctx, websocket.MessageType(messageType), data)
+}
+
+// WriteJSON writes v as a JSON text message.
+func (c *Conn) WriteJSON(v interface{}) error {
+	return wsjson.Write(c.ctx, c.c, v)
+}
+
+// Close closes the connection with a normal closure status.
+func (c *Conn) Close() error {
+	return c.c.Close(websocket.StatusNormalClosure, "")
+}
+
+// SubscriptionHandler serves subscriptions over coder/websocket, configured
+// by opts.
+func SubscriptionHandler(opts ...graphql.HandlerOption) http.Handler {
+	return graphql.NewSubscriptionHandler(append([]graphql.HandlerOption{graphql.WithUpgrader(Upgrader{})}, opts...)...)
+}
+
+// UpgradeCompressed accepts the handshake like Upgrade, negotiating
+// permessage-deflate without context takeover. coder/websocket applies
+// c.Threshold itself and does not support c.Level.
+func (u Upgrader) UpgradeCompressed(w http.ResponseWriter, r *http.Request, c graphql.Compression) (graphql.WebSocketConn, error) {
+	var opts websocket.AcceptOptions
+	if u.Options != nil {
+		opts = *u.Options
+	}
+	opts.CompressionMode = websocket.CompressionNoContextTakeover
+	opts.CompressionThreshold = c.Threshold
+	if opts.CompressionThreshold == 0 {
+		opts.CompressionThreshold = graphql.DefaultCompressionThreshold
+	}
+	return Upgrader{Options: &opts}.Upgrade(w, r)
+}
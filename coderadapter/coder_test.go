@@ -0,0 +1,90 @@
+package coderadapter
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/coder/websocket"
+	"github.com/coder/websocket/wsjson"
+)
+
+func TestSubscriptionHandler(t *testing.T) {
+	graphql.RegisterSubscriptionResolver("coderTicks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 2)
+		ch <- 1
+		ch <- 2
+		close(ch)
+		return ch, nil
+	})
+	srv := httptest.NewServer(SubscriptionHandler())
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, _, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	if err := wsjson.Write(ctx, conn, graphql.SubscriptionRequest{Query: "subscription { coderTicks }"}); err != nil {
+		t.Fatal(err)
+	}
+	for _, want := range []string{"1\n", "2\n"} {
+		typ, msg, err := conn.Read(ctx)
+		if err != nil || typ != websocket.MessageText || string(msg) != want {
+			t.Errorf("unexpected event %v %q, %v; want %q", typ, msg, err, want)
+		}
+	}
+	if _, _, err := conn.Read(ctx); websocket.CloseStatus(err) != websocket.StatusNormalClosure {
+		t.Errorf("expected a normal closure, got %v", err)
+	}
+}
+
+func TestSubscriptionHandler_Rejected(t *testing.T) {
+	srv := httptest.NewServer(SubscriptionHandler())
+	defer srv.Close()
+	resp, err := srv.Client().Get(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 400 {
+		t.Errorf("expected a plain HTTP request to be rejected, got %d", resp.StatusCode)
+	}
+}
+
+func TestSubscriptionHandler_Compression(t *testing.T) {
+	large := strings.Repeat("event ", 100)
+	graphql.RegisterSubscriptionResolver("coderLarge", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- large
+		close(ch)
+		return ch, nil
+	})
+	srv := httptest.NewServer(SubscriptionHandler(graphql.WithCompression(graphql.Compression{Threshold: 64})))
+	defer srv.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	conn, resp, err := websocket.Dial(ctx, "ws"+strings.TrimPrefix(srv.URL, "http"), &websocket.DialOptions{
+		CompressionMode: websocket.CompressionNoContextTakeover,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.CloseNow()
+	if ext := resp.Header.Get("Sec-WebSocket-Extensions"); !strings.HasPrefix(ext, "permessage-deflate") {
+		t.Errorf("expected permessage-deflate to be negotiated, got %q", ext)
+	}
+	if err := wsjson.Write(ctx, conn, graphql.SubscriptionRequest{Query: "subscription { coderLarge }"}); err != nil {
+		t.Fatal(err)
+	}
+	var event string
+	if err := wsjson.Read(ctx, conn, &event); err != nil || event != large {
+		t.Errorf("unexpected event %q, %v", event, err)
+	}
+}
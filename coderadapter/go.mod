@@ -0,0 +1,12 @@
+module github.com/Raezil/vibeGraphql/coderadapter
+
+go 1.23.0
+
+require (
+	github.com/Raezil/vibeGraphql v0.0.0
+	github.com/coder/websocket v1.8.14
+)
+
+require github.com/gorilla/websocket v1.5.3 // indirect
+
+replace github.com/Raezil/vibeGraphql => ../
@@ -0,0 +1,338 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// coerceVariables converts the variables of op to the Go types resolvers
+// receive for the same values written inline: Int becomes int, Float becomes
+// float64 and ID becomes string. This matters because encoding/json decodes
+// every JSON number as float64. When s is set, fields of input objects are
+// coerced according to their definitions as well. variables is not modified.
+func coerceVariables(s *Schema, op *OperationDefinition, variables map[string]interface{}) (map[string]interface{}, []*Error) {
+	if len(op.VariableDefinitions) == 0 {
+		return variables, nil
+	}
+	coerced := make(map[string]interface{}, len(variables))
+	for name, value := range variables {
+		coerced[name] = value
+	}
+	var errs []*Error
+	for _, def := range op.VariableDefinitions {
+		value, ok := variables[def.Variable]
+		if !ok {
+			if def.Type.NonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" of required type %q was not provided.", def.Variable, typeString(&def.Type))})
+			}
+			continue
+		}
+		if value == nil {
+			if def.Type.NonNull {
+				errs = append(errs, &Error{Message: fmt.Sprintf("Variable \"$%s\" of non-null type %q must not be null.", def.Variable, typeString(&def.Type))})
+			}
+			continue
+		}
+		v, err := coerceInputValue(s, &def.Type, value)
+		if err != nil {
+			errs = append(errs, &Error{Message: variableError(def.Variable, err)})
+			continue
+		}
+		coerced[def.Variable] = v
+	}
+	return coerced, errs
+}
+
+// inputError is an invalid value found within an input value, at path: the
+// input object fields and list indexes leading to it.
+type inputError struct {
+	path  []interface{}
+	value interface{}
+	err   error
+}
+
+func (e *inputError) Error() string {
+	if len(e.path) == 0 {
+		return e.err.Error()
+	}
+	return fmt.Sprintf("at %q: %v", inputPath(e.path), e.err)
+}
+
+// variableError formats the error of coercing the variable name like
+// graphql-js does, pointing at the invalid part of the value.
+func variableError(name string, err error) string {
+	ie, ok := err.(*inputError)
+	if !ok {
+		return fmt.Sprintf("Variable \"$%s\" got invalid value; %v", name, err)
+	}
+	at := ""
+	if len(ie.path) > 0 {
+		at = fmt.Sprintf(" at \"%s%s\"", name, inputPath(ie.path))
+	}
+	return fmt.Sprintf("Variable \"$%s\" got invalid value %s%s; %v", name, jsonString(ie.value), at, ie.err)
+}
+
+// inputPath formats path as a suffix such as ".filter.ids[1]".
+func inputPath(path []interface{}) string {
+	var b strings.Builder
+	for _, key := range path {
+		if i, ok := key.(int); ok {
+			fmt.Fprintf(&b, "[%d]", i)
+			continue
+		}
+		fmt.Fprintf(&b, ".%s", key)
+	}
+	return b.String()
+}
+
+// coerceInputValue coerces a variable value to the input type t. Errors are
+// *inputError values locating the invalid part of value.
+func coerceInputValue(s *Schema, t *Type, value interface{}) (interface{}, error) {
+	v, err := coerceInputAt(s, t, value, nil)
+	if err != nil {
+		return nil, err
+	}
+	return v, nil
+}
+
+func coerceInputAt(s *Schema, t *Type, value interface{}, path []interface{}) (interface{}, *inputError) {
+	invalid := func(err error) *inputError {
+		return &inputError{path: append([]interface{}(nil), path...), value: value, err: err}
+	}
+	if value == nil {
+		if t.NonNull {
+			return nil, invalid(fmt.Errorf("Expected non-nullable type %q not to be null.", typeString(t)))
+		}
+		return nil, nil
+	}
+	if t.IsList {
+		var list []interface{}
+		if rv := reflect.ValueOf(value); rv.Kind() != reflect.Slice {
+			// A single value is accepted where a list is expected.
+			item, err := coerceInputAt(s, t.Elem, value, path)
+			if err != nil {
+				return nil, err
+			}
+			list = []interface{}{item}
+		} else {
+			list = make([]interface{}, rv.Len())
+			for i := range list {
+				item, err := coerceInputAt(s, t.Elem, rv.Index(i).Interface(), append(path, i))
+				if err != nil {
+					return nil, err
+				}
+				list[i] = item
+			}
+		}
+		if isUploadList(t) {
+			uploads, err := uploadList(list)
+			if err != nil {
+				return nil, invalid(err)
+			}
+			return uploads, nil
+		}
+		return list, nil
+	}
+	var v interface{}
+	var err error
+	switch t.Name {
+	case "Int":
+		v, err = coerceInt(value)
+	case "Float":
+		v, err = coerceFloat(value)
+	case "String":
+		if str, ok := value.(string); ok {
+			return str, nil
+		}
+		err = fmt.Errorf("String cannot represent a non string value: %s", jsonString(value))
+	case "Boolean":
+		if b, ok := value.(bool); ok {
+			return b, nil
+		}
+		err = fmt.Errorf("Boolean cannot represent a non boolean value: %s", jsonString(value))
+	case "ID":
+		v, err = coerceID(value)
+	case "BigInt":
+		v, err = coerceBigInt(value)
+	case "Upload":
+		v, err = coerceUpload(value)
+	case "JSON":
+		// Any JSON value is valid and passed on as decoded.
+		return value, nil
+	default:
+		return coerceInputObject(s, t, value, path, invalid)
+	}
+	if err != nil {
+		return nil, invalid(err)
+	}
+	return v, nil
+}
+
+// coerceInputObject coerces value to t if t is an input object type, and
+// returns it unchanged otherwise.
+func coerceInputObject(s *Schema, t *Type, value interface{}, path []interface{}, invalid func(error) *inputError) (interface{}, *inputError) {
+	if s == nil {
+		return value, nil
+	}
+	td := s.Type(t.Name)
+	if td == nil || td.kind() != KindInputObject {
+		return value, nil
+	}
+	fields, ok := value.(map[string]interface{})
+	if !ok {
+		return nil, invalid(fmt.Errorf("Expected type %q to be an object.", t.Name))
+	}
+	defined := make(map[string]bool, len(td.InputFields))
+	for _, def := range td.InputFields {
+		defined[def.Name] = true
+	}
+	for name := range fields {
+		if !defined[name] {
+			options := make([]string, 0, len(td.InputFields))
+			for _, def := range td.InputFields {
+				options = append(options, def.Name)
+			}
+			hint := ""
+			if !s.DisableSuggestions {
+				hint = didYouMean(suggestionList(name, options))
+			}
+			return nil, invalid(fmt.Errorf("Field %q is not defined by type %q.%s", name, t.Name, hint))
+		}
+	}
+	out := make(map[string]interface{}, len(fields))
+	for name, field := range fields {
+		out[name] = field
+	}
+	for _, def := range td.InputFields {
+		field, ok := fields[def.Name]
+		if !ok {
+			if def.Type.NonNull && def.DefaultValue == nil {
+				return nil, invalid(fmt.Errorf("Field %q of required type %q was not provided.", def.Name, typeString(def.Type)))
+			}
+			continue
+		}
+		v, err := coerceInputAt(s, def.Type, field, append(path, def.Name))
+		if err != nil {
+			return nil, err
+		}
+		out[def.Name] = v
+	}
+	return out, nil
+}
+
+func coerceInt(value interface{}) (interface{}, error) {
+	var f float64
+	switch v := value.(type) {
+	case int:
+		f = float64(v)
+	case int32:
+		f = float64(v)
+	case int64:
+		f = float64(v)
+	case float64:
+		f = v
+	case *big.Int:
+		if !v.IsInt64() {
+			return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", v)
+		}
+		f = float64(v.Int64())
+	case json.Number:
+		parsed, err := v.Float64()
+		if err != nil {
+			return nil, fmt.Errorf("Int cannot represent non-integer value: %s", v)
+		}
+		f = parsed
+	default:
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", jsonString(value))
+	}
+	if f != math.Trunc(f) {
+		return nil, fmt.Errorf("Int cannot represent non-integer value: %s", jsonString(value))
+	}
+	if f > math.MaxInt32 || f < math.MinInt32 {
+		return nil, fmt.Errorf("Int cannot represent non 32-bit signed integer value: %s", jsonString(value))
+	}
+	return int(f), nil
+}
+
+func coerceFloat(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case float64:
+		return v, nil
+	case float32:
+		return float64(v), nil
+	case int:
+		return float64(v), nil
+	case int64:
+		return float64(v), nil
+	case json.Number:
+		if f, err := v.Float64(); err == nil {
+			return f, nil
+		}
+	}
+	return nil, fmt.Errorf("Float cannot represent non numeric value: %s", jsonString(value))
+}
+
+func coerceID(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case string:
+		return v, nil
+	case int:
+		return strconv.Itoa(v), nil
+	case int64:
+		return strconv.FormatInt(v, 10), nil
+	case json.Number:
+		if _, err := v.Int64(); err == nil {
+			return v.String(), nil
+		}
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) < 1<<53 {
+			return strconv.FormatInt(int64(v), 10), nil
+		}
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %s", jsonString(value))
+}
+
+// maxSafeInteger is the largest integer a float64 holds exactly. JSON numbers
+// decoded by encoding/json beyond it may already have lost precision.
+const maxSafeInteger = 1<<53 - 1
+
+// coerceBigInt converts a BigInt input to *big.Int. The handler decodes JSON
+// numbers as json.Number, which keeps them exact; values decoded as float64
+// outside the range it represents exactly must be sent as strings.
+func coerceBigInt(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case *big.Int:
+		return v, nil
+	case int:
+		return big.NewInt(int64(v)), nil
+	case int64:
+		return big.NewInt(v), nil
+	case float64:
+		if v == math.Trunc(v) && math.Abs(v) <= maxSafeInteger {
+			return big.NewInt(int64(v)), nil
+		}
+	case json.Number:
+		if n, ok := new(big.Int).SetString(v.String(), 10); ok {
+			return n, nil
+		}
+	case string:
+		if n, ok := new(big.Int).SetString(v, 10); ok {
+			return n, nil
+		}
+	}
+	return nil, fmt.Errorf("BigInt cannot represent value: %s; send integers beyond 2^53 as strings", jsonString(value))
+}
+
+// jsonString formats a value as JSON for error messages.
+func jsonString(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	return string(data)
+}
@@ -0,0 +1,203 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"math/big"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestCoerceVariables(t *testing.T) {
+	s, err := ParseSchema(`
+		input Page { first: Int ids: [ID!] }
+		type Query { items(limit: Int, ratio: Float, id: ID, page: Page, tags: [Int]): [String] }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	var variables map[string]interface{}
+	if err := json.Unmarshal([]byte(`{"limit": 10, "ratio": 2, "id": 42, "page": {"first": 3, "ids": [7, "x"]}, "tags": 5}`), &variables); err != nil {
+		t.Fatal(err)
+	}
+	op := parseQuery(`query ($limit: Int, $ratio: Float, $id: ID, $page: Page, $tags: [Int]) { items }`).Definitions[0].(*OperationDefinition)
+	got, errs := coerceVariables(s, op, variables)
+	if errs != nil {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+	want := map[string]interface{}{
+		"limit": 10,
+		"ratio": 2.0,
+		"id":    "42",
+		"page":  map[string]interface{}{"first": 3, "ids": []interface{}{"7", "x"}},
+		"tags":  []interface{}{5},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("coerceVariables =\n%#v\nwant\n%#v", got, want)
+	}
+	if variables["limit"] != 10.0 {
+		t.Errorf("input variables were modified: %v", variables)
+	}
+}
+
+func TestCoerceVariables_Errors(t *testing.T) {
+	tests := []struct {
+		query     string
+		variables map[string]interface{}
+		want      string
+	}{
+		{`query ($n: Int) { a }`, map[string]interface{}{"n": 1.5}, `Variable "$n" got invalid value 1.5; Int cannot represent non-integer value: 1.5`},
+		{`query ($n: Int) { a }`, map[string]interface{}{"n": "1"}, `Variable "$n" got invalid value "1"; Int cannot represent non-integer value: "1"`},
+		{`query ($n: Int) { a }`, map[string]interface{}{"n": 1e10}, `Variable "$n" got invalid value 10000000000; Int cannot represent non 32-bit signed integer value: 10000000000`},
+		{`query ($b: Boolean) { a }`, map[string]interface{}{"b": "yes"}, `Variable "$b" got invalid value "yes"; Boolean cannot represent a non boolean value: "yes"`},
+		{`query ($id: ID!) { a }`, map[string]interface{}{}, `Variable "$id" of required type "ID!" was not provided.`},
+		{`query ($ids: [ID!]) { a }`, map[string]interface{}{"ids": []interface{}{nil}}, `Variable "$ids" got invalid value null at "ids[0]"; Expected non-nullable type "ID!" not to be null.`},
+		{`query ($id: ID!) { a }`, map[string]interface{}{"id": nil}, `Variable "$id" of non-null type "ID!" must not be null.`},
+	}
+	for _, tt := range tests {
+		op := parseQuery(tt.query).Definitions[0].(*OperationDefinition)
+		_, errs := coerceVariables(nil, op, tt.variables)
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s %v: expected %q, got %v", tt.query, tt.variables, tt.want, errs)
+		}
+	}
+}
+
+func TestCoerceVariables_InputObjectErrors(t *testing.T) {
+	s, err := ParseSchema(`
+		input Page { first: Int, ids: [Int!], size: Int! = 10 }
+		input Filter { page: Page!, name: String }
+		type Query { a(filter: Filter): String }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	op := parseQuery(`query ($input: Filter) { a(filter: $input) }`).Definitions[0].(*OperationDefinition)
+	tests := []struct {
+		input interface{}
+		want  string
+	}{
+		{map[string]interface{}{"page": map[string]interface{}{"first": "x"}}, `Variable "$input" got invalid value "x" at "input.page.first"; Int cannot represent non-integer value: "x"`},
+		{map[string]interface{}{"page": map[string]interface{}{"ids": []interface{}{1, 2.5}}}, `Variable "$input" got invalid value 2.5 at "input.page.ids[1]"; Int cannot represent non-integer value: 2.5`},
+		{map[string]interface{}{"page": map[string]interface{}{"frist": 1}}, `Variable "$input" got invalid value {"frist":1} at "input.page"; Field "frist" is not defined by type "Page". Did you mean "first"?`},
+		{map[string]interface{}{"name": "ada"}, `Variable "$input" got invalid value {"name":"ada"}; Field "page" of required type "Page!" was not provided.`},
+		{map[string]interface{}{"page": nil}, `Variable "$input" got invalid value null at "input.page"; Expected non-nullable type "Page!" not to be null.`},
+		{"page", `Variable "$input" got invalid value "page"; Expected type "Filter" to be an object.`},
+	}
+	for _, tt := range tests {
+		_, errs := coerceVariables(s, op, map[string]interface{}{"input": tt.input})
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%v: expected %q, got %v", tt.input, tt.want, errs)
+		}
+	}
+	if _, errs := coerceVariables(s, op, map[string]interface{}{"input": map[string]interface{}{"page": map[string]interface{}{}}}); len(errs) != 0 {
+		t.Errorf("expected fields with defaults to be optional, got %v", errs)
+	}
+}
+
+func TestExecuteDocument_CoercesVariables(t *testing.T) {
+	var got interface{}
+	QueryResolvers["page"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args["size"]
+		return "ok", nil
+	}
+	defer delete(QueryResolvers, "page")
+
+	var variables map[string]interface{}
+	json.Unmarshal([]byte(`{"size": 25}`), &variables)
+	if _, err := executeDocument(context.Background(), parseQuery(`query ($size: Int) { page(size: $size) }`), variables); err != nil {
+		t.Fatal(err)
+	}
+	if got != 25 {
+		t.Errorf("expected resolver to receive int 25, got %#v", got)
+	}
+}
+
+func TestCoerceBigInt(t *testing.T) {
+	huge, _ := new(big.Int).SetString("123456789012345678901234567890", 10)
+	tests := []struct {
+		in   interface{}
+		want *big.Int
+	}{
+		{"123456789012345678901234567890", huge},
+		{float64(42), big.NewInt(42)},
+		{7, big.NewInt(7)},
+		{huge, huge},
+	}
+	for _, tt := range tests {
+		got, err := coerceBigInt(tt.in)
+		if err != nil || got.(*big.Int).Cmp(tt.want) != 0 {
+			t.Errorf("coerceBigInt(%v) = %v, %v; want %v", tt.in, got, err, tt.want)
+		}
+	}
+	for _, in := range []interface{}{1.5, 1e20, "12abc", true} {
+		if _, err := coerceBigInt(in); err == nil {
+			t.Errorf("coerceBigInt(%v): expected an error", in)
+		}
+	}
+}
+
+func TestExecuteDocument_CoercesLiteralArguments(t *testing.T) {
+	useTestSchema(t, `
+		scalar BigInt
+		type Query { stats(total: BigInt, ratio: Float, id: ID): String }
+	`)
+	var got map[string]interface{}
+	QueryResolvers["stats"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args
+		return "ok", nil
+	}
+	defer delete(QueryResolvers, "stats")
+
+	executeQuery(t, `{ stats(total: 99999999999999999999, ratio: 2, id: 5) }`, nil)
+	want, _ := new(big.Int).SetString("99999999999999999999", 10)
+	if total, ok := got["total"].(*big.Int); !ok || total.Cmp(want) != 0 {
+		t.Errorf("unexpected total %#v", got["total"])
+	}
+	if got["ratio"] != 2.0 || got["id"] != "5" {
+		t.Errorf("unexpected arguments %#v", got)
+	}
+}
+
+func TestJSONScalar(t *testing.T) {
+	var got []interface{}
+	useResolvers(t, map[string]ResolverFunc{
+		"meta": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			got = append(got, args["v"])
+			return json.RawMessage(`{"x": [1, 2.5, null]}`), nil
+		},
+		"blob": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return map[string]interface{}{"nested": []interface{}{"a", true}}, nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	s := useTestSchema(t, `input Filter { extra: JSON } type Query { meta(v: JSON, f: Filter): JSON blob: JSON }`)
+	if !strings.Contains(PrintSchema(s), "scalar JSON") {
+		t.Errorf("expected the JSON scalar to be defined, got %s", PrintSchema(s))
+	}
+
+	variables := map[string]interface{}{
+		"v": map[string]interface{}{"big": json.Number("12345678901234567890"), "list": []interface{}{1.5, "x"}},
+		"f": map[string]interface{}{"extra": map[string]interface{}{"anything": "goes"}},
+	}
+	result, err := executeRequest(context.Background(), `query ($v: JSON, $f: Filter) { a: meta(v: {a: [1, "x", {b: true}]}) b: meta(v: $v) c: meta(f: $f) blob }`, variables)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := json.Marshal(result)
+	if want := `{"data":{"a":{"x":[1,2.5,null]},"b":{"x":[1,2.5,null]},"blob":{"nested":["a",true]},"c":{"x":[1,2.5,null]}}}`; string(body) != want {
+		t.Errorf("got %s, want %s", body, want)
+	}
+	wantArgs := []interface{}{
+		map[string]interface{}{"a": []interface{}{1, "x", map[string]interface{}{"b": true}}},
+		variables["v"],
+		nil,
+	}
+	if !reflect.DeepEqual(got, wantArgs) {
+		t.Errorf("got arguments %#v, want %#v", got, wantArgs)
+	}
+
+	if _, err := ParseSchema(`scalar JSON type Query { meta: JSON }`); err != nil {
+		t.Errorf("declaring the JSON scalar failed: %v", err)
+	}
+}
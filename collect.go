@@ -0,0 +1,117 @@
+package vibeGraphql
+
+import "sync"
+
+// collectedField is a group of fields sharing a response key. Selecting the
+// same field more than once is valid GraphQL; the group is resolved once and
+// the sub-selections of all its fields are merged.
+type collectedField struct {
+	key    string
+	fields []*Field
+}
+
+// collectIndexPool recycles the maps collectFields uses to find the group of
+// a response key.
+var collectIndexPool = sync.Pool{
+	New: func() interface{} { return make(map[string]int) },
+}
+
+// collectFields implements the CollectFields algorithm of the specification:
+// it groups the fields of ss that are not excluded by @skip or @include by
+// response key, in the order each key first appears. The fields of
+// fragments are collected if they apply to parentType, the type of the
+// object ss is selected on.
+func collectFields(ss *SelectionSet, parentType string, variables map[string]interface{}) []collectedField {
+	c := fieldCollector{
+		parentType: parentType,
+		variables:  variables,
+		groups:     make([]collectedField, 0, len(ss.Selections)),
+		index:      collectIndexPool.Get().(map[string]int),
+	}
+	defer func() {
+		clear(c.index)
+		collectIndexPool.Put(c.index)
+	}()
+	// Groups of a single field, the common case, share one backing array.
+	fields := make([]*Field, len(ss.Selections))
+	for i, sel := range ss.Selections {
+		if field, ok := sel.(*Field); ok {
+			fields[i] = field
+			c.add(field, fields[i:i+1:i+1])
+			continue
+		}
+		c.fragment(sel)
+	}
+	return c.groups
+}
+
+// fieldCollector holds the state of collectFields.
+type fieldCollector struct {
+	parentType string
+	variables  map[string]interface{}
+	groups     []collectedField
+	index      map[string]int // response key -> index of its group
+	spread     map[*FragmentDefinition]bool
+}
+
+// add adds field, unless @skip or @include exclude it, to the group of its
+// response key, starting the group with group if there is none.
+func (c *fieldCollector) add(field *Field, group []*Field) {
+	if !shouldIncludeField(field, c.variables) {
+		return
+	}
+	key := field.ResponseKey()
+	if g, ok := c.index[key]; ok {
+		c.groups[g].fields = append(c.groups[g].fields, field)
+		return
+	}
+	c.index[key] = len(c.groups)
+	c.groups = append(c.groups, collectedField{key: key, fields: group})
+}
+
+// fragment collects the fields of sel if it is a fragment that applies and
+// is not excluded. Each named fragment is collected once.
+func (c *fieldCollector) fragment(sel Selection) {
+	ss, typeCondition, directives := expandFragment(sel)
+	if ss == nil || !shouldInclude(directives, c.variables) || !fragmentApplies(CurrentSchema(), typeCondition, c.parentType) {
+		return
+	}
+	if spread, ok := sel.(*FragmentSpread); ok {
+		if c.spread[spread.Fragment] {
+			return
+		}
+		if c.spread == nil {
+			c.spread = make(map[*FragmentDefinition]bool)
+		}
+		c.spread[spread.Fragment] = true
+	}
+	for _, sel := range ss.Selections {
+		if field, ok := sel.(*Field); ok {
+			c.add(field, []*Field{field})
+			continue
+		}
+		c.fragment(sel)
+	}
+}
+
+// field returns the field to resolve for the group: its first field, with
+// the selection sets of all fields in the group merged.
+func (g *collectedField) field() *Field {
+	first := g.fields[0]
+	if len(g.fields) == 1 {
+		return first
+	}
+	var merged *SelectionSet
+	for _, f := range g.fields {
+		if f.SelectionSet == nil {
+			continue
+		}
+		if merged == nil {
+			merged = &SelectionSet{}
+		}
+		merged.Selections = append(merged.Selections, f.SelectionSet.Selections...)
+	}
+	field := *first
+	field.SelectionSet = merged
+	return &field
+}
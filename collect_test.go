@@ -0,0 +1,57 @@
+package vibeGraphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestCollectFields(t *testing.T) {
+	ss := parseQuery(`query ($no: Boolean!) {
+		user { id }
+		name
+		user { email friends { id } }
+		u: user { id }
+		user @include(if: $no) { secret }
+		user { friends { name } }
+	}`).Definitions[0].(*OperationDefinition).SelectionSet
+
+	groups := collectFields(ss, "", map[string]interface{}{"no": false})
+	var keys []string
+	for _, g := range groups {
+		keys = append(keys, g.key)
+	}
+	if !reflect.DeepEqual(keys, []string{"user", "name", "u"}) {
+		t.Fatalf("unexpected keys %v", keys)
+	}
+	if n := len(groups[0].fields); n != 3 {
+		t.Errorf("expected the excluded field to be left out of the user group, got %d fields", n)
+	}
+	user := groups[0].field()
+	if got := fieldNames(user.SelectionSet); !reflect.DeepEqual(got, []string{"id", "email", "friends", "friends"}) {
+		t.Errorf("merged selection = %v", got)
+	}
+	if groups[0].fields[0].SelectionSet.Selections[0] != user.SelectionSet.Selections[0] || len(groups[0].fields[0].SelectionSet.Selections) != 1 {
+		t.Errorf("merging must not modify the document")
+	}
+	if groups[1].field() != groups[1].fields[0] {
+		t.Errorf("single fields should be used as is")
+	}
+}
+
+func TestExecuteSelectionSet_MergesDuplicateFields(t *testing.T) {
+	calls := 0
+	QueryResolvers["mergeUser"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return &DummyUser{Name: "Ada", Age: 36}, nil
+	}
+	defer delete(QueryResolvers, "mergeUser")
+
+	data := executeQuery(t, `{ mergeUser { name } mergeUser { age } }`, nil)
+	want := map[string]interface{}{"name": "Ada", "age": 36}
+	if !reflect.DeepEqual(data["mergeUser"], want) {
+		t.Errorf("mergeUser = %v, want %v", data["mergeUser"], want)
+	}
+	if calls != 1 {
+		t.Errorf("expected one resolver call, got %d", calls)
+	}
+}
@@ -0,0 +1,122 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// ExecutionLimit bounds the work a handler does at once, so that a traffic
+// spike degrades gracefully instead of exhausting goroutines and memory.
+type ExecutionLimit struct {
+	// MaxOperations is the number of queries and mutations executed at
+	// once. Further requests wait for a slot. Zero is unlimited.
+	MaxOperations int
+	// MaxQueued is the number of requests that may wait for a slot; requests
+	// beyond it are rejected at once. Zero puts no limit on the queue.
+	MaxQueued int
+	// QueueTimeout is how long a request waits for a slot before it is
+	// rejected. Zero rejects requests at once when all slots are taken.
+	QueueTimeout time.Duration
+	// MaxResolverGoroutines bounds the goroutines started, across all
+	// requests, to resolve list items in parallel (see ListConcurrency) and
+	// to enforce field timeouts. When none is available, the work is done
+	// in the request's own goroutine instead. Zero is unlimited.
+	MaxResolverGoroutines int
+}
+
+// WithExecutionLimit limits the operations and resolver goroutines the
+// handler runs at once. Requests that find no free slot within the queue
+// timeout are rejected with status 503 and a SERVICE_UNAVAILABLE error.
+func WithExecutionLimit(limit ExecutionLimit) HandlerOption {
+	return func(h *handler) {
+		l := &executionLimiter{limit: limit}
+		if limit.MaxOperations > 0 {
+			l.operations = make(chan struct{}, limit.MaxOperations)
+		}
+		if limit.MaxResolverGoroutines > 0 {
+			l.goroutines = make(chan struct{}, limit.MaxResolverGoroutines)
+		}
+		h.execLimit = l
+	}
+}
+
+type executionLimiterKey struct{}
+
+// executionLimiter holds the slots of an ExecutionLimit.
+type executionLimiter struct {
+	limit      ExecutionLimit
+	operations chan struct{}
+	goroutines chan struct{}
+	queued     atomic.Int64
+}
+
+// acquire waits for an operation slot and returns a function releasing it,
+// or a SERVICE_UNAVAILABLE error if none became free in time.
+func (l *executionLimiter) acquire(ctx context.Context) (func(), *Error) {
+	if l == nil || l.operations == nil {
+		return func() {}, nil
+	}
+	release := func() { <-l.operations }
+	select {
+	case l.operations <- struct{}{}:
+		return release, nil
+	default:
+	}
+	if l.limit.QueueTimeout <= 0 {
+		return nil, busyError()
+	}
+	if queued := l.queued.Add(1); l.limit.MaxQueued > 0 && queued > int64(l.limit.MaxQueued) {
+		l.queued.Add(-1)
+		return nil, busyError()
+	}
+	defer l.queued.Add(-1)
+	timer := time.NewTimer(l.limit.QueueTimeout)
+	defer timer.Stop()
+	select {
+	case l.operations <- struct{}{}:
+		return release, nil
+	case <-timer.C:
+		return nil, busyError()
+	case <-ctx.Done():
+		return nil, busyError()
+	}
+}
+
+func busyError() *Error {
+	err := NewError(CodeUnavailable, "The server is too busy to execute the operation; try again later.")
+	err.Extensions["retryAfter"] = 1
+	return err
+}
+
+// goroutine reports whether the request of ctx may start a resolver
+// goroutine. If it returns true, the goroutine must call release when done.
+func goroutine(ctx context.Context) (release func(), ok bool) {
+	l, _ := ctx.Value(executionLimiterKey{}).(*executionLimiter)
+	if l == nil || l.goroutines == nil {
+		return func() {}, true
+	}
+	select {
+	case l.goroutines <- struct{}{}:
+		return func() { <-l.goroutines }, true
+	default:
+		return nil, false
+	}
+}
+
+// limitExecution waits for an operation slot for r, answering it with a
+// 503 error if there is none. It returns the request to execute along with
+// a function releasing the slot, or ok false if r was answered.
+func (h *handler) limitExecution(w http.ResponseWriter, r *http.Request) (_ *http.Request, release func(), ok bool) {
+	if h.execLimit == nil {
+		return r, func() {}, true
+	}
+	release, busy := h.execLimit.acquire(r.Context())
+	if busy != nil {
+		w.Header().Set("Retry-After", "1")
+		writeResponse(w, r, http.StatusServiceUnavailable, encodeResponse(map[string]interface{}{"errors": withRequestIDs(r.Context(), []*Error{busy})}))
+		return r, nil, false
+	}
+	return r.WithContext(context.WithValue(r.Context(), executionLimiterKey{}, h.execLimit)), release, true
+}
@@ -0,0 +1,108 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestWithExecutionLimit(t *testing.T) {
+	started := make(chan struct{}, 10)
+	unblock := make(chan struct{})
+	RegisterQueryResolver("blockedOp", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		started <- struct{}{}
+		<-unblock
+		return "ok", nil
+	})
+	defer delete(QueryResolvers, "blockedOp")
+
+	send := func(h http.Handler) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ blockedOp }"}`)))
+		return rr
+	}
+	busy := func(rr *httptest.ResponseRecorder) bool {
+		return rr.Code == http.StatusServiceUnavailable && rr.Header().Get("Retry-After") == "1" &&
+			strings.Contains(rr.Body.String(), `"code":"SERVICE_UNAVAILABLE"`)
+	}
+
+	t.Run("no queue", func(t *testing.T) {
+		h := NewHandler(WithExecutionLimit(ExecutionLimit{MaxOperations: 1}))
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() { defer wg.Done(); send(h) }()
+		<-started
+		if rr := send(h); !busy(rr) {
+			t.Errorf("expected a busy response, got %d %s", rr.Code, rr.Body.String())
+		}
+		unblock <- struct{}{}
+		wg.Wait()
+		go func() { <-started; unblock <- struct{}{} }()
+		if rr := send(h); rr.Code != http.StatusOK {
+			t.Errorf("expected the slot to be released, got %d %s", rr.Code, rr.Body.String())
+		}
+	})
+
+	t.Run("queue", func(t *testing.T) {
+		hh := newHandler([]HandlerOption{WithExecutionLimit(ExecutionLimit{MaxOperations: 1, MaxQueued: 1, QueueTimeout: time.Minute})})
+		h := http.HandlerFunc(hh.serveUpload)
+		results := make(chan *httptest.ResponseRecorder, 2)
+		go func() { results <- send(h) }()
+		<-started
+		go func() { results <- send(h) }()
+		// Wait for the second request to be queued; a third one overflows
+		// the queue.
+		for hh.execLimit.queued.Load() == 0 {
+			time.Sleep(time.Millisecond)
+		}
+		if rr := send(h); !busy(rr) {
+			t.Errorf("expected a busy response, got %d %s", rr.Code, rr.Body.String())
+		}
+		unblock <- struct{}{}
+		<-started
+		unblock <- struct{}{}
+		for i := 0; i < 2; i++ {
+			if rr := <-results; rr.Code != http.StatusOK {
+				t.Errorf("expected queued requests to run, got %d %s", rr.Code, rr.Body.String())
+			}
+		}
+	})
+}
+
+func TestExecutionLimitGoroutines(t *testing.T) {
+	// A limiter whose only goroutine is taken forces work inline.
+	limiter := &executionLimiter{goroutines: make(chan struct{}, 1)}
+	limiter.goroutines <- struct{}{}
+	ctx := context.WithValue(context.Background(), executionLimiterKey{}, limiter)
+
+	type item struct{ ID int }
+	items := []*item{{1}, {2}, {3}}
+	useResolvers(t, map[string]ResolverFunc{
+		"items": func(source interface{}, args map[string]interface{}) (interface{}, error) { return items, nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	defer func(prev int) { ListConcurrency = prev }(ListConcurrency)
+	ListConcurrency = 4
+	result, err := executeRequest(ctx, `{ items { ID } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := encodeResponse(result); !strings.Contains(string(got), `{"items":[{"ID":1},{"ID":2},{"ID":3}]}`) {
+		t.Errorf("unexpected result %s", got)
+	}
+
+	slow := withFieldTimeout(10*time.Millisecond, func(ctx context.Context, source interface{}, parentType string, field *Field) (interface{}, error) {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	})
+	_, err = slow(ctx, nil, "Query", &Field{Name: "slow"})
+	if gqlErr, ok := err.(*Error); !ok || gqlErr.Extensions["code"] != CodeTimeout {
+		t.Errorf("expected a timeout error, got %v", err)
+	}
+	if len(limiter.goroutines) != 1 {
+		t.Errorf("expected the goroutine slot to stay taken, got %d", len(limiter.goroutines))
+	}
+}
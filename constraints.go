@@ -0,0 +1,248 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"math/big"
+	"reflect"
+	"regexp"
+	"sync"
+	"unicode/utf8"
+)
+
+// InputValidator checks the value of an argument or input field, returning
+// an error describing why it is invalid.
+type InputValidator func(value interface{}) error
+
+// inputValidators maps the schema coordinates of arguments, such as
+// "Mutation.createUser(email:)", and input fields, such as
+// "UserInput.email", to their validators.
+var inputValidators = make(map[string]InputValidator)
+
+// RegisterArgumentValidator validates argument of field on typeName before
+// the field is resolved. Invalid values null the field with a BAD_USER_INPUT
+// error naming the argument, so resolvers only see valid input.
+func RegisterArgumentValidator(typeName, field, argument string, validator InputValidator) {
+	inputValidators[typeName+"."+field+"("+argument+":)"] = validator
+}
+
+// RegisterInputFieldValidator validates field of the input object inputType
+// wherever the input object is used as an argument.
+//
+// Arguments and input fields can also declare constraints in the schema with
+// the @constraint directive, which are checked the same way:
+//
+//	input UserInput {
+//	  name: String @constraint(minLength: 1, maxLength: 50)
+//	  email: String @constraint(pattern: "^[^@]+@[^@]+$")
+//	  age: Int @constraint(min: 0, max: 150)
+//	}
+//
+// On list types, constraints apply to every item.
+func RegisterInputFieldValidator(inputType, field string, validator InputValidator) {
+	inputValidators[inputType+"."+field] = validator
+}
+
+// validateArguments checks the arguments of field on parentType against the
+// validators and constraints of the schema.
+func (e *executor) validateArguments(parentType string, field *Field) *Error {
+	s := CurrentSchema()
+	if s == nil || len(field.Arguments) == 0 || len(inputValidators) == 0 && !s.hasConstraints() {
+		return nil
+	}
+	def := s.Field(parentType, field.Name)
+	if def == nil {
+		return nil
+	}
+	args := e.fieldArgs(parentType, field)
+	for _, argDef := range def.ArgumentDefinitions {
+		coordinate := parentType + "." + field.Name + "(" + argDef.Name + ":)"
+		value, ok := args[argDef.Name]
+		if !ok {
+			continue
+		}
+		if invalid := checkInput(s, coordinate, argDef, value, nil); invalid != nil {
+			at := ""
+			if len(invalid.path) > 0 {
+				at = fmt.Sprintf(" at \"%s%s\"", argDef.Name, inputPath(invalid.path))
+			}
+			err := NewError(CodeBadUserInput, fmt.Sprintf("Argument %q of field \"%s.%s\" got invalid value %s%s; %v.",
+				argDef.Name, parentType, field.Name, jsonString(invalid.value), at, invalid.err))
+			err.Extensions["argumentPath"] = append([]interface{}{argDef.Name}, invalid.path...)
+			return err
+		}
+	}
+	return nil
+}
+
+// checkInput checks value, the value of the argument or input field def at
+// coordinate, and the input fields within it.
+func checkInput(s *Schema, coordinate string, def *InputValueDefinition, value interface{}, path []interface{}) *inputError {
+	if value == nil {
+		return nil
+	}
+	if validator := inputValidators[coordinate]; validator != nil {
+		if err := validator(value); err != nil {
+			return &inputError{path: path, value: value, err: err}
+		}
+	}
+	return checkInputValue(s, def.Type, constraintOf(def.Directives), value, path)
+}
+
+// checkInputValue checks value against the constraint c of its argument or
+// input field, of type t, and checks the fields of input objects.
+func checkInputValue(s *Schema, t *Type, c *constraint, value interface{}, path []interface{}) *inputError {
+	if value == nil || t == nil {
+		return nil
+	}
+	if t.IsList {
+		if items, ok := value.([]interface{}); ok {
+			for i, item := range items {
+				if invalid := checkInputValue(s, t.Elem, c, item, append(path[:len(path):len(path)], i)); invalid != nil {
+					return invalid
+				}
+			}
+			return nil
+		}
+		// A single value is accepted for a list.
+		return checkInputValue(s, t.Elem, c, value, path)
+	}
+	if c != nil {
+		if err := c.check(value); err != nil {
+			return &inputError{path: path, value: value, err: err}
+		}
+	}
+	td := s.Type(t.Name)
+	fields, ok := value.(map[string]interface{})
+	if td == nil || td.kind() != KindInputObject || !ok {
+		return nil
+	}
+	for _, fieldDef := range td.InputFields {
+		fieldPath := append(path[:len(path):len(path)], fieldDef.Name)
+		if invalid := checkInput(s, td.Name+"."+fieldDef.Name, fieldDef, fields[fieldDef.Name], fieldPath); invalid != nil {
+			return invalid
+		}
+	}
+	return nil
+}
+
+// constraint holds the arguments of a @constraint directive.
+type constraint struct {
+	min, max             *float64
+	minLength, maxLength *int
+	pattern              *regexp.Regexp
+}
+
+var patterns sync.Map // pattern string -> *regexp.Regexp, or error
+
+// constraintOf returns the @constraint among directives, or nil.
+func constraintOf(directives []Directive) *constraint {
+	for _, d := range directives {
+		if d.Name != "constraint" {
+			continue
+		}
+		c := &constraint{}
+		for name, value := range buildArgumentValues(d.Arguments, nil) {
+			switch name {
+			case "min", "max":
+				if n, ok := toFloat(value); ok {
+					if name == "min" {
+						c.min = &n
+					} else {
+						c.max = &n
+					}
+				}
+			case "minLength", "maxLength":
+				if n, ok := value.(int); ok {
+					if name == "minLength" {
+						c.minLength = &n
+					} else {
+						c.maxLength = &n
+					}
+				}
+			case "pattern":
+				if pattern, ok := value.(string); ok {
+					c.pattern = compilePattern(pattern)
+				}
+			}
+		}
+		return c
+	}
+	return nil
+}
+
+// compilePattern returns the compiled pattern, or nil if it is invalid.
+func compilePattern(pattern string) *regexp.Regexp {
+	if re, ok := patterns.Load(pattern); ok {
+		re, _ := re.(*regexp.Regexp)
+		return re
+	}
+	re, err := regexp.Compile(pattern)
+	if err != nil {
+		logger().Warn("graphql: invalid @constraint pattern", "pattern", pattern, "error", err)
+		patterns.Store(pattern, err)
+		return nil
+	}
+	patterns.Store(pattern, re)
+	return re
+}
+
+// check returns an error if value violates c.
+func (c *constraint) check(value interface{}) error {
+	if n, ok := toFloat(value); ok {
+		if c.min != nil && n < *c.min {
+			return fmt.Errorf("must be at least %v", *c.min)
+		}
+		if c.max != nil && n > *c.max {
+			return fmt.Errorf("must be at most %v", *c.max)
+		}
+	}
+	if str, ok := value.(string); ok {
+		length := utf8.RuneCountInString(str)
+		if c.minLength != nil && length < *c.minLength {
+			return fmt.Errorf("must be at least %d characters long", *c.minLength)
+		}
+		if c.maxLength != nil && length > *c.maxLength {
+			return fmt.Errorf("must be at most %d characters long", *c.maxLength)
+		}
+		if c.pattern != nil && !c.pattern.MatchString(str) {
+			return fmt.Errorf("must match the pattern %q", c.pattern.String())
+		}
+	}
+	return nil
+}
+
+// toFloat returns the numeric value n as a float64.
+func toFloat(n interface{}) (float64, bool) {
+	if b, ok := n.(*big.Int); ok {
+		f, _ := new(big.Float).SetInt(b).Float64()
+		return f, true
+	}
+	v := reflect.ValueOf(n)
+	switch {
+	case v.Kind() >= reflect.Int && v.Kind() <= reflect.Int64:
+		return float64(v.Int()), true
+	case v.Kind() >= reflect.Uint && v.Kind() <= reflect.Uint64:
+		return float64(v.Uint()), true
+	case v.Kind() == reflect.Float32 || v.Kind() == reflect.Float64:
+		return v.Float(), true
+	}
+	return 0, false
+}
+
+// hasConstraints reports whether any argument or input field of s has a
+// @constraint directive.
+func (s *Schema) hasConstraints() bool {
+	s.constraintsOnce.Do(func() {
+		for _, td := range s.Types {
+			for _, f := range td.Fields {
+				for _, arg := range f.ArgumentDefinitions {
+					s.constrained = s.constrained || constraintOf(arg.Directives) != nil
+				}
+			}
+			for _, f := range td.InputFields {
+				s.constrained = s.constrained || constraintOf(f.Directives) != nil
+			}
+		}
+	})
+	return s.constrained
+}
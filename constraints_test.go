@@ -0,0 +1,82 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestInputConstraints(t *testing.T) {
+	calls := 0
+	useResolvers(t, map[string]ResolverFunc{
+		"createUser": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			calls++
+			return "ok", nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `
+		input UserInput {
+			name: String @constraint(minLength: 2, maxLength: 5)
+			email: String @constraint(pattern: "^[^@]+@[^@]+$")
+			tags: [String] @constraint(maxLength: 3)
+		}
+		type Query { createUser(input: UserInput, age: Int @constraint(min: 0, max: 150), code: String): String }
+	`)
+	inputValidators = map[string]InputValidator{}
+	t.Cleanup(func() { inputValidators = map[string]InputValidator{} })
+	RegisterArgumentValidator("Query", "createUser", "code", func(value interface{}) error {
+		if value != "secret" {
+			return errors.New("must be the secret code")
+		}
+		return nil
+	})
+
+	tests := []struct {
+		query, message string
+		path           []interface{}
+	}{
+		{`{ createUser(input: {name: "Ann", email: "ann@example.com", tags: ["a"]}, age: 30, code: "secret") }`, "", nil},
+		{`{ createUser(age: 200) }`, `Argument "age" of field "Query.createUser" got invalid value 200; must be at most 150.`, []interface{}{"age"}},
+		{`{ createUser(input: {name: "A"}) }`, `Argument "input" of field "Query.createUser" got invalid value "A" at "input.name"; must be at least 2 characters long.`, []interface{}{"input", "name"}},
+		{`{ createUser(input: {email: "nope"}) }`, `Argument "input" of field "Query.createUser" got invalid value "nope" at "input.email"; must match the pattern "^[^@]+@[^@]+$".`, []interface{}{"input", "email"}},
+		{`{ createUser(input: {tags: ["ok", "toolong"]}) }`, `Argument "input" of field "Query.createUser" got invalid value "toolong" at "input.tags[1]"; must be at most 3 characters long.`, []interface{}{"input", "tags", 1.0}},
+		{`{ createUser(code: "guess") }`, `Argument "code" of field "Query.createUser" got invalid value "guess"; must be the secret code.`, []interface{}{"code"}},
+	}
+	for _, tt := range tests {
+		calls = 0
+		body, _ := json.Marshal(map[string]string{"query": tt.query})
+		rr := httptest.NewRecorder()
+		GraphqlHandler(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+		var resp struct {
+			Data   map[string]interface{}
+			Errors []*Error
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("%s: %v in %s", tt.query, err, rr.Body.String())
+		}
+		if tt.message == "" {
+			if len(resp.Errors) != 0 || resp.Data["createUser"] != "ok" {
+				t.Errorf("%s: unexpected response %s", tt.query, rr.Body.String())
+			}
+			continue
+		}
+		if len(resp.Errors) != 1 || resp.Errors[0].Message != tt.message {
+			t.Errorf("%s: got %s, want error %q", tt.query, rr.Body.String(), tt.message)
+			continue
+		}
+		if err := resp.Errors[0]; err.Extensions["code"] != CodeBadUserInput || !equalJSON(err.Extensions["argumentPath"], tt.path) {
+			t.Errorf("%s: unexpected extensions %v", tt.query, err.Extensions)
+		}
+		if calls != 0 || resp.Data["createUser"] != nil {
+			t.Errorf("%s: expected the resolver not to run", tt.query)
+		}
+	}
+}
+
+func equalJSON(a, b interface{}) bool {
+	x, _ := json.Marshal(a)
+	y, _ := json.Marshal(b)
+	return string(x) == string(y)
+}
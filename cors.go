@@ -0,0 +1,77 @@
+package vibeGraphql
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// CORS configures the cross-origin requests browsers may send; see WithCORS.
+type CORS struct {
+	// AllowedOrigins lists the origins, such as "https://app.example.com",
+	// allowed to send requests. "*" allows any origin.
+	AllowedOrigins []string
+	// AllowedHeaders lists the request headers allowed in cross-origin
+	// requests. When empty, the headers a preflight request asks for are
+	// allowed.
+	AllowedHeaders []string
+	// AllowCredentials lets cross-origin requests carry cookies.
+	AllowCredentials bool
+	// MaxAge is how long browsers may cache the answer to a preflight
+	// request. Zero leaves it to the browser.
+	MaxAge time.Duration
+}
+
+// WithCORS answers cross-origin requests from the allowed origins, including
+// the OPTIONS preflight requests browsers send before them. Requests from
+// other origins are served without CORS headers, so browsers do not expose
+// the responses.
+func WithCORS(cors CORS) HandlerOption {
+	return func(h *handler) {
+		h.cors = &cors
+	}
+}
+
+// allowedMethods are the methods the handlers serve.
+const allowedMethods = "GET, POST, OPTIONS"
+
+// allows reports whether requests from origin are allowed.
+func (c *CORS) allows(origin string) bool {
+	for _, allowed := range c.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+	}
+	return false
+}
+
+// writeHeaders sets the CORS headers of the response to r.
+func (c *CORS) writeHeaders(w http.ResponseWriter, r *http.Request) {
+	if c == nil {
+		return
+	}
+	header := w.Header()
+	header.Add("Vary", "Origin")
+	origin := r.Header.Get("Origin")
+	if origin == "" || !c.allows(origin) {
+		return
+	}
+	header.Set("Access-Control-Allow-Origin", origin)
+	if c.AllowCredentials {
+		header.Set("Access-Control-Allow-Credentials", "true")
+	}
+	if r.Method != http.MethodOptions || r.Header.Get("Access-Control-Request-Method") == "" {
+		return
+	}
+	header.Set("Access-Control-Allow-Methods", allowedMethods)
+	if len(c.AllowedHeaders) > 0 {
+		header.Set("Access-Control-Allow-Headers", strings.Join(c.AllowedHeaders, ", "))
+	} else if requested := r.Header.Get("Access-Control-Request-Headers"); requested != "" {
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Headers", requested)
+	}
+	if c.MaxAge > 0 {
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.MaxAge.Seconds())))
+	}
+}
@@ -0,0 +1,94 @@
+package vibeGraphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestHandlerMethodsAndContentTypes(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { hello: String }`)
+
+	for _, tt := range []struct {
+		name        string
+		handler     http.HandlerFunc
+		method      string
+		contentType string
+		want        int
+	}{
+		{"post", GraphqlHandler, "POST", "", http.StatusOK},
+		{"json", GraphqlHandler, "POST", "application/json; charset=utf-8", http.StatusOK},
+		{"json suffix", GraphqlHandler, "POST", "application/graphql+json", http.StatusOK},
+		{"get", GraphqlHandler, "GET", "", http.StatusOK},
+		{"put", GraphqlHandler, "PUT", "application/json", http.StatusMethodNotAllowed},
+		{"delete", GraphqlUploadHandler, "DELETE", "", http.StatusMethodNotAllowed},
+		{"options", GraphqlHandler, "OPTIONS", "", http.StatusNoContent},
+		{"text", GraphqlHandler, "POST", "text/plain", http.StatusUnsupportedMediaType},
+		{"xml", GraphqlUploadHandler, "POST", "application/xml", http.StatusUnsupportedMediaType},
+		{"invalid", GraphqlHandler, "POST", "application/", http.StatusUnsupportedMediaType},
+		{"multipart without uploads", GraphqlHandler, "POST", "multipart/form-data; boundary=x", http.StatusUnsupportedMediaType},
+	} {
+		req := httptest.NewRequest(tt.method, "/graphql?query=%7B+hello+%7D", strings.NewReader(`{"query": "{ hello }"}`))
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		rr := httptest.NewRecorder()
+		tt.handler(rr, req)
+		if rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d: %s", tt.name, rr.Code, tt.want, rr.Body.String())
+		}
+		if (tt.want == http.StatusMethodNotAllowed || tt.want == http.StatusNoContent) && rr.Header().Get("Allow") != "GET, POST, OPTIONS" {
+			t.Errorf("%s: unexpected Allow header %q", tt.name, rr.Header().Get("Allow"))
+		}
+	}
+}
+
+func TestWithCORS(t *testing.T) {
+	h := newHandler([]HandlerOption{WithCORS(CORS{
+		AllowedOrigins:   []string{"https://app.example.com"},
+		AllowCredentials: true,
+		MaxAge:           10 * time.Minute,
+	})})
+	preflight := func(origin string) http.Header {
+		req := httptest.NewRequest("OPTIONS", "/graphql", nil)
+		req.Header.Set("Origin", origin)
+		req.Header.Set("Access-Control-Request-Method", "POST")
+		req.Header.Set("Access-Control-Request-Headers", "content-type, authorization")
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, req)
+		if rr.Code != http.StatusNoContent {
+			t.Errorf("unexpected preflight status %d", rr.Code)
+		}
+		return rr.Header()
+	}
+
+	header := preflight("https://app.example.com")
+	for key, want := range map[string]string{
+		"Access-Control-Allow-Origin":      "https://app.example.com",
+		"Access-Control-Allow-Credentials": "true",
+		"Access-Control-Allow-Methods":     "GET, POST, OPTIONS",
+		"Access-Control-Allow-Headers":     "content-type, authorization",
+		"Access-Control-Max-Age":           "600",
+	} {
+		if got := header.Get(key); got != want {
+			t.Errorf("%s: got %q, want %q", key, got, want)
+		}
+	}
+	if header := preflight("https://evil.example.com"); header.Get("Access-Control-Allow-Origin") != "" || header.Get("Vary") != "Origin" {
+		t.Errorf("expected other origins to get no CORS headers, got %v", header)
+	}
+
+	// Actual requests carry the origin headers too.
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ __typename }"}`))
+	req.Header.Set("Origin", "https://app.example.com")
+	rr := httptest.NewRecorder()
+	h.serveUpload(rr, req)
+	if rr.Header().Get("Access-Control-Allow-Origin") != "https://app.example.com" || rr.Header().Get("Access-Control-Allow-Methods") != "" {
+		t.Errorf("unexpected headers %v", rr.Header())
+	}
+}
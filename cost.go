@@ -0,0 +1,81 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"strconv"
+)
+
+// CostLimit enables query cost analysis. The cost of an operation is its
+// OperationComplexity. Every response reports the cost in its "cost"
+// extension and in X-GraphQL-Cost headers, along with the limit and the
+// client's remaining budget when those are configured, so clients can adapt
+// their queries.
+type CostLimit struct {
+	// MaxCost is the highest cost a single operation may have. Zero means no
+	// limit.
+	MaxCost int
+	// Key identifies the client Budget is tracked for, as in RateLimit.
+	Key func(r *http.Request) string
+	// Budget is the total cost each client may spend per period. It is
+	// restored continuously. Operations over budget fail with a RATE_LIMITED
+	// error. A zero Budget is unlimited.
+	Budget Rate
+}
+
+// WithCostLimit analyzes the cost of every operation according to limit.
+func WithCostLimit(limit CostLimit) HandlerOption {
+	return func(h *handler) {
+		h.costs = newRateLimiter()
+		h.costLimit = limit
+	}
+}
+
+// chargeCost reports the cost of op and charges it against the budget of the
+// client sending r. It returns an error and the status to respond with if op
+// may not run.
+func (h *handler) chargeCost(w http.ResponseWriter, r *http.Request, op *OperationDefinition) (*Error, int) {
+	if h.costs == nil || op == nil {
+		return nil, http.StatusOK
+	}
+	limit := h.costLimit
+	cost := OperationComplexity(op)
+	report := map[string]interface{}{"requested": cost}
+	defer SetResponseExtension(r.Context(), "cost", report)
+	w.Header().Set("X-GraphQL-Cost", strconv.Itoa(cost))
+
+	if limit.MaxCost > 0 {
+		report["maximum"] = limit.MaxCost
+		w.Header().Set("X-GraphQL-Cost-Limit", strconv.Itoa(limit.MaxCost))
+		if cost > limit.MaxCost {
+			return NewError(CodeOperationLimitExceeded,
+				fmt.Sprintf("Operation has a cost of %d, exceeding the limit of %d.", cost, limit.MaxCost)), http.StatusOK
+		}
+	}
+
+	if limit.Key == nil || limit.Budget.Limit <= 0 || limit.Budget.Period <= 0 {
+		return nil, http.StatusOK
+	}
+	key := limit.Key(r)
+	if key == "" {
+		return nil, http.StatusOK
+	}
+	wait, remaining := h.costs.take(rateKey{op: "cost", client: key}, limit.Budget, float64(cost))
+	report["budget"] = limit.Budget.Limit
+	report["remaining"] = int(remaining)
+	w.Header().Set("X-GraphQL-Cost-Budget", strconv.Itoa(limit.Budget.Limit))
+	w.Header().Set("X-GraphQL-Cost-Remaining", strconv.Itoa(int(remaining)))
+	if wait <= 0 {
+		return nil, http.StatusOK
+	}
+	if cost > limit.Budget.Limit {
+		return NewError(CodeOperationLimitExceeded,
+			fmt.Sprintf("Operation has a cost of %d, exceeding the budget of %d.", cost, limit.Budget.Limit)), http.StatusOK
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	err := NewError(CodeRateLimited, fmt.Sprintf("Operation has a cost of %d, but only %d remains of the budget; retry after %d seconds.", cost, int(remaining), seconds))
+	err.Extensions["retryAfter"] = seconds
+	w.Header().Set("Retry-After", strconv.Itoa(seconds))
+	return err, http.StatusTooManyRequests
+}
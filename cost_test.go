@@ -0,0 +1,95 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithCostLimit(t *testing.T) {
+	type item struct{ A, B, C int }
+	QueryResolvers["costly"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return item{1, 2, 3}, nil
+	}
+	defer delete(QueryResolvers, "costly")
+
+	h := newHandler([]HandlerOption{WithCostLimit(CostLimit{
+		MaxCost: 4,
+		Key:     func(r *http.Request) string { return "client" },
+		Budget:  Rate{Limit: 6, Period: time.Minute},
+	})})
+	now := time.Unix(0, 0)
+	h.costs.now = func() time.Time { return now }
+
+	type response struct {
+		Errors     []*Error
+		Extensions map[string]interface{}
+	}
+	send := func(query string) (*httptest.ResponseRecorder, response) {
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`)))
+		var resp response
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatalf("invalid response %s", rr.Body.String())
+		}
+		return rr, resp
+	}
+
+	rr, resp := send("{ costly { A B } }")
+	if rr.Code != http.StatusOK || len(resp.Errors) != 0 {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Body.String())
+	}
+	want := map[string]interface{}{"requested": 3.0, "maximum": 4.0, "budget": 6.0, "remaining": 3.0}
+	for key, value := range want {
+		if resp.Extensions["cost"].(map[string]interface{})[key] != value {
+			t.Errorf("cost extension %s: got %v, want %v", key, resp.Extensions["cost"], want)
+		}
+	}
+	if rr.Header().Get("X-GraphQL-Cost") != "3" || rr.Header().Get("X-GraphQL-Cost-Limit") != "4" ||
+		rr.Header().Get("X-GraphQL-Cost-Budget") != "6" || rr.Header().Get("X-GraphQL-Cost-Remaining") != "3" {
+		t.Errorf("unexpected headers %v", rr.Header())
+	}
+
+	_, resp = send("{ costly { A B C } x: costly { A } }")
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "Operation has a cost of 6, exceeding the limit of 4." {
+		t.Errorf("unexpected errors %v", resp.Errors)
+	}
+
+	send("{ costly { A } }")
+	rr, resp = send("{ costly { A B } }")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "20" || len(resp.Errors) != 1 ||
+		resp.Errors[0].Extensions["code"] != CodeRateLimited {
+		t.Errorf("expected budget to be exhausted, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	now = now.Add(20 * time.Second)
+	if rr, _ := send("{ costly { A B } }"); rr.Code != http.StatusOK || rr.Header().Get("X-GraphQL-Cost-Remaining") != "0" {
+		t.Errorf("expected budget to be restored, got %d %v", rr.Code, rr.Header())
+	}
+}
+
+func TestWithCostLimit_CachedResponse(t *testing.T) {
+	useTestSchema(t, cacheSDL)
+	QueryResolvers["version"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "v1", nil
+	}
+	defer delete(QueryResolvers, "version")
+
+	h := newHandler([]HandlerOption{
+		WithResponseCache(ResponseCache{Store: NewMemoryCache(10)}),
+		WithCostLimit(CostLimit{Key: func(r *http.Request) string { return "client" }, Budget: Rate{Limit: 10, Period: time.Hour}}),
+	})
+	var bodies []string
+	for i := 0; i < 2; i++ {
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ version }"}`)))
+		bodies = append(bodies, rr.Body.String())
+	}
+	if !strings.Contains(bodies[0], `"remaining":9`) || !strings.Contains(bodies[1], `"remaining":8`) ||
+		!strings.Contains(bodies[1], `"version":"v1"`) {
+		t.Errorf("expected cached responses to report the current cost, got %q", bodies)
+	}
+}
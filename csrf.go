@@ -0,0 +1,60 @@
+package vibeGraphql
+
+import (
+	"mime"
+	"net/http"
+	"strings"
+)
+
+// DefaultCSRFHeaders are the headers WithCSRFPrevention accepts without any.
+var DefaultCSRFHeaders = []string{"GraphQL-Require-Preflight", "Apollo-Require-Preflight", "X-Apollo-Operation-Name"}
+
+// WithCSRFPrevention blocks requests a browser could send cross-site without
+// a CORS preflight, protecting endpoints that authenticate with cookies. A
+// request is served only if its Content-Type is not one a plain HTML form can
+// send (application/x-www-form-urlencoded, multipart/form-data or
+// text/plain), or if it carries a non-empty value for one of headers
+// (DefaultCSRFHeaders if none are given). GET requests and multipart uploads
+// therefore need one of the headers. Blocked requests are answered with
+// 400 Bad Request.
+func WithCSRFPrevention(headers ...string) HandlerOption {
+	if len(headers) == 0 {
+		headers = DefaultCSRFHeaders
+	}
+	return func(h *handler) {
+		h.csrfHeaders = headers
+	}
+}
+
+// simpleContentTypes are the content types of requests that do not need a
+// CORS preflight.
+var simpleContentTypes = map[string]bool{
+	"application/x-www-form-urlencoded": true,
+	"multipart/form-data":               true,
+	"text/plain":                        true,
+}
+
+// blockCSRF responds with an error and returns true if r may be a
+// cross-site request forgery.
+func (h *handler) blockCSRF(w http.ResponseWriter, r *http.Request) bool {
+	if len(h.csrfHeaders) == 0 {
+		return false
+	}
+	for _, header := range h.csrfHeaders {
+		if r.Header.Get(header) != "" {
+			return false
+		}
+	}
+	if contentType := r.Header.Get("Content-Type"); contentType != "" {
+		mediaType, _, err := mime.ParseMediaType(contentType)
+		if err == nil && !simpleContentTypes[strings.ToLower(mediaType)] {
+			return false
+		}
+	}
+	writeResponse(w, r, http.StatusBadRequest, encodeResponse(map[string]interface{}{"errors": []*Error{{
+		Message: "This operation has been blocked as a potential Cross-Site Request Forgery (CSRF). " +
+			"Send a Content-Type other than application/x-www-form-urlencoded, multipart/form-data or text/plain, " +
+			"or a non-empty value for one of these headers: " + strings.Join(h.csrfHeaders, ", ") + ".",
+	}}}))
+	return true
+}
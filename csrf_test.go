@@ -0,0 +1,65 @@
+package vibeGraphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithCSRFPrevention(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { hello: String }`)
+	h := newHandler([]HandlerOption{WithCSRFPrevention()})
+
+	for _, tt := range []struct {
+		name        string
+		method      string
+		contentType string
+		header      string
+		want        int
+	}{
+		{"json", "POST", "application/json", "", http.StatusOK},
+		{"json with charset", "POST", "application/json; charset=utf-8", "", http.StatusOK},
+		{"text", "POST", "text/plain", "", http.StatusBadRequest},
+		{"form", "POST", "application/x-www-form-urlencoded", "", http.StatusBadRequest},
+		{"multipart", "POST", "multipart/form-data; boundary=x", "", http.StatusBadRequest},
+		{"no content type", "POST", "", "", http.StatusBadRequest},
+		{"get", "GET", "", "", http.StatusBadRequest},
+		{"get with header", "GET", "", "GraphQL-Require-Preflight", http.StatusOK},
+		{"json with header", "POST", "application/json", "Apollo-Require-Preflight", http.StatusOK},
+	} {
+		var req *http.Request
+		if tt.method == "GET" {
+			req = httptest.NewRequest("GET", "/graphql?query=%7B+hello+%7D", nil)
+		} else {
+			req = httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ hello }"}`))
+		}
+		if tt.contentType != "" {
+			req.Header.Set("Content-Type", tt.contentType)
+		}
+		if tt.header != "" {
+			req.Header.Set(tt.header, "1")
+		}
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, req)
+		if rr.Code != tt.want {
+			t.Errorf("%s: got status %d, want %d: %s", tt.name, rr.Code, tt.want, rr.Body.String())
+		}
+		if tt.want == http.StatusBadRequest && !strings.Contains(rr.Body.String(), "Cross-Site Request Forgery") {
+			t.Errorf("%s: unexpected response %s", tt.name, rr.Body.String())
+		}
+	}
+
+	// Custom headers replace the defaults.
+	h = newHandler([]HandlerOption{WithCSRFPrevention("X-CSRF")})
+	req := httptest.NewRequest("GET", "/graphql?query=%7B+hello+%7D", nil)
+	req.Header.Set("GraphQL-Require-Preflight", "1")
+	rr := httptest.NewRecorder()
+	h.serveJSON(rr, req)
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), "X-CSRF") {
+		t.Errorf("expected only the custom header to be accepted, got %d %s", rr.Code, rr.Body.String())
+	}
+}
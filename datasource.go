@@ -0,0 +1,277 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// RESTDataSource wraps a REST API for use in resolvers. GET responses are
+// cached according to their Cache-Control header, and identical GET requests
+// in flight at the same time are sent only once. Requests are identical when
+// they have the same URL and headers, including those WillSendRequest adds,
+// so callers with different credentials never share a response.
+//
+// The cache is shared by all requests, so responses marked "private" are
+// never cached; responses that vary per user should be marked that way.
+type RESTDataSource struct {
+	BaseURL string
+	Client  *http.Client
+
+	// WillSendRequest, when set, is called before each request is sent and
+	// can add headers taken from ctx, e.g. the caller's credentials.
+	WillSendRequest func(ctx context.Context, req *http.Request)
+
+	mu       sync.Mutex
+	cache    map[string]cachedResponse
+	inflight map[string]*inflightRequest
+	now      func() time.Time
+}
+
+type cachedResponse struct {
+	body    []byte
+	expires time.Time
+}
+
+type inflightRequest struct {
+	done chan struct{}
+	body []byte
+	err  error
+}
+
+// NewRESTDataSource creates a RESTDataSource for the API at baseURL.
+func NewRESTDataSource(baseURL string) *RESTDataSource {
+	return &RESTDataSource{BaseURL: baseURL, Client: http.DefaultClient}
+}
+
+// Get fetches path with the query parameters and decodes the JSON response into out.
+func (ds *RESTDataSource) Get(ctx context.Context, path string, query url.Values, out interface{}) error {
+	u := ds.resolveURL(path)
+	if len(query) > 0 {
+		u += "?" + query.Encode()
+	}
+	body, err := ds.cachedGet(ctx, u)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(body, out)
+}
+
+// Post sends body as JSON to path and decodes the JSON response into out.
+func (ds *RESTDataSource) Post(ctx context.Context, path string, body, out interface{}) error {
+	return ds.send(ctx, http.MethodPost, path, body, out)
+}
+
+// Put sends body as JSON to path and decodes the JSON response into out.
+func (ds *RESTDataSource) Put(ctx context.Context, path string, body, out interface{}) error {
+	return ds.send(ctx, http.MethodPut, path, body, out)
+}
+
+// Patch sends body as JSON to path and decodes the JSON response into out.
+func (ds *RESTDataSource) Patch(ctx context.Context, path string, body, out interface{}) error {
+	return ds.send(ctx, http.MethodPatch, path, body, out)
+}
+
+// Delete sends a DELETE request to path and decodes the JSON response into out.
+func (ds *RESTDataSource) Delete(ctx context.Context, path string, out interface{}) error {
+	return ds.send(ctx, http.MethodDelete, path, nil, out)
+}
+
+func (ds *RESTDataSource) resolveURL(path string) string {
+	if strings.Contains(path, "://") {
+		return path
+	}
+	return strings.TrimSuffix(ds.BaseURL, "/") + "/" + strings.TrimPrefix(path, "/")
+}
+
+func (ds *RESTDataSource) clock() time.Time {
+	if ds.now != nil {
+		return ds.now()
+	}
+	return time.Now()
+}
+
+// cachedGet serves u from the cache, joins an identical request already in
+// flight, or sends a new one.
+//
+// A request in flight is shared by several callers, so it is sent with a
+// context that keeps the values of ctx but not its cancellation; a caller
+// whose ctx is canceled stops waiting without failing the others.
+func (ds *RESTDataSource) cachedGet(ctx context.Context, u string) ([]byte, error) {
+	req, err := ds.newRequest(context.WithoutCancel(ctx), http.MethodGet, u, nil)
+	if err != nil {
+		return nil, err
+	}
+	key := getKey(req)
+
+	ds.mu.Lock()
+	if entry, ok := ds.cache[key]; ok {
+		if ds.clock().Before(entry.expires) {
+			ds.mu.Unlock()
+			return entry.body, nil
+		}
+		delete(ds.cache, key)
+	}
+	call, ok := ds.inflight[key]
+	if !ok {
+		call = &inflightRequest{done: make(chan struct{})}
+		if ds.inflight == nil {
+			ds.inflight = make(map[string]*inflightRequest)
+		}
+		ds.inflight[key] = call
+		go ds.fetch(key, req, call)
+	}
+	ds.mu.Unlock()
+
+	select {
+	case <-call.done:
+		return call.body, call.err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+// fetch sends req for the callers waiting on call, caching the response
+// under key if its Cache-Control header allows it.
+func (ds *RESTDataSource) fetch(key string, req *http.Request, call *inflightRequest) {
+	var resp *http.Response
+	call.body, resp, call.err = ds.roundTrip(req)
+
+	ds.mu.Lock()
+	delete(ds.inflight, key)
+	if call.err == nil {
+		if ttl, ok := cacheTTL(resp.Header.Get("Cache-Control")); ok {
+			if ds.cache == nil {
+				ds.cache = make(map[string]cachedResponse)
+			}
+			ds.cache[key] = cachedResponse{body: call.body, expires: ds.clock().Add(ttl)}
+		}
+	}
+	ds.mu.Unlock()
+	close(call.done)
+}
+
+// getKey identifies a GET request by its URL and headers.
+func getKey(req *http.Request) string {
+	var sb strings.Builder
+	sb.WriteString(req.URL.String())
+	sb.WriteString("\n")
+	req.Header.Write(&sb) // Header.Write sorts the header names.
+	return sb.String()
+}
+
+func (ds *RESTDataSource) send(ctx context.Context, method, path string, body, out interface{}) error {
+	var payload []byte
+	if body != nil {
+		var err error
+		if payload, err = json.Marshal(body); err != nil {
+			return err
+		}
+	}
+	req, err := ds.newRequest(ctx, method, ds.resolveURL(path), payload)
+	if err != nil {
+		return err
+	}
+	data, _, err := ds.roundTrip(req)
+	if err != nil {
+		return err
+	}
+	return decodeJSON(data, out)
+}
+
+// newRequest creates a request with the headers set by WillSendRequest.
+func (ds *RESTDataSource) newRequest(ctx context.Context, method, u string, payload []byte) (*http.Request, error) {
+	var reader io.Reader
+	if payload != nil {
+		reader = bytes.NewReader(payload)
+	}
+	req, err := http.NewRequestWithContext(ctx, method, u, reader)
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Accept", "application/json")
+	if payload != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+	if ds.WillSendRequest != nil {
+		ds.WillSendRequest(ctx, req)
+	}
+	return req, nil
+}
+
+// roundTrip sends req and returns the body of a successful response.
+func (ds *RESTDataSource) roundTrip(req *http.Request) ([]byte, *http.Response, error) {
+	client := ds.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, err
+	}
+	defer resp.Body.Close()
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, nil, err
+	}
+	if resp.StatusCode < 200 || resp.StatusCode > 299 {
+		return nil, nil, restError(req.Method, req.URL.String(), resp.StatusCode)
+	}
+	return data, resp, nil
+}
+
+// restError reports a failed REST call as a GraphQL error, so only the
+// fields depending on it resolve to null.
+func restError(method, u string, status int) *Error {
+	code := "HTTP_ERROR"
+	switch status {
+	case http.StatusUnauthorized:
+		code = "UNAUTHENTICATED"
+	case http.StatusForbidden:
+		code = CodePermissionDenied
+	case http.StatusNotFound:
+		code = "NOT_FOUND"
+	}
+	err := NewError(code, fmt.Sprintf("%s %s: %d %s", method, u, status, http.StatusText(status)))
+	err.Extensions["status"] = status
+	return err
+}
+
+// cacheTTL returns how long a response with the given Cache-Control header
+// may be cached by a shared cache.
+func cacheTTL(header string) (time.Duration, bool) {
+	var maxAge, sMaxAge = -1, -1
+	for _, directive := range strings.Split(header, ",") {
+		name, value, _ := strings.Cut(strings.TrimSpace(strings.ToLower(directive)), "=")
+		switch name {
+		case "no-store", "no-cache", "private":
+			return 0, false
+		case "max-age":
+			maxAge, _ = strconv.Atoi(strings.Trim(value, `"`))
+		case "s-maxage":
+			sMaxAge, _ = strconv.Atoi(strings.Trim(value, `"`))
+		}
+	}
+	if sMaxAge >= 0 {
+		maxAge = sMaxAge
+	}
+	if maxAge <= 0 {
+		return 0, false
+	}
+	return time.Duration(maxAge) * time.Second, true
+}
+
+func decodeJSON(data []byte, out interface{}) error {
+	if out == nil || len(bytes.TrimSpace(data)) == 0 {
+		return nil
+	}
+	return json.Unmarshal(data, out)
+}
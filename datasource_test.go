@@ -0,0 +1,219 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+type tokenKey struct{}
+
+func TestRESTDataSource_GetCachesAndInjectsHeaders(t *testing.T) {
+	var hits int32
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			w.WriteHeader(http.StatusUnauthorized)
+			return
+		}
+		switch r.URL.Path {
+		case "/users/1":
+			w.Header().Set("Cache-Control", "public, max-age=60")
+		case "/users/2":
+			w.Header().Set("Cache-Control", "private, max-age=60")
+		}
+		w.Write([]byte(`{"id": "` + r.URL.Path[len("/users/"):] + `", "q": "` + r.URL.Query().Get("q") + `"}`))
+	}))
+	defer srv.Close()
+
+	now := time.Now()
+	ds := NewRESTDataSource(srv.URL + "/")
+	ds.now = func() time.Time { return now }
+	ds.WillSendRequest = func(ctx context.Context, req *http.Request) {
+		if token, ok := ctx.Value(tokenKey{}).(string); ok {
+			req.Header.Set("Authorization", "Bearer "+token)
+		}
+	}
+	ctx := context.WithValue(context.Background(), tokenKey{}, "secret")
+
+	var user struct{ ID, Q string }
+	for i := 0; i < 2; i++ {
+		if err := ds.Get(ctx, "/users/1", url.Values{"q": {"x"}}, &user); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if user.ID != "1" || user.Q != "x" || hits != 1 {
+		t.Errorf("expected one cached request, got %+v after %d hits", user, hits)
+	}
+
+	now = now.Add(2 * time.Minute)
+	if err := ds.Get(ctx, "/users/1", url.Values{"q": {"x"}}, &user); err != nil || hits != 2 {
+		t.Errorf("expected the cache entry to expire, got %d hits, %v", hits, err)
+	}
+
+	ds.Get(ctx, "users/2", nil, &user)
+	ds.Get(ctx, "users/2", nil, &user)
+	if hits != 4 {
+		t.Errorf("expected private responses not to be cached, got %d hits", hits)
+	}
+
+	err := ds.Get(context.Background(), "/users/1", nil, &user)
+	var gqlErr *Error
+	if !errors.As(err, &gqlErr) || gqlErr.Extensions["code"] != "UNAUTHENTICATED" || gqlErr.Extensions["status"] != 401 {
+		t.Errorf("unexpected error: %v", err)
+	}
+}
+
+func TestRESTDataSource_DeduplicatesInFlightGets(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+	ds := NewRESTDataSource(srv.URL)
+
+	var wg sync.WaitGroup
+	results := make([]string, 5)
+	for i := range results {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			ds.Get(context.Background(), "/slow", nil, &results[i])
+		}(i)
+	}
+	// Give the goroutines time to join the in-flight request.
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if hits != 1 {
+		t.Errorf("expected 1 upstream request, got %d", hits)
+	}
+	for _, r := range results {
+		if r != "ok" {
+			t.Errorf("unexpected results: %v", results)
+			break
+		}
+	}
+}
+
+func TestRESTDataSource_DeduplicatesPerCredentials(t *testing.T) {
+	var hits int32
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&hits, 1)
+		<-release
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`"` + r.Header.Get("Authorization") + `"`))
+	}))
+	defer srv.Close()
+	ds := NewRESTDataSource(srv.URL)
+	ds.WillSendRequest = func(ctx context.Context, req *http.Request) {
+		req.Header.Set("Authorization", ctx.Value(tokenKey{}).(string))
+	}
+
+	var wg sync.WaitGroup
+	tokens := []string{"ada", "bob", "ada"}
+	results := make([]string, len(tokens))
+	for i, token := range tokens {
+		wg.Add(1)
+		go func(i int, token string) {
+			defer wg.Done()
+			ds.Get(context.WithValue(context.Background(), tokenKey{}, token), "/me", nil, &results[i])
+		}(i, token)
+	}
+	time.Sleep(50 * time.Millisecond)
+	close(release)
+	wg.Wait()
+	if hits != 2 || results[0] != "ada" || results[1] != "bob" || results[2] != "ada" {
+		t.Errorf("expected one request per token, got %v after %d hits", results, hits)
+	}
+
+	var cached string
+	ds.Get(context.WithValue(context.Background(), tokenKey{}, "bob"), "/me", nil, &cached)
+	if hits != 2 || cached != "bob" {
+		t.Errorf("expected the cache to be keyed by token, got %q after %d hits", cached, hits)
+	}
+}
+
+func TestRESTDataSource_CanceledCallerDoesNotFailOthers(t *testing.T) {
+	release := make(chan struct{})
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		<-release
+		w.Write([]byte(`"ok"`))
+	}))
+	defer srv.Close()
+	ds := NewRESTDataSource(srv.URL)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	first := make(chan error)
+	go func() {
+		var out string
+		first <- ds.Get(ctx, "/slow", nil, &out)
+	}()
+	time.Sleep(20 * time.Millisecond)
+	second := make(chan string)
+	go func() {
+		var out string
+		ds.Get(context.Background(), "/slow", nil, &out)
+		second <- out
+	}()
+	time.Sleep(20 * time.Millisecond)
+	cancel()
+	if err := <-first; !errors.Is(err, context.Canceled) {
+		t.Errorf("expected the canceled caller to stop waiting, got %v", err)
+	}
+	close(release)
+	if got := <-second; got != "ok" {
+		t.Errorf("expected the other caller to get the response, got %q", got)
+	}
+}
+
+func TestRESTDataSource_Post(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Method != http.MethodPost || r.Header.Get("Content-Type") != "application/json" {
+			w.WriteHeader(http.StatusBadRequest)
+			return
+		}
+		w.Header().Set("Cache-Control", "max-age=60")
+		w.Write([]byte(`{"created": true}`))
+	}))
+	defer srv.Close()
+	ds := NewRESTDataSource(srv.URL)
+	var out struct{ Created bool }
+	if err := ds.Post(context.Background(), "/users", map[string]string{"name": "Ada"}, &out); err != nil || !out.Created {
+		t.Errorf("Post = %+v, %v", out, err)
+	}
+	if len(ds.cache) != 0 {
+		t.Error("expected non-GET responses not to be cached")
+	}
+}
+
+func TestCacheTTL(t *testing.T) {
+	tests := []struct {
+		header string
+		ttl    time.Duration
+		ok     bool
+	}{
+		{"max-age=30", 30 * time.Second, true},
+		{"public, max-age=30, s-maxage=90", 90 * time.Second, true},
+		{"no-store", 0, false},
+		{"max-age=30, no-cache", 0, false},
+		{"", 0, false},
+		{"max-age=0", 0, false},
+	}
+	for _, tt := range tests {
+		ttl, ok := cacheTTL(tt.header)
+		if ttl != tt.ttl || ok != tt.ok {
+			t.Errorf("cacheTTL(%q) = %v, %v; want %v, %v", tt.header, ttl, ok, tt.ttl, tt.ok)
+		}
+	}
+}
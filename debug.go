@@ -0,0 +1,231 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding"
+	"encoding/json"
+	"fmt"
+	"math"
+	"net/http"
+	"reflect"
+	"runtime/debug"
+	"strings"
+	"sync"
+	"time"
+)
+
+// WithDebug turns on the debug mode, meant for development. Responses are
+// indented, and carry a "debug" extension listing the time taken by each
+// resolver; panics in resolvers are reported as errors with a "stacktrace"
+// extension instead of failing the request. When a schema is loaded,
+// resolver results that do not match their field's type, such as a string
+// for an Int or null for a non-null field, are reported as errors. Keep it off in production, as
+// the extensions reveal the server's internals.
+//
+// Regardless of this option, responses to requests with a "pretty=1" or
+// "pretty=true" URL parameter are indented.
+func WithDebug() HandlerOption {
+	return func(h *handler) {
+		h.debug = true
+	}
+}
+
+type debugTraceKey struct{}
+
+// debugTrace collects the resolver timings of a request served in debug mode.
+type debugTrace struct {
+	start     time.Time
+	mu        sync.Mutex
+	resolvers []resolverTiming
+}
+
+// resolverTiming is an entry of the "resolvers" list of the debug extension.
+// Offsets and durations are in nanoseconds, as in Apollo tracing.
+type resolverTiming struct {
+	Path        []interface{} `json:"path"`
+	ParentType  string        `json:"parentType"`
+	FieldName   string        `json:"fieldName"`
+	StartOffset int64         `json:"startOffset"`
+	Duration    int64         `json:"duration"`
+}
+
+func debugTraceFrom(ctx context.Context) *debugTrace {
+	trace, _ := ctx.Value(debugTraceKey{}).(*debugTrace)
+	return trace
+}
+
+// record adds the timing of the field described by info, which started
+// resolving at start.
+func (t *debugTrace) record(info *ResolveInfo, start time.Time) {
+	timing := resolverTiming{
+		Path:        info.Path,
+		ParentType:  info.ParentType,
+		FieldName:   info.FieldName,
+		StartOffset: start.Sub(t.start).Nanoseconds(),
+		Duration:    time.Since(start).Nanoseconds(),
+	}
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.resolvers = append(t.resolvers, timing)
+}
+
+// extension returns the value of the debug extension.
+func (t *debugTrace) extension() map[string]interface{} {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return map[string]interface{}{
+		"duration":  time.Since(t.start).Nanoseconds(),
+		"resolvers": append([]resolverTiming{}, t.resolvers...),
+	}
+}
+
+// recoverResolverPanic turns a panic of the resolver of the field resolving
+// in ctx into an error with the panic's stack trace.
+func recoverResolverPanic(ctx context.Context, err *error) {
+	recovered := recover()
+	if recovered == nil {
+		return
+	}
+	gqlErr := NewError(CodeInternalServerError, fmt.Sprintf("panic: %v", recovered))
+	gqlErr.Extensions["stacktrace"] = strings.Split(strings.TrimSpace(string(debug.Stack())), "\n")
+	if info := ResolveInfoFromContext(ctx); info != nil {
+		gqlErr.Path = info.Path
+	}
+	*err = gqlErr
+}
+
+// prettyRequested reports whether the response to r should be indented.
+func prettyRequested(r *http.Request) bool {
+	if debugTraceFrom(r.Context()) != nil {
+		return true
+	}
+	switch r.URL.Query().Get("pretty") {
+	case "1", "true":
+		return true
+	}
+	return false
+}
+
+// indentJSON returns body indented, or body itself if it is not valid JSON.
+func indentJSON(body []byte) []byte {
+	var buf bytes.Buffer
+	if err := json.Indent(&buf, body, "", "  "); err != nil {
+		return body
+	}
+	return buf.Bytes()
+}
+
+// checkResultType returns an error if res, the result of the resolver of
+// field on parentType, does not conform to the field's type in s, such as
+// "Resolver for User.age returned string, expected Int." It is used in debug
+// mode to point at resolvers returning the wrong kind of value.
+func checkResultType(s *Schema, parentType string, field *Field, res interface{}) *Error {
+	if s == nil || strings.HasPrefix(parentType, "__") || strings.HasPrefix(field.Name, "__") {
+		return nil
+	}
+	def := s.Field(parentType, field.Name)
+	if def == nil || def.Type == nil {
+		return nil
+	}
+	mismatch := resultMismatch(s, def.Type, res)
+	if mismatch == "" {
+		return nil
+	}
+	return NewError(CodeInternalServerError, fmt.Sprintf("Resolver for %s.%s returned %s, expected %s.", parentType, field.Name, mismatch, typeString(def.Type)))
+}
+
+// resultMismatch describes the part of v that does not conform to t, or
+// returns "" if v conforms.
+func resultMismatch(s *Schema, t *Type, v interface{}) string {
+	if isNullResult(v) {
+		if t.NonNull {
+			return "null"
+		}
+		return ""
+	}
+	if t.IsList {
+		val := reflect.Indirect(reflect.ValueOf(v))
+		if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+			return fmt.Sprintf("%T", v)
+		}
+		for i := 0; i < val.Len(); i++ {
+			if mismatch := resultMismatch(s, t.Elem, val.Index(i).Interface()); mismatch != "" {
+				return fmt.Sprintf("%s at index %d", mismatch, i)
+			}
+		}
+		return ""
+	}
+	td := s.Type(t.Name)
+	if td == nil {
+		return ""
+	}
+	switch td.kind() {
+	case KindScalar, KindEnum:
+		leaf, err := serializeLeaf(v)
+		if err != nil {
+			// Serialization errors are reported when the field completes.
+			return ""
+		}
+		if leaf == nil {
+			if t.NonNull {
+				return "null"
+			}
+			return ""
+		}
+		if !scalarConforms(td, leaf) {
+			return fmt.Sprintf("%T", v)
+		}
+	default:
+		switch reflect.Indirect(reflect.ValueOf(v)).Kind() {
+		case reflect.Struct, reflect.Map:
+		default:
+			return fmt.Sprintf("%T", v)
+		}
+	}
+	return ""
+}
+
+// isNullResult reports whether v is nil or a nil pointer, map, slice or
+// interface.
+func isNullResult(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	switch val := reflect.ValueOf(v); val.Kind() {
+	case reflect.Ptr, reflect.Map, reflect.Slice, reflect.Interface:
+		return val.IsNil()
+	}
+	return false
+}
+
+// scalarConforms reports whether leaf, a serialized value, is a valid value
+// of the scalar or enum type td. Custom scalars accept any value, as do all
+// scalars for values that marshal themselves.
+func scalarConforms(td *TypeDefinition, leaf interface{}) bool {
+	switch leaf.(type) {
+	case json.Marshaler, encoding.TextMarshaler:
+		return true
+	}
+	val := reflect.ValueOf(leaf)
+	kind := val.Kind()
+	// Integers decoded from JSON, as by ResolverCache, arrive as float64.
+	integer := kind >= reflect.Int && kind <= reflect.Uint64 ||
+		(kind == reflect.Float32 || kind == reflect.Float64) && val.Float() == math.Trunc(val.Float())
+	if td.kind() == KindEnum {
+		return kind == reflect.String
+	}
+	switch td.Name {
+	case "Int":
+		return integer
+	case "Float":
+		return integer || kind == reflect.Float32 || kind == reflect.Float64
+	case "String":
+		return kind == reflect.String
+	case "Boolean":
+		return kind == reflect.Bool
+	case "ID":
+		return integer || kind == reflect.String
+	}
+	return true
+}
@@ -0,0 +1,129 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithDebug(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+		"boom":  func(source interface{}, args map[string]interface{}) (interface{}, error) { panic("kaboom") },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { hello: String boom: String }`)
+	h := newHandler([]HandlerOption{WithDebug()})
+
+	rr := httptest.NewRecorder()
+	h.serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ hello boom }"}`)))
+	body := rr.Body.String()
+	if !strings.Contains(body, "\n  \"data\": {") {
+		t.Errorf("expected an indented response, got %s", body)
+	}
+	var resp struct {
+		Data   map[string]interface{}
+		Errors []struct {
+			Message    string
+			Path       []interface{}
+			Extensions map[string]interface{}
+		}
+		Extensions struct {
+			Debug struct {
+				Resolvers []resolverTiming
+			}
+		}
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["hello"] != "world" || resp.Data["boom"] != nil {
+		t.Errorf("unexpected data %v", resp.Data)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "panic: kaboom" || len(resp.Errors[0].Path) != 1 || resp.Errors[0].Path[0] != "boom" {
+		t.Fatalf("unexpected errors %+v", resp.Errors)
+	}
+	if stack, _ := resp.Errors[0].Extensions["stacktrace"].([]interface{}); len(stack) == 0 || !strings.Contains(stack[0].(string), "goroutine") {
+		t.Errorf("expected a stack trace, got %v", resp.Errors[0].Extensions["stacktrace"])
+	}
+	fields := map[string]bool{}
+	for _, timing := range resp.Extensions.Debug.Resolvers {
+		fields[timing.FieldName] = timing.ParentType == "Query" && timing.Duration > 0
+	}
+	if !fields["hello"] || !fields["boom"] {
+		t.Errorf("expected timings of both resolvers, got %+v", resp.Extensions.Debug.Resolvers)
+	}
+}
+
+func TestPrettyParameter(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { hello: String }`)
+	for target, want := range map[string]string{
+		"/graphql":          `{"data":{"hello":"world"}}`,
+		"/graphql?pretty=1": "{\n  \"data\": {\n    \"hello\": \"world\"\n  }\n}",
+	} {
+		rr := httptest.NewRecorder()
+		GraphqlHandler(rr, httptest.NewRequest("POST", target, strings.NewReader(`{"query": "{ hello }"}`)))
+		if got := strings.TrimSpace(rr.Body.String()); got != want {
+			t.Errorf("%s: got %s, want %s", target, got, want)
+		}
+		if strings.Contains(rr.Body.String(), "debug") {
+			t.Errorf("%s: expected no debug extension without WithDebug", target)
+		}
+	}
+}
+
+func TestDebugResultTypeChecking(t *testing.T) {
+	type User struct {
+		Age  interface{}
+		Tags []interface{}
+	}
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return &User{Age: "42", Tags: []interface{}{"a", 1}}, nil
+		},
+		"count": func(source interface{}, args map[string]interface{}) (interface{}, error) { return nil, nil },
+		"name":  func(source interface{}, args map[string]interface{}) (interface{}, error) { return 7, nil },
+		"ratio": func(source interface{}, args map[string]interface{}) (interface{}, error) { return 2, nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `
+		type User { Age: Int Tags: [String] }
+		type Query { user: User count: Int! name: User ratio: Float }
+	`)
+	query := `{"query": "{ user { Age Tags } count name { Age } ratio }"}`
+
+	rr := httptest.NewRecorder()
+	newHandler([]HandlerOption{WithDebug()}).serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(query)))
+	var resp struct {
+		Errors []*Error
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	messages := map[string]bool{}
+	for _, err := range resp.Errors {
+		messages[err.Message] = true
+	}
+	for _, want := range []string{
+		"Resolver for User.Age returned string, expected Int.",
+		"Resolver for User.Tags returned int at index 1, expected [String].",
+		"Resolver for Query.count returned null, expected Int!.",
+		"Resolver for Query.name returned int, expected User.",
+	} {
+		if !messages[want] {
+			t.Errorf("missing error %q in %v", want, messages)
+		}
+	}
+	if len(resp.Errors) != 4 {
+		t.Errorf("expected 4 errors, got %v", messages)
+	}
+
+	// Outside debug mode, results are not checked.
+	rr = httptest.NewRecorder()
+	newHandler(nil).serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(query)))
+	if strings.Contains(rr.Body.String(), "Resolver for") {
+		t.Errorf("unexpected type errors in %s", rr.Body.String())
+	}
+}
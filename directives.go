@@ -0,0 +1,114 @@
+package vibeGraphql
+
+import "context"
+
+// DirectiveFunc runs around the resolution of a field that carries the
+// directive, either on its schema definition or in the query. args holds the
+// directive's arguments and next resolves the field (or the next directive).
+// Details about the field are available via ResolveInfoFromContext.
+type DirectiveFunc func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error)
+
+// DirectiveHandlers maps directive names to their runtime handlers.
+var DirectiveHandlers = make(map[string]DirectiveFunc)
+
+// RegisterDirective registers the runtime handler for the directive name.
+func RegisterDirective(name string, handler DirectiveFunc) {
+	DirectiveHandlers[name] = handler
+}
+
+// applyDirectives resolves field on source, wrapped with the handlers of every
+// directive attached to the field. Schema directives run outermost, in
+// declaration order, followed by the directives written in the query.
+func (e *executor) applyDirectives(ctx context.Context, source interface{}, parentType string, field *Field) (interface{}, error) {
+	var directives []Directive
+	if e.plan != nil {
+		directives = e.plan.field(parentType, field).directives
+	} else {
+		directives = fieldDirectives(parentType, field)
+	}
+	if len(directives) == 0 {
+		return e.resolve(ctx, source, parentType, field)
+	}
+
+	next := func(ctx context.Context) (interface{}, error) {
+		return e.resolve(ctx, source, parentType, field)
+	}
+	for i := len(directives) - 1; i >= 0; i-- {
+		handler, ok := DirectiveHandlers[directives[i].Name]
+		if !ok {
+			continue
+		}
+		args := buildArgumentValues(directives[i].Arguments, e.variables)
+		inner := next
+		next = func(ctx context.Context) (interface{}, error) {
+			return handler(ctx, args, inner)
+		}
+	}
+	return next(ctx)
+}
+
+// fieldDirectives returns the directives of field's schema definition
+// followed by those written in the query.
+func fieldDirectives(parentType string, field *Field) []Directive {
+	var directives []Directive
+	if s := CurrentSchema(); s != nil {
+		if def := s.Field(parentType, field.Name); def != nil {
+			directives = append(directives, def.Directives...)
+		}
+	}
+	return append(directives, field.Directives...)
+}
+
+// directiveLocations are the locations a directive definition may name.
+var directiveLocations = map[string]bool{
+	"QUERY": true, "MUTATION": true, "SUBSCRIPTION": true, "FIELD": true,
+	"FRAGMENT_DEFINITION": true, "FRAGMENT_SPREAD": true, "INLINE_FRAGMENT": true,
+	"VARIABLE_DEFINITION": true, "SCHEMA": true, "SCALAR": true, "OBJECT": true,
+	"FIELD_DEFINITION": true, "ARGUMENT_DEFINITION": true, "INTERFACE": true,
+	"UNION": true, "ENUM": true, "ENUM_VALUE": true, "INPUT_OBJECT": true,
+	"INPUT_FIELD_DEFINITION": true,
+}
+
+// specifiedDirectives are the definitions of the directives every schema
+// supports, which schemas may not redefine.
+var specifiedDirectives = map[string]*DirectiveDefinition{
+	"skip": {
+		Name:      "skip",
+		Arguments: []*InputValueDefinition{{Name: "if", Type: &Type{Name: "Boolean", NonNull: true}}},
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+	},
+	"include": {
+		Name:      "include",
+		Arguments: []*InputValueDefinition{{Name: "if", Type: &Type{Name: "Boolean", NonNull: true}}},
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+	},
+	"deprecated": {
+		Name: "deprecated",
+		Arguments: []*InputValueDefinition{{
+			Name:         "reason",
+			Type:         &Type{Name: "String"},
+			DefaultValue: &Value{Kind: "String", Literal: defaultDeprecationReason},
+		}},
+		Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+	},
+}
+
+// shouldIncludeField evaluates the built-in @skip and @include directives.
+func shouldIncludeField(field *Field, variables map[string]interface{}) bool {
+	return shouldInclude(field.Directives, variables)
+}
+
+// shouldInclude reports whether the @skip and @include directives among
+// directives leave the field or fragment they are attached to in.
+func shouldInclude(directives []Directive, variables map[string]interface{}) bool {
+	for _, d := range directives {
+		if d.Name != "skip" && d.Name != "include" {
+			continue
+		}
+		cond, _ := buildArgumentValues(d.Arguments, variables)["if"].(bool)
+		if d.Name == "skip" && cond || d.Name == "include" && !cond {
+			return false
+		}
+	}
+	return true
+}
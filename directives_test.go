@@ -0,0 +1,162 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestParser_DirectiveDefinition(t *testing.T) {
+	doc := NewParser(NewLexer(`directive @auth(role: String!) repeatable on FIELD_DEFINITION | OBJECT`)).ParseDocument()
+	dd, ok := doc.Definitions[0].(*DirectiveDefinition)
+	if !ok {
+		t.Fatalf("expected DirectiveDefinition, got %T", doc.Definitions[0])
+	}
+	if dd.Name != "auth" || !dd.Repeatable || len(dd.Arguments) != 1 || dd.Arguments[0].Type.Name != "String" {
+		t.Errorf("unexpected directive definition: %+v", dd)
+	}
+	if len(dd.Locations) != 2 || dd.Locations[0] != "FIELD_DEFINITION" || dd.Locations[1] != "OBJECT" {
+		t.Errorf("unexpected locations: %v", dd.Locations)
+	}
+}
+
+func TestDirectiveHandlers(t *testing.T) {
+	useTestSchema(t, `
+		directive @auth(role: String!) on FIELD_DEFINITION
+		directive @upper on FIELD
+		type Query {
+			secret: String @auth(role: "admin")
+			greeting: String
+		}
+	`)
+	RegisterFieldResolver("Query", "secret", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "s3cr3t", nil
+	})
+	RegisterFieldResolver("Query", "greeting", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hello", nil
+	})
+	RegisterDirective("auth", func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+		if args["role"] != "admin" {
+			return nil, fmt.Errorf("forbidden")
+		}
+		if info := ResolveInfoFromContext(ctx); info == nil || info.FieldName != "secret" {
+			return nil, fmt.Errorf("missing resolve info")
+		}
+		return next(ctx)
+	})
+	RegisterDirective("upper", func(ctx context.Context, args map[string]interface{}, next func(ctx context.Context) (interface{}, error)) (interface{}, error) {
+		res, err := next(ctx)
+		if s, ok := res.(string); ok {
+			return strings.ToUpper(s), err
+		}
+		return res, err
+	})
+	t.Cleanup(func() {
+		delete(DirectiveHandlers, "auth")
+		delete(DirectiveHandlers, "upper")
+		delete(FieldResolvers, "Query")
+	})
+
+	data := executeQuery(t, `{ secret greeting @upper }`, nil)
+	if data["secret"] != "s3cr3t" {
+		t.Errorf("expected secret to resolve, got %v", data["secret"])
+	}
+	if data["greeting"] != "HELLO" {
+		t.Errorf("expected upper-cased greeting, got %v", data["greeting"])
+	}
+
+	data = executeQuery(t, `{ __schema { directives { name } } }`, nil)
+	directives := data["__schema"].(map[string]interface{})["directives"].([]interface{})
+	if len(directives) != len(builtinDirectives)+2 {
+		t.Errorf("expected custom directives in introspection, got %v", directives)
+	}
+}
+
+func TestSkipAndIncludeDirectives(t *testing.T) {
+	RegisterQueryResolver("skipMe", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "value", nil
+	})
+	data := executeQuery(t, `query ($s: Boolean) { skipMe @skip(if: $s) }`, map[string]interface{}{"s": true})
+	if _, ok := data["skipMe"]; ok {
+		t.Error("expected field to be skipped")
+	}
+	data = executeQuery(t, `{ skipMe @include(if: false) }`, nil)
+	if _, ok := data["skipMe"]; ok {
+		t.Error("expected field to be excluded")
+	}
+	data = executeQuery(t, `{ skipMe @include(if: true) @skip(if: false) }`, nil)
+	if data["skipMe"] != "value" {
+		t.Errorf("expected field to be included, got %v", data["skipMe"])
+	}
+}
+
+func TestParser_DirectiveDefinitionErrors(t *testing.T) {
+	tests := map[string]string{
+		`directive auth on FIELD`:            `Syntax Error: Expected "@", found IDENT "auth".`,
+		`directive @(role: String) on FIELD`: `Syntax Error: Expected a directive name, found "(".`,
+		`directive @auth(role: String)`:      `Syntax Error: Expected "on", found <EOF>.`,
+		`directive @auth on FIELD | METHOD`:  `Syntax Error: Expected a directive location, found IDENT "METHOD".`,
+	}
+	for input, want := range tests {
+		p := NewParser(NewLexer(input))
+		p.ParseDocument()
+		if errs := p.Errors(); len(errs) == 0 || errs[0].Message != want {
+			t.Errorf("%s: got %v, want %q", input, errs, want)
+		}
+	}
+}
+
+func TestValidate_Directives(t *testing.T) {
+	s, err := ParseSchema(`
+		directive @upper on FIELD
+		directive @tag(name: String!) repeatable on FIELD | QUERY
+		directive @trace(level: Level = INFO, sample: Float) on QUERY | MUTATION
+		directive @auth(role: String!) on FIELD_DEFINITION
+		enum Level { INFO DEBUG }
+		type User { id: ID! name: String }
+		type Query { user: User version: String @auth(role: "admin") }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`query @trace(level: DEBUG, sample: 1) @tag(name: "a") { version @upper @tag(name: "b") @tag(name: "c") }`, ""},
+		{`query ($on: Boolean!) { user { ...f @include(if: $on) ... @skip(if: false) { id } } } fragment f on User { name }`, ""},
+		{`{ version @lower }`, `Unknown directive "@lower".`},
+		{`{ version @auth(role: "admin") }`, `Directive "@auth" may not be used on FIELD.`},
+		{`query @upper { version }`, `Directive "@upper" may not be used on QUERY.`},
+		{`{ user { ...f } } fragment f on User @include(if: true) { id }`, `Directive "@include" may not be used on FRAGMENT_DEFINITION.`},
+		{`{ version @upper @upper }`, `The directive "@upper" can only be used once at this location.`},
+		{`{ version @include(if: true, unless: false) }`, `Unknown argument "unless" on directive "@include".`},
+		{`{ version @tag(nmae: "a", name: "b") }`, `Unknown argument "nmae" on directive "@tag". Did you mean "name"?`},
+		{`{ version @skip }`, `Directive "@skip" argument "if" of type "Boolean!" is required, but it was not provided.`},
+		{`{ version @include(if: "yes") }`, `Boolean cannot represent a non boolean value: "yes"`},
+		{`{ version @include(if: null) }`, `Expected value of type "Boolean!", found null.`},
+		{`{ version @tag(name: 1) }`, `String cannot represent a non string value: 1`},
+		{`query @trace(level: TRACE) { version }`, `Value "TRACE" does not exist in "Level" enum.`},
+		{`query @trace(level: "INFO") { version }`, `Enum "Level" cannot represent non-enum value: "INFO".`},
+		{`query @trace(sample: true) { version }`, `Float cannot represent non numeric value: true`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, parseQuery(tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+
+	errs := Validate(s, parseQuery("{\n  version @lower\n}"))
+	if len(errs) != 1 || len(errs[0].Locations) != 1 || errs[0].Locations[0] != (Location{Line: 2, Column: 11}) {
+		t.Errorf("expected an error at the directive, got %+v", errs)
+	}
+}
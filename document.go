@@ -23,7 +23,10 @@ type OperationDefinition struct {
 	Operation           string
 	Name                string
 	VariableDefinitions []VariableDefinition
+	Directives          []Directive
 	SelectionSet        *SelectionSet
+	// Loc is where the definition starts in the parsed document.
+	Loc Location
 }
 
 func (op *OperationDefinition) TokenLiteral() string {
@@ -36,6 +39,8 @@ func (op *OperationDefinition) TokenLiteral() string {
 type VariableDefinition struct {
 	Variable string
 	Type     Type
+	// Loc is where the definition starts in the parsed document.
+	Loc Location
 }
 
 func (v *VariableDefinition) TokenLiteral() string {
@@ -53,14 +58,84 @@ type SelectionSet struct {
 	Selections []Selection
 }
 
+func (ss *SelectionSet) TokenLiteral() string {
+	return "{"
+}
+
 type Selection interface {
 	Node
 }
 
+// FragmentDefinition is a named fragment such as
+// "fragment userFields on User { id name }".
+type FragmentDefinition struct {
+	Name          string
+	TypeCondition string
+	Directives    []Directive
+	SelectionSet  *SelectionSet
+	// Loc is where the definition starts in the parsed document.
+	Loc Location
+}
+
+func (f *FragmentDefinition) TokenLiteral() string {
+	return f.Name
+}
+
+// FragmentSpread is a selection such as "...userFields".
+type FragmentSpread struct {
+	Name       string
+	Directives []Directive
+	Loc        Location
+	// Fragment is the definition of the spread fragment, linked when the
+	// document is parsed. It is nil if the document does not define the
+	// fragment, or if the spread is part of a cycle of fragments.
+	Fragment *FragmentDefinition
+}
+
+func (s *FragmentSpread) TokenLiteral() string {
+	return s.Name
+}
+
+// InlineFragment is a selection such as "... on User { name }". Its
+// TypeCondition is empty if it applies to every type.
+type InlineFragment struct {
+	TypeCondition string
+	Directives    []Directive
+	SelectionSet  *SelectionSet
+	Loc           Location
+}
+
+func (f *InlineFragment) TokenLiteral() string {
+	return f.TypeCondition
+}
+
 type Field struct {
+	Alias        string
 	Name         string
 	Arguments    []Argument
 	SelectionSet *SelectionSet
+	Directives   []Directive
+	// Loc is where the field starts in the parsed document.
+	Loc Location
+
+	// Schema-only information, populated when the field is part of a type definition.
+	Type                *Type
+	ArgumentDefinitions []*InputValueDefinition
+	Description         string
+}
+
+// ResponseKey returns the key the field's result is reported under: its alias
+// if one was given, otherwise its name.
+func (f *Field) ResponseKey() string {
+	if f.Alias != "" {
+		return f.Alias
+	}
+	return f.Name
+}
+
+// Deprecation reports whether the field is marked @deprecated and the reason given.
+func (f *Field) Deprecation() (string, bool) {
+	return deprecationOf(f.Directives)
 }
 
 func (f *Field) TokenLiteral() string {
@@ -87,12 +162,105 @@ func (v *Value) TokenLiteral() string {
 	return v.Literal
 }
 
+// Directive represents a directive usage such as @deprecated(reason: "...").
+type Directive struct {
+	Name      string
+	Arguments []Argument
+	// Loc is where the directive's "@" is in the parsed document.
+	Loc Location
+}
+
+func (d *Directive) TokenLiteral() string {
+	return d.Name
+}
+
+// DirectiveDefinition represents an SDL directive declaration such as
+// "directive @auth(role: String!) on FIELD_DEFINITION".
+type DirectiveDefinition struct {
+	Name        string
+	Description string
+	Arguments   []*InputValueDefinition
+	Locations   []string
+	Repeatable  bool
+}
+
+func (d *DirectiveDefinition) TokenLiteral() string {
+	return d.Name
+}
+
+// Type definition kinds, named after their introspection __TypeKind values.
+const (
+	KindScalar      = "SCALAR"
+	KindObject      = "OBJECT"
+	KindInterface   = "INTERFACE"
+	KindUnion       = "UNION"
+	KindEnum        = "ENUM"
+	KindInputObject = "INPUT_OBJECT"
+)
+
 // TypeDefinition represents a GraphQL type definition (e.g. "type Query { ... }").
 type TypeDefinition struct {
-	Name   string
-	Fields []*Field
+	Kind        string // one of the Kind* constants; defaults to KindObject
+	Name        string
+	Description string
+	Fields      []*Field
+	Interfaces  []string                // for objects and interfaces
+	Types       []string                // union members
+	EnumValues  []*EnumValueDefinition  // for enums
+	InputFields []*InputValueDefinition // for input objects
+	Directives  []Directive
+	// Extension is set for definitions such as "extend type Query { ... }",
+	// which add to a type defined elsewhere.
+	Extension bool
 }
 
 func (t *TypeDefinition) TokenLiteral() string {
 	return t.Name
 }
+
+// InputValueDefinition describes an argument or an input object field.
+type InputValueDefinition struct {
+	Name         string
+	Description  string
+	Type         *Type
+	DefaultValue *Value
+	Directives   []Directive
+}
+
+func (v *InputValueDefinition) TokenLiteral() string {
+	return v.Name
+}
+
+// EnumValueDefinition describes a single value of an enum type.
+type EnumValueDefinition struct {
+	Name        string
+	Description string
+	Directives  []Directive
+}
+
+func (v *EnumValueDefinition) TokenLiteral() string {
+	return v.Name
+}
+
+// Deprecation reports whether the enum value is marked @deprecated and the reason given.
+func (v *EnumValueDefinition) Deprecation() (string, bool) {
+	return deprecationOf(v.Directives)
+}
+
+// defaultDeprecationReason is the reason used when @deprecated is given without one.
+const defaultDeprecationReason = "No longer supported"
+
+func deprecationOf(directives []Directive) (string, bool) {
+	for _, d := range directives {
+		if d.Name != "deprecated" {
+			continue
+		}
+		for _, arg := range d.Arguments {
+			if arg.Name == "reason" && arg.Value != nil && arg.Value.Kind == "String" {
+				return arg.Value.Literal, true
+			}
+		}
+		return defaultDeprecationReason, true
+	}
+	return "", false
+}
@@ -0,0 +1,63 @@
+// Package echoadapter serves vibeGraphql handlers from an Echo router.
+package echoadapter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/labstack/echo/v4"
+)
+
+type echoContextKey struct{}
+
+// FromContext returns the echo.Context of the request a resolver runs in, or nil.
+func FromContext(ctx context.Context) echo.Context {
+	c, _ := ctx.Value(echoContextKey{}).(echo.Context)
+	return c
+}
+
+// Wrap converts an http.Handler into an echo.HandlerFunc. The echo.Context is
+// made available to resolvers through FromContext.
+func Wrap(h http.Handler) echo.HandlerFunc {
+	return func(c echo.Context) error {
+		ctx := context.WithValue(c.Request().Context(), echoContextKey{}, c)
+		h.ServeHTTP(c.Response(), c.Request().WithContext(ctx))
+		return nil
+	}
+}
+
+// Handler serves queries and mutations, including multipart uploads.
+func Handler() echo.HandlerFunc {
+	return Wrap(graphql.GraphqlUploadHandler)
+}
+
+// SubscriptionHandler serves subscriptions over WebSocket.
+func SubscriptionHandler() echo.HandlerFunc {
+	return Wrap(graphql.SubscriptionHandler)
+}
+
+// PlaygroundHandler serves the in-browser IDE.
+func PlaygroundHandler(endpoint, subscriptionEndpoint string) echo.HandlerFunc {
+	return Wrap(graphql.PlaygroundHandler(endpoint, subscriptionEndpoint))
+}
+
+// Router is implemented by *echo.Echo and *echo.Group.
+type Router interface {
+	GET(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+	POST(path string, h echo.HandlerFunc, m ...echo.MiddlewareFunc) *echo.Route
+}
+
+// Mount registers the same endpoints as graphql.Mount on an Echo router or group.
+func Mount(r Router, path string) {
+	path = strings.TrimSuffix(path, "/")
+	r.POST(path, Handler())
+	r.GET(path+"/ws", SubscriptionHandler())
+	r.POST(path+"/upload", Handler())
+	r.GET(path+"/playground", func(c echo.Context) error {
+		// c.Path includes the prefix of the group the routes were mounted on.
+		base := strings.TrimSuffix(c.Path(), "/playground")
+		return PlaygroundHandler(base, base+"/ws")(c)
+	})
+}
@@ -0,0 +1,77 @@
+package echoadapter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gorilla/websocket"
+	"github.com/labstack/echo/v4"
+)
+
+func newRouter(t *testing.T) *httptest.Server {
+	t.Helper()
+	graphql.RegisterFieldResolver("Query", "route", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return FromContext(ctx).Path(), nil
+	})
+	graphql.RegisterSubscriptionResolver("echoTicks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- 1
+		close(ch)
+		return ch, nil
+	})
+	e := echo.New()
+	Mount(e.Group("/api"), "/graphql")
+	srv := httptest.NewServer(e)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMount_Query(t *testing.T) {
+	srv := newRouter(t)
+	resp, err := http.Post(srv.URL+"/api/graphql", "application/json", strings.NewReader(`{"query": "{ route }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body.String(), `"route":"/api/graphql"`) {
+		t.Errorf("unexpected response: %s", body.String())
+	}
+
+	resp, err = http.Get(srv.URL + "/api/graphql/playground")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body.Reset()
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body.String(), `"/api/graphql/ws"`) {
+		t.Errorf("unexpected playground response %d: %s", resp.StatusCode, body.String())
+	}
+}
+
+func TestMount_Subscription(t *testing.T) {
+	srv := newRouter(t)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/graphql/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(graphql.SubscriptionRequest{Query: "subscription { echoTicks }"}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "1\n" {
+		t.Errorf("unexpected event %q, %v", msg, err)
+	}
+}
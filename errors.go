@@ -0,0 +1,98 @@
+package vibeGraphql
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+)
+
+// Error is a GraphQL error as reported in the "errors" list of a response.
+// When a resolver returns an *Error, the field resolves to null and the error
+// is reported alongside the data of its sibling fields.
+type Error struct {
+	Message    string                 `json:"message"`
+	Locations  []Location             `json:"locations,omitempty"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Location is a position in a GraphQL document. Lines and columns start at 1.
+type Location struct {
+	Line   int `json:"line"`
+	Column int `json:"column"`
+}
+
+func (e *Error) Error() string {
+	return e.Message
+}
+
+// Error codes reported in the "code" extension.
+const (
+	CodePermissionDenied       = "PERMISSION_DENIED"
+	CodeOperationLimitExceeded = "OPERATION_LIMIT_EXCEEDED"
+	CodeInternalServerError    = "INTERNAL_SERVER_ERROR"
+	CodeRateLimited            = "RATE_LIMITED"
+	CodeReadOnly               = "READ_ONLY"
+	CodeTimeout                = "TIMEOUT"
+	CodeUnavailable            = "SERVICE_UNAVAILABLE"
+	CodeBadUserInput           = "BAD_USER_INPUT"
+)
+
+// NewError creates an Error with the given message and extension code.
+func NewError(code, message string) *Error {
+	return &Error{Message: message, Extensions: map[string]interface{}{"code": code}}
+}
+
+// asError returns err as a GraphQL error, wrapping it if necessary.
+func asError(err error) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) {
+		return gqlErr
+	}
+	return &Error{Message: err.Error()}
+}
+
+// MaskInternalErrors enables the production error mode. Errors that are not
+// *Error values, such as a failed database query, may contain internal
+// details; when this is set they are reported to clients only as "internal
+// server error" with a correlation ID, and the original error is passed to
+// InternalErrorHook. Errors created with NewError are reported unchanged.
+var MaskInternalErrors = false
+
+// InternalErrorHook receives each masked error together with the correlation
+// ID reported to the client. By default the error is logged to Logger.
+var InternalErrorHook = func(ctx context.Context, correlationID string, err error) {
+	args := []interface{}{"correlationId", correlationID, "error", err}
+	if id := RequestID(ctx); id != "" {
+		args = append(args, "requestId", id)
+	}
+	logger().ErrorContext(ctx, "graphql: internal error", args...)
+}
+
+// maskError returns err as a GraphQL error. Unless err already is one, it is
+// masked when MaskInternalErrors is set.
+func maskError(ctx context.Context, err error) *Error {
+	var gqlErr *Error
+	if errors.As(err, &gqlErr) || !MaskInternalErrors {
+		return asError(err)
+	}
+	id := newCorrelationID()
+	if InternalErrorHook != nil {
+		InternalErrorHook(ctx, id, err)
+	}
+	masked := NewError(CodeInternalServerError, "internal server error")
+	masked.Extensions["correlationId"] = id
+	return masked
+}
+
+func newCorrelationID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	return hex.EncodeToString(b[:])
+}
+
+// ErrPermissionDenied returns the standard error reported for denied fields.
+func ErrPermissionDenied() *Error {
+	return NewError(CodePermissionDenied, "permission denied")
+}
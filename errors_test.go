@@ -0,0 +1,147 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func useMaskedErrors(t *testing.T) map[string]error {
+	t.Helper()
+	logged := make(map[string]error)
+	prevMask, prevHook := MaskInternalErrors, InternalErrorHook
+	MaskInternalErrors = true
+	InternalErrorHook = func(ctx context.Context, id string, err error) { logged[id] = err }
+	t.Cleanup(func() { MaskInternalErrors, InternalErrorHook = prevMask, prevHook })
+	return logged
+}
+
+func TestMaskInternalErrors_Handler(t *testing.T) {
+	logged := useMaskedErrors(t)
+	QueryResolvers["leaky"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, errors.New(`pq: relation "users" does not exist`)
+	}
+	defer delete(QueryResolvers, "leaky")
+
+	body, _ := json.Marshal(map[string]interface{}{"query": "{ leaky }"})
+	w := httptest.NewRecorder()
+	GraphqlHandler(w, httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body)))
+	if w.Code != http.StatusOK {
+		t.Fatalf("expected 200, got %d", w.Code)
+	}
+	if strings.Contains(w.Body.String(), "pq:") {
+		t.Fatalf("internal error leaked to the client: %s", w.Body)
+	}
+	var resp struct{ Errors []Error }
+	if err := json.Unmarshal(w.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.HasPrefix(w.Body.String(), `{"data":null,`) {
+		t.Errorf("expected explicit null data after a failure at the root, got %s", w.Body)
+	}
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "internal server error" || resp.Errors[0].Extensions["code"] != CodeInternalServerError {
+		t.Fatalf("unexpected response %s", w.Body)
+	}
+	id, _ := resp.Errors[0].Extensions["correlationId"].(string)
+	if logged[id] == nil || !strings.Contains(logged[id].Error(), "pq:") {
+		t.Errorf("expected the original error to be logged under %q, got %v", id, logged)
+	}
+}
+
+func TestMaskInternalErrors_FieldErrors(t *testing.T) {
+	logged := useMaskedErrors(t)
+	RegisterSerializer(money(0), func(v interface{}) (interface{}, error) {
+		return nil, errors.New("decimal: overflow at 0xc000")
+	})
+	defer delete(Serializers, reflect.TypeOf(money(0)))
+	QueryResolvers["price"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return money(5), nil
+	}
+	QueryResolvers["denied"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return nil, ErrPermissionDenied()
+	}
+	defer delete(QueryResolvers, "price")
+	defer delete(QueryResolvers, "denied")
+
+	resp, err := executeDocument(context.Background(), parseQuery(`{ price denied }`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs := resp["errors"].([]*Error)
+	var messages []string
+	for _, e := range errs {
+		messages = append(messages, e.Message)
+	}
+	if strings.Join(messages, "|") != "internal server error|permission denied" {
+		t.Errorf("unexpected errors %q", messages)
+	}
+	if len(logged) != 1 {
+		t.Errorf("expected one logged error, got %v", logged)
+	}
+}
+
+func TestErrorPaths(t *testing.T) {
+	type address struct{ ID int }
+	type user struct{ Address *address }
+	users := make([]*user, 5)
+	for i := range users {
+		users[i] = &user{Address: &address{ID: i}}
+	}
+	notFound := NewError("NOT_FOUND", "no zip code")
+	var failure error
+	useResolvers(t, map[string]ResolverFunc{
+		"users": func(source interface{}, args map[string]interface{}) (interface{}, error) { return users, nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"address": {"zip": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			switch source.(*address).ID {
+			case 1, 3:
+				return nil, failure
+			}
+			return "12345", nil
+		}},
+	})
+	defer func(prev int) { ListConcurrency = prev }(ListConcurrency)
+
+	for _, concurrency := range []int{1, 4} {
+		ListConcurrency = concurrency
+
+		failure = notFound
+		result, err := executeRequest(context.Background(), `{ users { address { zip } } }`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs := result["errors"].([]*Error)
+		want := [][]interface{}{{"users", 1, "address", "zip"}, {"users", 3, "address", "zip"}}
+		if len(errs) != len(want) {
+			t.Fatalf("ListConcurrency %d: unexpected errors %+v", concurrency, errs)
+		}
+		for i, err := range errs {
+			if !reflect.DeepEqual(err.Path, want[i]) {
+				t.Errorf("ListConcurrency %d: error %d path = %v, want %v", concurrency, i, err.Path, want[i])
+			}
+		}
+		if notFound.Path != nil {
+			t.Errorf("the error returned by the resolver must not be modified")
+		}
+
+		// Other errors stop execution, and still say where they occurred.
+		failure = errors.New("database unavailable")
+		result, err = executeRequest(context.Background(), `{ users { address { zip } } }`, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs = result["errors"].([]*Error)
+		if len(errs) != 1 || result["data"] != nil || errs[0].Message != "database unavailable" {
+			t.Fatalf("ListConcurrency %d: unexpected result %v", concurrency, result)
+		}
+		if path := errs[0].Path; len(path) != 4 || path[0] != "users" || path[2] != "address" || path[3] != "zip" {
+			t.Errorf("ListConcurrency %d: path = %v", concurrency, path)
+		}
+	}
+}
@@ -0,0 +1,25 @@
+package vibeGraphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"strings"
+)
+
+// responseETag returns a strong entity tag for a serialized response.
+func responseETag(body []byte) string {
+	sum := sha256.Sum256(body)
+	return `"` + hex.EncodeToString(sum[:16]) + `"`
+}
+
+// etagMatches reports whether the If-None-Match header value header matches
+// etag, using the weak comparison RFC 9110 prescribes for If-None-Match.
+func etagMatches(header, etag string) bool {
+	for _, candidate := range strings.Split(header, ",") {
+		candidate = strings.TrimSpace(candidate)
+		if candidate == "*" || strings.TrimPrefix(candidate, "W/") == etag {
+			return true
+		}
+	}
+	return false
+}
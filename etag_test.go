@@ -0,0 +1,78 @@
+package vibeGraphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestGetQueriesWithETag(t *testing.T) {
+	value := "v1"
+	QueryResolvers["etagged"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return value + args["suffix"].(string), nil
+	}
+	MutationResolvers["etagMutation"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("mutations should not run over GET")
+		return nil, nil
+	}
+	defer delete(QueryResolvers, "etagged")
+	defer delete(MutationResolvers, "etagMutation")
+
+	get := func(query, ifNoneMatch string) *httptest.ResponseRecorder {
+		params := url.Values{"query": {query}, "variables": {`{"s": "!"}`}}
+		req := httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil)
+		if ifNoneMatch != "" {
+			req.Header.Set("If-None-Match", ifNoneMatch)
+		}
+		rr := httptest.NewRecorder()
+		GraphqlHandler.ServeHTTP(rr, req)
+		return rr
+	}
+
+	const query = `query ($s: String) { etagged(suffix: $s) }`
+	rr := get(query, "")
+	etag := rr.Header().Get("ETag")
+	if rr.Code != http.StatusOK || etag == "" || !strings.Contains(rr.Body.String(), `"etagged":"v1!"`) {
+		t.Fatalf("unexpected response %d %q %s", rr.Code, etag, rr.Body.String())
+	}
+	if rr := get(query, `"other", W/`+etag); rr.Code != http.StatusNotModified || rr.Body.Len() != 0 {
+		t.Errorf("expected 304 for a matching ETag, got %d %s", rr.Code, rr.Body.String())
+	}
+	value = "v2"
+	if rr := get(query, etag); rr.Code != http.StatusOK || rr.Header().Get("ETag") == etag {
+		t.Errorf("expected changed data to produce a new ETag, got %d %q", rr.Code, rr.Header().Get("ETag"))
+	}
+
+	rr = get(`mutation { etagMutation }`, "")
+	if rr.Code != http.StatusMethodNotAllowed || rr.Header().Get("Allow") != "POST" || rr.Header().Get("ETag") != "" {
+		t.Errorf("unexpected response to a mutation over GET: %d %v", rr.Code, rr.Header())
+	}
+
+	req := httptest.NewRequest("GET", "/graphql?query=%7B+etagged+%7D&variables=%7B", nil)
+	rr = httptest.NewRecorder()
+	GraphqlHandler.ServeHTTP(rr, req)
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected invalid variables to be rejected, got %d", rr.Code)
+	}
+}
+
+func TestETagMatches(t *testing.T) {
+	tests := []struct {
+		header string
+		want   bool
+	}{
+		{`"abc"`, true},
+		{`W/"abc"`, true},
+		{`"x", "abc"`, true},
+		{`*`, true},
+		{`"x"`, false},
+		{``, false},
+	}
+	for _, tt := range tests {
+		if got := etagMatches(tt.header, `"abc"`); got != tt.want {
+			t.Errorf("%q: got %v, want %v", tt.header, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,136 @@
+package vibeGraphql
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// graphqlRequest is the payload of a GraphQL request.
+type graphqlRequest struct {
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	Extensions    map[string]interface{} `json:"extensions"`
+	// DocumentID identifies a persisted document sent in place of the
+	// query. Some clients call it "id".
+	DocumentID string `json:"documentId"`
+	ID         string `json:"id"`
+
+	readBody time.Duration // time taken to read and decode the body
+}
+
+// documentID returns the persisted document the request refers to, if any.
+func (req *graphqlRequest) documentID() string {
+	if req.DocumentID != "" {
+		return req.DocumentID
+	}
+	return req.ID
+}
+
+type requestKey struct{}
+
+// requestOf returns the request being served, or nil.
+func requestOf(ctx context.Context) *graphqlRequest {
+	req, _ := ctx.Value(requestKey{}).(*graphqlRequest)
+	return req
+}
+
+// RequestExtensions returns the "extensions" member of the request being
+// served, or nil if it had none.
+func RequestExtensions(ctx context.Context) map[string]interface{} {
+	if req := requestOf(ctx); req != nil {
+		return req.Extensions
+	}
+	return nil
+}
+
+// RequestOperationName returns the "operationName" member of the request
+// being served, or "" if it had none.
+func RequestOperationName(ctx context.Context) string {
+	if req := requestOf(ctx); req != nil {
+		return req.OperationName
+	}
+	return ""
+}
+
+// RequestDocumentID returns the persisted document the request being served
+// refers to with its "documentId" or "id" member, or "" if it sent none.
+func RequestDocumentID(ctx context.Context) string {
+	if req := requestOf(ctx); req != nil {
+		return req.documentID()
+	}
+	return ""
+}
+
+type responseExtensionsKey struct{}
+
+// responseExtensions collects the entries of a response's "extensions" object.
+type responseExtensions struct {
+	mu      sync.Mutex
+	entries map[string]interface{}
+}
+
+// WithResponseExtensions returns a context in which SetResponseExtension
+// takes effect. The handlers do this for every request; HTTP middleware that
+// wants to add extensions before calling the handler must do it itself and
+// pass the returned context on with the request.
+func WithResponseExtensions(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(responseExtensionsKey{}).(*responseExtensions); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, responseExtensionsKey{}, &responseExtensions{})
+}
+
+// SetResponseExtension sets an entry of the response's "extensions" object,
+// for example tracing or cost information. It can be called from resolvers,
+// directives and middleware, and does nothing if ctx does not belong to a
+// request; see WithResponseExtensions.
+func SetResponseExtension(ctx context.Context, key string, value interface{}) {
+	ext, ok := ctx.Value(responseExtensionsKey{}).(*responseExtensions)
+	if !ok {
+		return
+	}
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	if ext.entries == nil {
+		ext.entries = make(map[string]interface{})
+	}
+	ext.entries[key] = value
+}
+
+// responseExtensionsOf returns the extensions set in ctx, or nil if there
+// are none.
+func responseExtensionsOf(ctx context.Context) map[string]interface{} {
+	ext, ok := ctx.Value(responseExtensionsKey{}).(*responseExtensions)
+	if !ok {
+		return nil
+	}
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	if len(ext.entries) == 0 {
+		return nil
+	}
+	entries := make(map[string]interface{}, len(ext.entries))
+	for key, value := range ext.entries {
+		entries[key] = value
+	}
+	return entries
+}
+
+// updateResponseExtension replaces the entry key of the response's
+// "extensions" object with update applied to its current value, which is nil
+// if the entry is not set. update runs under the extensions' lock, so
+// concurrent resolvers can add to the same entry.
+func updateResponseExtension(ctx context.Context, key string, update func(value interface{}) interface{}) {
+	ext, ok := ctx.Value(responseExtensionsKey{}).(*responseExtensions)
+	if !ok {
+		return
+	}
+	ext.mu.Lock()
+	defer ext.mu.Unlock()
+	if ext.entries == nil {
+		ext.entries = make(map[string]interface{})
+	}
+	ext.entries[key] = update(ext.entries[key])
+}
@@ -0,0 +1,106 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestExtensionsPassthrough(t *testing.T) {
+	RegisterFieldResolver("Query", "traced", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		SetResponseExtension(ctx, "tracing", map[string]interface{}{"spans": 1})
+		return RequestExtensions(ctx)["client"], nil
+	})
+	defer delete(FieldResolvers["Query"], "traced")
+
+	middleware := func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			ctx := WithResponseExtensions(r.Context())
+			SetResponseExtension(ctx, "region", "eu")
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+
+	body := `{"query": "{ traced }", "extensions": {"client": "web"}}`
+	rr := httptest.NewRecorder()
+	middleware(GraphqlHandler).ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	var resp struct {
+		Data       map[string]interface{}
+		Extensions map[string]interface{}
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatalf("invalid response %s", rr.Body.String())
+	}
+	if resp.Data["traced"] != "web" {
+		t.Errorf("expected resolver to see request extensions, got %v", resp.Data)
+	}
+	if resp.Extensions["region"] != "eu" || resp.Extensions["tracing"] == nil {
+		t.Errorf("unexpected response extensions %v", resp.Extensions)
+	}
+
+	params := url.Values{"query": {"{ traced }"}, "extensions": {`{"client": "cli"}`}}
+	rr = httptest.NewRecorder()
+	GraphqlHandler.ServeHTTP(rr, httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil))
+	if !strings.Contains(rr.Body.String(), `"traced":"cli"`) {
+		t.Errorf("expected GET extensions to be passed through, got %s", rr.Body.String())
+	}
+
+	// Without extensions the response has no "extensions" member.
+	QueryResolvers["plain"] = func(source interface{}, args map[string]interface{}) (interface{}, error) { return 1, nil }
+	defer delete(QueryResolvers, "plain")
+	rr = httptest.NewRecorder()
+	GraphqlHandler.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ plain }"}`)))
+	if strings.Contains(rr.Body.String(), "extensions") {
+		t.Errorf("unexpected extensions in %s", rr.Body.String())
+	}
+
+	// Outside a request, setting an extension is a no-op.
+	SetResponseExtension(context.Background(), "ignored", true)
+}
+
+func TestOperationName(t *testing.T) {
+	var seen []string
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Query": {
+			"which": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				seen = append(seen, RequestOperationName(ctx)+"/"+RequestDocumentID(ctx))
+				return args["n"], nil
+			},
+		},
+	})
+	var logged []RequestLog
+	h := NewHandler(WithLogger(func(ctx context.Context, entry RequestLog) { logged = append(logged, entry) }), WithPlanCache(10))
+
+	send := func(body string) string {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+		return rr.Body.String()
+	}
+	doc := `query A { which(n: 1) } query B { which(n: 2) }`
+	if got := send(`{"query": "` + doc + `", "operationName": "B", "id": "doc-1"}`); !strings.Contains(got, `"which":2`) {
+		t.Errorf("expected operation B to run, got %s", got)
+	}
+	if got := send(`{"query": "` + doc + `", "operationName": "A"}`); !strings.Contains(got, `"which":1`) {
+		t.Errorf("expected operation A to run, got %s", got)
+	}
+	if got := send(`{"query": "` + doc + `", "operationName": "C"}`); !strings.Contains(got, `Unknown operation named \"C\".`) {
+		t.Errorf("expected an unknown operation error, got %s", got)
+	}
+	if want := []string{"B/doc-1", "A/"}; strings.Join(seen, ",") != strings.Join(want, ",") {
+		t.Errorf("resolvers saw %v, want %v", seen, want)
+	}
+	if len(logged) != 3 || logged[0].OperationName != "B" || logged[0].DocumentID != "doc-1" || logged[1].OperationName != "A" {
+		t.Errorf("unexpected request logs %+v", logged)
+	}
+
+	params := url.Values{"query": {doc}, "operationName": {"B"}}
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("GET", "/graphql?"+params.Encode(), nil))
+	if !strings.Contains(rr.Body.String(), `"which":2`) {
+		t.Errorf("expected GET to select operation B, got %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,64 @@
+// Package fiberadapter serves vibeGraphql handlers from a Fiber app.
+package fiberadapter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gofiber/contrib/websocket"
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/adaptor"
+)
+
+type fiberContextKey struct{}
+
+// FromContext returns the *fiber.Ctx of the request a resolver runs in, or nil.
+// Fiber reuses contexts, so the value must not be kept after the resolver returns.
+func FromContext(ctx context.Context) *fiber.Ctx {
+	c, _ := ctx.Value(fiberContextKey{}).(*fiber.Ctx)
+	return c
+}
+
+// Wrap converts an http.Handler into a fiber.Handler. The *fiber.Ctx is made
+// available to resolvers through FromContext.
+func Wrap(h http.Handler) fiber.Handler {
+	return func(c *fiber.Ctx) error {
+		return adaptor.HTTPHandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			h.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), fiberContextKey{}, c)))
+		})(c)
+	}
+}
+
+// Handler serves queries and mutations, including multipart uploads.
+func Handler() fiber.Handler {
+	return Wrap(graphql.GraphqlUploadHandler)
+}
+
+// SubscriptionHandler serves subscriptions over WebSocket. Fiber connections
+// cannot be hijacked through net/http, so the upgrade is done by Fiber's own
+// WebSocket middleware and the connection handed to graphql.ServeSubscription.
+func SubscriptionHandler() fiber.Handler {
+	return websocket.New(func(conn *websocket.Conn) {
+		graphql.ServeSubscription(conn)
+	})
+}
+
+// PlaygroundHandler serves the in-browser IDE.
+func PlaygroundHandler(endpoint, subscriptionEndpoint string) fiber.Handler {
+	return Wrap(graphql.PlaygroundHandler(endpoint, subscriptionEndpoint))
+}
+
+// Mount registers the same endpoints as graphql.Mount on a Fiber app or group.
+func Mount(r fiber.Router, path string) {
+	path = strings.TrimSuffix(path, "/")
+	r.Post(path, Handler())
+	r.Get(path+"/ws", SubscriptionHandler())
+	r.Post(path+"/upload", Handler())
+	r.Get(path+"/playground", func(c *fiber.Ctx) error {
+		// Route().Path includes the prefix of the group the routes were mounted on.
+		base := strings.TrimSuffix(c.Route().Path, "/playground")
+		return PlaygroundHandler(base, base+"/ws")(c)
+	})
+}
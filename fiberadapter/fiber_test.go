@@ -0,0 +1,75 @@
+package fiberadapter
+
+import (
+	"context"
+	"io"
+	"net"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gofiber/fiber/v2"
+	gorilla "github.com/gorilla/websocket"
+)
+
+func newApp() *fiber.App {
+	graphql.RegisterFieldResolver("Query", "route", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return FromContext(ctx).Route().Path, nil
+	})
+	graphql.RegisterSubscriptionResolver("fiberTicks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- 1
+		close(ch)
+		return ch, nil
+	})
+	app := fiber.New(fiber.Config{DisableStartupMessage: true})
+	Mount(app.Group("/api"), "/graphql")
+	return app
+}
+
+func TestMount_Query(t *testing.T) {
+	app := newApp()
+	req := httptest.NewRequest("POST", "/api/graphql", strings.NewReader(`{"query": "{ route }"}`))
+	req.Header.Set("Content-Type", "application/json")
+	resp, err := app.Test(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ := io.ReadAll(resp.Body)
+	if !strings.Contains(string(body), `"route":"/api/graphql"`) {
+		t.Errorf("unexpected response: %s", body)
+	}
+
+	resp, err = app.Test(httptest.NewRequest("GET", "/api/graphql/playground", nil))
+	if err != nil {
+		t.Fatal(err)
+	}
+	body, _ = io.ReadAll(resp.Body)
+	if resp.StatusCode != 200 || !strings.Contains(string(body), `"/api/graphql/ws"`) {
+		t.Errorf("unexpected playground response %d: %s", resp.StatusCode, body)
+	}
+}
+
+func TestMount_Subscription(t *testing.T) {
+	app := newApp()
+	ln, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	go app.Listener(ln)
+	defer app.Shutdown()
+
+	conn, _, err := gorilla.DefaultDialer.Dial("ws://"+ln.Addr().String()+"/api/graphql/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(graphql.SubscriptionRequest{Query: "subscription { fiberTicks }"}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "1\n" {
+		t.Errorf("unexpected event %q, %v", msg, err)
+	}
+}
@@ -0,0 +1,59 @@
+package vibeGraphql
+
+import "strings"
+
+// FormatQuery parses src and prints it back in canonical form, for keeping
+// checked-in .graphql files consistent: selections on lines of their own,
+// indented by two spaces per level, single spaces between tokens, and a
+// blank line between definitions. Type system definitions are printed as
+// by PrintSchema. Comments are not preserved. The first syntax error, if
+// any, is returned as an *Error instead.
+func FormatQuery(src string) (string, error) {
+	p := NewParser(NewLexer(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		return "", errs[0]
+	}
+	var sb strings.Builder
+	for _, def := range doc.Definitions {
+		if sb.Len() > 0 {
+			sb.WriteString("\n")
+		}
+		switch def := def.(type) {
+		case *OperationDefinition:
+			writeOperationHeader(&sb, def)
+			writeIndentedSelectionSet(&sb, def.SelectionSet, "")
+		case *FragmentDefinition:
+			writeFragmentHeader(&sb, def)
+			writeIndentedSelectionSet(&sb, def.SelectionSet, "")
+		case *TypeDefinition:
+			writeTypeDefinition(&sb, def) // ends with a newline already
+			continue
+		case *DirectiveDefinition:
+			writeDirectiveDefinition(&sb, def)
+		default:
+			continue
+		}
+		sb.WriteString("\n")
+	}
+	return sb.String(), nil
+}
+
+// writeIndentedSelectionSet serializes ss with each selection on a line of
+// its own, indented by two spaces more than indent.
+func writeIndentedSelectionSet(sb *strings.Builder, ss *SelectionSet, indent string) {
+	if ss == nil || len(ss.Selections) == 0 {
+		sb.WriteString("{}")
+		return
+	}
+	inner := indent + "  "
+	sb.WriteString("{\n")
+	for _, sel := range ss.Selections {
+		sb.WriteString(inner)
+		writeSelection(sb, sel, func(sb *strings.Builder, ss *SelectionSet) {
+			writeIndentedSelectionSet(sb, ss, inner)
+		})
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent + "}")
+}
@@ -0,0 +1,71 @@
+package vibeGraphql
+
+import (
+	"errors"
+	"testing"
+)
+
+func TestFormatQuery(t *testing.T) {
+	tests := []struct {
+		src  string
+		want string
+	}{
+		{`{user{id,name}}`, "{\n  user {\n    id\n    name\n  }\n}\n"},
+		{
+			`query  Q( $id:ID! , $f:[Int!] )@cached{ user(id:$id){ ...f  friends(first:2,filter:{b:1,a:"x"})@include(if:true){name} ...on Admin{level} } }
+			fragment f on User{id}`,
+			`query Q($id: ID!, $f: [Int!]) @cached {
+  user(id: $id) {
+    ...f
+    friends(first: 2, filter: {a: "x", b: 1}) @include(if: true) {
+      name
+    }
+    ... on Admin {
+      level
+    }
+  }
+}
+
+fragment f on User {
+  id
+}
+`,
+		},
+		{
+			"# Users.\n\"\"\"\nA user.\n\"\"\"\ntype User{id:ID! name(upper:Boolean=false):String}\nextend type Query{me:User}\ndirective @key(fields:String!) on OBJECT",
+			`"A user."
+type User {
+  id: ID!
+  name(upper: Boolean = false): String
+}
+
+extend type Query {
+  me: User
+}
+
+directive @key(fields: String!) on OBJECT
+`,
+		},
+		{"", ""},
+	}
+	for _, tt := range tests {
+		got, err := FormatQuery(tt.src)
+		if err != nil {
+			t.Fatalf("%q: %v", tt.src, err)
+		}
+		if got != tt.want {
+			t.Errorf("%q: got\n%s\nwant\n%s", tt.src, got, tt.want)
+		}
+		if again, _ := FormatQuery(got); again != got {
+			t.Errorf("formatting %q again changed it to\n%s", got, again)
+		}
+	}
+}
+
+func TestFormatQuery_SyntaxError(t *testing.T) {
+	_, err := FormatQuery("{\n  user(id: 1 }")
+	var gqlErr *Error
+	if !errors.As(err, &gqlErr) || gqlErr.Message != `Syntax Error: Expected ")", found "}".` || gqlErr.Locations[0] != (Location{Line: 2, Column: 14}) {
+		t.Errorf("unexpected error %v", err)
+	}
+}
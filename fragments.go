@@ -0,0 +1,193 @@
+package vibeGraphql
+
+// fragmentsOf returns the fragment definitions of doc by name. When a name
+// is defined more than once, the first definition is used.
+func fragmentsOf(doc *Document) map[string]*FragmentDefinition {
+	defs := make(map[string]*FragmentDefinition)
+	for _, def := range fragmentDefinitions(doc) {
+		if _, exists := defs[def.Name]; !exists {
+			defs[def.Name] = def
+		}
+	}
+	return defs
+}
+
+// fragmentDefinitions returns the fragment definitions of doc, in order.
+func fragmentDefinitions(doc *Document) []*FragmentDefinition {
+	if doc == nil {
+		return nil
+	}
+	var defs []*FragmentDefinition
+	for _, def := range doc.Definitions {
+		if def, ok := def.(*FragmentDefinition); ok {
+			defs = append(defs, def)
+		}
+	}
+	return defs
+}
+
+// linkFragments sets the Fragment of every spread in doc to the definition
+// it spreads, unless the spread closes a cycle, such as a spread of B in
+// fragment A when B spreads A. Leaving those spreads unlinked lets documents
+// be walked without keeping track of the fragments entered;
+// NoFragmentCyclesRule reports the cycles.
+func linkFragments(doc *Document) {
+	defs := fragmentsOf(doc)
+	for _, def := range doc.Definitions {
+		switch def := def.(type) {
+		case *OperationDefinition:
+			forEachSpread(def.SelectionSet, func(spread *FragmentSpread) {
+				spread.Fragment = defs[spread.Name]
+			})
+		case *FragmentDefinition:
+			forEachSpread(def.SelectionSet, func(spread *FragmentSpread) {
+				if target := defs[spread.Name]; target != nil && !spreadsReach(defs, target, def.Name) {
+					spread.Fragment = target
+				}
+			})
+		}
+	}
+}
+
+// forEachSpread calls visit for each fragment spread in ss, including those
+// in the selection sets of its fields and inline fragments but not those in
+// the fragments spread.
+func forEachSpread(ss *SelectionSet, visit func(spread *FragmentSpread)) {
+	if ss == nil {
+		return
+	}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *Field:
+			forEachSpread(sel.SelectionSet, visit)
+		case *InlineFragment:
+			forEachSpread(sel.SelectionSet, visit)
+		case *FragmentSpread:
+			visit(sel)
+		}
+	}
+}
+
+// spreadsReach reports whether from is the fragment named name, or spreads
+// it directly or through other fragments of defs.
+func spreadsReach(defs map[string]*FragmentDefinition, from *FragmentDefinition, name string) bool {
+	visited := make(map[string]bool)
+	var reach func(def *FragmentDefinition) bool
+	reach = func(def *FragmentDefinition) bool {
+		if def.Name == name {
+			return true
+		}
+		if visited[def.Name] {
+			return false
+		}
+		visited[def.Name] = true
+		found := false
+		forEachSpread(def.SelectionSet, func(spread *FragmentSpread) {
+			if target := defs[spread.Name]; !found && target != nil {
+				found = reach(target)
+			}
+		})
+		return found
+	}
+	return reach(from)
+}
+
+// expandFragment returns the selection set, type condition and directives of
+// sel if it is an inline fragment or a linked fragment spread. The selection
+// set is nil for other selections.
+func expandFragment(sel Selection) (ss *SelectionSet, typeCondition string, directives []Directive) {
+	switch sel := sel.(type) {
+	case *InlineFragment:
+		return sel.SelectionSet, sel.TypeCondition, sel.Directives
+	case *FragmentSpread:
+		if sel.Fragment != nil {
+			return sel.Fragment.SelectionSet, sel.Fragment.TypeCondition, sel.Directives
+		}
+	}
+	return nil, "", nil
+}
+
+// fragmentApplies reports whether a fragment with typeCondition applies to
+// a value of the object type typeName: whether the condition names the type
+// itself, or an interface it implements or a union it belongs to in s.
+// Fragments apply to values whose type is not known, such as maps.
+func fragmentApplies(s *Schema, typeCondition, typeName string) bool {
+	if typeCondition == "" || typeName == "" || typeCondition == typeName {
+		return true
+	}
+	if s == nil {
+		return false
+	}
+	td := s.Type(typeCondition)
+	if td == nil {
+		return false
+	}
+	switch td.kind() {
+	case KindInterface:
+		if object := s.Type(typeName); object != nil {
+			for _, iface := range object.Interfaces {
+				if iface == typeCondition {
+					return true
+				}
+			}
+		}
+	case KindUnion:
+		for _, member := range td.Types {
+			if member == typeName {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// fieldsOf returns the fields of ss, including those of the fragments
+// within it, in order. Type conditions and directives are not considered,
+// and the fields of a fragment spread more than once are returned once.
+func fieldsOf(ss *SelectionSet) []*Field {
+	var fields []*Field
+	spread := make(map[*FragmentDefinition]bool)
+	var walk func(ss *SelectionSet)
+	walk = func(ss *SelectionSet) {
+		if ss == nil {
+			return
+		}
+		for _, sel := range ss.Selections {
+			if field, ok := sel.(*Field); ok {
+				fields = append(fields, field)
+				continue
+			}
+			if s, ok := sel.(*FragmentSpread); ok {
+				if spread[s.Fragment] {
+					continue
+				}
+				spread[s.Fragment] = true
+			}
+			fragment, _, _ := expandFragment(sel)
+			walk(fragment)
+		}
+	}
+	walk(ss)
+	return fields
+}
+
+// spreadFragments returns the definitions of the fragments spread in ss,
+// directly or through other fragments, once each in the order they are
+// first spread.
+func spreadFragments(ss *SelectionSet) []*FragmentDefinition {
+	var defs []*FragmentDefinition
+	seen := make(map[*FragmentDefinition]bool)
+	var walk func(ss *SelectionSet)
+	walk = func(ss *SelectionSet) {
+		forEachSpread(ss, func(spread *FragmentSpread) {
+			if spread.Fragment == nil || seen[spread.Fragment] {
+				return
+			}
+			seen[spread.Fragment] = true
+			defs = append(defs, spread.Fragment)
+			walk(spread.Fragment.SelectionSet)
+		})
+	}
+	walk(ss)
+	return defs
+}
@@ -0,0 +1,228 @@
+package vibeGraphql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParser_Fragments(t *testing.T) {
+	doc := parseQuery(`
+		query { user { ...userFields ... on Admin @include(if: true) { level } ... { id } } }
+		fragment userFields on User @cached { id name }
+	`)
+	if len(doc.Definitions) != 2 {
+		t.Fatalf("expected 2 definitions, got %d", len(doc.Definitions))
+	}
+	def, ok := doc.Definitions[1].(*FragmentDefinition)
+	if !ok || def.Name != "userFields" || def.TypeCondition != "User" || len(def.Directives) != 1 {
+		t.Fatalf("unexpected fragment definition %+v", doc.Definitions[1])
+	}
+	if got := fieldNames(def.SelectionSet); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("fragment fields = %v", got)
+	}
+
+	user := doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections[0].(*Field)
+	sels := user.SelectionSet.Selections
+	if spread, ok := sels[0].(*FragmentSpread); !ok || spread.Name != "userFields" || spread.Fragment != def {
+		t.Errorf("expected a spread linked to userFields, got %+v", sels[0])
+	}
+	if inline, ok := sels[1].(*InlineFragment); !ok || inline.TypeCondition != "Admin" || len(inline.Directives) != 1 {
+		t.Errorf("unexpected inline fragment %+v", sels[1])
+	}
+	if inline, ok := sels[2].(*InlineFragment); !ok || inline.TypeCondition != "" {
+		t.Errorf("expected an inline fragment without type condition, got %+v", sels[2])
+	}
+
+	want := "{user {...userFields ... on Admin @include(if: true) {level} ... {id}}}\n\nfragment userFields on User @cached {id name}"
+	if got := PrintDocument(doc); got != want {
+		t.Errorf("PrintDocument =\n%s\nwant\n%s", got, want)
+	}
+}
+
+func TestParser_FragmentErrors(t *testing.T) {
+	tests := map[string]string{
+		`fragment on User { id }`:            `Syntax Error: Expected a fragment name, found IDENT "on".`,
+		`fragment f User { id }`:             `Syntax Error: Expected "on", found IDENT "User".`,
+		`fragment f on { id }`:               `Syntax Error: Expected a type name, found "{".`,
+		`fragment f on User`:                 `Syntax Error: Expected "{", found <EOF>.`,
+		`{ ... on User @include(if: true) }`: `Syntax Error: Expected "{", found "}".`,
+	}
+	for input, want := range tests {
+		p := NewParser(NewLexer(input))
+		p.ParseDocument()
+		if errs := p.Errors(); len(errs) == 0 || errs[0].Message != want {
+			t.Errorf("%s: got %v, want %q", input, errs, want)
+		}
+	}
+}
+
+func TestParser_FragmentCyclesAreNotLinked(t *testing.T) {
+	doc := parseQuery(`{ ...a } fragment a on Query { ...b } fragment b on Query { ...a }`)
+	a := doc.Definitions[1].(*FragmentDefinition)
+	b := doc.Definitions[2].(*FragmentDefinition)
+	if spread := a.SelectionSet.Selections[0].(*FragmentSpread); spread.Fragment != nil {
+		t.Errorf("the spread of b in a closes a cycle and must not be linked")
+	}
+	if spread := b.SelectionSet.Selections[0].(*FragmentSpread); spread.Fragment != nil {
+		t.Errorf("the spread of a in b closes a cycle and must not be linked")
+	}
+	if spread := doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections[0].(*FragmentSpread); spread.Fragment != a {
+		t.Errorf("the spread of a in the operation must be linked")
+	}
+}
+
+func TestExecuteDocument_Fragments(t *testing.T) {
+	pets := []interface{}{
+		map[string]interface{}{"kind": "dog", "name": "Rex", "barks": true},
+		map[string]interface{}{"kind": "cat", "name": "Tom", "lives": 9},
+	}
+	useResolvers(t, map[string]ResolverFunc{
+		"pets": func(source interface{}, args map[string]interface{}) (interface{}, error) { return pets, nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `
+		interface Pet { name: String }
+		type Dog implements Pet { name: String barks: Boolean }
+		type Cat implements Pet { name: String lives: Int }
+		union Animal = Dog | Cat
+		type Query { pets: [Pet] }
+	`)
+	defer delete(TypeNameResolvers, "Pet")
+	RegisterTypeNameResolver("Pet", func(value interface{}) string {
+		switch value.(map[string]interface{})["kind"] {
+		case "dog":
+			return "Dog"
+		case "cat":
+			return "Cat"
+		}
+		return ""
+	})
+
+	tests := []struct {
+		query string
+		vars  map[string]interface{}
+		want  string
+	}{
+		{
+			`{ pets { ...petFields } } fragment petFields on Pet { name }`, nil,
+			`{"data":{"pets":[{"name":"Rex"},{"name":"Tom"}]}}`,
+		},
+		{
+			`{ pets { name ... on Dog { barks } ... on Cat { lives } } }`, nil,
+			`{"data":{"pets":[{"barks":true,"name":"Rex"},{"lives":9,"name":"Tom"}]}}`,
+		},
+		{
+			`{ pets { ...animal } } fragment animal on Pet { ... on Animal { ...dog ...cat } } fragment dog on Dog { barks } fragment cat on Cat { lives }`, nil,
+			`{"data":{"pets":[{"barks":true},{"lives":9}]}}`,
+		},
+		{
+			`query ($cats: Boolean!) { pets { name ... on Cat @include(if: $cats) { lives } } }`, map[string]interface{}{"cats": false},
+			`{"data":{"pets":[{"name":"Rex"},{"name":"Tom"}]}}`,
+		},
+		{
+			`query ($cats: Boolean!) { pets { name ... on Cat @include(if: $cats) { lives } } }`, map[string]interface{}{"cats": true},
+			`{"data":{"pets":[{"name":"Rex"},{"lives":9,"name":"Tom"}]}}`,
+		},
+		{
+			`{ pets { ...n ...n name } } fragment n on Pet { name }`, nil,
+			`{"data":{"pets":[{"name":"Rex"},{"name":"Tom"}]}}`,
+		},
+	}
+	for _, tt := range tests {
+		result, err := executeRequest(context.Background(), tt.query, tt.vars)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(encodeResponse(result))); got != tt.want {
+			t.Errorf("%s\n got %s\nwant %s", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestValidate_Fragments(t *testing.T) {
+	s, err := ParseSchema(`
+		type User { id: ID! name: String }
+		type Query { user: User version: String }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user { ...f } } fragment f on User { id ...g } fragment g on User { name }`, ""},
+		{`{ user { ... on User { id } ... { name } } }`, ""},
+		{`{ user { ...f } } fragment f on User { email }`, `Cannot query field "email" on type "User".`},
+		{`{ user { ... on User { email } } }`, `Cannot query field "email" on type "User".`},
+		{`{ user { ...missing } }`, `Unknown fragment "missing".`},
+		{`{ user { ...f } } fragment f on User { id } fragment f on User { name }`, `There can be only one fragment named "f".`},
+		{`{ version } fragment f on User { id }`, `Fragment "f" is never used.`},
+		{`{ user { ...f } } fragment f on User { ...f }`, `Cannot spread fragment "f" within itself.`},
+		{`{ user { ...a } } fragment a on User { ...b } fragment b on User { ...c } fragment c on User { ...a }`,
+			`Cannot spread fragment "a" within itself via "b", "c".`},
+		{`query ($show: Boolean!) { user { ...f } } fragment f on User { name @include(if: $show) }`, ""},
+		{`query Get { user { ...f } } fragment f on User { name @include(if: $show) }`, `Variable "$show" is not defined by operation "Get".`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, parseQuery(tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+}
+
+func TestValidate_FragmentLocations(t *testing.T) {
+	errs := validateOperations(parseQuery("{\n  ...a\n}\nfragment a on Query {\n  ...b\n}\nfragment b on Query {\n  ...a\n}\nfragment unused on Query { id }"))
+	want := map[string][]Location{
+		`Cannot spread fragment "a" within itself via "b".`: {{Line: 5, Column: 3}, {Line: 8, Column: 3}},
+		`Fragment "unused" is never used.`:                  {{Line: 10, Column: 1}},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for _, err := range errs {
+		if locs, ok := want[err.Message]; !ok || !reflect.DeepEqual(err.Locations, locs) {
+			t.Errorf("unexpected error %q at %+v", err.Message, err.Locations)
+		}
+	}
+}
+
+func TestExecuteRequest_FragmentCycle(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"version": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "1", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	// Without a schema, cycles are still rejected before execution.
+	result, err := executeRequest(context.Background(), `{ ...a } fragment a on Query { version ...a }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := result["errors"].([]*Error)
+	if len(errs) != 1 || errs[0].Message != `Cannot spread fragment "a" within itself.` || result["data"] != nil {
+		t.Errorf("unexpected result %v", result)
+	}
+}
+
+func TestOperationLimits_Fragments(t *testing.T) {
+	// Each fragment spreads the next twice, doubling the fields selected.
+	op := parseQuery(`{ ...f2 }
+		fragment f2 on Query { ...f1 ...f1 }
+		fragment f1 on Query { ...f0 ...f0 }
+		fragment f0 on Query { a: version b: version }
+	`).Definitions[0].(*OperationDefinition)
+	if n := OperationComplexity(op); n != 8 {
+		t.Errorf("OperationComplexity = %d, want 8", n)
+	}
+	errs := CheckOperationLimits(op, OperationLimits{MaxAliases: 5, MaxRootFields: 5})
+	if len(errs) != 1 || errs[0].Message != "Operation has 8 aliases, exceeding the limit of 5." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
@@ -0,0 +1,56 @@
+// Package ginadapter serves vibeGraphql handlers from a Gin router.
+package ginadapter
+
+import (
+	"context"
+	"net/http"
+	"strings"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gin-gonic/gin"
+)
+
+type ginContextKey struct{}
+
+// FromContext returns the *gin.Context of the request a resolver runs in, or nil.
+func FromContext(ctx context.Context) *gin.Context {
+	c, _ := ctx.Value(ginContextKey{}).(*gin.Context)
+	return c
+}
+
+// Wrap converts an http.Handler into a gin.HandlerFunc. The *gin.Context is
+// made available to resolvers through FromContext.
+func Wrap(h http.Handler) gin.HandlerFunc {
+	return func(c *gin.Context) {
+		ctx := context.WithValue(c.Request.Context(), ginContextKey{}, c)
+		h.ServeHTTP(c.Writer, c.Request.WithContext(ctx))
+	}
+}
+
+// Handler serves queries and mutations, including multipart uploads.
+func Handler() gin.HandlerFunc {
+	return Wrap(graphql.GraphqlUploadHandler)
+}
+
+// SubscriptionHandler serves subscriptions over WebSocket.
+func SubscriptionHandler() gin.HandlerFunc {
+	return Wrap(graphql.SubscriptionHandler)
+}
+
+// PlaygroundHandler serves the in-browser IDE.
+func PlaygroundHandler(endpoint, subscriptionEndpoint string) gin.HandlerFunc {
+	return Wrap(graphql.PlaygroundHandler(endpoint, subscriptionEndpoint))
+}
+
+// Mount registers the same endpoints as graphql.Mount on a Gin router or group.
+func Mount(r gin.IRoutes, path string) {
+	path = strings.TrimSuffix(path, "/")
+	r.POST(path, Handler())
+	r.GET(path+"/ws", SubscriptionHandler())
+	r.POST(path+"/upload", Handler())
+	r.GET(path+"/playground", func(c *gin.Context) {
+		// FullPath includes the prefix of the group the routes were mounted on.
+		base := strings.TrimSuffix(c.FullPath(), "/playground")
+		PlaygroundHandler(base, base+"/ws")(c)
+	})
+}
@@ -0,0 +1,78 @@
+package ginadapter
+
+import (
+	"bytes"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gin-gonic/gin"
+	"github.com/gorilla/websocket"
+)
+
+func newRouter(t *testing.T) *httptest.Server {
+	t.Helper()
+	gin.SetMode(gin.TestMode)
+	graphql.RegisterFieldResolver("Query", "route", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		return FromContext(ctx).FullPath(), nil
+	})
+	graphql.RegisterSubscriptionResolver("ginTicks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- 1
+		close(ch)
+		return ch, nil
+	})
+	r := gin.New()
+	Mount(r.Group("/api"), "/graphql")
+	srv := httptest.NewServer(r)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestMount_Query(t *testing.T) {
+	srv := newRouter(t)
+	resp, err := http.Post(srv.URL+"/api/graphql", "application/json", strings.NewReader(`{"query": "{ route }"}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if !strings.Contains(body.String(), `"route":"/api/graphql"`) {
+		t.Errorf("unexpected response: %s", body.String())
+	}
+
+	resp, err = http.Get(srv.URL + "/api/graphql/playground")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	body.Reset()
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatal(err)
+	}
+	if resp.StatusCode != http.StatusOK || !strings.Contains(body.String(), `"/api/graphql/ws"`) {
+		t.Errorf("unexpected playground response %d: %s", resp.StatusCode, body.String())
+	}
+}
+
+func TestMount_Subscription(t *testing.T) {
+	srv := newRouter(t)
+	conn, _, err := websocket.DefaultDialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http")+"/api/graphql/ws", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.WriteJSON(graphql.SubscriptionRequest{Query: "subscription { ginTicks }"}); err != nil {
+		t.Fatal(err)
+	}
+	_, msg, err := conn.ReadMessage()
+	if err != nil || string(msg) != "1\n" {
+		t.Errorf("unexpected event %q, %v", msg, err)
+	}
+}
@@ -2,6 +2,7 @@ package vibeGraphql
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"fmt"
 	"net/http"
@@ -43,9 +44,48 @@ func TestGraphqlHandlerNoDefinitions(t *testing.T) {
 	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
 	w := httptest.NewRecorder()
 	GraphqlHandler(w, req)
+	assertRequestError(t, w, "Document does not contain an operation to execute.")
+}
+
+// assertRequestError checks that the response recorded by w reports a
+// request error with message, and no data.
+func assertRequestError(t *testing.T, w *httptest.ResponseRecorder, message string) {
+	t.Helper()
+	if w.Code != http.StatusOK {
+		t.Errorf("expected 200 for a request error, got %d", w.Code)
+	}
+	var result map[string]json.RawMessage
+	if err := json.Unmarshal(w.Body.Bytes(), &result); err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := result["data"]; ok {
+		t.Errorf("expected no data for a request error, got %s", w.Body)
+	}
+	var errs []Error
+	json.Unmarshal(result["errors"], &errs)
+	if len(errs) != 1 || errs[0].Message != message {
+		t.Errorf("expected the error %q, got %s", message, w.Body)
+	}
+}
+
+func TestGraphqlHandlerSyntaxError(t *testing.T) {
+	body, _ := json.Marshal(map[string]interface{}{"query": "{ user(1) }"})
+	req := httptest.NewRequest("POST", "/graphql", bytes.NewBuffer(body))
+	w := httptest.NewRecorder()
+	GraphqlHandler(w, req)
 	resp := w.Result()
-	if resp.StatusCode != http.StatusInternalServerError {
-		t.Errorf("expected 500 for empty document, got %d", resp.StatusCode)
+	if resp.StatusCode != http.StatusOK {
+		t.Fatalf("expected 200 for a syntax error, got %d", resp.StatusCode)
+	}
+	var result struct {
+		Data   interface{} `json:"data"`
+		Errors []Error     `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&result); err != nil {
+		t.Fatal(err)
+	}
+	if result.Data != nil || len(result.Errors) != 1 || result.Errors[0].Message != `Syntax Error: Unexpected INT "1" in arguments.` {
+		t.Errorf("unexpected response: %+v", result)
 	}
 }
 
@@ -133,7 +173,7 @@ func TestExecuteSelectionSetWithNestedArray(t *testing.T) {
 	}
 
 	// Execute document.
-	resp, err := executeDocument(doc, map[string]interface{}{})
+	resp, err := executeDocument(context.Background(), doc, map[string]interface{}{})
 	if err != nil {
 		t.Fatalf("executeDocument error: %v", err)
 	}
@@ -194,7 +234,7 @@ func TestParserIllegalValue(t *testing.T) {
 
 func TestLexerIllegalCharacter(t *testing.T) {
 	// Test lexer with an unexpected character.
-	input := "@"
+	input := "%"
 	lexer := NewLexer(input)
 	tok := lexer.NextToken()
 	if tok.Type != ILLEGAL {
@@ -364,7 +404,7 @@ func TestResolveField_Reflective(t *testing.T) {
 	src := &sample{Test: 300, JsonField: "reflective"}
 	// Create a field with no resolver registered, so it will try reflective lookup.
 	field := &Field{Name: "Test"}
-	res, err := resolveField(src, field, nil)
+	res, err := newExecutor(context.Background(), nil, nil).resolveField(src, field)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -524,10 +564,7 @@ func TestGraphqlHandler_MissingQueryField(t *testing.T) {
 	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body)))
 	w := httptest.NewRecorder()
 	GraphqlHandler(w, req)
-	resp := w.Result()
-	if resp.StatusCode != 500 {
-		t.Errorf("expected status 500 for missing query field, got %d", resp.StatusCode)
-	}
+	assertRequestError(t, w, "Document does not contain an operation to execute.")
 }
 
 func TestValueTokenLiteral(t *testing.T) {
@@ -545,9 +582,9 @@ func (d *dummyNonOp) TokenLiteral() string { return "dummy" }
 
 func TestExecuteDocumentNonOpDefinition(t *testing.T) {
 	doc := &Document{Definitions: []Definition{&dummyNonOp{}}}
-	_, err := executeDocument(doc, nil)
-	if err == nil {
-		t.Error("expected error when definition is not an OperationDefinition")
+	result, err := executeDocument(context.Background(), doc, nil)
+	if err != nil || result["errors"] == nil {
+		t.Errorf("expected an error when definition is not an OperationDefinition, got %v %v", result, err)
 	}
 }
 
@@ -638,9 +675,9 @@ func TestSubscriptionHandlerUpgradeFail(t *testing.T) {
 	// Override upgrader with one that always fails.
 	origUpgrader := upgrader
 	defer func() { upgrader = origUpgrader }()
-	upgrader = websocket.Upgrader{
+	upgrader = gorillaUpgrader{websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool { return false },
-	}
+	}}
 
 	req := httptest.NewRequest("GET", "/subscription", nil)
 	w := httptest.NewRecorder()
@@ -728,7 +765,7 @@ func (d *dummySel) TokenLiteral() string {
 func TestExecuteSelectionSet_SkipNonField(t *testing.T) {
 	// Create a selection set with a dummy selection that is not a *Field.
 	ss := &SelectionSet{Selections: []Selection{&dummySel{}}}
-	result, err := executeSelectionSet(nil, ss, nil)
+	result, err := newExecutor(context.Background(), nil, nil).executeSelectionSet(nil, ss)
 	if err != nil {
 		t.Errorf("unexpected error: %v", err)
 	}
@@ -796,3 +833,38 @@ func TestBuildArgs_NestedObject(t *testing.T) {
 		t.Errorf("expected threshold to be 5, got %v", config["threshold"])
 	}
 }
+
+func TestExecuteRequest_DataSemantics(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"broken": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, fmt.Errorf("database unavailable")
+		},
+		"denied": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return nil, ErrPermissionDenied()
+		},
+		"version": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "1", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		// Request errors: the operation never executes, so there is no data.
+		{`{ version(1) }`, `{"errors":[{"message":"Syntax Error: Unexpected INT \"1\" in arguments.","locations":[{"line":1,"column":11}]}]}`},
+		{`{ version `, `{"errors":[{"message":"Syntax Error: Expected \"}\", found \u003cEOF\u003e.","locations":[{"line":1,"column":11}]}]}`},
+		{`query A { version } query A { version }`, `{"errors":[{"message":"There can be only one operation named \"A\"."}]}`},
+		// Field errors null the field.
+		{`{ version denied }`, `{"data":{"denied":null,"version":"1"},"errors":[{"message":"permission denied","path":["denied"],"extensions":{"code":"PERMISSION_DENIED"}}]}`},
+		// Other errors fail execution at the root, so data is null.
+		{`{ version broken }`, `{"data":null,"errors":[{"message":"database unavailable","path":["broken"]}]}`},
+	}
+	for _, tt := range tests {
+		result, err := executeRequest(context.Background(), tt.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got := strings.TrimSpace(string(encodeResponse(result))); got != tt.want {
+			t.Errorf("%s\n got %s\nwant %s", tt.query, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,222 @@
+// Package graphqltest provides utilities for testing vibeGraphql servers:
+// an in-process test server, structured query results, JSON assertions and
+// a subscription client.
+package graphqltest
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/gorilla/websocket"
+)
+
+// Paths served by a Server.
+const (
+	GraphQLPath      = "/graphql"
+	SubscriptionPath = "/graphql/ws"
+)
+
+// Server is an HTTP test server running the GraphQL handlers.
+type Server struct {
+	*httptest.Server
+
+	previous *graphql.Schema
+}
+
+// NewTestServer starts a server with the endpoints registered by
+// graphql.Mount under GraphQLPath. A non-nil schema is made current for the
+// lifetime of the server. Call Close when finished.
+func NewTestServer(schema *graphql.Schema) *Server {
+	s := &Server{previous: graphql.CurrentSchema()}
+	if schema != nil {
+		graphql.UseSchema(schema)
+	}
+	mux := http.NewServeMux()
+	graphql.Mount(mux, GraphQLPath)
+	s.Server = httptest.NewServer(mux)
+	return s
+}
+
+// Close shuts the server down and restores the previously current schema.
+func (s *Server) Close() {
+	s.Server.Close()
+	graphql.UseSchema(s.previous)
+}
+
+// Error is a single entry of a response's "errors" array.
+type Error struct {
+	Message    string                 `json:"message"`
+	Path       []interface{}          `json:"path,omitempty"`
+	Extensions map[string]interface{} `json:"extensions,omitempty"`
+}
+
+// Result is the outcome of an operation executed against a Server.
+type Result struct {
+	StatusCode int
+	Data       json.RawMessage `json:"data"`
+	Errors     []Error         `json:"errors"`
+	// Body is the raw response body.
+	Body []byte
+}
+
+// Decode unmarshals the result's data into v.
+func (r *Result) Decode(v interface{}) error {
+	if len(r.Data) == 0 {
+		return fmt.Errorf("graphqltest: response has no data")
+	}
+	return json.Unmarshal(r.Data, v)
+}
+
+// ExecuteQuery posts query with variables to the server and returns the
+// parsed response. Transport failures fail the test immediately; GraphQL
+// errors are returned in the Result for the test to inspect.
+func (s *Server) ExecuteQuery(t testing.TB, query string, variables map[string]interface{}) *Result {
+	t.Helper()
+	payload, err := json.Marshal(map[string]interface{}{"query": query, "variables": variables})
+	if err != nil {
+		t.Fatalf("graphqltest: encoding request: %v", err)
+	}
+	resp, err := s.Client().Post(s.URL+GraphQLPath, "application/json", bytes.NewReader(payload))
+	if err != nil {
+		t.Fatalf("graphqltest: sending request: %v", err)
+	}
+	defer resp.Body.Close()
+
+	var body bytes.Buffer
+	if _, err := body.ReadFrom(resp.Body); err != nil {
+		t.Fatalf("graphqltest: reading response: %v", err)
+	}
+	result := &Result{StatusCode: resp.StatusCode, Body: body.Bytes()}
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		if err := json.Unmarshal(result.Body, result); err != nil {
+			t.Fatalf("graphqltest: decoding response %q: %v", result.Body, err)
+		}
+	}
+	return result
+}
+
+// MustMatchJSON fails the test unless got, encoded as JSON, is equivalent to
+// the JSON document want. Object key order and whitespace are ignored. got
+// may be a value, a json.RawMessage or a []byte of JSON.
+func MustMatchJSON(t testing.TB, got interface{}, want string) {
+	t.Helper()
+	gotJSON, err := canonicalJSON(got)
+	if err != nil {
+		t.Fatalf("graphqltest: encoding actual value: %v", err)
+	}
+	wantJSON, err := canonicalJSON(json.RawMessage(want))
+	if err != nil {
+		t.Fatalf("graphqltest: invalid expected JSON: %v", err)
+	}
+	if gotJSON != wantJSON {
+		t.Fatalf("JSON mismatch\n got: %s\nwant: %s", gotJSON, wantJSON)
+	}
+}
+
+// UpdateGoldenEnv names the environment variable that, when set to a
+// non-empty value, makes MustMatchGolden rewrite golden files.
+const UpdateGoldenEnv = "GRAPHQLTEST_UPDATE"
+
+// MustMatchGolden compares got against the JSON stored in the golden file at
+// path. Setting UpdateGoldenEnv writes got to the file instead.
+func MustMatchGolden(t testing.TB, got interface{}, path string) {
+	t.Helper()
+	if os.Getenv(UpdateGoldenEnv) != "" {
+		gotJSON, err := canonicalJSON(got)
+		if err != nil {
+			t.Fatalf("graphqltest: encoding actual value: %v", err)
+		}
+		var pretty bytes.Buffer
+		json.Indent(&pretty, []byte(gotJSON), "", "  ")
+		pretty.WriteByte('\n')
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			t.Fatalf("graphqltest: %v", err)
+		}
+		if err := os.WriteFile(path, pretty.Bytes(), 0644); err != nil {
+			t.Fatalf("graphqltest: writing golden file: %v", err)
+		}
+		return
+	}
+	want, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("graphqltest: reading golden file (set %s=1 to create it): %v", UpdateGoldenEnv, err)
+	}
+	MustMatchJSON(t, got, string(want))
+}
+
+// canonicalJSON re-encodes v so that equivalent documents compare equal.
+func canonicalJSON(v interface{}) (string, error) {
+	var raw []byte
+	switch v := v.(type) {
+	case json.RawMessage:
+		raw = v
+	case []byte:
+		raw = v
+	default:
+		var err error
+		if raw, err = json.Marshal(v); err != nil {
+			return "", err
+		}
+	}
+	var decoded interface{}
+	if err := json.Unmarshal(raw, &decoded); err != nil {
+		return "", err
+	}
+	out, err := json.Marshal(decoded)
+	return string(out), err
+}
+
+// Subscription is a client connected to a server's subscription endpoint.
+type Subscription struct {
+	conn *websocket.Conn
+}
+
+// Subscribe opens a WebSocket to the server and starts the subscription.
+func (s *Server) Subscribe(t testing.TB, query string, variables map[string]interface{}) *Subscription {
+	t.Helper()
+	url := "ws" + strings.TrimPrefix(s.URL, "http") + SubscriptionPath
+	conn, _, err := websocket.DefaultDialer.Dial(url, nil)
+	if err != nil {
+		t.Fatalf("graphqltest: dialing %s: %v", url, err)
+	}
+	req := graphql.SubscriptionRequest{Query: query, Variables: variables}
+	if err := conn.WriteJSON(req); err != nil {
+		conn.Close()
+		t.Fatalf("graphqltest: sending subscription: %v", err)
+	}
+	return &Subscription{conn: conn}
+}
+
+// Next waits up to timeout for the next event and returns its raw payload.
+func (sub *Subscription) Next(timeout time.Duration) (json.RawMessage, error) {
+	sub.conn.SetReadDeadline(time.Now().Add(timeout))
+	_, msg, err := sub.conn.ReadMessage()
+	if err != nil {
+		return nil, err
+	}
+	return msg, nil
+}
+
+// MustNext is like Next but fails the test on error.
+func (sub *Subscription) MustNext(t testing.TB, timeout time.Duration) json.RawMessage {
+	t.Helper()
+	msg, err := sub.Next(timeout)
+	if err != nil {
+		t.Fatalf("graphqltest: waiting for subscription event: %v", err)
+	}
+	return msg
+}
+
+// Close closes the subscription's connection.
+func (sub *Subscription) Close() error {
+	return sub.conn.Close()
+}
@@ -0,0 +1,90 @@
+package graphqltest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+func newServer(t *testing.T) *Server {
+	t.Helper()
+	schema, err := graphql.ParseSchema(`
+		type Query { greeting(name: String!): String }
+		type Subscription { ticks: Int }
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+	graphql.RegisterQueryResolver("greeting", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hi " + args["name"].(string), nil
+	})
+	srv := NewTestServer(schema)
+	t.Cleanup(srv.Close)
+	return srv
+}
+
+func TestExecuteQuery(t *testing.T) {
+	srv := newServer(t)
+
+	res := srv.ExecuteQuery(t, `query ($n: String!) { greeting(name: $n) }`, map[string]interface{}{"n": "ann"})
+	if res.StatusCode != 200 || len(res.Errors) != 0 {
+		t.Fatalf("unexpected result: %d %s", res.StatusCode, res.Body)
+	}
+	MustMatchJSON(t, res.Data, `{"greeting": "hi ann"}`)
+
+	var out struct{ Greeting string }
+	if err := res.Decode(&out); err != nil || out.Greeting != "hi ann" {
+		t.Errorf("Decode = %+v, %v", out, err)
+	}
+
+	res = srv.ExecuteQuery(t, `{ farewell }`, nil)
+	if len(res.Errors) != 1 || res.Errors[0].Message != `Cannot query field "farewell" on type "Query".` {
+		t.Errorf("unexpected errors: %+v", res.Errors)
+	}
+}
+
+func TestNewTestServer_RestoresSchema(t *testing.T) {
+	srv := newServer(t)
+	srv.Close()
+	if graphql.CurrentSchema() != nil {
+		t.Error("expected Close to restore the previous schema")
+	}
+}
+
+func TestMustMatchJSON_IgnoresKeyOrder(t *testing.T) {
+	MustMatchJSON(t, map[string]interface{}{"b": 1, "a": []int{1, 2}}, `{"a":[1,2],"b":1}`)
+	MustMatchJSON(t, []byte(`{ "x": null }`), `{"x":null}`)
+}
+
+func TestMustMatchGolden(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "testdata", "result.json")
+	t.Setenv(UpdateGoldenEnv, "1")
+	MustMatchGolden(t, map[string]int{"n": 1}, path)
+	if _, err := os.Stat(path); err != nil {
+		t.Fatalf("expected golden file to be written: %v", err)
+	}
+	t.Setenv(UpdateGoldenEnv, "")
+	MustMatchGolden(t, map[string]int{"n": 1}, path)
+}
+
+func TestSubscribe(t *testing.T) {
+	srv := newServer(t)
+	graphql.RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 2)
+		ch <- 1
+		ch <- 2
+		close(ch)
+		return ch, nil
+	})
+
+	sub := srv.Subscribe(t, `subscription { ticks }`, nil)
+	defer sub.Close()
+	MustMatchJSON(t, sub.MustNext(t, time.Second), `1`)
+	MustMatchJSON(t, sub.MustNext(t, time.Second), `2`)
+	if _, err := sub.Next(time.Second); err == nil {
+		t.Error("expected the connection to close after the last event")
+	}
+}
@@ -0,0 +1,17 @@
+module github.com/Raezil/vibeGraphql/grpcresolver
+
+go 1.23.0
+
+require (
+	github.com/Raezil/vibeGraphql v0.0.0
+	google.golang.org/grpc v1.67.1
+	google.golang.org/protobuf v1.35.1
+)
+
+require (
+	github.com/gorilla/websocket v1.5.3 // indirect
+	golang.org/x/sys v0.24.0 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240814211410-ddb44dafa142 // indirect
+)
+
+replace github.com/Raezil/vibeGraphql => ../
@@ -0,0 +1,148 @@
+// Package grpcresolver helps write resolvers backed by gRPC services. Proto
+// messages are converted to GraphQL-friendly values and gRPC status errors to
+// GraphQL errors carrying the status code.
+package grpcresolver
+
+import (
+	"context"
+	"encoding/base64"
+	"reflect"
+	"regexp"
+	"strings"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protoreflect"
+)
+
+// Resolver adapts a function calling a gRPC service into a field resolver.
+// Proto messages in the result, including slices of messages, are converted
+// with Convert and errors with Error.
+func Resolver(fn func(ctx context.Context, args map[string]interface{}) (interface{}, error)) graphql.ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		res, err := fn(ctx, args)
+		if err != nil {
+			return nil, Error(err)
+		}
+		return Convert(res), nil
+	}
+}
+
+// Convert converts proto messages in v into maps keyed by the fields' JSON
+// (lowerCamelCase) names. Slices of messages become slices of maps; any
+// other value is returned unchanged.
+func Convert(v interface{}) interface{} {
+	if msg, ok := v.(proto.Message); ok {
+		if reflect.ValueOf(msg).IsNil() {
+			return nil
+		}
+		return messageValue(msg.ProtoReflect())
+	}
+	rv := reflect.ValueOf(v)
+	if rv.Kind() == reflect.Slice && rv.Type().Elem().Implements(reflect.TypeOf((*proto.Message)(nil)).Elem()) {
+		items := make([]interface{}, rv.Len())
+		for i := range items {
+			items[i] = Convert(rv.Index(i).Interface())
+		}
+		return items
+	}
+	return v
+}
+
+// messageValue converts a message. Well-known types map to scalars:
+// Timestamp to an RFC 3339 string, Duration to a Go duration string and the
+// wrapper types to their wrapped value.
+func messageValue(m protoreflect.Message) interface{} {
+	desc := m.Descriptor()
+	switch desc.FullName() {
+	case "google.protobuf.Timestamp":
+		seconds, nanos := secondsAndNanos(m)
+		return time.Unix(seconds, nanos).UTC().Format(time.RFC3339Nano)
+	case "google.protobuf.Duration":
+		seconds, nanos := secondsAndNanos(m)
+		return (time.Duration(seconds)*time.Second + time.Duration(nanos)).String()
+	}
+	if desc.ParentFile().Package() == "google.protobuf" && strings.HasSuffix(string(desc.Name()), "Value") && desc.Fields().Len() == 1 {
+		fd := desc.Fields().Get(0)
+		return scalarValue(fd, m.Get(fd))
+	}
+	out := make(map[string]interface{}, desc.Fields().Len())
+	fields := desc.Fields()
+	for i := 0; i < fields.Len(); i++ {
+		fd := fields.Get(i)
+		out[fd.JSONName()] = fieldValue(m, fd)
+	}
+	return out
+}
+
+// secondsAndNanos reads a Timestamp or Duration without requiring the
+// generated Go type, so dynamic messages convert too.
+func secondsAndNanos(m protoreflect.Message) (int64, int64) {
+	fields := m.Descriptor().Fields()
+	return m.Get(fields.ByName("seconds")).Int(), m.Get(fields.ByName("nanos")).Int()
+}
+
+func fieldValue(m protoreflect.Message, fd protoreflect.FieldDescriptor) interface{} {
+	switch {
+	case fd.IsList():
+		list := m.Get(fd).List()
+		items := make([]interface{}, list.Len())
+		for i := range items {
+			items[i] = singularValue(fd, list.Get(i))
+		}
+		return items
+	case fd.IsMap():
+		entries := make(map[string]interface{})
+		m.Get(fd).Map().Range(func(k protoreflect.MapKey, v protoreflect.Value) bool {
+			entries[k.String()] = singularValue(fd.MapValue(), v)
+			return true
+		})
+		return entries
+	case fd.Message() != nil && !m.Has(fd):
+		return nil
+	}
+	return singularValue(fd, m.Get(fd))
+}
+
+func singularValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	if fd.Message() != nil {
+		return messageValue(v.Message())
+	}
+	return scalarValue(fd, v)
+}
+
+func scalarValue(fd protoreflect.FieldDescriptor, v protoreflect.Value) interface{} {
+	switch fd.Kind() {
+	case protoreflect.EnumKind:
+		if ev := fd.Enum().Values().ByNumber(v.Enum()); ev != nil {
+			return string(ev.Name())
+		}
+		return int32(v.Enum())
+	case protoreflect.BytesKind:
+		return base64.StdEncoding.EncodeToString(v.Bytes())
+	}
+	return v.Interface()
+}
+
+// Error converts a gRPC status error into a *graphql.Error whose "code"
+// extension is the status code in SCREAMING_SNAKE_CASE (e.g. "NOT_FOUND")
+// and whose "grpcCode" extension is its numeric value. Errors without a gRPC
+// status are returned unchanged.
+func Error(err error) error {
+	st, ok := status.FromError(err)
+	if !ok || st.Code() == codes.OK {
+		return err
+	}
+	gqlErr := graphql.NewError(codeName(st.Code()), st.Message())
+	gqlErr.Extensions["grpcCode"] = int(st.Code())
+	return gqlErr
+}
+
+var wordBoundary = regexp.MustCompile(`([a-z0-9])([A-Z])`)
+
+func codeName(c codes.Code) string {
+	return strings.ToUpper(wordBoundary.ReplaceAllString(c.String(), "${1}_${2}"))
+}
@@ -0,0 +1,158 @@
+package grpcresolver
+
+import (
+	"context"
+	"errors"
+	"reflect"
+	"testing"
+	"time"
+
+	graphql "github.com/Raezil/vibeGraphql"
+	"github.com/Raezil/vibeGraphql/graphqltest"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/reflect/protodesc"
+	"google.golang.org/protobuf/reflect/protoreflect"
+	"google.golang.org/protobuf/reflect/protoregistry"
+	"google.golang.org/protobuf/types/descriptorpb"
+	"google.golang.org/protobuf/types/dynamicpb"
+	"google.golang.org/protobuf/types/known/durationpb"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+)
+
+// userDescriptor describes:
+//
+//	enum Status { STATUS_UNKNOWN = 0; ACTIVE = 1; }
+//	message User {
+//	  string display_name = 1;
+//	  Status status = 2;
+//	  google.protobuf.Timestamp created_at = 3;
+//	  google.protobuf.StringValue nickname = 4;
+//	  repeated string tags = 5;
+//	  User manager = 6;
+//	  google.protobuf.Duration session_length = 7;
+//	  bytes avatar = 8;
+//	}
+func userDescriptor(t *testing.T) protoreflect.MessageDescriptor {
+	t.Helper()
+	field := func(name string, number int32, typ descriptorpb.FieldDescriptorProto_Type, typeName string, repeated bool) *descriptorpb.FieldDescriptorProto {
+		label := descriptorpb.FieldDescriptorProto_LABEL_OPTIONAL
+		if repeated {
+			label = descriptorpb.FieldDescriptorProto_LABEL_REPEATED
+		}
+		f := &descriptorpb.FieldDescriptorProto{Name: proto.String(name), Number: proto.Int32(number), Type: typ.Enum(), Label: label.Enum()}
+		if typeName != "" {
+			f.TypeName = proto.String(typeName)
+		}
+		return f
+	}
+	fdp := &descriptorpb.FileDescriptorProto{
+		Name:       proto.String("users.proto"),
+		Package:    proto.String("users"),
+		Syntax:     proto.String("proto3"),
+		Dependency: []string{"google/protobuf/timestamp.proto", "google/protobuf/wrappers.proto", "google/protobuf/duration.proto"},
+		EnumType: []*descriptorpb.EnumDescriptorProto{{
+			Name: proto.String("Status"),
+			Value: []*descriptorpb.EnumValueDescriptorProto{
+				{Name: proto.String("STATUS_UNKNOWN"), Number: proto.Int32(0)},
+				{Name: proto.String("ACTIVE"), Number: proto.Int32(1)},
+			},
+		}},
+		MessageType: []*descriptorpb.DescriptorProto{{
+			Name: proto.String("User"),
+			Field: []*descriptorpb.FieldDescriptorProto{
+				field("display_name", 1, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", false),
+				field("status", 2, descriptorpb.FieldDescriptorProto_TYPE_ENUM, ".users.Status", false),
+				field("created_at", 3, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Timestamp", false),
+				field("nickname", 4, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.StringValue", false),
+				field("tags", 5, descriptorpb.FieldDescriptorProto_TYPE_STRING, "", true),
+				field("manager", 6, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".users.User", false),
+				field("session_length", 7, descriptorpb.FieldDescriptorProto_TYPE_MESSAGE, ".google.protobuf.Duration", false),
+				field("avatar", 8, descriptorpb.FieldDescriptorProto_TYPE_BYTES, "", false),
+			},
+		}},
+	}
+	fd, err := protodesc.NewFile(fdp, protoregistry.GlobalFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return fd.Messages().ByName("User")
+}
+
+func newUser(t *testing.T) proto.Message {
+	md := userDescriptor(t)
+	fields := md.Fields()
+	user := dynamicpb.NewMessage(md)
+	user.Set(fields.ByName("display_name"), protoreflect.ValueOfString("Ada"))
+	user.Set(fields.ByName("status"), protoreflect.ValueOfEnum(1))
+	user.Set(fields.ByName("created_at"), protoreflect.ValueOfMessage(timestamppb.New(time.Date(2024, 5, 1, 12, 0, 0, 0, time.UTC)).ProtoReflect()))
+	user.Set(fields.ByName("nickname"), protoreflect.ValueOfMessage(wrapperspb.String("ada").ProtoReflect()))
+	user.Set(fields.ByName("session_length"), protoreflect.ValueOfMessage(durationpb.New(90*time.Second).ProtoReflect()))
+	user.Set(fields.ByName("avatar"), protoreflect.ValueOfBytes([]byte("hi")))
+	tags := user.Mutable(fields.ByName("tags")).List()
+	tags.Append(protoreflect.ValueOfString("admin"))
+	return user
+}
+
+func TestConvert(t *testing.T) {
+	got := Convert(newUser(t))
+	want := map[string]interface{}{
+		"displayName":   "Ada",
+		"status":        "ACTIVE",
+		"createdAt":     "2024-05-01T12:00:00Z",
+		"nickname":      "ada",
+		"tags":          []interface{}{"admin"},
+		"manager":       nil,
+		"sessionLength": "1m30s",
+		"avatar":        "aGk=",
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("Convert =\n%#v\nwant\n%#v", got, want)
+	}
+
+	list := Convert([]proto.Message{newUser(t)}).([]interface{})
+	if len(list) != 1 || list[0].(map[string]interface{})["displayName"] != "Ada" {
+		t.Errorf("unexpected list conversion: %v", list)
+	}
+}
+
+func TestError(t *testing.T) {
+	err := Error(status.Error(codes.NotFound, "no such user"))
+	var gqlErr *graphql.Error
+	if !errors.As(err, &gqlErr) {
+		t.Fatalf("expected *graphql.Error, got %T", err)
+	}
+	if gqlErr.Message != "no such user" || gqlErr.Extensions["code"] != "NOT_FOUND" || gqlErr.Extensions["grpcCode"] != 5 {
+		t.Errorf("unexpected error: %+v", gqlErr)
+	}
+	if Error(status.Error(codes.PermissionDenied, "x")).(*graphql.Error).Extensions["code"] != graphql.CodePermissionDenied {
+		t.Error("expected PermissionDenied to map to the standard code")
+	}
+
+	plain := errors.New("boom")
+	if Error(plain) != plain {
+		t.Error("expected non-gRPC errors to pass through")
+	}
+}
+
+func TestResolver(t *testing.T) {
+	graphql.RegisterFieldResolver("Query", "grpcUser", Resolver(func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return newUser(t), nil
+	}))
+	graphql.RegisterFieldResolver("Query", "grpcMissing", Resolver(func(ctx context.Context, args map[string]interface{}) (interface{}, error) {
+		return nil, status.Error(codes.NotFound, "missing")
+	}))
+
+	srv := graphqltest.NewTestServer(nil)
+	defer srv.Close()
+	res := srv.ExecuteQuery(t, `{ grpcUser { displayName status createdAt } grpcMissing { displayName } }`, nil)
+	graphqltest.MustMatchJSON(t, res.Data, `{
+		"grpcUser": {"displayName": "Ada", "status": "ACTIVE", "createdAt": "2024-05-01T12:00:00Z"},
+		"grpcMissing": null
+	}`)
+	if len(res.Errors) != 1 || res.Errors[0].Extensions["code"] != "NOT_FOUND" {
+		t.Errorf("unexpected errors: %+v", res.Errors)
+	}
+}
@@ -1,17 +1,20 @@
 package vibeGraphql
 
 import (
+	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"io/ioutil"
-	"log"
+	"io"
+	"math/big"
 	"net/http"
 	"reflect"
+	"sort"
 	"strconv"
 	"strings"
 	"sync"
-
-	"github.com/gorilla/websocket"
+	"time"
+	"unicode"
 )
 
 func resolveArgument(arg *Argument, variables map[string]interface{}) (interface{}, error) {
@@ -32,40 +35,354 @@ func resolveArgument(arg *Argument, variables map[string]interface{}) (interface
 	}
 }
 
+// ResolveInfo describes the field currently being resolved. Resolvers registered
+// with RegisterFieldResolver can retrieve it via ResolveInfoFromContext.
+type ResolveInfo struct {
+	FieldName  string
+	ParentType string
+	Field      *Field
+	Operation  *OperationDefinition
+	Variables  map[string]interface{}
+	// Path is the response path of the field, such as ["posts", 0, "title"].
+	Path []interface{}
+}
+
+type resolveInfoKey struct{}
+
+// ResolveInfoFromContext returns the ResolveInfo stored in ctx, or nil.
+func ResolveInfoFromContext(ctx context.Context) *ResolveInfo {
+	info, _ := ctx.Value(resolveInfoKey{}).(*ResolveInfo)
+	return info
+}
+
+// executor holds the per-request state used while walking a document.
+type executor struct {
+	ctx       context.Context
+	operation *OperationDefinition
+	variables map[string]interface{}
+	errors    []*Error
+	path      []interface{} // response path of the field being resolved
+
+	// mu guards memo, which is shared with the executors of list items
+	// resolved in parallel and written by resolvers that outlive their
+	// timeout.
+	mu   *sync.Mutex
+	memo map[memoKey]memoEntry
+
+	plan *executionPlan // nil when plans are not cached
+
+	// abstractType is the interface or union, with a TypeNameResolver, that
+	// the value being completed is declared as.
+	abstractType string
+}
+
+func newExecutor(ctx context.Context, op *OperationDefinition, variables map[string]interface{}) *executor {
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	return &executor{ctx: ctx, operation: op, variables: variables, mu: &sync.Mutex{}, memo: make(map[memoKey]memoEntry)}
+}
+
+// fork returns an executor for resolving part of the result concurrently
+// with e. It shares e's memo but collects its own errors.
+func (e *executor) fork() *executor {
+	return &executor{
+		ctx:       e.ctx,
+		operation: e.operation,
+		variables: e.variables,
+		path:      append([]interface{}(nil), e.path...),
+		mu:        e.mu,
+		memo:      e.memo,
+		plan:      e.plan,
+
+		abstractType: e.abstractType,
+	}
+}
+
+// rootTypeName returns the name of the root type for the executed operation.
+func (e *executor) rootTypeName() string {
+	if e.operation == nil {
+		return "Query"
+	}
+	switch e.operation.Operation {
+	case "mutation":
+		return "Mutation"
+	case "subscription":
+		return "Subscription"
+	}
+	return "Query"
+}
+
+// typeNameOf returns the GraphQL type name used to look up field resolvers
+// for a resolved value. It defaults to the name of the underlying Go type.
+func typeNameOf(source interface{}) string {
+	if namer, ok := source.(typeNamer); ok {
+		return namer.graphqlTypeName()
+	}
+	t := reflect.TypeOf(source)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name()
+}
+
+// executeRequest parses query and executes it. Syntax errors are reported in
+// the response's "errors" list instead of executing a partial document.
+func executeRequest(ctx context.Context, query string, variables map[string]interface{}) (map[string]interface{}, error) {
+	doc, errs := parseRequest(query)
+	if len(errs) > 0 {
+		return map[string]interface{}{"errors": errs}, nil
+	}
+	return executeDocument(ctx, doc, variables)
+}
+
+// parserPool recycles the lexers and parsers of requests.
+var parserPool = sync.Pool{
+	New: func() interface{} { return &Parser{l: &Lexer{}} },
+}
+
+// parseRequest parses the query of a request.
+func parseRequest(query string) (*Document, []*Error) {
+	return parseRequestIn(query, nil)
+}
+
+// parseRequestIn parses the query of a request, allocating its nodes in
+// arena if it is not nil.
+func parseRequestIn(query string, arena *Arena) (*Document, []*Error) {
+	parser := parserPool.Get().(*Parser)
+	parser.reset(query, arena)
+	doc := parser.ParseDocument()
+	errs := parser.errors
+	parser.errors = nil
+	parserPool.Put(parser)
+	return doc, errs
+}
+
 // executeDocument processes the parsed AST and returns a response.
-func executeDocument(doc *Document, variables map[string]interface{}) (map[string]interface{}, error) {
+func executeDocument(ctx context.Context, doc *Document, variables map[string]interface{}) (map[string]interface{}, error) {
+	return executePlanned(ctx, doc, nil, "", variables)
+}
+
+// executePlanned executes the operation of doc named operationName, reusing
+// the checks and per-field work recorded in plan if it is not nil.
+//
+// The response follows the specification: request errors, which keep the
+// operation from executing, such as validation errors or invalid variables,
+// yield a response with "errors" and no "data". Once execution starts,
+// "data" is always present; it is null if execution failed at the root,
+// with an error that is not a field error. Only the cancellation of ctx is
+// returned as an error, as no one may be left to respond to.
+func executePlanned(ctx context.Context, doc *Document, plan *executionPlan, operationName string, variables map[string]interface{}) (map[string]interface{}, error) {
 	response := map[string]interface{}{}
-	// For simplicity, we assume one operation definition.
-	if len(doc.Definitions) == 0 {
-		return response, fmt.Errorf("no definitions found")
+	s := CurrentSchema()
+	var op *OperationDefinition
+	var errs []*Error
+	phases := phasesFrom(ctx)
+	start := time.Now()
+	if plan != nil {
+		s, op, errs = plan.schema, plan.operation, plan.errors
+	} else {
+		op, errs = checkDocument(s, doc, operationName)
 	}
-	op, ok := doc.Definitions[0].(*OperationDefinition)
-	if !ok {
-		return response, fmt.Errorf("unsupported definition type")
+	if len(errs) > 0 {
+		response["errors"] = errs
+		return response, nil
+	}
+	variables, errs = coerceVariables(s, op, variables)
+	if phases != nil {
+		phases.Validate = time.Since(start)
+	}
+	if len(errs) > 0 {
+		response["errors"] = errs
+		return response, nil
 	}
 	// Execute the top-level selection set (root query)
-	data, err := executeSelectionSet(nil, op.SelectionSet, variables)
+	start = time.Now()
+	e := newExecutor(ctx, op, variables)
+	e.plan = plan
+	data, err := e.executeSelectionSet(nil, op.SelectionSet)
+	if phases != nil {
+		phases.Execute = time.Since(start)
+	}
 	if err != nil {
-		return response, err
+		if errors.Is(err, context.Canceled) {
+			return response, err
+		}
+		failure := maskError(ctx, err)
+		var located *executionError
+		if errors.As(err, &located) {
+			failure = e.located(failure)
+			failure.Path = located.path
+		}
+		response["data"] = nil
+		response["errors"] = append(e.errors, failure)
+		return response, nil
 	}
 	response["data"] = data
+	if len(e.errors) > 0 {
+		response["errors"] = e.errors
+	}
 	return response, nil
 }
 
-// resolveField looks up the appropriate resolver for a field. When the source is nil (top-level),
-// it checks both QueryResolvers and MutationResolvers. For nested fields, it falls back to reflective
-// lookup on the source object.
-func resolveField(source interface{}, field *Field, variables map[string]interface{}) (interface{}, error) {
+// checkDocument returns the operation of doc named operationName, or the
+// errors that keep it from executing: the rules on operation names, the
+// operation limits and, when a schema is loaded, validation.
+func checkDocument(s *Schema, doc *Document, operationName string) (*OperationDefinition, []*Error) {
+	if errs := validateOperations(doc); len(errs) > 0 {
+		return nil, errs
+	}
+	op := selectOperation(doc, operationName)
+	switch {
+	case op != nil:
+	case operationName != "":
+		return nil, []*Error{{Message: fmt.Sprintf("Unknown operation named \"%s\".", operationName)}}
+	case len(operationsOf(doc)) > 1:
+		return nil, []*Error{{Message: "Must provide operation name if query contains multiple operations."}}
+	default:
+		return nil, []*Error{{Message: "Document does not contain an operation to execute."}}
+	}
+	if errs := CheckOperationLimits(op, DefaultOperationLimits); len(errs) > 0 {
+		return op, errs
+	}
+	// When a schema is loaded, reject invalid documents before executing them.
+	if s != nil {
+		if errs := Validate(s, doc); len(errs) > 0 {
+			return op, errs
+		}
+	}
+	return op, nil
+}
+
+// resolveField resolves a single field against source. The schema's Authorize
+// hook runs first, followed by any directive handlers attached to the field's
+// schema definition or to the query field.
+func (e *executor) resolveField(source interface{}, field *Field) (interface{}, error) {
+	return e.resolveFieldOf(source, e.parentTypeOf(source), field)
+}
+
+// parentTypeOf returns the GraphQL type name of source, or the root type
+// name at the top level.
+func (e *executor) parentTypeOf(source interface{}) string {
+	if source == nil {
+		return e.rootTypeName()
+	}
+	return e.typeNameOf(source)
+}
+
+// typeNameOf returns the GraphQL type name of source, asking the
+// TypeNameResolver of the abstract type source is declared as, if any.
+func (e *executor) typeNameOf(source interface{}) string {
+	if resolver := TypeNameResolvers[e.abstractType]; resolver != nil && e.abstractType != "" {
+		if name := resolver(source); name != "" {
+			return name
+		}
+	}
+	return typeNameOf(source)
+}
+
+// abstractTypeOf returns the type of field on parentType if it has a
+// TypeNameResolver, or "".
+func (e *executor) abstractTypeOf(parentType string, field *Field) string {
+	if len(TypeNameResolvers) == 0 {
+		return ""
+	}
+	s := CurrentSchema()
+	if s == nil {
+		return ""
+	}
+	def := s.Field(parentType, field.Name)
+	if def == nil {
+		return ""
+	}
+	if name := namedType(def.Type); TypeNameResolvers[name] != nil {
+		return name
+	}
+	return ""
+}
+
+// resolveFieldOf resolves field on source, a value of the GraphQL type parentType.
+func (e *executor) resolveFieldOf(source interface{}, parentType string, field *Field) (interface{}, error) {
+	info := &ResolveInfo{
+		FieldName:  field.Name,
+		ParentType: parentType,
+		Field:      field,
+		Operation:  e.operation,
+		Variables:  e.variables,
+		Path:       append([]interface{}(nil), e.path...),
+	}
+	ctx := context.WithValue(e.ctx, resolveInfoKey{}, info)
+	if trace := debugTraceFrom(ctx); trace != nil {
+		defer trace.record(info, time.Now())
+	}
+	timeout := e.fieldTimeout(parentType, field)
+	if source == nil && e.operation != nil && e.operation.Operation == "mutation" {
+		if audit := auditLogFrom(ctx); audit != nil {
+			start := time.Now()
+			res, err := e.resolveWithin(ctx, timeout, source, parentType, field)
+			audit.record(ctx, e.operation, field, e.fieldArgs(parentType, field), time.Since(start), err)
+			return res, err
+		}
+	}
+	return e.resolveWithin(ctx, timeout, source, parentType, field)
+}
+
+// resolveWithin runs authorizeAndResolve, limited to timeout if it is
+// positive.
+func (e *executor) resolveWithin(ctx context.Context, timeout time.Duration, source interface{}, parentType string, field *Field) (interface{}, error) {
+	if timeout > 0 {
+		return withFieldTimeout(timeout, e.authorizeAndResolve)(ctx, source, parentType, field)
+	}
+	return e.authorizeAndResolve(ctx, source, parentType, field)
+}
+
+// authorizeAndResolve runs the schema's Authorize hook and then the field's
+// directives and resolver. In debug mode, panics are reported as errors.
+func (e *executor) authorizeAndResolve(ctx context.Context, source interface{}, parentType string, field *Field) (res interface{}, err error) {
+	if debugTraceFrom(ctx) != nil {
+		defer recoverResolverPanic(ctx, &err)
+	}
+	if err := e.validateArguments(parentType, field); err != nil {
+		return nil, err
+	}
+	if s := CurrentSchema(); s != nil && s.Authorize != nil {
+		if err := s.Authorize(ctx, parentType, field.Name, e.fieldArgs(parentType, field)); err != nil {
+			return nil, permissionDenied(err)
+		}
+	}
+	return e.applyDirectives(ctx, source, parentType, field)
+}
+
+// resolve looks up the appropriate resolver for a field. Resolvers registered for the
+// parent type take precedence. When the source is nil (top-level), it checks both QueryResolvers
+// and MutationResolvers. For nested fields, it falls back to reflective lookup on the source object.
+func (e *executor) resolve(ctx context.Context, source interface{}, parentType string, field *Field) (interface{}, error) {
+	resolver, ok := introspectionResolvers[parentType][field.Name]
+	if !ok {
+		resolver, ok = FieldResolvers[parentType][field.Name]
+	}
+	if ok {
+		args := e.fieldArgs(parentType, field)
+		return e.memoize(source, parentType, field, args, func() (interface{}, error) {
+			return resolver(ctx, source, args)
+		})
+	}
+
 	// At the top level, source is nil, so try both query and mutation resolvers.
 	if source == nil {
 		// First, try the query resolver.
 		if resolver, ok := QueryResolvers[field.Name]; ok {
-			args := buildArgs(field, variables)
-			return resolver(source, args)
+			args := e.fieldArgs(parentType, field)
+			return e.memoize(source, parentType, field, args, func() (interface{}, error) {
+				return resolver(source, args)
+			})
 		}
 		// Next, try the mutation resolver.
 		if resolver, ok := MutationResolvers[field.Name]; ok {
-			args := buildArgs(field, variables)
+			args := e.fieldArgs(parentType, field)
 			return resolver(source, args)
 		}
 	}
@@ -74,17 +391,33 @@ func resolveField(source interface{}, field *Field, variables map[string]interfa
 	// fallback to reflective lookup on the source (if it's a struct).
 	// (This is optional; you may want to require resolvers for all top-level fields.)
 	if source != nil {
-		// Use reflection or your existing logic to resolve nested fields.
-		// For brevity, we'll assume the reflective resolution is implemented elsewhere.
+		if s := CurrentSchema(); s != nil && s.DefaultResolver != nil {
+			args := e.fieldArgs(parentType, field)
+			return e.memoize(source, parentType, field, args, func() (interface{}, error) {
+				return s.DefaultResolver(ctx, source, args)
+			})
+		}
 		return reflectResolve(source, field)
 	}
 
 	return nil, fmt.Errorf("no resolver found for field %s", field.Name)
 }
 
-// reflectResolve is a helper that uses reflection to find a field value
-// on a source struct. (Implementation not shown here.)
+// ReflectResolver is the default resolver for fields without a registered
+// resolver: it reads the field from a struct, by json tag or name, or from a
+// map keyed by field name. Custom default resolvers can fall back to it.
+func ReflectResolver(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+	info := ResolveInfoFromContext(ctx)
+	if info == nil || info.Field == nil {
+		return nil, fmt.Errorf("ReflectResolver requires resolve info in the context")
+	}
+	return reflectResolve(source, info.Field)
+}
+
 func reflectResolve(source interface{}, field *Field) (interface{}, error) {
+	if obj, ok := source.(remoteObject); ok {
+		return obj[field.ResponseKey()], nil
+	}
 	val := reflect.ValueOf(source)
 	// Dereference pointer if needed.
 	if val.Kind() == reflect.Ptr {
@@ -93,29 +426,78 @@ func reflectResolve(source interface{}, field *Field) (interface{}, error) {
 		}
 		val = val.Elem()
 	}
+	if val.Kind() == reflect.Map && val.Type().Key().Kind() == reflect.String {
+		v := val.MapIndex(reflect.ValueOf(field.Name).Convert(val.Type().Key()))
+		if !v.IsValid() {
+			return nil, nil
+		}
+		return v.Interface(), nil
+	}
 	if val.Kind() != reflect.Struct {
 		return nil, fmt.Errorf("source is not a struct")
 	}
 
-	typ := val.Type()
-	// Loop through all fields.
-	for i := 0; i < typ.NumField(); i++ {
-		sf := typ.Field(i)
-		// Check if the field name matches (case-insensitive).
-		if strings.EqualFold(sf.Name, field.Name) {
-			return val.Field(i).Interface(), nil
-		}
-		// Also check the "json" tag if present.
-		if tag, ok := sf.Tag.Lookup("json"); ok {
-			// The tag may contain options like "id,omitempty"; split them.
-			tagName := strings.Split(tag, ",")[0]
-			if strings.EqualFold(tagName, field.Name) {
-				return val.Field(i).Interface(), nil
+	if sf, ok := structFieldFor(val.Type(), field.Name); ok {
+		return val.FieldByIndex(sf.Index).Interface(), nil
+	}
+	return nil, fmt.Errorf("no resolver found for field %s via reflection", field.Name)
+}
+
+// NilListsAsEmpty makes a nil slice returned for a field with a selection
+// set, such as a user's friends, resolve to an empty list. By default it
+// resolves to null, as a nil slice encodes in JSON.
+var NilListsAsEmpty = false
+
+// StrictFieldMatching makes struct fields match GraphQL fields case-sensitively,
+// as the specification requires. A field with a json tag then matches only the
+// tag name; other fields match their Go name or its lowerCamelCase form, so
+// UserID serves "userID" but not "userId". By default names are compared
+// case-insensitively, which can resolve the wrong field when names differ
+// only in case. Strict matching will become the default in a future major
+// version.
+var StrictFieldMatching = false
+
+// structFieldFor finds the struct field a GraphQL field resolves to.
+func structFieldFor(t reflect.Type, name string) (reflect.StructField, bool) {
+	for i := 0; i < t.NumField(); i++ {
+		sf := t.Field(i)
+		if !sf.IsExported() {
+			continue
+		}
+		if StrictFieldMatching {
+			if strictFieldMatch(sf, name) {
+				return sf, true
 			}
+			continue
+		}
+		if tag, ok := sf.Tag.Lookup("json"); ok && strings.EqualFold(jsonName(tag), name) {
+			return sf, true
+		}
+		if strings.EqualFold(sf.Name, name) {
+			return sf, true
 		}
 	}
+	return reflect.StructField{}, false
+}
 
-	return nil, fmt.Errorf("no resolver found for field %s via reflection", field.Name)
+// jsonName returns the name in a json struct tag.
+func jsonName(tag string) string {
+	name, _, _ := strings.Cut(tag, ",")
+	return name
+}
+
+func strictFieldMatch(sf reflect.StructField, name string) bool {
+	if tag, ok := sf.Tag.Lookup("json"); ok {
+		if tagName := jsonName(tag); tagName != "" {
+			return tagName == name
+		}
+	}
+	if sf.Name == name {
+		return true
+	}
+	runes := []rune(sf.Name)
+	runes[0] = unicode.ToLower(runes[0])
+	return string(runes) == name
 }
 
 // buildArgs constructs a map of argument names to values extracted
@@ -124,13 +506,53 @@ func reflectResolve(source interface{}, field *Field) (interface{}, error) {
 // buildArgs constructs a map of argument names to Go values.
 // It recursively handles nested object arguments.
 func buildArgs(field *Field, variables map[string]interface{}) map[string]interface{} {
+	return buildArgumentValues(field.Arguments, variables)
+}
+
+// buildArgumentValues converts a list of field or directive arguments into Go values.
+func buildArgumentValues(arguments []Argument, variables map[string]interface{}) map[string]interface{} {
 	args := make(map[string]interface{})
-	for _, arg := range field.Arguments {
+	for _, arg := range arguments {
 		args[arg.Name] = buildValue(arg.Value, variables)
 	}
 	return args
 }
 
+// fieldArgs builds the arguments of field. When a schema is loaded, literal
+// arguments are coerced to their declared types the same way variables are,
+// so a resolver receives an int for an Int argument however it was written.
+func (e *executor) fieldArgs(parentType string, field *Field) map[string]interface{} {
+	if e.plan != nil {
+		return e.plan.field(parentType, field).arguments(e.variables)
+	}
+	return coercedArgs(parentType, field, e.variables)
+}
+
+// coercedArgs builds the arguments of field, coercing them to the types of
+// the field's schema definition.
+func coercedArgs(parentType string, field *Field, variables map[string]interface{}) map[string]interface{} {
+	args := buildArgs(field, variables)
+	s := CurrentSchema()
+	if s == nil {
+		return args
+	}
+	def := s.Field(parentType, field.Name)
+	if def == nil {
+		return args
+	}
+	for _, argDef := range def.ArgumentDefinitions {
+		value, ok := args[argDef.Name]
+		if !ok || argDef.Type == nil {
+			continue
+		}
+		// Invalid literals are reported by validation; leave them as written.
+		if coerced, err := coerceInputValue(s, argDef.Type, value); err == nil {
+			args[argDef.Name] = coerced
+		}
+	}
+	return args
+}
+
 // buildValue converts a Value to a corresponding Go value.
 // It handles variables, basic scalar types, and nested object values.
 func buildValue(val *Value, variables map[string]interface{}) interface{} {
@@ -143,9 +565,14 @@ func buildValue(val *Value, variables map[string]interface{}) interface{} {
 	case "Int":
 		i, err := strconv.Atoi(val.Literal)
 		if err != nil {
-			return 0
+			// Too large for int; only valid for scalars such as BigInt.
+			n, _ := new(big.Int).SetString(val.Literal, 10)
+			return n
 		}
 		return i
+	case "Float":
+		f, _ := strconv.ParseFloat(val.Literal, 64)
+		return f
 	case "String":
 		return val.Literal
 	case "Boolean":
@@ -169,55 +596,143 @@ func buildValue(val *Value, variables map[string]interface{}) interface{} {
 
 // executeSelectionSet traverses the selection set, resolves each field,
 // and uses resolveNestedSelection to process any nested selections.
-func executeSelectionSet(source interface{}, ss *SelectionSet, variables map[string]interface{}) (map[string]interface{}, error) {
+func (e *executor) executeSelectionSet(source interface{}, ss *SelectionSet) (map[string]interface{}, error) {
+	return e.executeSelectionSetOf(source, e.parentTypeOf(source), ss)
+}
+
+// executeSelectionSetOf executes ss on source, a value of the GraphQL type parentType.
+func (e *executor) executeSelectionSetOf(source interface{}, parentType string, ss *SelectionSet) (map[string]interface{}, error) {
 	result := make(map[string]interface{})
-	for _, sel := range ss.Selections {
-		field, ok := sel.(*Field)
-		if !ok {
+	depth := len(e.path)
+	defer func() { e.path = e.path[:depth] }()
+	done := e.ctx.Done()
+	for _, field := range e.collect(ss, parentType) {
+		// Stop resolving once the request is cancelled, for example because
+		// the client disconnected.
+		select {
+		case <-done:
+			return nil, e.ctx.Err()
+		default:
+		}
+		e.path = append(e.path[:depth], field.ResponseKey())
+		if field.Name == "__typename" {
+			result[field.ResponseKey()] = parentType
 			continue
 		}
 		// Resolve the field based on the current source.
-		res, err := resolveField(source, field, variables)
+		res, err := e.resolveLive(source, parentType, field)
+		if err == nil && debugTraceFrom(e.ctx) != nil {
+			if mismatch := checkResultType(CurrentSchema(), parentType, field, res); mismatch != nil {
+				mismatch.Path = append([]interface{}(nil), e.path...)
+				err = mismatch
+			}
+		}
 		if err != nil {
-			return nil, err
+			// GraphQL errors null out the field and let its siblings resolve.
+			var gqlErr *Error
+			if errors.As(err, &gqlErr) {
+				e.errors = append(e.errors, e.located(gqlErr))
+				result[field.ResponseKey()] = nil
+				continue
+			}
+			return nil, e.failed(err)
 		}
 		// If the field has nested selections, process them.
 		if field.SelectionSet != nil {
-			nested, err := resolveNestedSelection(res, field.SelectionSet, variables)
+			abstractType := e.abstractType
+			e.abstractType = e.abstractTypeOf(parentType, field)
+			nested, err := e.resolveNestedSelection(res, field.SelectionSet)
+			e.abstractType = abstractType
 			if err != nil {
-				return nil, err
+				var gqlErr *Error
+				if errors.As(err, &gqlErr) {
+					e.errors = append(e.errors, e.located(gqlErr))
+					result[field.ResponseKey()] = nil
+					continue
+				}
+				return nil, e.failed(err)
 			}
-			result[field.Name] = nested
+			result[field.ResponseKey()] = nested
 		} else {
-			result[field.Name] = res
+			leaf, err := serializeLeaf(res)
+			if err == nil && e.isIDField(parentType, field) {
+				leaf, err = serializeID(leaf)
+			}
+			if err != nil {
+				e.errors = append(e.errors, e.located(maskError(e.ctx, err)))
+			}
+			result[field.ResponseKey()] = leaf
 		}
 	}
 	return result, nil
 }
 
 // resolveNestedSelection handles nested selection sets by examining the
-// resolved value. It supports both single objects (e.g. *User) and slices (e.g. []*User).
-func resolveNestedSelection(res interface{}, ss *SelectionSet, variables map[string]interface{}) (interface{}, error) {
+// resolved value. It supports single objects (e.g. *User or a map keyed by
+// field name) and lists of them (e.g. []*User, [3]User, []interface{} or
+// *[]*User), including nested lists.
+func (e *executor) resolveNestedSelection(res interface{}, ss *SelectionSet) (interface{}, error) {
+	// A nil result resolves to null rather than to an object or list.
+	if res == nil {
+		return nil, nil
+	}
 	val := reflect.ValueOf(res)
+	if m, ok := res.(Marshaler); ok && !(val.Kind() == reflect.Ptr && val.IsNil()) {
+		// The marshaled value is resolved in place of res, but keeps the
+		// GraphQL type name of res.
+		marshaled, err := m.MarshalGQL()
+		if err != nil {
+			return nil, e.located(maskError(e.ctx, err))
+		}
+		switch reflect.Indirect(reflect.ValueOf(marshaled)).Kind() {
+		case reflect.Struct, reflect.Map:
+			return e.executeSelectionSetOf(marshaled, e.typeNameOf(res), ss)
+		}
+		return e.resolveNestedSelection(marshaled, ss)
+	}
 	switch val.Kind() {
 	case reflect.Ptr:
-		// If pointer is nil, return as is.
+		// A nil pointer resolves to null.
 		if val.IsNil() {
-			return res, nil
+			return nil, nil
 		}
-		// If pointer to struct, process the struct.
+		// If pointer to struct, process the struct. Other pointers, such as
+		// *[]*User, resolve to what they point to.
 		if val.Elem().Kind() == reflect.Struct {
-			return executeSelectionSet(res, ss, variables)
+			return e.executeSelectionSet(res, ss)
 		}
+		return e.resolveNestedSelection(val.Elem().Interface(), ss)
 	case reflect.Struct:
-		return executeSelectionSet(res, ss, variables)
-	case reflect.Slice:
-		var arr []interface{}
+		return e.executeSelectionSet(res, ss)
+	case reflect.Map:
+		if val.IsNil() {
+			return nil, nil
+		}
+		return e.executeSelectionSet(res, ss)
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			if NilListsAsEmpty {
+				return []interface{}{}, nil
+			}
+			return nil, nil
+		}
+		if ListConcurrency > 1 && val.Len() > 1 {
+			return e.resolveListParallel(val, ss)
+		}
+		arr := make([]interface{}, 0, val.Len())
+		depth := len(e.path)
+		defer func() { e.path = e.path[:depth] }()
 		for i := 0; i < val.Len(); i++ {
+			e.path = append(e.path[:depth], i)
 			item := val.Index(i).Interface()
-			sub, err := executeSelectionSet(item, ss, variables)
+			sub, err := e.resolveNestedSelection(item, ss)
 			if err != nil {
-				return nil, err
+				// A GraphQL error nulls only the affected item.
+				var gqlErr *Error
+				if !errors.As(err, &gqlErr) {
+					return nil, e.failed(err)
+				}
+				e.errors = append(e.errors, e.located(gqlErr))
 			}
 			arr = append(arr, sub)
 		}
@@ -227,43 +742,139 @@ func resolveNestedSelection(res interface{}, ss *SelectionSet, variables map[str
 	return res, nil
 }
 
-func GraphqlHandler(w http.ResponseWriter, r *http.Request) {
-	// Expect a JSON body with at least a "query" field.
-	body, err := ioutil.ReadAll(r.Body)
-	if err != nil {
-		http.Error(w, "unable to read body", http.StatusBadRequest)
+// located returns err with the response path of the field or list item being
+// resolved, unless it has a path already. err is copied, as resolvers may
+// return the same *Error more than once.
+func (e *executor) located(err *Error) *Error {
+	if err.Path != nil {
+		return err
+	}
+	located := *err
+	located.Path = append([]interface{}(nil), e.path...)
+	return &located
+}
+
+// executionError is an error other than a GraphQL error, which stops
+// execution, with the response path of the field it occurred at.
+type executionError struct {
+	path []interface{}
+	err  error
+}
+
+func (e *executionError) Error() string { return e.err.Error() }
+func (e *executionError) Unwrap() error { return e.err }
+
+// failed returns err, which stops execution, with the response path of the
+// field being resolved, unless it has a path already.
+func (e *executor) failed(err error) error {
+	var located *executionError
+	if errors.As(err, &located) {
+		return err
+	}
+	return &executionError{path: append([]interface{}(nil), e.path...), err: err}
+}
+
+// writeInternalError responds to a request whose execution failed. With
+// MaskInternalErrors set, the error is masked and reported as a GraphQL error.
+func writeInternalError(w http.ResponseWriter, r *http.Request, err error) {
+	writeResponseHeader(w, r)
+	if !MaskInternalErrors {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
 		return
 	}
-	defer r.Body.Close()
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusInternalServerError)
+	json.NewEncoder(w).Encode(map[string]interface{}{"errors": withRequestIDs(r.Context(), []*Error{maskError(r.Context(), err)})})
+}
+
+// GraphqlHandler serves GraphQL queries and mutations sent as JSON POST bodies.
+// It can be used both as an http.Handler and as a handler function.
+var GraphqlHandler = http.HandlerFunc(defaultHandler.serveJSON)
+
+// MaxRequestBodySize limits the size in bytes of JSON request bodies. Larger
+// requests are answered with 413 Request Entity Too Large. Zero disables the
+// limit. Multipart uploads are limited separately.
+var MaxRequestBodySize int64 = 1 << 20
+
+// decodeRequestJSON decodes the single JSON value read from r into v. Numbers are
+// decoded as json.Number so that large integers keep their precision.
+func decodeRequestJSON(r io.Reader, v interface{}) error {
+	dec := json.NewDecoder(r)
+	dec.UseNumber()
+	if err := dec.Decode(v); err != nil {
+		return err
+	}
+	if _, err := dec.Token(); err != io.EOF {
+		return fmt.Errorf("unexpected data after the JSON value")
+	}
+	return nil
+}
+
+func (h *handler) serveJSON(w http.ResponseWriter, r *http.Request) {
+	if !h.accept(w, r, false) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		h.serveGet(w, r)
+		return
+	}
+	h.servePost(w, r)
+}
 
-	var req struct {
-		Query     string                 `json:"query"`
-		Variables map[string]interface{} `json:"variables"`
+// servePost serves a request sent as a JSON POST body.
+func (h *handler) servePost(w http.ResponseWriter, r *http.Request) {
+	// Expect a JSON body with at least a "query" field.
+	start := time.Now()
+	body := r.Body
+	if MaxRequestBodySize > 0 {
+		body = http.MaxBytesReader(w, body, MaxRequestBodySize)
 	}
+	defer body.Close()
 
-	if err := json.Unmarshal(body, &req); err != nil {
+	var req graphqlRequest
+	if err := decodeRequestJSON(body, &req); err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			http.Error(w, "request body too large", http.StatusRequestEntityTooLarge)
+			return
+		}
 		http.Error(w, "invalid JSON", http.StatusBadRequest)
 		return
 	}
 	if req.Variables == nil {
 		req.Variables = make(map[string]interface{})
 	}
+	req.readBody = time.Since(start)
 
-	// Lex and parse the query.
-	lexer := NewLexer(req.Query)
-	parser := NewParser(lexer)
-	doc := parser.ParseDocument()
+	// Parse and execute the query.
+	h.execute(w, r, req)
+}
 
-	// Execute the query.
-	result, err := executeDocument(doc, req.Variables)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+// serveGet serves a query sent as the "query", "operationName", "variables",
+// "extensions" and "documentId" or "id" URL parameters.
+// Only queries may be sent this way.
+func (h *handler) serveGet(w http.ResponseWriter, r *http.Request) {
+	params := r.URL.Query()
+	req := graphqlRequest{
+		Query:         params.Get("query"),
+		OperationName: params.Get("operationName"),
+		DocumentID:    params.Get("documentId"),
+		ID:            params.Get("id"),
+		Variables:     make(map[string]interface{}),
 	}
-
-	// Return the JSON result.
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
+	if raw := params.Get("variables"); raw != "" {
+		if err := decodeRequestJSON(strings.NewReader(raw), &req.Variables); err != nil {
+			http.Error(w, "invalid variables JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	if raw := params.Get("extensions"); raw != "" {
+		if err := decodeRequestJSON(strings.NewReader(raw), &req.Extensions); err != nil {
+			http.Error(w, "invalid extensions JSON", http.StatusBadRequest)
+			return
+		}
+	}
+	h.execute(w, r, req)
 }
 
 // executeSubscription calls the registered subscription resolver and returns a channel.
@@ -271,58 +882,118 @@ func GraphqlHandler(w http.ResponseWriter, r *http.Request) {
 // executeSubscription calls the registered subscription resolver and returns a channel.
 // The resolver should return either a chan interface{} or a <-chan interface{}.
 func executeSubscription(source interface{}, field *Field, variables map[string]interface{}) (<-chan interface{}, error) {
+	res, err := resolveSubscription(context.Background(), source, field, variables)
+	if err != nil {
+		return nil, err
+	}
+	return subscriptionChannel(field, res)
+}
+
+// resolveSubscription calls the registered subscription resolver and returns
+// its result. As for queries, a resolver registered with RegisterFieldResolver
+// on the subscription type takes precedence over one registered with
+// RegisterSubscriptionResolver; it is called with ctx, the context of the
+// subscription request.
+func resolveSubscription(ctx context.Context, source interface{}, field *Field, variables map[string]interface{}) (interface{}, error) {
+	typeName := "Subscription"
+	if s := CurrentSchema(); s != nil && s.SubscriptionType != "" {
+		typeName = s.SubscriptionType
+	}
+	if resolver, ok := FieldResolvers[typeName][field.Name]; ok {
+		return resolver(ctx, source, buildArgs(field, variables))
+	}
 	if resolver, ok := SubscriptionResolvers[field.Name]; ok {
 		args := buildArgs(field, variables)
-		res, err := resolver(source, args)
-		if err != nil {
-			return nil, err
-		}
-		// Try to type assert to a read-only channel.
-		if ch, ok := res.(<-chan interface{}); ok {
-			return ch, nil
-		}
-		// Otherwise, try to type assert to a bidirectional channel and convert it.
-		if ch, ok := res.(chan interface{}); ok {
-			return (<-chan interface{})(ch), nil
-		}
-		return nil, fmt.Errorf("subscription resolver for field %s did not return a channel", field.Name)
+		return resolver(source, args)
 	}
 	return nil, fmt.Errorf("no subscription resolver found for field %s", field.Name)
 }
 
-// upgrader upgrades HTTP connections to WebSocket connections.
-var upgrader = websocket.Upgrader{
-	CheckOrigin: func(r *http.Request) bool { return true },
+// subscriptionChannel returns the channel a subscription resolver returned.
+func subscriptionChannel(field *Field, res interface{}) (<-chan interface{}, error) {
+	// Try to type assert to a read-only channel.
+	if ch, ok := res.(<-chan interface{}); ok {
+		return ch, nil
+	}
+	// Otherwise, try to type assert to a bidirectional channel and convert it.
+	if ch, ok := res.(chan interface{}); ok {
+		return (<-chan interface{})(ch), nil
+	}
+	return nil, fmt.Errorf("subscription resolver for field %s did not return a channel", field.Name)
+}
+
+// subscriptionErrorMessage returns the message reporting err to a
+// subscriber, masked if internal errors are.
+func subscriptionErrorMessage(err error) string {
+	msg := err.Error()
+	if masked := maskError(context.Background(), err); masked.Extensions["correlationId"] != nil {
+		msg = fmt.Sprintf("%s (correlation ID %s)", masked.Message, masked.Extensions["correlationId"])
+	}
+	return msg
 }
 
 // SubscriptionRequest represents the expected JSON payload for a subscription request.
 type SubscriptionRequest struct {
-	Query     string                 `json:"query"`
-	Variables map[string]interface{} `json:"variables"`
+	Query         string                 `json:"query"`
+	OperationName string                 `json:"operationName"`
+	Variables     map[string]interface{} `json:"variables"`
+	// LastEventID is the ID of the last event a reconnecting client
+	// received from a Topic; see TopicEvent.
+	LastEventID string `json:"lastEventId,omitempty"`
 }
 
 // SubscriptionHandler handles incoming subscription requests over WebSocket.
-func SubscriptionHandler(w http.ResponseWriter, r *http.Request) {
+var SubscriptionHandler = http.HandlerFunc(defaultHandler.serveSubscription)
+
+func (h *handler) serveSubscription(w http.ResponseWriter, r *http.Request) {
+	r = h.withContext(h.withRequestID(w, r))
+	u := h.upgraderOf()
+	if u == nil {
+		http.Error(w, "no WebSocket upgrader configured", http.StatusInternalServerError)
+		return
+	}
 	// Upgrade HTTP to WebSocket.
-	conn, err := upgrader.Upgrade(w, r, nil)
+	conn, err := h.upgrade(u, w, r)
 	if err != nil {
 		// Before upgrade, it's safe to use http.Error.
 		http.Error(w, "unable to upgrade to websocket", http.StatusBadRequest)
 		return
 	}
 	defer conn.Close()
+	h.subscribe(r, conn)
+}
+
+// SubscriptionConn is the WebSocket connection a subscription is served on.
+// *websocket.Conn from gorilla/websocket and API-compatible forks implement it.
+// Messages are sent as TextMessage frames.
+type SubscriptionConn interface {
+	ReadMessage() (messageType int, p []byte, err error)
+	WriteMessage(messageType int, data []byte) error
+	WriteJSON(v interface{}) error
+}
+
+// ServeSubscription reads a subscription request from an upgraded connection
+// and streams the resolver's events to it until the event channel is closed.
+// The caller owns conn and is responsible for closing it.
+func ServeSubscription(conn SubscriptionConn) {
+	defaultHandler.subscribe(nil, conn)
+}
 
+// subscribe serves a subscription on conn. r is the request conn was upgraded
+// from, or nil if it is not known.
+func (h *handler) subscribe(r *http.Request, conn SubscriptionConn) {
+	defer h.metrics.connect()()
 	// Read the subscription request from the WebSocket.
 	_, msg, err := conn.ReadMessage()
 	if err != nil {
 		// After upgrade, write error messages directly to the WebSocket.
-		conn.WriteMessage(websocket.TextMessage, []byte("failed to read subscription message"))
+		conn.WriteMessage(TextMessage, []byte("failed to read subscription message"))
 		return
 	}
 
 	var req SubscriptionRequest
 	if err := json.Unmarshal(msg, &req); err != nil {
-		conn.WriteMessage(websocket.TextMessage, []byte("invalid subscription JSON"))
+		conn.WriteMessage(TextMessage, []byte("invalid subscription JSON"))
 		return
 	}
 
@@ -330,67 +1001,148 @@ func SubscriptionHandler(w http.ResponseWriter, r *http.Request) {
 	lexer := NewLexer(req.Query)
 	parser := NewParser(lexer)
 	doc := parser.ParseDocument()
+	if errs := parser.Errors(); len(errs) > 0 {
+		conn.WriteMessage(TextMessage, []byte(errs[0].Message))
+		return
+	}
 
 	if len(doc.Definitions) == 0 {
-		conn.WriteMessage(websocket.TextMessage, []byte("no subscription definition found"))
+		conn.WriteMessage(TextMessage, []byte("no subscription definition found"))
+		return
+	}
+	if errs := validateOperations(doc); len(errs) > 0 {
+		conn.WriteMessage(TextMessage, []byte(errs[0].Message))
 		return
 	}
 
-	op, ok := doc.Definitions[0].(*OperationDefinition)
-	if !ok || op.Operation != "subscription" {
-		conn.WriteMessage(websocket.TextMessage, []byte("provided operation is not a subscription"))
+	op := selectOperation(doc, req.OperationName)
+	live := h.liveQueries && op != nil && op.Operation == "query"
+	if op == nil || op.Operation != "subscription" && !live {
+		conn.WriteMessage(TextMessage, []byte("provided operation is not a subscription"))
 		return
 	}
 
 	if len(op.SelectionSet.Selections) == 0 {
-		conn.WriteMessage(websocket.TextMessage, []byte("subscription selection set is empty"))
+		conn.WriteMessage(TextMessage, []byte("subscription selection set is empty"))
+		return
+	}
+	if errs := CheckOperationLimits(op, DefaultOperationLimits); len(errs) > 0 {
+		conn.WriteMessage(TextMessage, []byte(errs[0].Message))
+		return
+	}
+	// As for queries, reject invalid documents when a schema is loaded.
+	if s := CurrentSchema(); s != nil {
+		if errs := Validate(s, doc); len(errs) > 0 {
+			conn.WriteMessage(TextMessage, []byte(errs[0].Message))
+			return
+		}
+	}
+	if rejected := h.readOnly.check(op); rejected != nil {
+		conn.WriteMessage(TextMessage, []byte(rejected.Message))
+		return
+	}
+	if rejected := h.filter.check(op); rejected != nil {
+		conn.WriteMessage(TextMessage, []byte(rejected.Message))
+		return
+	}
+	if rejected := h.checkLimits(op); rejected != nil {
+		conn.WriteMessage(TextMessage, []byte(rejected.Message))
+		return
+	}
+	if r != nil {
+		if limited := h.rateLimit(r, op); limited != nil {
+			conn.WriteMessage(TextMessage, []byte(limited.Message))
+			return
+		}
+	}
+	ctx := context.Background()
+	if r != nil {
+		ctx = r.Context()
+	}
+	release, rejected := h.quota.acquire(ctx)
+	if rejected != nil {
+		conn.WriteMessage(TextMessage, []byte(rejected.Message))
+		return
+	}
+	defer release()
+	if live {
+		h.serveLiveQuery(ctx, conn, doc, op, req)
+		return
+	}
+	variables, errs := coerceVariables(CurrentSchema(), op, req.Variables)
+	if len(errs) > 0 {
+		conn.WriteMessage(TextMessage, []byte(errs[0].Message))
 		return
 	}
 
-	field, ok := op.SelectionSet.Selections[0].(*Field)
-	if !ok {
-		conn.WriteMessage(websocket.TextMessage, []byte("invalid subscription field"))
+	fields := fieldsOf(op.SelectionSet)
+	if len(fields) == 0 {
+		conn.WriteMessage(TextMessage, []byte("invalid subscription field"))
 		return
 	}
+	field := fields[0]
 
 	// Execute the subscription.
-	subCh, err := executeSubscription(nil, field, req.Variables)
+	var subCh <-chan interface{}
+	res, err := resolveSubscription(ctx, nil, field, variables)
+	if topic, ok := res.(*Topic); ok && err == nil {
+		var stop func()
+		subCh, stop = topic.events(req.LastEventID)
+		defer stop()
+	} else if err == nil {
+		subCh, err = subscriptionChannel(field, res)
+	}
 	if err != nil {
-		conn.WriteMessage(websocket.TextMessage, []byte(fmt.Sprintf("subscription error: %v", err)))
+		conn.WriteMessage(TextMessage, []byte("subscription error: "+subscriptionErrorMessage(err)))
 		return
 	}
 
+	defer h.metrics.start(field.Name, variables)()
+
 	// Stream events from the subscription channel to the WebSocket.
 	for event := range subCh {
-		if err := conn.WriteJSON(event); err != nil {
-			fmt.Printf("failed to write event: %v\n", err)
+		start := time.Now()
+		err := conn.WriteJSON(event)
+		h.metrics.deliver(time.Since(start), err)
+		if err != nil {
+			logger().Warn("graphql: failed to write subscription event", "error", err)
 			break
 		}
 	}
 }
 
 // GraphqlUploadHandler supports both regular JSON GraphQL requests and multipart uploads.
-// GraphqlUploadHandler handles multipart/form-data requests for file uploads.
+var GraphqlUploadHandler = http.HandlerFunc(defaultHandler.serveUpload)
 
-func GraphqlUploadHandler(w http.ResponseWriter, r *http.Request) {
+func (h *handler) serveUpload(w http.ResponseWriter, r *http.Request) {
+	if !h.accept(w, r, true) {
+		return
+	}
+	if r.Method == http.MethodGet {
+		h.serveGet(w, r)
+		return
+	}
 	if !strings.HasPrefix(r.Header.Get("Content-Type"), "multipart/form-data") {
-		GraphqlHandler(w, r)
+		h.servePost(w, r)
 		return
 	}
-	if err := r.ParseMultipartForm(32 << 20); err != nil {
+	start := time.Now()
+	maxMemory := int64(defaultUploadMemory)
+	if h.uploadMemory > 0 {
+		maxMemory = h.uploadMemory
+	}
+	if err := r.ParseMultipartForm(maxMemory); err != nil {
 		http.Error(w, "failed to parse multipart form: "+err.Error(), http.StatusBadRequest)
 		return
 	}
+	defer r.MultipartForm.RemoveAll()
 	operations := r.FormValue("operations")
 	if operations == "" {
 		http.Error(w, "missing operations field", http.StatusBadRequest)
 		return
 	}
-	var req struct {
-		Query     string                 `json:"query"`
-		Variables map[string]interface{} `json:"variables"`
-	}
-	if err := json.Unmarshal([]byte(operations), &req); err != nil {
+	var req graphqlRequest
+	if err := decodeRequestJSON(strings.NewReader(operations), &req); err != nil {
 		http.Error(w, "invalid operations JSON: "+err.Error(), http.StatusBadRequest)
 		return
 	}
@@ -408,111 +1160,39 @@ func GraphqlUploadHandler(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	var wg sync.WaitGroup
-	var varMu sync.Mutex
-
-	for fileKey, paths := range fileMap {
-		wg.Add(1)
-		go func(fileKey string, paths []string) {
-			defer wg.Done()
-			file, header, err := r.FormFile(fileKey)
-			if err != nil {
-				log.Printf("failed to retrieve file %s: %v", fileKey, err)
-				return
-			}
-			defer file.Close()
-			fileData, err := ioutil.ReadAll(file)
-			if err != nil {
-				log.Printf("failed to read file %s: %v", header.Filename, err)
-				return
-			}
-			log.Printf("Uploaded file %q with %d bytes", header.Filename, len(fileData))
-			for _, path := range paths {
-				// Remove the "variables." prefix if present.
-				adjustedPath := strings.TrimPrefix(path, "variables.")
-				varMu.Lock()
-				// If the path contains a dot and the second part is numeric, update as an array.
-				parts := strings.Split(adjustedPath, ".")
-				if len(parts) == 2 {
-					if _, err := strconv.Atoi(parts[1]); err == nil {
-						setNestedArrayValue(req.Variables, adjustedPath, map[string]interface{}{
-							"filename": header.Filename,
-							"data":     fileData,
-						})
-						varMu.Unlock()
-						continue
-					}
-				}
-				setNestedValue(req.Variables, adjustedPath, map[string]interface{}{
-					"filename": header.Filename,
-					"data":     fileData,
-				})
-				varMu.Unlock()
-			}
-		}(fileKey, paths)
-	}
-	wg.Wait()
-
-	// Continue processing the GraphQL query.
-	lexer := NewLexer(req.Query)
-	parser := NewParser(lexer)
-	doc := parser.ParseDocument()
-	result, err := executeDocument(doc, req.Variables)
-	if err != nil {
-		http.Error(w, err.Error(), http.StatusInternalServerError)
-		return
+	keys := make([]string, 0, len(fileMap))
+	for fileKey := range fileMap {
+		keys = append(keys, fileKey)
 	}
-	w.Header().Set("Content-Type", "application/json")
-	json.NewEncoder(w).Encode(result)
-}
-
-// setNestedValue is used for updating nested maps (non-array paths).
-func setNestedValue(vars map[string]interface{}, path string, value interface{}) {
-	keys := strings.Split(path, ".")
-	current := vars
-	for i, key := range keys {
-		if i == len(keys)-1 {
-			current[key] = value
+	sort.Strings(keys)
+	placer := newUploadPlacer(req.Variables)
+	for _, fileKey := range keys {
+		file, header, err := r.FormFile(fileKey)
+		if err != nil {
+			http.Error(w, fmt.Sprintf("missing file %q: %v", fileKey, err), http.StatusBadRequest)
 			return
 		}
-		if next, ok := current[key].(map[string]interface{}); ok {
-			current = next
-		} else {
-			newMap := make(map[string]interface{})
-			current[key] = newMap
-			current = newMap
+		defer file.Close()
+		upload, err := readUpload(file, header, h.uploadMemory <= 0)
+		if err != nil {
+			logger().ErrorContext(r.Context(), "graphql: failed to read uploaded file", "filename", header.Filename, "error", err)
+			http.Error(w, fmt.Sprintf("failed to read file %q", fileKey), http.StatusBadRequest)
+			return
+		}
+		logger().DebugContext(r.Context(), "graphql: uploaded file", "filename", header.Filename, "bytes", header.Size)
+		for _, path := range fileMap[fileKey] {
+			if err := placer.place(path, uploadValue(upload)); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
 		}
 	}
-}
-
-// setNestedArrayValue updates an array element given a path like "files.0".
-func setNestedArrayValue(vars map[string]interface{}, path string, value interface{}) {
-	// Expecting a two-part path: e.g. "files.0"
-	parts := strings.Split(path, ".")
-	if len(parts) != 2 {
-		// Fallback to the regular setter if not the expected format.
-		setNestedValue(vars, path, value)
-		return
-	}
-	arrayKey := parts[0]
-	idx, err := strconv.Atoi(parts[1])
-	if err != nil {
-		setNestedValue(vars, path, value)
+	if err := placer.check(); err != nil {
+		http.Error(w, err.Error(), http.StatusBadRequest)
 		return
 	}
-	if arr, ok := vars[arrayKey].([]interface{}); ok {
-		// Extend the slice if needed.
-		if idx >= len(arr) {
-			newArr := make([]interface{}, idx+1)
-			copy(newArr, arr)
-			arr = newArr
-			vars[arrayKey] = arr
-		}
-		arr[idx] = value
-	} else {
-		// If not an array, create one.
-		newArr := make([]interface{}, idx+1)
-		newArr[idx] = value
-		vars[arrayKey] = newArr
-	}
+	req.readBody = time.Since(start)
+
+	// Continue processing the GraphQL query.
+	h.execute(w, r, req)
 }
@@ -2,9 +2,12 @@ package vibeGraphql
 
 import (
 	"bytes"
+	"context"
 	"mime/multipart"
 	"net/http"
 	"net/http/httptest"
+	"reflect"
+	"strings"
 	"testing"
 
 	"github.com/gorilla/websocket"
@@ -70,6 +73,30 @@ func TestReflectResolve(t *testing.T) {
 	}
 }
 
+func TestReflectResolve_Strict(t *testing.T) {
+	StrictFieldMatching = true
+	defer func() { StrictFieldMatching = false }()
+
+	type account struct {
+		UserID   string
+		FullName string `json:"name"`
+		Email    string `json:",omitempty"`
+		Password string `json:"-"`
+	}
+	src := account{UserID: "u1", FullName: "Ada", Email: "ada@example.com", Password: "secret"}
+	for name, want := range map[string]interface{}{"userID": "u1", "UserID": "u1", "name": "Ada", "email": "ada@example.com"} {
+		res, err := reflectResolve(src, &Field{Name: name})
+		if err != nil || res != want {
+			t.Errorf("%s: got %v, %v; want %v", name, res, err, want)
+		}
+	}
+	for _, name := range []string{"userId", "USERID", "fullName", "Name", "password"} {
+		if res, err := reflectResolve(src, &Field{Name: name}); err == nil {
+			t.Errorf("%s: expected no match in strict mode, got %v", name, res)
+		}
+	}
+}
+
 // ---------- Additional test for executeSelectionSet with nested selections ----------
 
 // DummyUser is used to simulate nested field resolution.
@@ -91,7 +118,7 @@ func TestExecuteSelectionSet_Nested(t *testing.T) {
 	}
 
 	// Call executeSelectionSet with the dummy user as source.
-	result, err := executeSelectionSet(user, selectionSet, nil)
+	result, err := newExecutor(context.Background(), nil, nil).executeSelectionSet(user, selectionSet)
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
@@ -136,9 +163,34 @@ func TestExecuteDocument_UnsupportedDefinition(t *testing.T) {
 			struct{ Node }{},
 		},
 	}
-	_, err := executeDocument(doc, nil)
-	if err == nil {
-		t.Error("expected error for unsupported definition type, got nil")
+	result, err := executeDocument(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := result["errors"].([]*Error)
+	if len(errs) != 1 || errs[0].Message != "Document does not contain an operation to execute." {
+		t.Errorf("expected an error for a document without operations, got %v", result)
+	}
+}
+
+func TestExecuteDocument_SkipsTypeDefinitions(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	result, err := executeRequest(context.Background(), `type Query { hello: String } { hello }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data, _ := result["data"].(map[string]interface{}); data["hello"] != "world" || result["errors"] != nil {
+		t.Errorf("expected the operation after the type definition to run, got %v", result)
+	}
+
+	result, err = executeRequest(context.Background(), `type Query { hello: String }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := result["errors"].([]*Error); len(errs) != 1 {
+		t.Errorf("expected an error for a document with only type definitions, got %v", result)
 	}
 }
 
@@ -183,10 +235,12 @@ func TestGraphqlUploadHandler_Success(t *testing.T) {
 	}
 }
 
-// TestSetNestedValue verifies that setNestedValue properly updates a nested map.
-func TestSetNestedValue(t *testing.T) {
+// TestUploadPlacerObject verifies that files are placed in nested objects.
+func TestUploadPlacerObject(t *testing.T) {
 	vars := make(map[string]interface{})
-	setNestedValue(vars, "a.b", "nestedValue")
+	if err := newUploadPlacer(vars).place("variables.a.b", "nestedValue"); err != nil {
+		t.Fatal(err)
+	}
 	m, ok := vars["a"].(map[string]interface{})
 	if !ok {
 		t.Fatalf("expected key 'a' to be a map, got %T", vars["a"])
@@ -196,11 +250,13 @@ func TestSetNestedValue(t *testing.T) {
 	}
 }
 
-// TestSetNestedArrayValue verifies that setNestedArrayValue correctly creates or extends an array.
-func TestSetNestedArrayValue(t *testing.T) {
+// TestUploadPlacerList verifies that the placer creates or extends lists and
+// rejects sparse indexes.
+func TestUploadPlacerList(t *testing.T) {
 	vars := make(map[string]interface{})
+	placer := newUploadPlacer(vars)
 	// Set an element at index 0.
-	setNestedArrayValue(vars, "files.0", "file0")
+	placer.place("variables.files.0", "file0")
 	arr, ok := vars["files"].([]interface{})
 	if !ok {
 		t.Fatalf("expected key 'files' to be an array, got %T", vars["files"])
@@ -210,7 +266,7 @@ func TestSetNestedArrayValue(t *testing.T) {
 	}
 
 	// Extend the array to index 2.
-	setNestedArrayValue(vars, "files.2", "file2")
+	placer.place("variables.files.2", "file2")
 	arr, ok = vars["files"].([]interface{})
 	if !ok || len(arr) < 3 {
 		t.Fatalf("expected array of length at least 3, got %v", vars["files"])
@@ -218,6 +274,26 @@ func TestSetNestedArrayValue(t *testing.T) {
 	if arr[2] != "file2" {
 		t.Errorf("expected element at index 2 to be 'file2', got %v", arr[2])
 	}
+	if err := placer.check(); err == nil || !strings.Contains(err.Error(), `"variables.files.1"`) {
+		t.Errorf("expected index 1 to be reported missing, got %v", err)
+	}
+	placer.place("variables.files.1", "file1")
+	if err := placer.check(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+
+	// Lists nested in input objects are supported too.
+	placer.place("variables.input.docs.1", "doc1")
+	placer.place("variables.input.docs.0", "doc0")
+	if docs := vars["input"].(map[string]interface{})["docs"]; !reflect.DeepEqual(docs, []interface{}{"doc0", "doc1"}) {
+		t.Errorf("unexpected nested list %v", docs)
+	}
+	if err := placer.check(); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := placer.place("files.0", "file"); err == nil {
+		t.Error("expected paths outside variables to be rejected")
+	}
 }
 
 // TestGraphqlUploadHandler_MissingOperations verifies that GraphqlUploadHandler returns an error
@@ -266,9 +342,9 @@ func TestSubscriptionHandler_UpgradeFailure(t *testing.T) {
 	// Override the upgrader to always fail by rejecting the origin.
 	origUpgrader := upgrader
 	defer func() { upgrader = origUpgrader }()
-	upgrader = websocket.Upgrader{
+	upgrader = gorillaUpgrader{websocket.Upgrader{
 		CheckOrigin: func(r *http.Request) bool { return false },
-	}
+	}}
 
 	req := httptest.NewRequest("GET", "/subscription", nil)
 	rr := httptest.NewRecorder()
@@ -277,3 +353,205 @@ func TestSubscriptionHandler_UpgradeFailure(t *testing.T) {
 		t.Errorf("expected status 400 for upgrade failure, got %d", rr.Code)
 	}
 }
+
+func TestResolveNestedSelection_Maps(t *testing.T) {
+	RegisterQueryResolver("mapUser", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return map[string]interface{}{
+			"name":    "Ada",
+			"secret":  "hidden",
+			"friends": []interface{}{map[string]interface{}{"name": "Grace", "secret": "x"}, nil},
+		}, nil
+	})
+	data := executeQuery(t, `{ mapUser { name friends { name } } }`, nil)
+	user := data["mapUser"].(map[string]interface{})
+	if _, ok := user["secret"]; ok {
+		t.Errorf("expected unselected map keys to be omitted, got %v", user)
+	}
+	friends := user["friends"].([]interface{})
+	if len(friends) != 2 || friends[0].(map[string]interface{})["name"] != "Grace" || friends[1] != nil {
+		t.Errorf("unexpected friends: %v", friends)
+	}
+}
+
+func TestResolveNestedSelection_Nil(t *testing.T) {
+	type friend struct{ Name string }
+	resolve := func(v interface{}) ResolverFunc {
+		return func(source interface{}, args map[string]interface{}) (interface{}, error) { return v, nil }
+	}
+	useResolvers(t, map[string]ResolverFunc{
+		"none":    resolve(nil),
+		"pointer": resolve((*friend)(nil)),
+		"object":  resolve(map[string]interface{}(nil)),
+		"list":    resolve([]*friend(nil)),
+		"items":   resolve([]interface{}{nil, (*friend)(nil), &friend{Name: "Ada"}}),
+	}, map[string]map[string]ContextResolverFunc{})
+	query := `{ none { name } pointer { name } object { name } list { name } items { name } }`
+
+	want := map[string]interface{}{
+		"none": nil, "pointer": nil, "object": nil, "list": nil,
+		"items": []interface{}{nil, nil, map[string]interface{}{"name": "Ada"}},
+	}
+	if data := executeQuery(t, query, nil); !reflect.DeepEqual(data, want) {
+		t.Errorf("data = %v, want %v", data, want)
+	}
+
+	defer func(prev bool) { NilListsAsEmpty = prev }(NilListsAsEmpty)
+	NilListsAsEmpty = true
+	want["list"] = []interface{}{}
+	if data := executeQuery(t, query, nil); !reflect.DeepEqual(data, want) {
+		t.Errorf("with NilListsAsEmpty, data = %v, want %v", data, want)
+	}
+}
+
+func TestResolveNestedSelection_Lists(t *testing.T) {
+	type user struct{ Name string }
+	users := []*user{{Name: "Ada"}, {Name: "Grace"}}
+	resolve := func(v interface{}) ResolverFunc {
+		return func(source interface{}, args map[string]interface{}) (interface{}, error) { return v, nil }
+	}
+	useResolvers(t, map[string]ResolverFunc{
+		"pointer":    resolve(&users),
+		"array":      resolve([2]user{{Name: "Ada"}, {Name: "Grace"}}),
+		"interfaces": resolve([]interface{}{user{Name: "Ada"}, &user{Name: "Grace"}}),
+		"nested":     resolve(&[][2]*user{{users[0], users[1]}}),
+	}, map[string]map[string]ContextResolverFunc{
+		"user": {"greeting": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			switch u := source.(type) {
+			case user:
+				return "Hi " + u.Name, nil
+			case *user:
+				return "Hi " + u.Name, nil
+			}
+			return nil, nil
+		}},
+	})
+	defer func(prev int) { ListConcurrency = prev }(ListConcurrency)
+
+	people := []interface{}{
+		map[string]interface{}{"name": "Ada", "greeting": "Hi Ada"},
+		map[string]interface{}{"name": "Grace", "greeting": "Hi Grace"},
+	}
+	want := map[string]interface{}{
+		"pointer": people, "array": people, "interfaces": people,
+		"nested": []interface{}{people},
+	}
+	for _, concurrency := range []int{1, 4} {
+		ListConcurrency = concurrency
+		data := executeQuery(t, `{
+			pointer { name greeting }
+			array { name greeting }
+			interfaces { name greeting }
+			nested { name greeting }
+		}`, nil)
+		if !reflect.DeepEqual(data, want) {
+			t.Errorf("ListConcurrency %d: data = %v, want %v", concurrency, data, want)
+		}
+	}
+}
+
+type dynamicRecord struct {
+	fields map[string]interface{}
+}
+
+func TestSchemaDefaultResolver(t *testing.T) {
+	s := useTestSchema(t, `
+		type Record { id: ID title: String }
+		type Query { record: Record }
+	`)
+	s.DefaultResolver = func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		if rec, ok := source.(*dynamicRecord); ok {
+			return rec.fields[ResolveInfoFromContext(ctx).FieldName], nil
+		}
+		return ReflectResolver(ctx, source, args)
+	}
+	QueryResolvers["record"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return &dynamicRecord{fields: map[string]interface{}{"id": "r1", "title": "Dynamic"}}, nil
+	}
+	defer delete(QueryResolvers, "record")
+	FieldResolvers["dynamicRecord"] = map[string]ContextResolverFunc{
+		"title": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return "registered resolvers win", nil
+		},
+	}
+	defer delete(FieldResolvers, "dynamicRecord")
+
+	data := executeQuery(t, `{ record { id title } }`, nil)
+	want := map[string]interface{}{"id": "r1", "title": "registered resolvers win"}
+	if !reflect.DeepEqual(data["record"], want) {
+		t.Errorf("record = %v, want %v", data["record"], want)
+	}
+}
+
+func BenchmarkExecute(b *testing.B) {
+	type author struct {
+		ID   string `json:"id"`
+		Name string `json:"name"`
+	}
+	type comment struct {
+		ID     string  `json:"id"`
+		Body   string  `json:"body"`
+		Author *author `json:"author"`
+	}
+	type post struct {
+		ID       string     `json:"id"`
+		Title    string     `json:"title"`
+		Tags     []string   `json:"tags"`
+		Comments []*comment `json:"comments"`
+	}
+	type user struct {
+		ID    string  `json:"id"`
+		Name  string  `json:"name"`
+		Posts []*post `json:"posts"`
+	}
+	u := &user{ID: "1", Name: "Ada"}
+	for i := 0; i < 10; i++ {
+		p := &post{ID: "p", Title: "Title", Tags: []string{"go", "graphql"}}
+		for j := 0; j < 5; j++ {
+			p.Comments = append(p.Comments, &comment{ID: "c", Body: "Body", Author: &author{ID: "2", Name: "Grace"}})
+		}
+		u.Posts = append(u.Posts, p)
+	}
+	useResolvers(b, map[string]ResolverFunc{
+		"user":  func(source interface{}, args map[string]interface{}) (interface{}, error) { return u, nil },
+		"stats": func(source interface{}, args map[string]interface{}) (interface{}, error) { return map[string]int{"users": 1, "posts": 10, "comments": 50}, nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	variables := map[string]interface{}{"id": "1", "first": 10}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		result, err := executeRequest(context.Background(), benchmarkQuery, variables)
+		if err != nil || result["errors"] != nil {
+			b.Fatal(err, result["errors"])
+		}
+	}
+}
+
+func TestGraphqlHandler_RequestBody(t *testing.T) {
+	var received interface{}
+	QueryResolvers["bodyEcho"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		received = args["n"]
+		return "ok", nil
+	}
+	defer delete(QueryResolvers, "bodyEcho")
+	defer func(prev int64) { MaxRequestBodySize = prev }(MaxRequestBodySize)
+	MaxRequestBodySize = 200
+
+	send := func(body string) *httptest.ResponseRecorder {
+		rr := httptest.NewRecorder()
+		GraphqlHandler.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+		return rr
+	}
+	if rr := send(`{"query": "query ($n: ID) { bodyEcho(n: $n) }", "variables": {"n": 9007199254740993}}`); rr.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Body.String())
+	}
+	if received != "9007199254740993" {
+		t.Errorf("expected the number to keep its precision, got %#v", received)
+	}
+	if rr := send(`{"query": "{ bodyEcho }"} {"query": "{ bodyEcho }"}`); rr.Code != http.StatusBadRequest {
+		t.Errorf("expected trailing data to be rejected, got %d", rr.Code)
+	}
+	if rr := send(`{"query": "{ bodyEcho }", "variables": {"pad": "` + strings.Repeat("x", 200) + `"}}`); rr.Code != http.StatusRequestEntityTooLarge {
+		t.Errorf("expected an oversized body to be rejected, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,68 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"sync"
+)
+
+type responseHeaderKey struct{}
+
+// responseHeader collects the headers resolvers set on a response.
+type responseHeader struct {
+	mu     sync.Mutex
+	header http.Header
+}
+
+// withResponseHeader returns a context in which SetResponseHeader and the
+// related functions take effect.
+func withResponseHeader(ctx context.Context) context.Context {
+	if _, ok := ctx.Value(responseHeaderKey{}).(*responseHeader); ok {
+		return ctx
+	}
+	return context.WithValue(ctx, responseHeaderKey{}, &responseHeader{header: make(http.Header)})
+}
+
+// updateResponseHeader calls update with the response's headers under their
+// lock. It does nothing if ctx does not belong to a request.
+func updateResponseHeader(ctx context.Context, update func(header http.Header)) {
+	h, ok := ctx.Value(responseHeaderKey{}).(*responseHeader)
+	if !ok {
+		return
+	}
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	update(h.header)
+}
+
+// SetResponseHeader sets a header of the HTTP response, replacing any values
+// it has. It can be called from resolvers, directives and middleware while a
+// query or mutation executes; the headers are written along with the
+// response, but are not stored in the response cache. It does nothing if
+// ctx does not belong to a request.
+func SetResponseHeader(ctx context.Context, key, value string) {
+	updateResponseHeader(ctx, func(header http.Header) { header.Set(key, value) })
+}
+
+// AddResponseHeader adds a value to a header of the HTTP response, like
+// SetResponseHeader.
+func AddResponseHeader(ctx context.Context, key, value string) {
+	updateResponseHeader(ctx, func(header http.Header) { header.Add(key, value) })
+}
+
+// SetCookie adds a Set-Cookie header to the HTTP response, for example after
+// a login mutation. Invalid cookies are dropped, as with http.SetCookie.
+func SetCookie(ctx context.Context, cookie *http.Cookie) {
+	if v := cookie.String(); v != "" {
+		AddResponseHeader(ctx, "Set-Cookie", v)
+	}
+}
+
+// writeResponseHeader copies the headers set by resolvers during r to w.
+func writeResponseHeader(w http.ResponseWriter, r *http.Request) {
+	updateResponseHeader(r.Context(), func(header http.Header) {
+		for key, values := range header {
+			w.Header()[key] = append([]string(nil), values...)
+		}
+	})
+}
@@ -0,0 +1,43 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestSetResponseHeader(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Mutation": {"login": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			SetCookie(ctx, &http.Cookie{Name: "session", Value: "abc", HttpOnly: true})
+			SetCookie(ctx, &http.Cookie{Name: "bad name"})
+			AddResponseHeader(ctx, "Vary", "Cookie")
+			AddResponseHeader(ctx, "Vary", "Origin")
+			SetResponseHeader(ctx, "X-Login", "ok")
+			return true, nil
+		}},
+	})
+	useTestSchema(t, `type Query { me: String } type Mutation { login: Boolean }`)
+
+	rr := httptest.NewRecorder()
+	GraphqlHandler(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "mutation { login }"}`)))
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"data":{"login":true}}` {
+		t.Fatalf("unexpected response %s", body)
+	}
+	header := rr.Result().Header
+	if got := header.Values("Set-Cookie"); !reflect.DeepEqual(got, []string{"session=abc; HttpOnly"}) {
+		t.Errorf("unexpected cookies %q", got)
+	}
+	if got := header.Values("Vary"); !reflect.DeepEqual(got, []string{"Cookie", "Origin"}) {
+		t.Errorf("unexpected Vary headers %q", got)
+	}
+	if header.Get("X-Login") != "ok" || header.Get("Content-Type") != "application/json" {
+		t.Errorf("unexpected headers %v", header)
+	}
+
+	// Outside of a request the functions do nothing.
+	SetResponseHeader(context.Background(), "X-Login", "ok")
+}
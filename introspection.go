@@ -0,0 +1,383 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// The introspection model mirrors the __Schema, __Type, __Field, __InputValue,
+// __EnumValue and __Directive types of the specification. Values are resolved
+// reflectively through their json tags like any other resolver result.
+
+// introspectionSDL declares the introspection types so that introspection
+// queries can be validated like any other query.
+const introspectionSDL = `
+type __Schema {
+  description: String
+  types: [__Type!]!
+  queryType: __Type!
+  mutationType: __Type
+  subscriptionType: __Type
+  directives: [__Directive!]!
+}
+type __Type {
+  kind: __TypeKind!
+  name: String
+  description: String
+  specifiedByURL: String
+  fields(includeDeprecated: Boolean = false): [__Field!]
+  interfaces: [__Type!]
+  possibleTypes: [__Type!]
+  enumValues(includeDeprecated: Boolean = false): [__EnumValue!]
+  inputFields: [__InputValue!]
+  ofType: __Type
+}
+type __Field {
+  name: String!
+  description: String
+  args: [__InputValue!]!
+  type: __Type!
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+type __InputValue {
+  name: String!
+  description: String
+  type: __Type!
+  defaultValue: String
+}
+type __EnumValue {
+  name: String!
+  description: String
+  isDeprecated: Boolean!
+  deprecationReason: String
+}
+type __Directive {
+  name: String!
+  description: String
+  locations: [__DirectiveLocation!]!
+  args: [__InputValue!]!
+  isRepeatable: Boolean!
+}
+enum __TypeKind { SCALAR OBJECT INTERFACE UNION ENUM INPUT_OBJECT LIST NON_NULL }
+enum __DirectiveLocation {
+  QUERY MUTATION SUBSCRIPTION FIELD FRAGMENT_DEFINITION FRAGMENT_SPREAD INLINE_FRAGMENT VARIABLE_DEFINITION
+  SCHEMA SCALAR OBJECT FIELD_DEFINITION ARGUMENT_DEFINITION INTERFACE UNION ENUM ENUM_VALUE INPUT_OBJECT INPUT_FIELD_DEFINITION
+}
+`
+
+var introspectionTypes = NewParser(NewLexer(introspectionSDL)).ParseDocument().Definitions
+
+type introspectionSchema struct {
+	Types            []*introspectionType      `json:"types"`
+	QueryType        *introspectionType        `json:"queryType"`
+	MutationType     *introspectionType        `json:"mutationType"`
+	SubscriptionType *introspectionType        `json:"subscriptionType"`
+	Directives       []*introspectionDirective `json:"directives"`
+	Description      *string                   `json:"description"`
+
+	byName map[string]*introspectionType
+}
+
+type introspectionType struct {
+	Kind           string                     `json:"kind"`
+	Name           *string                    `json:"name"`
+	Description    *string                    `json:"description"`
+	Fields         []*introspectionField      `json:"fields"`
+	Interfaces     []*introspectionType       `json:"interfaces"`
+	PossibleTypes  []*introspectionType       `json:"possibleTypes"`
+	EnumValues     []*introspectionEnumValue  `json:"enumValues"`
+	InputFields    []*introspectionInputValue `json:"inputFields"`
+	OfType         *introspectionType         `json:"ofType"`
+	SpecifiedByURL *string                    `json:"specifiedByURL"`
+}
+
+type introspectionField struct {
+	Name              string                     `json:"name"`
+	Description       *string                    `json:"description"`
+	Args              []*introspectionInputValue `json:"args"`
+	Type              *introspectionType         `json:"type"`
+	IsDeprecated      bool                       `json:"isDeprecated"`
+	DeprecationReason *string                    `json:"deprecationReason"`
+}
+
+type introspectionInputValue struct {
+	Name         string             `json:"name"`
+	Description  *string            `json:"description"`
+	Type         *introspectionType `json:"type"`
+	DefaultValue *string            `json:"defaultValue"`
+}
+
+type introspectionEnumValue struct {
+	Name              string  `json:"name"`
+	Description       *string `json:"description"`
+	IsDeprecated      bool    `json:"isDeprecated"`
+	DeprecationReason *string `json:"deprecationReason"`
+}
+
+type introspectionDirective struct {
+	Name         string                     `json:"name"`
+	Description  *string                    `json:"description"`
+	Locations    []string                   `json:"locations"`
+	Args         []*introspectionInputValue `json:"args"`
+	IsRepeatable bool                       `json:"isRepeatable"`
+}
+
+func (*introspectionSchema) graphqlTypeName() string     { return "__Schema" }
+func (*introspectionType) graphqlTypeName() string       { return "__Type" }
+func (*introspectionField) graphqlTypeName() string      { return "__Field" }
+func (*introspectionInputValue) graphqlTypeName() string { return "__InputValue" }
+func (*introspectionEnumValue) graphqlTypeName() string  { return "__EnumValue" }
+func (*introspectionDirective) graphqlTypeName() string  { return "__Directive" }
+
+// builtinDirectives are the directives every schema supports.
+var builtinDirectives = []*introspectionDirective{
+	{
+		Name:      "skip",
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:      []*introspectionInputValue{{Name: "if", Type: nonNullRef("Boolean")}},
+	},
+	{
+		Name:      "include",
+		Locations: []string{"FIELD", "FRAGMENT_SPREAD", "INLINE_FRAGMENT"},
+		Args:      []*introspectionInputValue{{Name: "if", Type: nonNullRef("Boolean")}},
+	},
+	{
+		Name:      "deprecated",
+		Locations: []string{"FIELD_DEFINITION", "ARGUMENT_DEFINITION", "INPUT_FIELD_DEFINITION", "ENUM_VALUE"},
+		Args: []*introspectionInputValue{{
+			Name:         "reason",
+			Type:         &introspectionType{Kind: KindScalar, Name: stringPtr("String")},
+			DefaultValue: stringPtr(`"` + defaultDeprecationReason + `"`),
+		}},
+	},
+}
+
+func isBuiltinDirective(name string) bool {
+	for _, d := range builtinDirectives {
+		if d.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+func nonNullRef(name string) *introspectionType {
+	return &introspectionType{Kind: "NON_NULL", OfType: &introspectionType{Kind: KindScalar, Name: stringPtr(name)}}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+// descriptionPtr returns the description s, or nil if it is empty.
+func descriptionPtr(s string) *string {
+	if s == "" {
+		return nil
+	}
+	return &s
+}
+
+// buildIntrospection converts a Schema into the introspection model.
+func buildIntrospection(s *Schema) *introspectionSchema {
+	is := &introspectionSchema{byName: make(map[string]*introspectionType)}
+	// Create every named type first so references can share pointers. Types
+	// hidden by the schema's Visible func are left out, together with every
+	// field, argument and input field referring to them.
+	for _, name := range s.typeNames {
+		if !s.visible(name, "") {
+			continue
+		}
+		td := s.Types[name]
+		it := &introspectionType{Kind: td.kind(), Name: stringPtr(td.Name), Description: descriptionPtr(td.Description)}
+		is.byName[name] = it
+		is.Types = append(is.Types, it)
+	}
+	for _, name := range s.typeNames {
+		td := s.Types[name]
+		it, ok := is.byName[name]
+		if !ok {
+			continue
+		}
+		switch td.kind() {
+		case KindObject, KindInterface:
+			it.Fields = []*introspectionField{}
+			for _, f := range td.Fields {
+				if !s.visible(name, f.Name) || is.byName[namedType(f.Type)] == nil {
+					continue
+				}
+				field := &introspectionField{
+					Name:        f.Name,
+					Description: descriptionPtr(f.Description),
+					Args:        is.inputValues(f.ArgumentDefinitions),
+					Type:        is.typeRef(f.Type),
+				}
+				if reason, ok := f.Deprecation(); ok {
+					field.IsDeprecated = true
+					field.DeprecationReason = stringPtr(reason)
+				}
+				it.Fields = append(it.Fields, field)
+			}
+			it.Interfaces = []*introspectionType{}
+			for _, iface := range td.Interfaces {
+				if ref, ok := is.byName[iface]; ok {
+					it.Interfaces = append(it.Interfaces, ref)
+				}
+			}
+		case KindUnion:
+			for _, member := range td.Types {
+				if ref, ok := is.byName[member]; ok {
+					it.PossibleTypes = append(it.PossibleTypes, ref)
+				}
+			}
+		case KindEnum:
+			it.EnumValues = []*introspectionEnumValue{}
+			for _, v := range td.EnumValues {
+				if !s.visible(name, v.Name) {
+					continue
+				}
+				value := &introspectionEnumValue{Name: v.Name, Description: descriptionPtr(v.Description)}
+				if reason, ok := v.Deprecation(); ok {
+					value.IsDeprecated = true
+					value.DeprecationReason = stringPtr(reason)
+				}
+				it.EnumValues = append(it.EnumValues, value)
+			}
+		case KindInputObject:
+			var fields []*InputValueDefinition
+			for _, f := range td.InputFields {
+				if s.visible(name, f.Name) {
+					fields = append(fields, f)
+				}
+			}
+			it.InputFields = is.inputValues(fields)
+		}
+	}
+	// Interfaces list the object types implementing them.
+	for _, name := range s.typeNames {
+		td := s.Types[name]
+		impl, ok := is.byName[name]
+		if !ok {
+			continue
+		}
+		for _, iface := range td.Interfaces {
+			if target, ok := is.byName[iface]; ok {
+				target.PossibleTypes = append(target.PossibleTypes, impl)
+			}
+		}
+	}
+	is.QueryType = is.byName[s.QueryType]
+	is.MutationType = is.byName[s.MutationType]
+	is.SubscriptionType = is.byName[s.SubscriptionType]
+	is.Directives = append([]*introspectionDirective{}, builtinDirectives...)
+	for _, dd := range s.directiveDefinitions() {
+		is.Directives = append(is.Directives, &introspectionDirective{
+			Name:         dd.Name,
+			Description:  descriptionPtr(dd.Description),
+			Locations:    dd.Locations,
+			Args:         is.inputValues(dd.Arguments),
+			IsRepeatable: dd.Repeatable,
+		})
+	}
+	return is
+}
+
+func (is *introspectionSchema) inputValues(defs []*InputValueDefinition) []*introspectionInputValue {
+	values := []*introspectionInputValue{}
+	for _, def := range defs {
+		if is.byName[namedType(def.Type)] == nil {
+			continue
+		}
+		value := &introspectionInputValue{Name: def.Name, Description: descriptionPtr(def.Description), Type: is.typeRef(def.Type)}
+		if def.DefaultValue != nil {
+			var sb strings.Builder
+			writeValue(&sb, def.DefaultValue)
+			value.DefaultValue = stringPtr(sb.String())
+		}
+		values = append(values, value)
+	}
+	return values
+}
+
+// typeRef converts a type reference into NON_NULL/LIST wrappers around a named type.
+func (is *introspectionSchema) typeRef(t *Type) *introspectionType {
+	if t == nil {
+		return nil
+	}
+	var ref *introspectionType
+	if t.IsList {
+		ref = &introspectionType{Kind: "LIST", OfType: is.typeRef(t.Elem)}
+	} else {
+		ref = is.byName[t.Name]
+	}
+	if t.NonNull {
+		ref = &introspectionType{Kind: "NON_NULL", OfType: ref}
+	}
+	return ref
+}
+
+// typeNamer is implemented by values that know their GraphQL type name.
+type typeNamer interface {
+	graphqlTypeName() string
+}
+
+// introspectionResolvers resolve the introspection meta-fields.
+var introspectionResolvers = map[string]map[string]ContextResolverFunc{
+	"Query": {
+		"__schema": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := CurrentSchema()
+			if s == nil {
+				return nil, fmt.Errorf("introspection requires a schema; call UseSchema first")
+			}
+			return s.introspection(), nil
+		},
+		"__type": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			s := CurrentSchema()
+			if s == nil {
+				return nil, fmt.Errorf("introspection requires a schema; call UseSchema first")
+			}
+			name, _ := args["name"].(string)
+			if t, ok := s.introspection().byName[name]; ok {
+				return t, nil
+			}
+			return nil, nil
+		},
+	},
+	"__Type": {
+		"fields": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*introspectionType)
+			if t.Fields == nil || args["includeDeprecated"] == true {
+				return t.Fields, nil
+			}
+			fields := []*introspectionField{}
+			for _, f := range t.Fields {
+				if !f.IsDeprecated {
+					fields = append(fields, f)
+				}
+			}
+			return fields, nil
+		},
+		"enumValues": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			t := source.(*introspectionType)
+			if t.EnumValues == nil || args["includeDeprecated"] == true {
+				return t.EnumValues, nil
+			}
+			values := []*introspectionEnumValue{}
+			for _, v := range t.EnumValues {
+				if !v.IsDeprecated {
+					values = append(values, v)
+				}
+			}
+			return values, nil
+		},
+	},
+}
+
+// introspection returns the schema's introspection model, building it once.
+func (s *Schema) introspection() *introspectionSchema {
+	s.introspectionOnce.Do(func() {
+		s.introspectionModel = buildIntrospection(s)
+	})
+	return s.introspectionModel
+}
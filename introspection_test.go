@@ -0,0 +1,153 @@
+package vibeGraphql
+
+import (
+	"context"
+	"testing"
+)
+
+func useTestSchema(t testing.TB, sdl string) *Schema {
+	t.Helper()
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	UseSchema(s)
+	t.Cleanup(func() { UseSchema(nil) })
+	return s
+}
+
+func executeQuery(t *testing.T, query string, variables map[string]interface{}) map[string]interface{} {
+	t.Helper()
+	doc := NewParser(NewLexer(query)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, variables)
+	if err != nil {
+		t.Fatalf("executeDocument error: %v", err)
+	}
+	return resp["data"].(map[string]interface{})
+}
+
+func TestIntrospection_Deprecated(t *testing.T) {
+	useTestSchema(t, `
+		type Query {
+			current: String
+			legacy: String @deprecated(reason: "use current")
+		}
+		enum Color { RED BLUE @deprecated }
+	`)
+
+	data := executeQuery(t, `{ __type(name: "Query") { name kind fields { name } } }`, nil)
+	typ := data["__type"].(map[string]interface{})
+	if typ["name"] != "Query" || typ["kind"] != KindObject {
+		t.Errorf("unexpected type: %v", typ)
+	}
+	if fields := typ["fields"].([]interface{}); len(fields) != 1 {
+		t.Errorf("expected deprecated fields to be hidden by default, got %v", fields)
+	}
+
+	data = executeQuery(t, `{ __type(name: "Query") { fields(includeDeprecated: true) { name isDeprecated deprecationReason } } }`, nil)
+	fields := data["__type"].(map[string]interface{})["fields"].([]interface{})
+	if len(fields) != 2 {
+		t.Fatalf("expected 2 fields, got %v", fields)
+	}
+	legacy := fields[1].(map[string]interface{})
+	if legacy["isDeprecated"] != true || legacy["deprecationReason"] != "use current" {
+		t.Errorf("unexpected deprecation info: %v", legacy)
+	}
+
+	data = executeQuery(t, `{ __type(name: "Color") { enumValues(includeDeprecated: true) { name isDeprecated } } }`, nil)
+	values := data["__type"].(map[string]interface{})["enumValues"].([]interface{})
+	if len(values) != 2 || values[1].(map[string]interface{})["isDeprecated"] != true {
+		t.Errorf("unexpected enum values: %v", values)
+	}
+}
+
+func TestIntrospection_Descriptions(t *testing.T) {
+	useTestSchema(t, `
+		"""
+		A registered user.
+		"""
+		type User { "The user's name." name: String }
+		type Query { user("The user's ID." id: ID!): User }
+		enum Role { "Can do anything." ADMIN USER }
+		input Filter { "Roles to match." role: Role }
+		"Requires a role." directive @auth on FIELD_DEFINITION
+	`)
+
+	data := executeQuery(t, `{
+		user: __type(name: "User") { description fields { description } }
+		query: __type(name: "Query") { description fields { args { description } } }
+		role: __type(name: "Role") { enumValues { description } }
+		filter: __type(name: "Filter") { inputFields { description } }
+		__schema { directives { name description } }
+	}`, nil)
+	get := func(v interface{}, keys ...interface{}) interface{} {
+		for _, key := range keys {
+			switch key := key.(type) {
+			case string:
+				v = v.(map[string]interface{})[key]
+			case int:
+				v = v.([]interface{})[key]
+			}
+		}
+		return v
+	}
+	for _, tt := range []struct {
+		got  interface{}
+		want interface{}
+	}{
+		{get(data, "user", "description"), "A registered user."},
+		{get(data, "user", "fields", 0, "description"), "The user's name."},
+		{get(data, "query", "description"), nil},
+		{get(data, "query", "fields", 0, "args", 0, "description"), "The user's ID."},
+		{get(data, "role", "enumValues", 0, "description"), "Can do anything."},
+		{get(data, "role", "enumValues", 1, "description"), nil},
+		{get(data, "filter", "inputFields", 0, "description"), "Roles to match."},
+		{get(data, "__schema", "directives", 3, "description"), "Requires a role."},
+	} {
+		if tt.got != tt.want {
+			t.Errorf("got description %v, want %v in %v", tt.got, tt.want, data)
+		}
+	}
+}
+
+func TestIntrospection_Schema(t *testing.T) {
+	useTestSchema(t, `type Query { hello(name: String = "world"): String! }`)
+
+	data := executeQuery(t, `{ __typename __schema { queryType { name } mutationType { name } types { name } } }`, nil)
+	if data["__typename"] != "Query" {
+		t.Errorf("expected __typename Query, got %v", data["__typename"])
+	}
+	schema := data["__schema"].(map[string]interface{})
+	if schema["queryType"].(map[string]interface{})["name"] != "Query" {
+		t.Errorf("unexpected queryType: %v", schema["queryType"])
+	}
+	if schema["mutationType"] != nil {
+		t.Errorf("expected null mutationType, got %v", schema["mutationType"])
+	}
+	if types := schema["types"].([]interface{}); len(types) != len(builtinScalars)+len(introspectionTypes)+1 {
+		t.Errorf("expected built-in scalars, introspection types and Query, got %d types", len(types))
+	}
+
+	data = executeQuery(t, `{ __type(name: "Query") { fields { args { name defaultValue } type { kind ofType { name } } } } }`, nil)
+	field := data["__type"].(map[string]interface{})["fields"].([]interface{})[0].(map[string]interface{})
+	if kind := field["type"].(map[string]interface{})["kind"]; kind != "NON_NULL" {
+		t.Errorf("expected NON_NULL type, got %v", kind)
+	}
+	arg := field["args"].([]interface{})[0].(map[string]interface{})
+	if arg["defaultValue"] != `"world"` {
+		t.Errorf("unexpected default value: %v", arg["defaultValue"])
+	}
+}
+
+func TestIntrospection_NoSchema(t *testing.T) {
+	UseSchema(nil)
+	doc := NewParser(NewLexer(`{ __schema { types { name } } }`)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := resp["errors"].([]*Error)
+	if data, ok := resp["data"]; !ok || data != nil || len(errs) != 1 {
+		t.Errorf("expected null data and an error when introspecting without a schema, got %v", resp)
+	}
+}
@@ -1,119 +1,630 @@
 package vibeGraphql
 
-import "unicode"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf16"
+	"unicode/utf8"
+)
 
+// Lexer splits a GraphQL document into tokens, skipping whitespace and
+// comments. Together with Parser it forms a front end that tools such as
+// formatters and linters can build on: tokens carry their byte offsets, and
+// Locate turns offsets into lines and columns.
 type Lexer struct {
-	input        string
-	position     int  // current position in input (points to current char)
-	readPosition int  // next reading position (after current char)
-	ch           byte // current char under examination
+	input    string
+	position int  // current position in input (points to current char)
+	ch       byte // current char under examination
+	start    int  // position of the token being read
+
+	peeked  Token // the token PeekToken read ahead, if peeking
+	peeking bool
+
+	// A lexer reading from reader holds a window of the document in input,
+	// starting at offset. Input before keep, the start of the token read
+	// last, is dropped when more is read.
+	reader io.Reader
+	buf    []byte
+	offset int
+	keep   int
+	err    error
+
+	// line, lineStart, column and scanned track the line and column of the
+	// last located offset.
+	line      int
+	lineStart int
+	column    int
+	scanned   int
 }
 
+// NewLexer returns a lexer tokenizing input.
 func NewLexer(input string) *Lexer {
-	l := &Lexer{input: input}
+	l := &Lexer{}
+	l.reset(input)
+	return l
+}
+
+// readerChunkSize is the number of bytes a lexer reads from its reader at a
+// time.
+const readerChunkSize = 4096
+
+// NewLexerFromReader returns a lexer reading the document from r as it is
+// tokenized, so that large documents need not be read into memory first.
+// Only the current token and the input after it are held. Token offsets
+// are relative to the start of the document, as with NewLexer.
+func NewLexerFromReader(r io.Reader) *Lexer {
+	l := &Lexer{reader: r, buf: make([]byte, readerChunkSize), position: -1}
 	l.readChar()
 	return l
 }
 
+// reset makes l read input from the start.
+func (l *Lexer) reset(input string) {
+	*l = Lexer{input: input, position: -1}
+	l.readChar()
+}
+
+// Err returns the error reading from the lexer's reader, if any. The
+// document then ends where reading failed.
+func (l *Lexer) Err() error {
+	return l.err
+}
+
 func (l *Lexer) readChar() {
-	if l.readPosition >= len(l.input) {
-		l.ch = 0 // ASCII 0 signifies end-of-input
+	l.position++
+	if l.position < len(l.input) {
+		l.ch = l.input[l.position]
+		return
+	}
+	l.readLastChar()
+}
+
+// readLastChar is readChar past the end of the input read so far. It is
+// kept apart so that readChar stays cheap enough to be inlined.
+func (l *Lexer) readLastChar() {
+	for l.reader != nil && l.position >= len(l.input) {
+		l.readChunk()
+	}
+	if l.position < len(l.input) {
+		l.ch = l.input[l.position]
 	} else {
-		l.ch = l.input[l.readPosition]
+		l.ch = 0 // ASCII 0 signifies end-of-input
 	}
-	l.position = l.readPosition
-	l.readPosition++
 }
 
-func (l *Lexer) NextToken() Token {
-	var tok Token
+// ensure reads from the reader, if any, until n bytes of input are
+// available from the current position, or the input ends.
+func (l *Lexer) ensure(n int) {
+	for l.reader != nil && len(l.input)-l.position < n {
+		l.readChunk()
+	}
+}
+
+// readChunk reads the next chunk of input, dropping the input before keep.
+func (l *Lexer) readChunk() {
+	n, err := l.reader.Read(l.buf)
+	if n == 0 && err == nil {
+		return
+	}
+	chunk := string(l.buf[:n])
+	if err != nil {
+		if !errors.Is(err, io.EOF) {
+			l.err = err
+		}
+		l.reader, l.buf = nil, nil
+	}
+	// Lines are counted before the input they are on is dropped.
+	l.locate(l.offset + l.keep)
+	drop := l.keep
+	l.input = l.input[drop:] + chunk
+	l.offset += drop
+	l.position -= drop
+	l.start -= drop
+	l.keep = 0
+}
+
+// hasPrefix reports whether the input at the current position starts with
+// prefix.
+func (l *Lexer) hasPrefix(prefix string) bool {
+	l.ensure(len(prefix))
+	return strings.HasPrefix(l.input[l.position:], prefix)
+}
+
+// Locate returns the line and column of offset, the byte offset of a token
+// in the input, such as its Start. The column counts characters. Offsets
+// must be located in increasing order, as the input before them may have
+// been dropped when reading from a reader; offsets of earlier tokens return
+// the line of the last offset located. A lexer used by a Parser must not be
+// used to locate tokens, as the parser locates its errors with it.
+func (l *Lexer) Locate(offset int) Location {
+	return l.locate(offset)
+}
+
+// locate returns the location of offset in the input, with the column
+// counted in characters. Offsets must be located in increasing order, so that
+// each byte of the input is scanned once.
+func (l *Lexer) locate(offset int) Location {
+	for ; l.scanned < offset && l.scanned-l.offset < len(l.input); l.scanned++ {
+		switch ch := l.input[l.scanned-l.offset]; {
+		case ch == '\n':
+			l.line++
+			l.lineStart = l.scanned + 1
+			l.column = 0
+		case utf8.RuneStart(ch):
+			l.column++
+		}
+	}
+	if l.scanned != offset {
+		return Location{Line: l.line + 1, Column: offset - l.lineStart + 1}
+	}
+	return Location{Line: l.line + 1, Column: l.column + 1}
+}
 
+// symbols maps the characters of single-character tokens to their type. The
+// type doubles as the token's literal, so lexing a symbol allocates nothing.
+var symbols = [256]TokenType{
+	'=': ASSIGN,
+	':': COLON,
+	',': COMMA,
+	';': SEMICOLON,
+	'(': LPAREN,
+	')': RPAREN,
+	'{': LBRACE,
+	'}': RBRACE,
+	'[': LBRACKET,
+	']': RBRACKET,
+	'$': DOLLAR,
+	'!': BANG,
+	'@': AT,
+	'|': PIPE,
+	'&': AMP,
+}
+
+// NextToken returns the next token of the input. Literals of names and
+// numbers, and of strings without escape sequences, are slices of the input
+// rather than copies.
+func (l *Lexer) NextToken() Token {
+	if l.peeking {
+		l.peeking = false
+		return l.peeked
+	}
+	l.keep = l.start
 	l.skipWhitespace()
-	switch l.ch {
-	case '=':
-		tok = Token{Type: ASSIGN, Literal: string(l.ch)}
-	case ':':
-		tok = Token{Type: COLON, Literal: string(l.ch)}
-	case ',':
-		tok = Token{Type: COMMA, Literal: string(l.ch)}
-	case ';':
-		tok = Token{Type: SEMICOLON, Literal: string(l.ch)}
-	case '(':
-		tok = Token{Type: LPAREN, Literal: string(l.ch)}
-	case ')':
-		tok = Token{Type: RPAREN, Literal: string(l.ch)}
-	case '{':
-		tok = Token{Type: LBRACE, Literal: string(l.ch)}
-	case '}':
-		tok = Token{Type: RBRACE, Literal: string(l.ch)}
-	case '[':
-		tok = Token{Type: LBRACKET, Literal: string(l.ch)}
-	case ']':
-		tok = Token{Type: RBRACKET, Literal: string(l.ch)}
-	case '"':
-		tok.Type = STRING
-		tok.Literal = l.readString()
-		return tok
-	case '$':
-		tok = Token{Type: DOLLAR, Literal: string(l.ch)}
-	case '!':
-		tok = Token{Type: BANG, Literal: string(l.ch)}
-	case 0:
+	l.start = l.position
+	if typ := symbols[l.ch]; typ != "" {
+		start := l.offset + l.start
+		l.readChar()
+		return Token{Type: typ, Literal: string(typ), Start: start, End: start + 1}
+	}
+	var tok Token
+	switch {
+	case l.ch == 0 && l.atEnd():
 		tok = Token{Type: EOF, Literal: ""}
+		l.start = len(l.input)
+	case l.ch == '.' && l.hasPrefix("..."):
+		l.readChar()
+		l.readChar()
+		l.readChar()
+		tok = Token{Type: SPREAD, Literal: string(SPREAD)}
+	case l.ch == '"' && l.hasPrefix(`"""`):
+		tok.Type = STRING
+		tok.Literal, tok.Message = l.readBlockString()
+	case l.ch == '"':
+		tok.Type = STRING
+		tok.Literal, tok.Message = l.readString()
+	case isLetter(l.ch) || l.ch >= utf8.RuneSelf && unicode.IsLetter(l.peekRune()):
+		tok = Token{Type: IDENT, Literal: l.readIdentifier()}
+	case isDigit(l.ch) || (l.ch == '-' && isDigit(l.peekChar())):
+		tok.Literal, tok.Type, tok.Message = l.readNumber()
 	default:
-		if isLetter(l.ch) {
-			tok.Literal = l.readIdentifier()
-			tok.Type = IDENT
-			return tok
-		} else if isDigit(l.ch) {
-			tok.Literal = l.readNumber()
-			tok.Type = INT
-			return tok
-		} else {
-			tok = Token{Type: ILLEGAL, Literal: string(l.ch)}
-		}
+		message := l.invalidCharacter()
+		l.readRune()
+		tok = Token{Type: ILLEGAL, Literal: l.input[l.start:l.position], Message: message}
+	}
+	if tok.Message != "" {
+		tok.Type, tok.Literal = ILLEGAL, l.input[l.start:l.position]
+	}
+	tok.Start, tok.End = l.offset+l.start, l.offset+l.position
+	if tok.Type == EOF {
+		tok.End = tok.Start
 	}
-	l.readChar()
 	return tok
 }
 
+// PeekToken returns the token NextToken will return next, without consuming
+// it.
+func (l *Lexer) PeekToken() Token {
+	if !l.peeking {
+		l.peeked = l.NextToken()
+		l.peeking = true
+	}
+	return l.peeked
+}
+
+// byteOrderMark is ignored wherever it appears, most often at the start of
+// documents saved by Windows editors.
+const byteOrderMark = "\uFEFF"
+
+// skipWhitespace skips insignificant whitespace, byte order marks and "#"
+// comments.
 func (l *Lexer) skipWhitespace() {
-	for l.ch == ' ' || l.ch == '\t' || l.ch == '\n' || l.ch == '\r' {
-		l.readChar()
+	for {
+		switch l.ch {
+		case ' ', '\t', '\n', '\r':
+			l.readChar()
+		case byteOrderMark[0]:
+			if !l.hasPrefix(byteOrderMark) {
+				return
+			}
+			l.position += len(byteOrderMark) - 1
+			l.readChar()
+		case '#':
+			// Comments end at the end of their line, or at a character that
+			// may not appear in documents, which is then reported.
+			for l.ch != '\n' && l.ch != '\r' && !l.atEnd() && l.invalidCharacter() == "" {
+				l.readRune()
+			}
+		default:
+			return
+		}
 	}
 }
 
+// readIdentifier reads a name, which may contain letters of any script.
 func (l *Lexer) readIdentifier() string {
-	start := l.position
-	for isLetter(l.ch) || isDigit(l.ch) {
+	for {
+		switch {
+		case isLetter(l.ch) || isDigit(l.ch):
+			l.readChar()
+		case l.ch >= utf8.RuneSelf && unicode.IsLetter(l.peekRune()):
+			l.readRune()
+		default:
+			return l.input[l.start:l.position]
+		}
+	}
+}
+
+// peekRune returns the character at the current position, decoding it if it
+// is not ASCII. Invalid UTF-8 decodes to utf8.RuneError.
+func (l *Lexer) peekRune() rune {
+	if l.ch < utf8.RuneSelf {
+		return rune(l.ch)
+	}
+	l.ensure(utf8.UTFMax)
+	r, _ := utf8.DecodeRuneInString(l.input[l.position:])
+	return r
+}
+
+// readRune moves past the character at the current position, which may
+// take several bytes, or past an invalid byte.
+func (l *Lexer) readRune() {
+	if l.ch >= utf8.RuneSelf {
+		l.ensure(utf8.UTFMax)
+		_, size := utf8.DecodeRuneInString(l.input[l.position:])
+		l.position += size - 1
+	}
+	l.readChar()
+}
+
+// atEnd reports whether the whole input has been read, telling the end of
+// the input from a NUL character.
+func (l *Lexer) atEnd() bool {
+	return l.position >= len(l.input)
+}
+
+// invalidCharacter returns a message if the character at the current
+// position is a control character other than whitespace, or is not valid
+// UTF-8, as such characters may not appear in documents. Otherwise, or at
+// the end of the input, it returns "".
+func (l *Lexer) invalidCharacter() string {
+	switch {
+	case l.ch >= utf8.RuneSelf:
+		if l.peekRune() == utf8.RuneError {
+			if _, size := utf8.DecodeRuneInString(l.input[l.position:]); size == 1 {
+				return fmt.Sprintf("Invalid UTF-8 byte 0x%02X", l.ch)
+			}
+		}
+	case l.ch < ' ' && l.ch != '\t' && l.ch != '\n' && l.ch != '\r' && !(l.ch == 0 && l.atEnd()):
+		return fmt.Sprintf("Invalid character U+%04X", l.ch)
+	}
+	return ""
+}
+
+func (l *Lexer) peekChar() byte {
+	l.ensure(2)
+	if l.position+1 >= len(l.input) {
+		return 0
+	}
+	return l.input[l.position+1]
+}
+
+// readNumber reads an integer such as -12 or a float such as 1.5e-3. It
+// returns a message instead if the number has a leading zero, such as 01,
+// or an exponent without digits, such as 1e.
+func (l *Lexer) readNumber() (string, TokenType, string) {
+	typ := INT
+	var message string
+	if l.ch == '-' {
+		l.readChar()
+	}
+	if l.ch == '0' && isDigit(l.peekChar()) {
+		message = "Invalid number, unexpected digit after 0"
+	}
+	l.readDigits()
+	if l.ch == '.' && isDigit(l.peekChar()) {
+		typ = FLOAT
+		l.readChar()
+		l.readDigits()
+	}
+	if l.ch == 'e' || l.ch == 'E' {
+		typ = FLOAT
 		l.readChar()
+		if l.ch == '+' || l.ch == '-' {
+			l.readChar()
+		}
+		if !isDigit(l.ch) && message == "" {
+			message = "Invalid number, expected a digit in the exponent"
+		}
+		l.readDigits()
+	}
+	if message != "" {
+		message += fmt.Sprintf(": %q", l.input[l.start:l.position])
 	}
-	return l.input[start:l.position]
+	return l.input[l.start:l.position], typ, message
 }
 
-func (l *Lexer) readNumber() string {
-	start := l.position
+func (l *Lexer) readDigits() {
 	for isDigit(l.ch) {
 		l.readChar()
 	}
-	return l.input[start:l.position]
 }
 
-func (l *Lexer) readString() string {
+// unterminatedString is the message of strings missing their closing quotes.
+const unterminatedString = "Unterminated string"
+
+// readString reads a string such as "a\nb" and returns its value, or a
+// message if it is unterminated or contains an invalid escape sequence or
+// character. Strings end at the end of their line.
+func (l *Lexer) readString() (string, string) {
 	// skip opening quote
 	l.readChar()
-	start := l.position
-	for l.ch != '"' && l.ch != 0 {
+	// Until the first escape sequence, the value is a slice of the input
+	// rather than a copy.
+	var sb strings.Builder
+	escaped := false
+	for {
+		switch ch := l.ch; {
+		case ch == '"':
+			literal := l.input[l.start+1 : l.position]
+			if escaped {
+				literal = sb.String()
+			}
+			l.readChar() // skip closing quote
+			return literal, ""
+		case ch == '\\':
+			if !escaped {
+				sb.WriteString(l.input[l.start+1 : l.position])
+				escaped = true
+			}
+			// The escape is located relative to the start of the token, as
+			// reading from a reader may move the input.
+			escape := l.position - l.start
+			l.readChar()
+			if l.ch == 0 && l.atEnd() {
+				return "", unterminatedString
+			}
+			decoded, ok := l.readEscape()
+			if !ok {
+				// Report the digits of an invalid Unicode escape along with it.
+				for n := 0; n < 8 && l.input[l.start+escape+1] == 'u' && (isHexDigit(l.ch) || l.ch == '{' || l.ch == '}'); n++ {
+					l.readChar()
+				}
+				message := `Invalid escape sequence "` + l.input[l.start+escape:l.position] + `"`
+				l.skipString()
+				return "", message
+			}
+			sb.WriteString(decoded)
+		case ch == '\n' || ch == '\r' || ch == 0 && l.atEnd():
+			return "", unterminatedString
+		default:
+			if message := l.invalidCharacter(); message != "" {
+				l.skipString()
+				return "", message + " in string"
+			}
+			from := l.position - l.start
+			l.readRune()
+			if escaped {
+				sb.WriteString(l.input[l.start+from : l.position])
+			}
+		}
+	}
+}
+
+// skipString moves past the rest of a string with an invalid escape
+// sequence, so that the whole string makes one token.
+func (l *Lexer) skipString() {
+	for l.ch != '"' && !l.endsString() {
+		if l.ch == '\\' {
+			l.readChar()
+			if l.endsString() {
+				return
+			}
+		}
+		l.readChar()
+	}
+	if l.ch == '"' {
+		l.readChar()
+	}
+}
+
+// endsString reports whether the current position, at the end of the input
+// or of a line, ends a string before its closing quote.
+func (l *Lexer) endsString() bool {
+	return l.ch == '\n' || l.ch == '\r' || l.ch == 0 && l.atEnd()
+}
+
+// readBlockString reads a block string such as """A user.""", in which
+// only \""" is escaped, and returns its value with the common indentation
+// and the leading and trailing blank lines removed, or a message if it is
+// unterminated.
+func (l *Lexer) readBlockString() (string, string) {
+	l.position += 2
+	l.readChar() // skip the opening quotes
+	var sb strings.Builder
+	for {
+		if l.ch == 0 && l.atEnd() {
+			return "", unterminatedString
+		}
+		if message := l.invalidCharacter(); message != "" {
+			return "", message + " in string"
+		}
+		if l.hasPrefix(`"""`) {
+			l.position += 2
+			l.readChar() // skip the closing quotes
+			break
+		}
+		if l.hasPrefix(`\"""`) {
+			sb.WriteString(`"""`)
+			l.position += 3
+			l.readChar()
+			continue
+		}
+		from := l.position - l.start
+		l.readRune()
+		sb.WriteString(l.input[l.start+from : l.position])
+	}
+	return blockStringValue(sb.String()), ""
+}
+
+// blockStringValue removes the common indentation of the lines of raw after
+// the first, and its leading and trailing blank lines.
+func blockStringValue(raw string) string {
+	lines := strings.Split(strings.NewReplacer("\r\n", "\n", "\r", "\n").Replace(raw), "\n")
+	indent := -1
+	for _, line := range lines[1:] {
+		trimmed := strings.TrimLeft(line, " \t")
+		if trimmed == "" {
+			continue
+		}
+		if n := len(line) - len(trimmed); indent < 0 || n < indent {
+			indent = n
+		}
+	}
+	if indent > 0 {
+		for i := 1; i < len(lines); i++ {
+			if len(lines[i]) >= indent {
+				lines[i] = lines[i][indent:]
+			} else {
+				lines[i] = strings.TrimLeft(lines[i], " \t")
+			}
+		}
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[0], " \t") == "" {
+		lines = lines[1:]
+	}
+	for len(lines) > 0 && strings.TrimLeft(lines[len(lines)-1], " \t") == "" {
+		lines = lines[:len(lines)-1]
+	}
+	return strings.Join(lines, "\n")
+}
+
+// readEscape decodes the escape sequence following a backslash, reporting
+// whether it is valid.
+func (l *Lexer) readEscape() (string, bool) {
+	ch := l.ch
+	l.readChar()
+	switch ch {
+	case 'n':
+		return "\n", true
+	case 't':
+		return "\t", true
+	case 'r':
+		return "\r", true
+	case 'b':
+		return "\b", true
+	case 'f':
+		return "\f", true
+	case '"', '\\', '/':
+		return string(ch), true
+	case 'u':
+		if r, ok := l.readUnicodeEscape(); ok {
+			return string(r), true
+		}
+	}
+	return "", false
+}
+
+// readUnicodeEscape decodes the escape sequence following \u: four hex
+// digits such as 00e9, a pair of them encoding a character outside the Basic
+// Multilingual Plane as UTF-16, such as D83D\uDE00, or up to six hex digits
+// in braces, such as {1F600}. Unpaired surrogates decode to U+FFFD.
+func (l *Lexer) readUnicodeEscape() (rune, bool) {
+	if l.ch == '{' {
+		l.ensure(9)
+		end := strings.IndexByte(l.input[l.position:min(l.position+9, len(l.input))], '}')
+		if end < 2 {
+			return 0, false
+		}
+		code, err := strconv.ParseUint(l.input[l.position+1:l.position+end], 16, 32)
+		if err != nil || code > unicode.MaxRune {
+			return 0, false
+		}
+		l.position += end
 		l.readChar()
+		return utf8ValidRune(rune(code)), true
 	}
-	str := l.input[start:l.position]
-	// skip closing quote
+	code, ok := l.hexDigits(0)
+	if !ok {
+		return 0, false
+	}
+	l.position += 3
 	l.readChar()
-	return str
+	if !utf16.IsSurrogate(code) {
+		return code, true
+	}
+	if l.ch == '\\' && l.hasPrefix(`\u`) {
+		if low, ok := l.hexDigits(2); ok {
+			if r := utf16.DecodeRune(code, low); r != utf8.RuneError {
+				l.position += 5
+				l.readChar()
+				return r, true
+			}
+		}
+	}
+	return utf8.RuneError, true
+}
+
+// hexDigits parses the four hex digits at offset from the current position.
+func (l *Lexer) hexDigits(offset int) (rune, bool) {
+	l.ensure(offset + 4)
+	start := l.position + offset
+	if start+4 > len(l.input) {
+		return 0, false
+	}
+	code, err := strconv.ParseUint(l.input[start:start+4], 16, 32)
+	return rune(code), err == nil
+}
+
+// utf8ValidRune returns r, or U+FFFD if r cannot be encoded in UTF-8.
+func utf8ValidRune(r rune) rune {
+	if !utf8.ValidRune(r) {
+		return utf8.RuneError
+	}
+	return r
+}
+
+func isHexDigit(ch byte) bool {
+	return isDigit(ch) || 'a' <= ch && ch <= 'f' || 'A' <= ch && ch <= 'F'
 }
 
+// isLetter reports whether ch is an ASCII letter or an underscore. Letters
+// of other scripts are decoded and checked with unicode.IsLetter.
 func isLetter(ch byte) bool {
-	return unicode.IsLetter(rune(ch)) || ch == '_'
+	return 'a' <= ch && ch <= 'z' || 'A' <= ch && ch <= 'Z' || ch == '_'
 }
 
 func isDigit(ch byte) bool {
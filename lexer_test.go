@@ -1,7 +1,13 @@
 package vibeGraphql
 
 import (
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"strings"
 	"testing"
+	"testing/iotest"
 )
 
 func TestLexer_Numbers(t *testing.T) {
@@ -124,15 +130,15 @@ func TestLexer_IdentifierWithUnderscore(t *testing.T) {
 }
 
 func TestLexer_IllegalCharacter(t *testing.T) {
-	input := "@"
+	input := "%"
 	lexer := NewLexer(input)
 
 	tok := lexer.NextToken()
 	if tok.Type != ILLEGAL {
 		t.Fatalf("expected token type ILLEGAL, got %s", tok.Type)
 	}
-	if tok.Literal != "@" {
-		t.Errorf("expected literal '@', got %q", tok.Literal)
+	if tok.Literal != "%" {
+		t.Errorf("expected literal '%%', got %q", tok.Literal)
 	}
 
 	tok = lexer.NextToken()
@@ -170,3 +176,320 @@ func TestNextToken(t *testing.T) {
 		}
 	}
 }
+
+func TestLexer_StringEscapes(t *testing.T) {
+	lexer := NewLexer(`"a\"b\\c\/d\neéf"`)
+	tok := lexer.NextToken()
+	if tok.Type != STRING || tok.Literal != "a\"b\\c/d\neéf" {
+		t.Errorf("unexpected token %s %q", tok.Type, tok.Literal)
+	}
+	if tok := lexer.NextToken(); tok.Type != EOF {
+		t.Errorf("expected EOF after string, got %s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestLexer_InvalidStrings(t *testing.T) {
+	tests := []struct {
+		input   string
+		literal string
+		message string
+	}{
+		{`"abc`, `"abc`, "Unterminated string"},
+		{`"a\"`, `"a\"`, "Unterminated string"},
+		{`"a\`, `"a\`, "Unterminated string"},
+		{"\"a\nb\"", `"a`, "Unterminated string"},
+		{`"""abc`, `"""abc`, "Unterminated string"},
+		{`"a\qb" x`, `"a\qb"`, `Invalid escape sequence "\q"`},
+		{`"\u12"`, `"\u12"`, `Invalid escape sequence "\u12"`},
+		{`"\u{}"`, `"\u{}"`, `Invalid escape sequence "\u{}"`},
+		{`"\uXYZW"`, `"\uXYZW"`, `Invalid escape sequence "\u"`},
+	}
+	for _, tt := range tests {
+		for _, l := range []*Lexer{NewLexer(tt.input), NewLexerFromReader(iotest.OneByteReader(strings.NewReader(tt.input)))} {
+			tok := l.NextToken()
+			if tok.Type != ILLEGAL || tok.Literal != tt.literal || tok.Message != tt.message {
+				t.Errorf("%q: got %s %q %q, want ILLEGAL %q %q", tt.input, tok.Type, tok.Literal, tok.Message, tt.literal, tt.message)
+			}
+		}
+	}
+
+	p := NewParser(NewLexer("{\n  user(name: \"Ada\\q\") }"))
+	p.ParseDocument()
+	errs := p.Errors()
+	if len(errs) != 1 || errs[0].Message != `Syntax Error: Invalid escape sequence "\q".` || errs[0].Locations[0] != (Location{Line: 2, Column: 14}) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	p = NewParser(NewLexer(`{ user(name: "Ada) }`))
+	p.ParseDocument()
+	if errs := p.Errors(); len(errs) != 1 || errs[0].Message != "Syntax Error: Unterminated string." {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLexer_InvalidCharacters(t *testing.T) {
+	tests := []struct {
+		input   string
+		literal string
+		message string
+	}{
+		{"\x00{ a }", "\x00", "Invalid character U+0000"},
+		{"\x01", "\x01", "Invalid character U+0001"},
+		{"\xff", "\xff", "Invalid UTF-8 byte 0xFF"},
+		{"# caf\xe9\n", "\xe9", "Invalid UTF-8 byte 0xE9"},
+		{"# a\x07b", "\x07", "Invalid character U+0007"},
+		{"\"a\x01b\" x", "\"a\x01b\"", "Invalid character U+0001 in string"},
+		{"\"a\x00b\"", "\"a\x00b\"", "Invalid character U+0000 in string"},
+		{"\"caf\xe9\"", "\"caf\xe9\"", "Invalid UTF-8 byte 0xE9 in string"},
+		{"\"\"\"a\x00b\"\"\"", "\"\"\"a", "Invalid character U+0000 in string"},
+		{"01", "01", `Invalid number, unexpected digit after 0: "01"`},
+		{"-007.5", "-007.5", `Invalid number, unexpected digit after 0: "-007.5"`},
+		{"1e", "1e", `Invalid number, expected a digit in the exponent: "1e"`},
+		{"1.5E+ ", "1.5E+", `Invalid number, expected a digit in the exponent: "1.5E+"`},
+	}
+	for _, tt := range tests {
+		for _, l := range []*Lexer{NewLexer(tt.input), NewLexerFromReader(iotest.OneByteReader(strings.NewReader(tt.input)))} {
+			tok := l.NextToken()
+			if tok.Type != ILLEGAL || tok.Literal != tt.literal || tok.Message != tt.message {
+				t.Errorf("%q: got %s %q %q, want ILLEGAL %q %q", tt.input, tok.Type, tok.Literal, tok.Message, tt.literal, tt.message)
+			}
+		}
+	}
+
+	// Valid characters outside ASCII, tabs and zeros are still accepted.
+	for _, input := range []string{"\"a\tb\"", "\"caf\u00e9 \U0001F44B\"", "\"\"\"caf\u00e9\n\"\"\"", "0", "-0", "0.5", "10e3", "# caf\u00e9 \U0001F44B"} {
+		if tok := NewLexer(input).NextToken(); tok.Type == ILLEGAL {
+			t.Errorf("%q: unexpected %+v", input, tok)
+		}
+	}
+
+	// A NUL character does not end the document early.
+	p := NewParser(NewLexer("{ a }\x00{ b }"))
+	p.ParseDocument()
+	if errs := p.Errors(); len(errs) != 1 || errs[0].Message != "Syntax Error: Invalid character U+0000." || errs[0].Locations[0] != (Location{Line: 1, Column: 6}) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+	p = NewParser(NewLexer("{ f(x: 01) }"))
+	p.ParseDocument()
+	if errs := p.Errors(); len(errs) != 1 || errs[0].Message != `Syntax Error: Invalid number, unexpected digit after 0: "01".` {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLexer_UTF8(t *testing.T) {
+	tests := []struct {
+		input string
+		typ   TokenType
+		want  string
+	}{
+		{"café", IDENT, "café"},
+		{"名前", IDENT, "名前"},
+		{"Ωmega_2", IDENT, "Ωmega_2"},
+		{"\uFEFFquery", IDENT, "query"},
+		{"\uFEFF \uFEFF{", LBRACE, "{"},
+		{`"héllo 👋 世界"`, STRING, "héllo 👋 世界"},
+		{`"\u00e9\u4e16"`, STRING, "é世"},
+		{`"\uD83D\uDE00"`, STRING, "😀"},
+		{`"\u{1F600}\u{e9}"`, STRING, "😀é"},
+		{`"\uD83D!"`, STRING, "\uFFFD!"},
+		{`"\uDE00\uD83D"`, STRING, "\uFFFD\uFFFD"},
+		{`"\u{D800}"`, STRING, "\uFFFD"},
+		{`"\u{110000}"`, ILLEGAL, `"\u{110000}"`},
+		{"👋", ILLEGAL, "👋"},
+		{"\xff", ILLEGAL, "\xff"},
+		{"\u00a0", ILLEGAL, "\u00a0"},
+	}
+	for _, tt := range tests {
+		tok := NewLexer(tt.input).NextToken()
+		if tok.Type != tt.typ || tok.Literal != tt.want {
+			t.Errorf("%q: got %s %q, want %s %q", tt.input, tok.Type, tok.Literal, tt.typ, tt.want)
+		}
+	}
+
+	// Offsets count bytes, but error columns count characters.
+	l := NewLexer("\uFEFF{ café }")
+	if tok := l.NextToken(); tok.Start != 3 {
+		t.Errorf("the byte order mark must be skipped, got %+v", tok)
+	}
+	if tok := l.NextToken(); tok.Start != 5 || tok.End != 10 {
+		t.Errorf("unexpected span of %+v", tok)
+	}
+	p := NewParser(NewLexer("{\n  user(name: \"José 👋\") 世界 ☃ }"))
+	p.ParseDocument()
+	errs := p.Errors()
+	if len(errs) == 0 || errs[0].Message != `Syntax Error: Unexpected "☃" in selection set.` || errs[0].Locations[0] != (Location{Line: 2, Column: 27}) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLexer_BlockStrings(t *testing.T) {
+	lexer := NewLexer("\"\"\"\n    A user.\n\n      Has a \\\"\"\" quote.\n  \"\"\" x")
+	tok := lexer.NextToken()
+	if tok.Type != STRING || tok.Literal != "A user.\n\n  Has a \"\"\" quote." {
+		t.Errorf("unexpected token %s %q", tok.Type, tok.Literal)
+	}
+	if tok := lexer.NextToken(); tok.Type != IDENT || tok.Literal != "x" {
+		t.Errorf("expected x after the block string, got %s %q", tok.Type, tok.Literal)
+	}
+}
+
+func TestLexer_SignedAndFloatNumbers(t *testing.T) {
+	tests := []struct {
+		input string
+		typ   TokenType
+		want  string
+	}{
+		{"-42", INT, "-42"},
+		{"0", INT, "0"},
+		{"1.5", FLOAT, "1.5"},
+		{"-0.25", FLOAT, "-0.25"},
+		{"6.02e23", FLOAT, "6.02e23"},
+		{"1E-3", FLOAT, "1E-3"},
+		{"2e+8", FLOAT, "2e+8"},
+	}
+	for _, tt := range tests {
+		tok := NewLexer(tt.input).NextToken()
+		if tok.Type != tt.typ || tok.Literal != tt.want {
+			t.Errorf("%s: got %s %q, want %s %q", tt.input, tok.Type, tok.Literal, tt.typ, tt.want)
+		}
+	}
+
+	// A dot not followed by a digit ends the number.
+	l := NewLexer("1.")
+	if tok := l.NextToken(); tok.Type != INT || tok.Literal != "1" {
+		t.Errorf("unexpected token %v", tok)
+	}
+}
+
+func TestLexer_Spans(t *testing.T) {
+	input := `{ user(name: "Ada\n") }`
+	l := NewLexer(input)
+	want := []struct {
+		literal    string
+		start, end int
+	}{
+		{"{", 0, 1}, {"user", 2, 6}, {"(", 6, 7}, {"name", 7, 11}, {":", 11, 12},
+		{"Ada\n", 13, 20}, {")", 20, 21}, {"}", 22, 23}, {"", 23, 23},
+	}
+	for _, w := range want {
+		tok := l.NextToken()
+		if tok.Literal != w.literal || tok.Start != w.start || tok.End != w.end {
+			t.Errorf("got %q [%d:%d], want %q [%d:%d]", tok.Literal, tok.Start, tok.End, w.literal, w.start, w.end)
+		}
+	}
+}
+
+// TestLexer_NoAllocations verifies that tokens without escape sequences
+// refer to the input instead of copying it.
+func TestLexer_NoAllocations(t *testing.T) {
+	input := `query Q($id: ID!) { user(id: $id, name: "Ada", age: -1.5) @include(if: true) { name } }`
+	allocs := testing.AllocsPerRun(100, func() {
+		var l Lexer
+		l.reset(input)
+		for l.NextToken().Type != EOF {
+		}
+	})
+	if allocs != 0 {
+		t.Errorf("lexing allocated %v times", allocs)
+	}
+}
+
+func BenchmarkLexer(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		l := NewLexer(benchmarkQuery)
+		for l.NextToken().Type != EOF {
+		}
+	}
+}
+
+func TestLexerFromReader(t *testing.T) {
+	inputs := []string{
+		`query Q($id: ID!) { user(id: $id) { ...f name @include(if: true) } } # done`,
+		"\"\"\"\n  A \\\"\"\" quote\n\"\"\" type User { id: ID }",
+		`{ s(a: "\u00e9t\u00e9 \"x\"", b: -1.5e+3, c: 2E-2, d: 1e, e: 7) }`,
+		"{ a }\n# comment\r\n\t{ b ... on T { c } } ?",
+		strings.Repeat("type Query { field(argument: String = \"value\"): [Int!]! }\n", 500),
+		"\uFEFF{ caf\u00e9 \u540d\u524d(s: \"\U0001F44B \u4e16\u754c\", e: \"\\uD83D\\uDE00\\u{1F600}\") \U0001F44B }",
+	}
+	for _, input := range inputs {
+		want := NewLexer(input)
+		for _, r := range []io.Reader{strings.NewReader(input), iotest.OneByteReader(strings.NewReader(input)), iotest.HalfReader(strings.NewReader(input)), iotest.DataErrReader(strings.NewReader(input))} {
+			want.reset(input)
+			got := NewLexerFromReader(r)
+			for {
+				wantTok, gotTok := want.NextToken(), got.NextToken()
+				if gotTok != wantTok {
+					t.Fatalf("%.40q: got %+v, want %+v", input, gotTok, wantTok)
+				}
+				if wantTok.Type == EOF {
+					break
+				}
+			}
+		}
+	}
+}
+
+func TestParserFromReader(t *testing.T) {
+	var sb strings.Builder
+	for i := 0; i < 1000; i++ {
+		fmt.Fprintf(&sb, "\"\"\"Type %d.\"\"\"\ntype T%d {\n  id: ID!\n  next: T%d\n}\n\n", i, i, i+1)
+	}
+	sdl := sb.String()
+
+	want := NewParser(NewLexer(sdl)).ParseDocument()
+	p := NewParser(NewLexerFromReader(iotest.OneByteReader(strings.NewReader(sdl))))
+	got := p.ParseDocument()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected errors %v", p.Errors())
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("parsing from a reader gives a different document")
+	}
+
+	// Errors are located in the whole document.
+	broken := sdl + "type Broken {\n  id: ID!\n  name: (\n}"
+	p = NewParser(NewLexerFromReader(iotest.OneByteReader(strings.NewReader(broken))))
+	p.ParseDocument()
+	wantErrs := NewParser(NewLexer(broken))
+	wantErrs.ParseDocument()
+	if len(p.Errors()) == 0 || !reflect.DeepEqual(p.Errors(), wantErrs.Errors()) {
+		t.Errorf("got errors %v, want %v", p.Errors(), wantErrs.Errors())
+	}
+	if loc := p.Errors()[0].Locations[0]; loc.Line != 6003 {
+		t.Errorf("error located at %+v, want line 6003", loc)
+	}
+
+	failure := errors.New("connection reset")
+	p = NewParser(NewLexerFromReader(io.MultiReader(strings.NewReader("{ a b"), iotest.ErrReader(failure))))
+	p.ParseDocument()
+	errs := p.Errors()
+	if len(errs) == 0 || errs[len(errs)-1].Message != "Could not read the document: connection reset" {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestLexer_PeekTokenAndLocate(t *testing.T) {
+	input := "query {\n  user(name: \"Zoë\") { id }\n}"
+	for _, l := range []*Lexer{NewLexer(input), NewLexerFromReader(iotest.OneByteReader(strings.NewReader(input)))} {
+		var got []string
+		for {
+			peeked := l.PeekToken()
+			if again := l.PeekToken(); again != peeked {
+				t.Fatalf("peeking twice gave %+v and %+v", peeked, again)
+			}
+			tok := l.NextToken()
+			if tok != peeked {
+				t.Fatalf("NextToken = %+v, want the peeked %+v", tok, peeked)
+			}
+			if tok.Type == EOF {
+				break
+			}
+			loc := l.Locate(tok.Start)
+			got = append(got, fmt.Sprintf("%s@%d:%d", tok.Literal, loc.Line, loc.Column))
+		}
+		want := []string{"query@1:1", "{@1:7", "user@2:3", "(@2:7", "name@2:8", ":@2:12", "Zoë@2:14", ")@2:19", "{@2:21", "id@2:23", "}@2:26", "}@3:1"}
+		if !reflect.DeepEqual(got, want) {
+			t.Errorf("got %v\nwant %v", got, want)
+		}
+	}
+}
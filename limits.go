@@ -0,0 +1,131 @@
+package vibeGraphql
+
+import "fmt"
+
+// OperationLimits bounds the size of a single operation. Aliases let one
+// request run an expensive field many times, so unrestricted documents can
+// be used to amplify load; these limits reject such documents before any
+// resolver runs. A zero value disables the corresponding limit.
+type OperationLimits struct {
+	// MaxAliases is the number of aliased fields allowed in the operation.
+	MaxAliases int
+	// MaxRootFields is the number of fields allowed in the root selection set.
+	MaxRootFields int
+	// MaxDirectives is the number of directives allowed in the operation.
+	MaxDirectives int
+}
+
+// DefaultOperationLimits are applied to every executed operation. They are
+// zero, disabling every limit, until set; use WithOperationLimits to limit
+// the operations of a single handler instead.
+var DefaultOperationLimits OperationLimits
+
+// WithOperationLimits rejects the operations that exceed limits with an
+// OPERATION_LIMIT_EXCEEDED error before they execute, in addition to
+// DefaultOperationLimits.
+func WithOperationLimits(limits OperationLimits) HandlerOption {
+	return func(h *handler) {
+		h.limits = &limits
+	}
+}
+
+// checkLimits returns an error if op exceeds the limits of the handler.
+func (h *handler) checkLimits(op *OperationDefinition) *Error {
+	if h.limits == nil {
+		return nil
+	}
+	if errs := CheckOperationLimits(op, *h.limits); len(errs) > 0 {
+		return errs[0]
+	}
+	return nil
+}
+
+// CheckOperationLimits returns an error for each limit op exceeds. The
+// selections of fragments count wherever the fragments are spread.
+func CheckOperationLimits(op *OperationDefinition, limits OperationLimits) []*Error {
+	if op == nil || op.SelectionSet == nil {
+		return nil
+	}
+	counts := (&selectionCounter{}).count(op.SelectionSet)
+
+	var errs []*Error
+	check := func(n, max int, what string) {
+		if max > 0 && n > max {
+			errs = append(errs, NewError(CodeOperationLimitExceeded,
+				fmt.Sprintf("Operation has %d %s, exceeding the limit of %d.", n, what, max)))
+		}
+	}
+	check(counts.aliases, limits.MaxAliases, "aliases")
+	check(len(fieldsOf(op.SelectionSet)), limits.MaxRootFields, "root fields")
+	check(counts.directives, limits.MaxDirectives, "directives")
+	return errs
+}
+
+// OperationComplexity scores op by the number of fields it selects, counting
+// nested fields at every level and the fields of fragments wherever the
+// fragments are spread.
+func OperationComplexity(op *OperationDefinition) int {
+	if op == nil {
+		return 0
+	}
+	return (&selectionCounter{}).count(op.SelectionSet).fields
+}
+
+// selectionCounts are the numbers of fields, aliases and directives in a
+// selection set, at every level.
+type selectionCounts struct {
+	fields, aliases, directives int
+}
+
+func (c *selectionCounts) add(other selectionCounts) {
+	c.fields += other.fields
+	c.aliases += other.aliases
+	c.directives += other.directives
+}
+
+// selectionCounter counts the selections of selection sets. The counts of
+// each fragment are computed once, so that fragments spreading others many
+// times are counted in time linear in the size of the document.
+type selectionCounter struct {
+	fragments map[*FragmentDefinition]selectionCounts
+}
+
+func (c *selectionCounter) count(ss *SelectionSet) selectionCounts {
+	var n selectionCounts
+	if ss == nil {
+		return n
+	}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *Field:
+			n.fields++
+			if sel.Alias != "" {
+				n.aliases++
+			}
+			n.directives += len(sel.Directives)
+			n.add(c.count(sel.SelectionSet))
+		case *InlineFragment:
+			n.directives += len(sel.Directives)
+			n.add(c.count(sel.SelectionSet))
+		case *FragmentSpread:
+			n.directives += len(sel.Directives)
+			if sel.Fragment != nil {
+				n.add(c.fragment(sel.Fragment))
+			}
+		}
+	}
+	return n
+}
+
+func (c *selectionCounter) fragment(def *FragmentDefinition) selectionCounts {
+	if n, ok := c.fragments[def]; ok {
+		return n
+	}
+	n := c.count(def.SelectionSet)
+	n.directives += len(def.Directives)
+	if c.fragments == nil {
+		c.fragments = make(map[*FragmentDefinition]selectionCounts)
+	}
+	c.fragments[def] = n
+	return n
+}
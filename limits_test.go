@@ -0,0 +1,116 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"net/url"
+	"strings"
+	"testing"
+)
+
+func TestCheckOperationLimits(t *testing.T) {
+	limits := OperationLimits{MaxAliases: 2, MaxRootFields: 3, MaxDirectives: 1}
+	tests := []struct {
+		query string
+		want  []string
+	}{
+		{`{ a b: a c: a { d } }`, nil},
+		{`{ a b: a c: a d: a }`, []string{
+			"Operation has 3 aliases, exceeding the limit of 2.",
+			"Operation has 4 root fields, exceeding the limit of 3.",
+		}},
+		{`{ a { b: c { d: e f: g } } }`, []string{"Operation has 3 aliases, exceeding the limit of 2."}},
+		{`{ a @skip(if: true) b { c @include(if: true) } }`, []string{"Operation has 2 directives, exceeding the limit of 1."}},
+	}
+	for _, tt := range tests {
+		op := parseQuery(tt.query).Definitions[0].(*OperationDefinition)
+		var got []string
+		for _, err := range CheckOperationLimits(op, limits) {
+			if err.Extensions["code"] != CodeOperationLimitExceeded {
+				t.Errorf("%s: unexpected code %v", tt.query, err.Extensions["code"])
+			}
+			got = append(got, err.Message)
+		}
+		if strings.Join(got, "\n") != strings.Join(tt.want, "\n") {
+			t.Errorf("%s: got %q, want %q", tt.query, got, tt.want)
+		}
+	}
+
+	op := parseQuery(`{ a b: a c: a d: a }`).Definitions[0].(*OperationDefinition)
+	if errs := CheckOperationLimits(op, OperationLimits{}); errs != nil {
+		t.Errorf("zero limits should disable checks, got %v", errs)
+	}
+}
+
+func TestExecuteDocument_OperationLimits(t *testing.T) {
+	QueryResolvers["limited"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("resolver should not run when the operation exceeds its limits")
+		return "x", nil
+	}
+	defer delete(QueryResolvers, "limited")
+	defer func(limits OperationLimits) { DefaultOperationLimits = limits }(DefaultOperationLimits)
+	DefaultOperationLimits = OperationLimits{MaxAliases: 15}
+
+	query := "{" + strings.Repeat(" l: limited", DefaultOperationLimits.MaxAliases+1) + " }"
+	result, err := executeDocument(context.Background(), parseQuery(query), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := result["errors"].([]*Error)
+	if len(errs) == 0 || !strings.Contains(errs[0].Message, "aliases") {
+		t.Errorf("expected an alias limit error, got %v", result)
+	}
+}
+
+func TestWithOperationLimits(t *testing.T) {
+	useTestSchema(t, `type Query { version: String } type Subscription { ticks: Int }`)
+	useResolvers(t, map[string]ResolverFunc{
+		"version": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "1", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("subscriptions exceeding the limits should not reach the resolver")
+		ch := make(chan interface{})
+		close(ch)
+		return ch, nil
+	})
+	query := "{" + strings.Repeat(" v: version", 20) + " }"
+
+	// Operations are not limited by default.
+	rr := httptest.NewRecorder()
+	NewHandler().ServeHTTP(rr, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(query), nil))
+	if body := rr.Body.String(); strings.Contains(body, "errors") {
+		t.Errorf("expected no limits by default, got %s", body)
+	}
+
+	opts := []HandlerOption{WithOperationLimits(OperationLimits{MaxAliases: 3, MaxDirectives: 1})}
+	rr = httptest.NewRecorder()
+	NewHandler(opts...).ServeHTTP(rr, httptest.NewRequest("GET", "/graphql?query="+url.QueryEscape(query), nil))
+	var resp struct{ Errors []*Error }
+	json.Unmarshal(rr.Body.Bytes(), &resp)
+	if len(resp.Errors) != 1 || resp.Errors[0].Message != "Operation has 20 aliases, exceeding the limit of 3." || resp.Errors[0].Extensions["code"] != CodeOperationLimitExceeded {
+		t.Errorf("unexpected response %s", rr.Body.String())
+	}
+
+	conn := &jsonConn{messageConn: messageConn{request: `{"query": "subscription { ticks @include(if: true) @skip(if: false) }"}`}}
+	newHandler(opts).subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.messages) != 1 || conn.messages[0] != "Operation has 2 directives, exceeding the limit of 1." {
+		t.Errorf("unexpected messages %q", conn.messages)
+	}
+}
+
+func TestExecuteDocument_Aliases(t *testing.T) {
+	QueryResolvers["greeting"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "hello " + args["name"].(string), nil
+	}
+	defer delete(QueryResolvers, "greeting")
+
+	result, err := executeDocument(context.Background(), parseQuery(`{ a: greeting(name: "a") greeting(name: "b") }`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := result["data"].(map[string]interface{})
+	if data["a"] != "hello a" || data["greeting"] != "hello b" {
+		t.Errorf("unexpected data: %v", data)
+	}
+}
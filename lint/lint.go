@@ -0,0 +1,206 @@
+// Package lint checks GraphQL documents for problems that are legal but
+// worth fixing: use of deprecated fields, anonymous operations, selections
+// nested too deeply and variables that are never used.
+//
+// Rules are vibeGraphql validation rules with a name and a severity, so
+// projects can adjust the defaults or add rules of their own:
+//
+//	rules := lint.DefaultRules()
+//	rules[0].Severity = lint.Error
+//	for _, d := range lint.Lint(schema, doc, rules) {
+//		fmt.Println(d)
+//	}
+package lint
+
+import (
+	"fmt"
+	"sort"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+// Severity ranks how serious a diagnostic is.
+type Severity int
+
+const (
+	// Warning marks a problem that should be fixed eventually.
+	Warning Severity = iota
+	// Error marks a problem that should fail a lint run.
+	Error
+)
+
+func (s Severity) String() string {
+	if s == Error {
+		return "error"
+	}
+	return "warning"
+}
+
+// Diagnostic is a problem found by a rule.
+type Diagnostic struct {
+	Rule     string
+	Severity Severity
+	Message  string
+	// Location is where the problem is in the document; it is zero if unknown.
+	Location graphql.Location
+}
+
+// String formats the diagnostic as "line:column: severity: message (rule)".
+func (d Diagnostic) String() string {
+	return fmt.Sprintf("%d:%d: %s: %s (%s)", d.Location.Line, d.Location.Column, d.Severity, d.Message, d.Rule)
+}
+
+// Rule is a named check reporting diagnostics of one severity.
+type Rule struct {
+	// Name identifies the rule in diagnostics, such as "deprecated-field".
+	Name     string
+	Severity Severity
+	// Check inspects the document, reporting each problem through its
+	// ValidationContext.
+	Check graphql.ValidationRule
+}
+
+// DefaultMaxDepth is the nesting limit DefaultRules uses.
+const DefaultMaxDepth = 10
+
+// DefaultRules returns the rules of this package with their default severities.
+func DefaultRules() []Rule {
+	return []Rule{
+		DeprecatedFields,
+		OperationNames,
+		MaxDepth(DefaultMaxDepth),
+		UnusedVariables,
+	}
+}
+
+// Lint checks doc with rules and returns the diagnostics ordered by
+// location. schema may be nil, in which case rules that need field
+// definitions, such as DeprecatedFields, report nothing.
+func Lint(schema *graphql.Schema, doc *graphql.Document, rules []Rule) []Diagnostic {
+	if schema == nil {
+		schema = &graphql.Schema{}
+	}
+	var diags []Diagnostic
+	for _, rule := range rules {
+		for _, err := range graphql.ValidateWithRules(schema, doc, []graphql.ValidationRule{rule.Check}) {
+			d := Diagnostic{Rule: rule.Name, Severity: rule.Severity, Message: err.Message}
+			if len(err.Locations) > 0 {
+				d.Location = err.Locations[0]
+			}
+			diags = append(diags, d)
+		}
+	}
+	sort.SliceStable(diags, func(i, j int) bool {
+		a, b := diags[i].Location, diags[j].Location
+		if a.Line != b.Line {
+			return a.Line < b.Line
+		}
+		return a.Column < b.Column
+	})
+	return diags
+}
+
+// DeprecatedFields reports each selection of a field marked @deprecated.
+var DeprecatedFields = Rule{
+	Name:     "deprecated-field",
+	Severity: Warning,
+	Check: func(ctx *graphql.ValidationContext) graphql.RuleVisitor {
+		return graphql.RuleVisitor{EnterField: func(parentType string, field, def *graphql.Field) {
+			if def == nil {
+				return
+			}
+			if reason, ok := def.Deprecation(); ok {
+				ctx.Reportf(field, "The field %s.%s is deprecated: %s", parentType, def.Name, reason)
+			}
+		}}
+	},
+}
+
+// OperationNames reports anonymous operations, which are hard to tell apart
+// in logs and metrics.
+var OperationNames = Rule{
+	Name:     "operation-name",
+	Severity: Warning,
+	Check: func(ctx *graphql.ValidationContext) graphql.RuleVisitor {
+		return graphql.RuleVisitor{EnterOperation: func(op *graphql.OperationDefinition) {
+			if op.Name != "" {
+				return
+			}
+			err := &graphql.Error{Message: fmt.Sprintf("Anonymous %s should be given a name.", op.Operation)}
+			if op.Loc.Line > 0 {
+				err.Locations = []graphql.Location{op.Loc}
+			}
+			ctx.Report(err)
+		}}
+	},
+}
+
+// UnusedVariables reports variables an operation declares but never uses.
+var UnusedVariables = Rule{
+	Name:     "unused-variable",
+	Severity: Error,
+	Check:    graphql.NoUnusedVariablesRule,
+}
+
+// MaxDepth returns a rule reporting operations whose fields are nested more
+// than max levels deep, counting the fields of fragments where they are
+// spread. The deepest field of each such operation is reported.
+func MaxDepth(max int) Rule {
+	return Rule{
+		Name:     "max-depth",
+		Severity: Error,
+		Check: func(ctx *graphql.ValidationContext) graphql.RuleVisitor {
+			m := depthMeasurer{fragments: make(map[*graphql.FragmentDefinition]deepest)}
+			return graphql.RuleVisitor{EnterOperation: func(op *graphql.OperationDefinition) {
+				if d := m.measure(op.SelectionSet); max > 0 && d.depth > max {
+					ctx.Reportf(d.field, "Field \"%s\" is nested %d levels deep, exceeding the limit of %d.", d.field.Name, d.depth, max)
+				}
+			}}
+		},
+	}
+}
+
+// deepest is the most deeply nested field of a selection set and its depth.
+type deepest struct {
+	field *graphql.Field
+	depth int
+}
+
+// depthMeasurer measures selection sets, remembering the depth of each
+// fragment so that fragments spread repeatedly are measured once.
+type depthMeasurer struct {
+	fragments map[*graphql.FragmentDefinition]deepest
+}
+
+func (m depthMeasurer) measure(ss *graphql.SelectionSet) deepest {
+	var d deepest
+	if ss == nil {
+		return d
+	}
+	for _, sel := range ss.Selections {
+		var inner deepest
+		switch sel := sel.(type) {
+		case *graphql.Field:
+			inner = m.measure(sel.SelectionSet)
+			if inner.field == nil {
+				inner.field = sel
+			}
+			inner.depth++
+		case *graphql.InlineFragment:
+			inner = m.measure(sel.SelectionSet)
+		case *graphql.FragmentSpread:
+			if sel.Fragment == nil {
+				continue
+			}
+			var ok bool
+			if inner, ok = m.fragments[sel.Fragment]; !ok {
+				inner = m.measure(sel.Fragment.SelectionSet)
+				m.fragments[sel.Fragment] = inner
+			}
+		}
+		if inner.depth > d.depth {
+			d = inner
+		}
+	}
+	return d
+}
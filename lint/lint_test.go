@@ -0,0 +1,82 @@
+package lint
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+const testSDL = `
+	type User {
+		id: ID!
+		name: String
+		login: String @deprecated(reason: "Use name.")
+		friends: [User!]
+	}
+	type Query { user(id: ID!): User }
+`
+
+func parse(t *testing.T, src string) *graphql.Document {
+	t.Helper()
+	p := graphql.NewParser(graphql.NewLexer(src))
+	doc := p.ParseDocument()
+	if errs := p.Errors(); len(errs) > 0 {
+		t.Fatalf("parse error: %v", errs[0])
+	}
+	return doc
+}
+
+func TestLint(t *testing.T) {
+	schema, err := graphql.ParseSchema(testSDL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	doc := parse(t, `{
+  user(id: 1) { login }
+}
+
+query Friends($id: ID!, $unused: Int) {
+  user(id: $id) { ...F }
+}
+
+fragment F on User { friends { friends { name } } }
+`)
+	rules := []Rule{DeprecatedFields, OperationNames, MaxDepth(3), UnusedVariables}
+	var got []string
+	for _, d := range Lint(schema, doc, rules) {
+		got = append(got, d.String())
+	}
+	want := []string{
+		`1:1: warning: Anonymous query should be given a name. (operation-name)`,
+		`2:17: warning: The field User.login is deprecated: Use name. (deprecated-field)`,
+		`5:25: error: Variable "$unused" is never used in operation "Friends". (unused-variable)`,
+		`9:42: error: Field "name" is nested 4 levels deep, exceeding the limit of 3. (max-depth)`,
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("unexpected diagnostics:\n%s", strings.Join(got, "\n"))
+	}
+}
+
+func TestLint_WithoutSchema(t *testing.T) {
+	doc := parse(t, `query Q { user(id: 1) { login } }`)
+	if diags := Lint(nil, doc, DefaultRules()); len(diags) != 0 {
+		t.Errorf("expected no diagnostics without a schema, got %v", diags)
+	}
+}
+
+func TestMaxDepth_RepeatedFragments(t *testing.T) {
+	doc := parse(t, `
+query Q { a { ...F ...F b { ...F } } }
+fragment F on T { c { d } }
+`)
+	diags := Lint(nil, doc, []Rule{MaxDepth(4)})
+	if len(diags) != 0 {
+		t.Errorf("expected depth 4 to be allowed, got %v", diags)
+	}
+	diags = Lint(nil, doc, []Rule{MaxDepth(3)})
+	if len(diags) != 1 || diags[0].Message != `Field "d" is nested 4 levels deep, exceeding the limit of 3.` {
+		t.Errorf("unexpected diagnostics %v", diags)
+	}
+}
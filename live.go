@@ -0,0 +1,148 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// WithLiveQueries lets clients send queries, not only subscriptions, to the
+// subscription handler. Such a query is a live query: it is executed once,
+// and executed again whenever a resolver's data changes, the new result
+// being pushed to the client.
+//
+// A resolver takes part by returning a chan interface{} or <-chan
+// interface{}, as subscription resolvers do. The first value received from
+// the channel is the field's value; every later value replaces it and
+// triggers a new execution. The resolver is not called again while its
+// channel is open. The live query ends once every channel is closed, or when
+// a result cannot be written because the client went away.
+func WithLiveQueries() HandlerOption {
+	return func(h *handler) {
+		h.liveQueries = true
+	}
+}
+
+type liveQueryKey struct{}
+
+// liveQuery tracks the channels returned by the resolvers of a live query,
+// by the response path of their fields.
+type liveQuery struct {
+	ctx    context.Context
+	mu     sync.Mutex
+	fields map[string]*liveField
+}
+
+type liveField struct {
+	ch     <-chan interface{}
+	value  interface{}
+	closed bool
+}
+
+// resolveLive resolves field like resolveFieldOf, taking the values of
+// fields whose resolvers returned a channel from the channel during a live
+// query.
+func (e *executor) resolveLive(source interface{}, parentType string, field *Field) (interface{}, error) {
+	q, _ := e.ctx.Value(liveQueryKey{}).(*liveQuery)
+	if q == nil {
+		return e.resolveFieldOf(source, parentType, field)
+	}
+	key := fmt.Sprint(e.path)
+	q.mu.Lock()
+	f := q.fields[key]
+	q.mu.Unlock()
+	if f != nil {
+		return f.value, nil
+	}
+	res, err := e.resolveFieldOf(source, parentType, field)
+	if err != nil {
+		return res, err
+	}
+	ch, err := subscriptionChannel(field, res)
+	if err != nil {
+		return res, nil
+	}
+	f = &liveField{ch: ch}
+	select {
+	case value, ok := <-ch:
+		f.value, f.closed = value, !ok
+	case <-q.ctx.Done():
+		return nil, q.ctx.Err()
+	}
+	q.mu.Lock()
+	q.fields[key] = f
+	q.mu.Unlock()
+	return f.value, nil
+}
+
+// wait blocks until an open channel of the query yields a value, and
+// records it. It returns false when the context is done or every channel is
+// closed.
+func (q *liveQuery) wait() bool {
+	q.mu.Lock()
+	var fields []*liveField
+	cases := []reflect.SelectCase{{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(q.ctx.Done())}}
+	for _, f := range q.fields {
+		if !f.closed {
+			fields = append(fields, f)
+			cases = append(cases, reflect.SelectCase{Dir: reflect.SelectRecv, Chan: reflect.ValueOf(f.ch)})
+		}
+	}
+	q.mu.Unlock()
+	for len(fields) > 0 {
+		chosen, value, ok := reflect.Select(cases)
+		if chosen == 0 {
+			return false
+		}
+		f := fields[chosen-1]
+		if ok {
+			q.mu.Lock()
+			f.value = value.Interface()
+			q.mu.Unlock()
+			return true
+		}
+		// A closed channel keeps its last value but is no longer watched.
+		f.closed = true
+		fields = append(fields[:chosen-1], fields[chosen:]...)
+		cases = append(cases[:chosen], cases[chosen+1:]...)
+	}
+	return false
+}
+
+// serveLiveQuery executes the query op of doc on conn, pushing a new result
+// whenever a channel returned by one of its resolvers yields.
+func (h *handler) serveLiveQuery(ctx context.Context, conn SubscriptionConn, doc *Document, op *OperationDefinition, req SubscriptionRequest) {
+	q := &liveQuery{ctx: ctx, fields: make(map[string]*liveField)}
+	ctx = context.WithValue(ctx, liveQueryKey{}, q)
+	var name string
+	if fields := fieldsOf(op.SelectionSet); len(fields) > 0 {
+		name = fields[0].Name
+	}
+	defer h.metrics.start(name, req.Variables)()
+
+	var previous []byte
+	for {
+		result, err := executePlanned(ctx, doc, nil, req.OperationName, req.Variables)
+		if err != nil {
+			conn.WriteMessage(TextMessage, []byte("live query error: "+subscriptionErrorMessage(err)))
+			return
+		}
+		// Results that did not change are not pushed again.
+		if body := encodeResponse(result); !bytes.Equal(body, previous) {
+			start := time.Now()
+			err := conn.WriteJSON(result)
+			h.metrics.deliver(time.Since(start), err)
+			if err != nil {
+				logger().Warn("graphql: failed to write live query result", "error", err)
+				return
+			}
+			previous = body
+		}
+		if _, executed := result["data"]; !executed || !q.wait() {
+			return
+		}
+	}
+}
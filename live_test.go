@@ -0,0 +1,49 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+)
+
+func TestWithLiveQueries(t *testing.T) {
+	prices := make(chan interface{}, 3)
+	prices <- 1
+	prices <- 1
+	prices <- 2
+	close(prices)
+	priceCalls, versionCalls := 0, 0
+	useResolvers(t, map[string]ResolverFunc{
+		"version": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			versionCalls++
+			return "v1", nil
+		},
+	}, map[string]map[string]ContextResolverFunc{
+		"Query": {"price": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			priceCalls++
+			return prices, nil
+		}},
+	})
+
+	h := newHandler([]HandlerOption{WithLiveQueries()})
+	conn := &jsonConn{messageConn: messageConn{request: `{"query": "{ price version }"}`}}
+	h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	want := []string{
+		`{"data":{"price":1,"version":"v1"}}`,
+		`{"data":{"price":2,"version":"v1"}}`,
+	}
+	if !reflect.DeepEqual(conn.events, want) {
+		t.Errorf("unexpected results %q", conn.events)
+	}
+	if priceCalls != 1 || versionCalls != 3 {
+		t.Errorf("expected 1 price and 3 version calls, got %d and %d", priceCalls, versionCalls)
+	}
+
+	// Without the option, queries are rejected.
+	conn = &jsonConn{messageConn: messageConn{request: `{"query": "{ version }"}`}}
+	newHandler(nil).subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.events) != 0 || len(conn.messages) != 1 || conn.messages[0] != "provided operation is not a subscription" {
+		t.Errorf("unexpected messages %q %q", conn.messages, conn.events)
+	}
+}
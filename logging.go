@@ -0,0 +1,108 @@
+package vibeGraphql
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// RequestLog describes a request served by a handler created with NewHandler.
+type RequestLog struct {
+	// OperationName is the name of the executed operation, if it has one.
+	OperationName string
+	// DocumentID is the persisted document the request referred to, if any.
+	DocumentID string
+	// RequestID is the ID of the request; see WithRequestID.
+	RequestID string
+	// OperationType is "query", "mutation" or "subscription".
+	OperationType string
+	// Signature identifies the operation independently of its literal
+	// values and formatting; see Normalize.
+	Signature string
+	// Variables holds the request variables after redaction.
+	Variables map[string]interface{}
+	// Duration is the time taken to parse and execute the request.
+	Duration time.Duration
+	// Phases is the time spent in each phase of the request. All but
+	// ReadBody are part of Duration.
+	Phases PhaseTimings
+	// ErrorCount is the number of errors reported in the response, or 1 if
+	// the request failed with an internal error.
+	ErrorCount int
+	// Complexity is the operation's score as computed by OperationComplexity.
+	Complexity int
+}
+
+// RequestLogger receives a RequestLog for every executed request.
+type RequestLogger func(ctx context.Context, entry RequestLog)
+
+// RedactFunc returns the value of the variable name as it should be logged.
+type RedactFunc func(name string, value interface{}) interface{}
+
+// WithLogger reports every request served by the handler to logger, making it
+// straightforward to produce consistent access logs.
+func WithLogger(logger RequestLogger) HandlerOption {
+	return func(h *handler) {
+		h.logger = logger
+	}
+}
+
+// WithVariableRedactor passes each variable through redact before it is
+// logged, so that passwords, tokens and other secrets can be removed. Without
+// it, variables are logged as sent.
+func WithVariableRedactor(redact RedactFunc) HandlerOption {
+	return func(h *handler) {
+		h.redact = redact
+	}
+}
+
+// requestLog builds the log entry for a request executing op.
+func (h *handler) requestLog(doc *Document, op *OperationDefinition, variables map[string]interface{}, d time.Duration, result map[string]interface{}, err error) RequestLog {
+	entry := RequestLog{
+		Variables: make(map[string]interface{}, len(variables)),
+		Duration:  d,
+	}
+	for name, value := range variables {
+		if h.redact != nil {
+			value = h.redact(name, value)
+		}
+		entry.Variables[name] = value
+	}
+	if op != nil {
+		entry.OperationName = op.Name
+		entry.OperationType = op.Operation
+		entry.Complexity = OperationComplexity(op)
+	}
+	if doc != nil && len(doc.Definitions) > 0 {
+		entry.Signature = Normalize(doc).Signature
+	}
+	if errs, ok := result["errors"].([]*Error); ok {
+		entry.ErrorCount = len(errs)
+	}
+	if err != nil {
+		entry.ErrorCount++
+	}
+	return entry
+}
+
+// Logger receives the package's internal log messages, such as failures to
+// write subscription events. When nil, slog.Default() is used. Set it to
+// DiscardLogger to silence the package.
+var Logger *slog.Logger
+
+// DiscardLogger discards all messages.
+var DiscardLogger = slog.New(discardHandler{})
+
+func logger() *slog.Logger {
+	if Logger != nil {
+		return Logger
+	}
+	return slog.Default()
+}
+
+type discardHandler struct{}
+
+func (discardHandler) Enabled(context.Context, slog.Level) bool  { return false }
+func (discardHandler) Handle(context.Context, slog.Record) error { return nil }
+func (h discardHandler) WithAttrs([]slog.Attr) slog.Handler      { return h }
+func (h discardHandler) WithGroup(string) slog.Handler           { return h }
@@ -0,0 +1,95 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithLogger(t *testing.T) {
+	RegisterQueryResolver("logged", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return args["id"], nil
+	})
+	defer delete(QueryResolvers, "logged")
+
+	var entries []RequestLog
+	h := NewHandler(
+		WithLogger(func(ctx context.Context, entry RequestLog) { entries = append(entries, entry) }),
+		WithVariableRedactor(func(name string, value interface{}) interface{} {
+			if name == "password" {
+				return "[REDACTED]"
+			}
+			return value
+		}),
+	)
+
+	body := `{"query": "query Lookup($id: ID) { logged(id: $id) a: logged(id: 1) }", "variables": {"id": "7", "password": "hunter2"}}`
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+	if rr.Code != http.StatusOK {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Body.String())
+	}
+	if len(entries) != 1 {
+		t.Fatalf("expected one log entry, got %d", len(entries))
+	}
+	entry := entries[0]
+	if entry.OperationName != "Lookup" || entry.OperationType != "query" {
+		t.Errorf("unexpected operation %q %q", entry.OperationName, entry.OperationType)
+	}
+	if entry.Signature != Normalize(parseQuery("query Lookup($id: ID) { logged(id: $id) a: logged(id: 1) }")).Signature {
+		t.Errorf("unexpected signature %q", entry.Signature)
+	}
+	if entry.Variables["password"] != "[REDACTED]" || entry.Variables["id"] != "7" {
+		t.Errorf("unexpected variables %v", entry.Variables)
+	}
+	if entry.ErrorCount != 0 || entry.Complexity != 2 || entry.Duration <= 0 {
+		t.Errorf("unexpected entry %+v", entry)
+	}
+
+	rr = httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ a: }"}`)))
+	if len(entries) != 2 || entries[1].ErrorCount == 0 {
+		t.Errorf("expected syntax errors to be counted, got %+v", entries[1:])
+	}
+}
+
+func TestOperationComplexity(t *testing.T) {
+	tests := []struct {
+		query string
+		want  int
+	}{
+		{`{ a }`, 1},
+		{`{ a b: a }`, 2},
+		{`{ a { b c { d } } e }`, 5},
+	}
+	for _, tt := range tests {
+		op := parseQuery(tt.query).Definitions[0].(*OperationDefinition)
+		if got := OperationComplexity(op); got != tt.want {
+			t.Errorf("%s: got %d, want %d", tt.query, got, tt.want)
+		}
+	}
+}
+
+func TestLogger(t *testing.T) {
+	var buf bytes.Buffer
+	prev := Logger
+	Logger = slog.New(slog.NewTextHandler(&buf, nil))
+	defer func() { Logger = prev }()
+
+	InternalErrorHook(context.Background(), "abc123", errors.New("db down"))
+	if out := buf.String(); !strings.Contains(out, "level=ERROR") || !strings.Contains(out, "correlationId=abc123") || !strings.Contains(out, `error="db down"`) {
+		t.Errorf("unexpected log output %q", out)
+	}
+
+	buf.Reset()
+	Logger = DiscardLogger
+	InternalErrorHook(context.Background(), "abc123", errors.New("db down"))
+	if buf.Len() != 0 {
+		t.Errorf("expected DiscardLogger to silence logging, got %q", buf.String())
+	}
+}
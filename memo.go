@@ -0,0 +1,72 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"reflect"
+	"strings"
+)
+
+// MemoizeFieldResults makes resolvers run once per request for each distinct
+// parent object, field and arguments, even when aliases select the same field
+// several times. The selection set is part of the key too, so aliases with
+// different sub-selections are resolved separately. The parent must be the
+// root or a pointer or map, since other values have no identity. Mutations are
+// never memoized.
+var MemoizeFieldResults = false
+
+// memoKey identifies a resolver call within a request.
+type memoKey struct {
+	sourceType reflect.Type
+	source     uintptr
+	parentType string
+	field      string
+	args       string
+	// selection is the printed selection set of the field, as resolvers
+	// may shape their result after it; see ResolveInfo.
+	selection string
+}
+
+type memoEntry struct {
+	source interface{} // keeps source, and therefore its address, alive
+	result interface{}
+	err    error
+}
+
+// memoize returns the result of an earlier identical call to resolve, or
+// calls it and records its result.
+func (e *executor) memoize(source interface{}, parentType string, field *Field, args map[string]interface{}, resolve func() (interface{}, error)) (interface{}, error) {
+	if !MemoizeFieldResults || e.operation == nil || e.operation.Operation == "mutation" {
+		return resolve()
+	}
+	key := memoKey{parentType: parentType, field: field.Name}
+	if source != nil {
+		v := reflect.ValueOf(source)
+		if v.Kind() != reflect.Ptr && v.Kind() != reflect.Map || v.IsNil() {
+			return resolve()
+		}
+		key.sourceType, key.source = v.Type(), v.Pointer()
+	}
+	if len(args) > 0 {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return resolve()
+		}
+		key.args = string(encoded)
+	}
+	if field.SelectionSet != nil {
+		var sb strings.Builder
+		writeSelectionSet(&sb, field.SelectionSet)
+		key.selection = sb.String()
+	}
+	e.mu.Lock()
+	entry, ok := e.memo[key]
+	e.mu.Unlock()
+	if ok {
+		return entry.result, entry.err
+	}
+	result, err := resolve()
+	e.mu.Lock()
+	e.memo[key] = memoEntry{source: source, result: result, err: err}
+	e.mu.Unlock()
+	return result, err
+}
@@ -0,0 +1,92 @@
+package vibeGraphql
+
+import (
+	"context"
+	"testing"
+)
+
+type memoAuthor struct{ ID string }
+
+func (*memoAuthor) graphqlTypeName() string { return "MemoAuthor" }
+
+func TestMemoizeFieldResults(t *testing.T) {
+	calls := map[string]int{}
+	author := &memoAuthor{ID: "1"}
+	QueryResolvers["memoAuthor"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls["memoAuthor"]++
+		return author, nil
+	}
+	MutationResolvers["memoLike"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls["memoLike"]++
+		return calls["memoLike"], nil
+	}
+	RegisterFieldResolver("MemoAuthor", "posts", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls["posts"]++
+		return []string{"a", "b"}, nil
+	})
+	defer delete(QueryResolvers, "memoAuthor")
+	defer delete(MutationResolvers, "memoLike")
+	defer delete(FieldResolvers, "MemoAuthor")
+	MemoizeFieldResults = true
+	defer func() { MemoizeFieldResults = false }()
+
+	data := executeQuery(t, `{
+		a: memoAuthor(id: 1) { ID posts(first: 2) p: posts(first: 2) }
+		b: memoAuthor(id: 1) { posts(first: 2) q: posts(first: 3) }
+		c: memoAuthor(id: 2) { ID }
+		d: memoAuthor(id: 1) { ID posts(first: 2) p: posts(first: 2) }
+	}`, nil)
+	// a and d share a call; b selects other fields and c passes other arguments.
+	if calls["memoAuthor"] != 3 || calls["posts"] != 2 {
+		t.Errorf("unexpected resolver calls %v", calls)
+	}
+	if b := data["b"].(map[string]interface{}); len(b["q"].([]string)) != 2 {
+		t.Errorf("unexpected data %v", data)
+	}
+
+	executeQuery(t, `mutation { a: memoLike b: memoLike }`, nil)
+	if calls["memoLike"] != 2 {
+		t.Errorf("mutations should not be memoized, got %d calls", calls["memoLike"])
+	}
+
+	MemoizeFieldResults = false
+	calls["memoAuthor"] = 0
+	executeQuery(t, `{ a: memoAuthor(id: 1) { ID } b: memoAuthor(id: 1) { ID } }`, nil)
+	if calls["memoAuthor"] != 2 {
+		t.Errorf("expected memoization to be disabled, got %d calls", calls["memoAuthor"])
+	}
+}
+
+func TestMemoizeFieldResults_Selections(t *testing.T) {
+	calls := 0
+	// The resolver only fetches the fields that are selected, as delegating
+	// and projecting resolvers do.
+	RegisterFieldResolver("Query", "memoUser", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		user := map[string]interface{}{}
+		for _, sel := range ResolveInfoFromContext(ctx).Field.SelectionSet.Selections {
+			name := sel.(*Field).Name
+			user[name] = name + " of " + args["id"].(string)
+		}
+		return user, nil
+	})
+	defer delete(FieldResolvers, "Query")
+	MemoizeFieldResults = true
+	defer func() { MemoizeFieldResults = false }()
+
+	data := executeQuery(t, `{ a: memoUser(id: "1") { name } b: memoUser(id: "1") { email } c: memoUser(id: "1") { name } }`, nil)
+	a, b := data["a"].(map[string]interface{}), data["b"].(map[string]interface{})
+	if a["name"] != "name of 1" || b["email"] != "email of 1" {
+		t.Errorf("expected a and b to be resolved separately, got %v", data)
+	}
+	if calls != 2 {
+		t.Errorf("expected a and c to share a call, got %d calls", calls)
+	}
+
+	// Aliases with identical selections still share a call.
+	calls = 0
+	executeQuery(t, `{ a: memoUser(id: "1") { name email } b: memoUser(id: "1") { name email } }`, nil)
+	if calls != 1 {
+		t.Errorf("expected identical selections to share a call, got %d calls", calls)
+	}
+}
@@ -0,0 +1,186 @@
+package vibeGraphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// SubscriptionMetrics counts the connections, subscriptions and events served
+// by subscription handlers configured with WithSubscriptionMetrics. Its
+// Handler lists the active subscriptions for operators. The zero value is
+// ready to use.
+type SubscriptionMetrics struct {
+	mu            sync.Mutex
+	connections   int
+	nextID        uint64
+	subscriptions map[uint64]*ActiveSubscription
+	delivered     uint64
+	dropped       uint64
+	latency       time.Duration
+}
+
+// SubscriptionStats is a snapshot of SubscriptionMetrics.
+type SubscriptionStats struct {
+	// ActiveConnections is the number of open WebSocket connections.
+	ActiveConnections int
+	// ActiveSubscriptions is the number of streaming subscriptions by root
+	// field.
+	ActiveSubscriptions map[string]int
+	// EventsDelivered and EventsDropped count the events written to clients
+	// and those lost because writing them failed.
+	EventsDelivered uint64
+	EventsDropped   uint64
+	// AverageDeliveryLatency is the average time taken to write an event to
+	// a client.
+	AverageDeliveryLatency time.Duration
+}
+
+// ActiveSubscription describes a streaming subscription.
+type ActiveSubscription struct {
+	Field string
+	// VariablesHash identifies the subscription's variables without
+	// revealing them.
+	VariablesHash string
+	Started       time.Time
+}
+
+// WithSubscriptionMetrics records the subscriptions served by a subscription
+// handler in m. One SubscriptionMetrics can be shared by several handlers.
+func WithSubscriptionMetrics(m *SubscriptionMetrics) HandlerOption {
+	return func(h *handler) {
+		h.metrics = m
+	}
+}
+
+// Stats returns the current counters.
+func (m *SubscriptionMetrics) Stats() SubscriptionStats {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	stats := SubscriptionStats{
+		ActiveConnections:   m.connections,
+		ActiveSubscriptions: make(map[string]int),
+		EventsDelivered:     m.delivered,
+		EventsDropped:       m.dropped,
+	}
+	for _, sub := range m.subscriptions {
+		stats.ActiveSubscriptions[sub.Field]++
+	}
+	if m.delivered > 0 {
+		stats.AverageDeliveryLatency = m.latency / time.Duration(m.delivered)
+	}
+	return stats
+}
+
+// Active returns the streaming subscriptions, oldest first.
+func (m *SubscriptionMetrics) Active() []ActiveSubscription {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	active := make([]ActiveSubscription, 0, len(m.subscriptions))
+	for _, sub := range m.subscriptions {
+		active = append(active, *sub)
+	}
+	sort.Slice(active, func(i, j int) bool { return active[i].Started.Before(active[j].Started) })
+	return active
+}
+
+// Handler serves the counters and the active subscriptions as JSON. It is
+// meant for operators and should not be exposed publicly.
+func (m *SubscriptionMetrics) Handler() http.Handler {
+	type subscription struct {
+		Field         string  `json:"field"`
+		VariablesHash string  `json:"variablesHash"`
+		AgeSeconds    float64 `json:"ageSeconds"`
+	}
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		stats := m.Stats()
+		subscriptions := []subscription{}
+		for _, sub := range m.Active() {
+			subscriptions = append(subscriptions, subscription{
+				Field:         sub.Field,
+				VariablesHash: sub.VariablesHash,
+				AgeSeconds:    time.Since(sub.Started).Seconds(),
+			})
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"activeConnections":        stats.ActiveConnections,
+			"activeSubscriptions":      stats.ActiveSubscriptions,
+			"eventsDelivered":          stats.EventsDelivered,
+			"eventsDropped":            stats.EventsDropped,
+			"averageDeliveryLatencyMs": float64(stats.AverageDeliveryLatency) / float64(time.Millisecond),
+			"subscriptions":            subscriptions,
+		})
+	})
+}
+
+// connect counts an open connection and returns a function that uncounts it.
+func (m *SubscriptionMetrics) connect() func() {
+	if m == nil {
+		return func() {}
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.connections++
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		m.connections--
+	}
+}
+
+// start records a subscription to field with variables and returns a
+// function that ends it.
+func (m *SubscriptionMetrics) start(field string, variables map[string]interface{}) func() {
+	if m == nil {
+		return func() {}
+	}
+	sub := &ActiveSubscription{Field: field, VariablesHash: variablesHash(variables), Started: time.Now()}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if m.subscriptions == nil {
+		m.subscriptions = make(map[uint64]*ActiveSubscription)
+	}
+	m.nextID++
+	id := m.nextID
+	m.subscriptions[id] = sub
+	return func() {
+		m.mu.Lock()
+		defer m.mu.Unlock()
+		delete(m.subscriptions, id)
+	}
+}
+
+// deliver records an event written to a client in latency, or lost if err
+// is not nil.
+func (m *SubscriptionMetrics) deliver(latency time.Duration, err error) {
+	if m == nil {
+		return
+	}
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	if err != nil {
+		m.dropped++
+		return
+	}
+	m.delivered++
+	m.latency += latency
+}
+
+// variablesHash returns a short hash of variables. Map keys are encoded in
+// sorted order, so equal variables hash alike.
+func variablesHash(variables map[string]interface{}) string {
+	if len(variables) == 0 {
+		return ""
+	}
+	data, err := json.Marshal(variables)
+	if err != nil {
+		return ""
+	}
+	sum := sha256.Sum256(data)
+	return hex.EncodeToString(sum[:8])
+}
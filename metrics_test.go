@@ -0,0 +1,89 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// failingConn is a messageConn whose JSON writes fail.
+type failingConn struct {
+	messageConn
+}
+
+func (c *failingConn) WriteJSON(v interface{}) error { return errors.New("connection reset") }
+
+func TestWithSubscriptionMetrics(t *testing.T) {
+	events := make(chan interface{})
+	RegisterSubscriptionResolver("metricEvents", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return events, nil
+	})
+	defer delete(SubscriptionResolvers, "metricEvents")
+
+	var m SubscriptionMetrics
+	h := newHandler([]HandlerOption{WithSubscriptionMetrics(&m)})
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		h.subscribe(nil, &messageConn{request: `{"query": "subscription { metricEvents }", "variables": {"room": "a"}}`})
+	}()
+	events <- 1
+	events <- 2
+
+	stats := m.Stats()
+	if stats.ActiveConnections != 1 || !reflect.DeepEqual(stats.ActiveSubscriptions, map[string]int{"metricEvents": 1}) {
+		t.Errorf("unexpected stats while streaming %+v", stats)
+	}
+	active := m.Active()
+	if len(active) != 1 || active[0].Field != "metricEvents" || active[0].VariablesHash != variablesHash(map[string]interface{}{"room": "a"}) || active[0].VariablesHash == "" {
+		t.Errorf("unexpected active subscriptions %+v", active)
+	}
+
+	rr := httptest.NewRecorder()
+	m.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/debug/subscriptions", nil))
+	var body struct {
+		ActiveConnections int `json:"activeConnections"`
+		Subscriptions     []struct {
+			Field string `json:"field"`
+		} `json:"subscriptions"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &body); err != nil || body.ActiveConnections != 1 ||
+		len(body.Subscriptions) != 1 || body.Subscriptions[0].Field != "metricEvents" {
+		t.Errorf("unexpected debug response %s", rr.Body.String())
+	}
+
+	// A client that cannot be written to loses its event.
+	go h.subscribe(nil, &failingConn{messageConn{request: `{"query": "subscription { metricEvents }"}`}})
+	for m.Stats().ActiveConnections != 2 {
+		time.Sleep(time.Millisecond)
+	}
+	for m.Stats().EventsDropped == 0 {
+		select {
+		case events <- 3:
+		case <-time.After(time.Millisecond):
+		}
+	}
+	close(events)
+	<-done
+	for m.Stats().ActiveConnections != 0 {
+		time.Sleep(time.Millisecond)
+	}
+	stats = m.Stats()
+	if stats.EventsDropped != 1 || stats.EventsDelivered < 2 || len(stats.ActiveSubscriptions) != 0 {
+		t.Errorf("unexpected stats after streaming %+v", stats)
+	}
+}
+
+func TestVariablesHash(t *testing.T) {
+	a := variablesHash(map[string]interface{}{"a": 1, "b": "x"})
+	b := variablesHash(map[string]interface{}{"b": "x", "a": 1})
+	if a != b || len(a) != 16 {
+		t.Errorf("expected equal 16-character hashes, got %q and %q", a, b)
+	}
+	if variablesHash(nil) != "" {
+		t.Error("expected no hash without variables")
+	}
+}
@@ -0,0 +1,166 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"hash/fnv"
+	"strings"
+)
+
+// MockField describes the field a Faker produces a value for.
+type MockField struct {
+	ParentType string
+	Name       string
+	// Seed is derived from the field's position in the response, so the same
+	// query always yields the same values.
+	Seed uint32
+}
+
+// Faker produces a fake value for a field.
+type Faker func(f MockField) interface{}
+
+// MockOption configures MockSchema.
+type MockOption func(*mocker)
+
+// WithFaker overrides the value produced for a type, e.g. "DateTime" or
+// "User", or for a single field, e.g. "User.email".
+func WithFaker(name string, faker Faker) MockOption {
+	return func(m *mocker) { m.fakers[name] = faker }
+}
+
+// WithListLength sets how many items mocked list fields return (default 2).
+func WithListLength(n int) MockOption {
+	return func(m *mocker) { m.listLength = n }
+}
+
+// MockSchema parses sdl and registers deterministic fake resolvers for every
+// query and mutation field and every field of the schema's object types, so a
+// server can answer queries before real resolvers exist. The returned schema
+// still needs to be activated with UseSchema. Subscriptions are not mocked.
+func MockSchema(sdl string, opts ...MockOption) (*Schema, error) {
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		return nil, err
+	}
+	m := &mocker{schema: s, fakers: make(map[string]Faker), listLength: 2}
+	for _, opt := range opts {
+		opt(m)
+	}
+	for _, name := range s.typeNames {
+		td := s.Types[name]
+		if td.kind() != KindObject || name == s.SubscriptionType || strings.HasPrefix(name, "__") {
+			continue
+		}
+		for _, f := range td.Fields {
+			RegisterFieldResolver(name, f.Name, m.resolver(name, f))
+		}
+	}
+	return s, nil
+}
+
+type mocker struct {
+	schema     *Schema
+	fakers     map[string]Faker
+	listLength int
+}
+
+// mockObject is the source value of a mocked object type.
+type mockObject struct {
+	typeName string
+	path     string
+}
+
+func (o *mockObject) graphqlTypeName() string { return o.typeName }
+
+func (m *mocker) resolver(typeName string, def *Field) ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		path := typeName
+		switch src := source.(type) {
+		case *mockObject:
+			path = src.path
+		case nil:
+		default:
+			// Values returned by a custom Faker resolve like any other Go value.
+			if info := ResolveInfoFromContext(ctx); info != nil {
+				return reflectResolve(source, info.Field)
+			}
+		}
+		path += "." + def.Name
+		if len(args) > 0 {
+			path += fmt.Sprint(args)
+		}
+		if faker, ok := m.fakers[typeName+"."+def.Name]; ok {
+			return faker(MockField{ParentType: typeName, Name: def.Name, Seed: seedOf(path)}), nil
+		}
+		return m.value(typeName, def.Name, def.Type, path), nil
+	}
+}
+
+func seedOf(path string) uint32 {
+	h := fnv.New32a()
+	h.Write([]byte(path))
+	return h.Sum32()
+}
+
+// value fakes a value of type t for the field at path.
+func (m *mocker) value(parentType, fieldName string, t *Type, path string) interface{} {
+	if t.IsList {
+		items := make([]interface{}, m.listLength)
+		for i := range items {
+			items[i] = m.value(parentType, fieldName, t.Elem, fmt.Sprintf("%s[%d]", path, i))
+		}
+		return items
+	}
+	seed := seedOf(path)
+	if faker, ok := m.fakers[t.Name]; ok {
+		return faker(MockField{ParentType: parentType, Name: fieldName, Seed: seed})
+	}
+	td := m.schema.Type(t.Name)
+	if td == nil {
+		return nil
+	}
+	switch td.kind() {
+	case KindEnum:
+		if len(td.EnumValues) == 0 {
+			return nil
+		}
+		return td.EnumValues[seed%uint32(len(td.EnumValues))].Name
+	case KindInterface, KindUnion:
+		possible := m.possibleTypes(td)
+		if len(possible) == 0 {
+			return nil
+		}
+		return &mockObject{typeName: possible[seed%uint32(len(possible))], path: path}
+	case KindObject:
+		return &mockObject{typeName: td.Name, path: path}
+	}
+	switch t.Name {
+	case "Int":
+		return int(seed % 1000)
+	case "Float":
+		return float64(seed%100000) / 100
+	case "Boolean":
+		return seed%2 == 0
+	case "ID":
+		return fmt.Sprintf("%x", seed)
+	case "String":
+		return fmt.Sprintf("%s.%s %d", parentType, fieldName, seed%1000)
+	}
+	return fmt.Sprintf("%s %d", t.Name, seed%1000)
+}
+
+// possibleTypes returns the object types a union or interface may resolve to.
+func (m *mocker) possibleTypes(td *TypeDefinition) []string {
+	if td.kind() == KindUnion {
+		return td.Types
+	}
+	var names []string
+	for _, name := range m.schema.typeNames {
+		for _, iface := range m.schema.Types[name].Interfaces {
+			if iface == td.Name {
+				names = append(names, name)
+			}
+		}
+	}
+	return names
+}
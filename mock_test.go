@@ -0,0 +1,103 @@
+package vibeGraphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func useMockSchema(t *testing.T, sdl string, opts ...MockOption) {
+	t.Helper()
+	saved := FieldResolvers
+	FieldResolvers = make(map[string]map[string]ContextResolverFunc)
+	t.Cleanup(func() { FieldResolvers = saved })
+	s, err := MockSchema(sdl, opts...)
+	if err != nil {
+		t.Fatalf("MockSchema error: %v", err)
+	}
+	UseSchema(s)
+	t.Cleanup(func() { UseSchema(nil) })
+}
+
+const mockSDL = `
+	enum Role { ADMIN USER }
+	interface Node { id: ID! }
+	type User implements Node {
+		id: ID!
+		name: String
+		age: Int
+		role: Role
+		friends: [User!]
+		createdAt: DateTime
+	}
+	scalar DateTime
+	type Query {
+		me: User
+		node(id: ID!): Node
+	}
+`
+
+func TestMockSchema_Deterministic(t *testing.T) {
+	useMockSchema(t, mockSDL)
+	query := `{ me { id name age role createdAt friends { id name } } }`
+
+	first := executeQuery(t, query, nil)
+	second := executeQuery(t, query, nil)
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("expected identical results, got %v and %v", first, second)
+	}
+
+	me := first["me"].(map[string]interface{})
+	if _, ok := me["id"].(string); !ok {
+		t.Errorf("expected string id, got %#v", me["id"])
+	}
+	if _, ok := me["age"].(int); !ok {
+		t.Errorf("expected int age, got %#v", me["age"])
+	}
+	if role := me["role"]; role != "ADMIN" && role != "USER" {
+		t.Errorf("expected an enum value, got %#v", role)
+	}
+	friends := me["friends"].([]interface{})
+	if len(friends) != 2 {
+		t.Fatalf("expected 2 friends, got %v", friends)
+	}
+	if reflect.DeepEqual(friends[0], friends[1]) {
+		t.Errorf("expected list items to differ, got %v", friends)
+	}
+}
+
+func TestMockSchema_Interface(t *testing.T) {
+	useMockSchema(t, mockSDL)
+	data := executeQuery(t, `{ node(id: "1") { id __typename } }`, nil)
+	if typename := data["node"].(map[string]interface{})["__typename"]; typename != "User" {
+		t.Errorf("expected interface to resolve to User, got %v", typename)
+	}
+}
+
+func TestMockSchema_Fakers(t *testing.T) {
+	useMockSchema(t, mockSDL,
+		WithListLength(3),
+		WithFaker("DateTime", func(MockField) interface{} { return "2024-01-01T00:00:00Z" }),
+		WithFaker("User.name", func(f MockField) interface{} { return "Ada" }),
+		WithFaker("User", func(MockField) interface{} {
+			return map[string]interface{}{"id": "u1", "name": "Grace"}
+		}),
+	)
+	data := executeQuery(t, `{ me { id name } }`, nil)
+	if me := data["me"].(map[string]interface{}); me["id"] != "u1" || me["name"] != "Grace" {
+		t.Errorf("expected the User faker's value, got %v", me)
+	}
+
+	useMockSchema(t, mockSDL,
+		WithListLength(3),
+		WithFaker("DateTime", func(MockField) interface{} { return "2024-01-01T00:00:00Z" }),
+		WithFaker("User.name", func(MockField) interface{} { return "Ada" }),
+	)
+	data = executeQuery(t, `{ me { name createdAt friends { id } } }`, nil)
+	me := data["me"].(map[string]interface{})
+	if me["name"] != "Ada" || me["createdAt"] != "2024-01-01T00:00:00Z" {
+		t.Errorf("unexpected faked values: %v", me)
+	}
+	if friends := me["friends"].([]interface{}); len(friends) != 3 {
+		t.Errorf("expected 3 friends, got %v", friends)
+	}
+}
@@ -0,0 +1,91 @@
+package vibeGraphql
+
+import (
+	"html/template"
+	"net/http"
+	"strings"
+)
+
+// Router is implemented by *http.ServeMux and most third-party routers.
+type Router interface {
+	Handle(pattern string, handler http.Handler)
+}
+
+// Mount registers the GraphQL endpoints under path (e.g. "/graphql"):
+//
+//	path             queries and mutations, JSON or multipart
+//	path/ws          subscriptions over WebSocket
+//	path/upload      multipart file uploads
+//	path/playground  an in-browser IDE
+//
+// The query, subscription and upload endpoints are configured by opts.
+func Mount(r Router, path string, opts ...HandlerOption) {
+	path = strings.TrimSuffix(path, "/")
+	h := NewHandler(opts...)
+	r.Handle(path, h)
+	r.Handle(path+"/ws", NewSubscriptionHandler(opts...))
+	r.Handle(path+"/upload", h)
+	r.Handle(path+"/playground", PlaygroundHandler(path, path+"/ws"))
+}
+
+// PlaygroundHandler serves a GraphiQL page that sends queries to endpoint and
+// subscriptions to subscriptionEndpoint.
+func PlaygroundHandler(endpoint, subscriptionEndpoint string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		playgroundTemplate.Execute(w, map[string]string{
+			"Endpoint":             endpoint,
+			"SubscriptionEndpoint": subscriptionEndpoint,
+		})
+	})
+}
+
+var playgroundTemplate = template.Must(template.New("playground").Parse(`<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>vibeGraphQL Playground</title>
+  <link rel="stylesheet" href="https://unpkg.com/graphiql@3/graphiql.min.css">
+  <style>body { margin: 0; height: 100vh; } #graphiql { height: 100vh; }</style>
+</head>
+<body>
+  <div id="graphiql"></div>
+  <script src="https://unpkg.com/react@18/umd/react.production.min.js"></script>
+  <script src="https://unpkg.com/react-dom@18/umd/react-dom.production.min.js"></script>
+  <script src="https://unpkg.com/graphiql@3/graphiql.min.js"></script>
+  <script>
+    const endpoint = new URL({{.Endpoint}}, location.href).toString();
+    const wsEndpoint = new URL({{.SubscriptionEndpoint}}, location.href).toString().replace(/^http/, "ws");
+    const fetcher = async (params) => {
+      if (/^\s*subscription\b/.test(params.query)) {
+        return {
+          [Symbol.asyncIterator]() {
+            const socket = new WebSocket(wsEndpoint);
+            const queue = [];
+            let wake = null;
+            let done = false;
+            socket.onopen = () => socket.send(JSON.stringify(params));
+            socket.onmessage = (e) => { queue.push({ data: JSON.parse(e.data) }); if (wake) wake(); };
+            socket.onclose = () => { done = true; if (wake) wake(); };
+            return {
+              async next() {
+                while (!queue.length && !done) await new Promise((r) => (wake = r));
+                return queue.length ? { value: queue.shift(), done: false } : { done: true };
+              },
+              async return() { socket.close(); return { done: true }; },
+            };
+          },
+        };
+      }
+      const res = await fetch(endpoint, {
+        method: "POST",
+        headers: { "Content-Type": "application/json" },
+        body: JSON.stringify(params),
+      });
+      return res.json();
+    };
+    ReactDOM.createRoot(document.getElementById("graphiql")).render(React.createElement(GraphiQL, { fetcher }));
+  </script>
+</body>
+</html>
+`))
@@ -0,0 +1,46 @@
+package vibeGraphql
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestHandlersImplementHTTPHandler(t *testing.T) {
+	for _, h := range []http.Handler{GraphqlHandler, SubscriptionHandler, GraphqlUploadHandler} {
+		if h == nil {
+			t.Fatal("expected handler to be set")
+		}
+	}
+}
+
+func TestMount(t *testing.T) {
+	RegisterQueryResolver("mounted", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "yes", nil
+	})
+	mux := http.NewServeMux()
+	Mount(mux, "/api/graphql/")
+
+	for _, path := range []string{"/api/graphql", "/api/graphql/upload"} {
+		req := httptest.NewRequest("POST", path, strings.NewReader(`{"query": "{ mounted }"}`))
+		rr := httptest.NewRecorder()
+		mux.ServeHTTP(rr, req)
+		if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"mounted":"yes"`) {
+			t.Errorf("%s: unexpected response %d %s", path, rr.Code, rr.Body.String())
+		}
+	}
+
+	rr := httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/graphql/playground", nil))
+	body := rr.Body.String()
+	if rr.Code != http.StatusOK || !strings.Contains(body, "GraphiQL") || !strings.Contains(body, `"/api/graphql/ws"`) {
+		t.Errorf("unexpected playground response %d %s", rr.Code, body)
+	}
+
+	rr = httptest.NewRecorder()
+	mux.ServeHTTP(rr, httptest.NewRequest("GET", "/api/graphql/ws", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected websocket endpoint to reject plain GET, got %d", rr.Code)
+	}
+}
@@ -0,0 +1,242 @@
+package vibeGraphql
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"sort"
+	"strconv"
+)
+
+// NormalizedOperation is the canonical form of the operations in a document.
+type NormalizedOperation struct {
+	// Query is the canonical query: fields and arguments sorted, whitespace
+	// and comments dropped, and literal values replaced by variables.
+	Query string
+	// Variables holds the literal values extracted from the document, keyed
+	// by the generated variable names ("_0", "_1", ...).
+	Variables map[string]interface{}
+	// Signature is the hex SHA-256 of Query. Operations that differ only in
+	// literal values, formatting or field order share a signature, which makes
+	// it suitable as a metric label or log key without exposing user data.
+	Signature string
+}
+
+// Normalize returns the canonical form of the operations in doc. Type system
+// definitions are ignored and doc itself is not modified. Because selections
+// are reordered, the normalized query is meant for identification rather than
+// execution.
+//
+// Fragment definitions follow the operations, sorted by name. The variables
+// extracted from fragments are declared by every operation.
+func Normalize(doc *Document) *NormalizedOperation {
+	n := &normalizer{variables: make(map[string]interface{})}
+	normalized := &Document{}
+	var ops []*OperationDefinition
+	for _, def := range doc.Definitions {
+		op, ok := def.(*OperationDefinition)
+		if !ok {
+			continue
+		}
+		ops = append(ops, n.operation(op))
+		normalized.Definitions = append(normalized.Definitions, ops[len(ops)-1])
+	}
+	fragments := fragmentDefinitions(doc)
+	sort.SliceStable(fragments, func(i, j int) bool { return fragments[i].Name < fragments[j].Name })
+	n.defs = nil
+	for _, def := range fragments {
+		normalized.Definitions = append(normalized.Definitions, n.fragment(def))
+	}
+	for _, op := range ops {
+		op.VariableDefinitions = append(op.VariableDefinitions, n.defs...)
+	}
+	query := PrintDocument(normalized)
+	sum := sha256.Sum256([]byte(query))
+	return &NormalizedOperation{
+		Query:     query,
+		Variables: n.variables,
+		Signature: hex.EncodeToString(sum[:]),
+	}
+}
+
+type normalizer struct {
+	variables map[string]interface{}
+	defs      []VariableDefinition
+}
+
+func (n *normalizer) operation(op *OperationDefinition) *OperationDefinition {
+	out := &OperationDefinition{
+		Operation:    op.Operation,
+		Name:         op.Name,
+		Directives:   copyDirectives(op.Directives),
+		SelectionSet: copySelectionSet(op.SelectionSet),
+	}
+	n.defs = append([]VariableDefinition{}, op.VariableDefinitions...)
+	sortDirectives(out.Directives)
+	sortSelections(out.SelectionSet)
+	n.extractArguments(out)
+	out.VariableDefinitions = n.defs
+	return out
+}
+
+// fragment returns the canonical form of def, adding the variables extracted
+// from it to n.defs.
+func (n *normalizer) fragment(def *FragmentDefinition) *FragmentDefinition {
+	out := &FragmentDefinition{
+		Name:          def.Name,
+		TypeCondition: def.TypeCondition,
+		Directives:    copyDirectives(def.Directives),
+		SelectionSet:  copySelectionSet(def.SelectionSet),
+	}
+	sortDirectives(out.Directives)
+	sortSelections(out.SelectionSet)
+	n.extractArguments(out)
+	return out
+}
+
+// extractArguments replaces the scalar literals in the arguments within node
+// with new variables.
+func (n *normalizer) extractArguments(node Node) {
+	Walk(node, Visitor{Enter: func(c *Cursor) bool {
+		if arg, ok := c.Node().(*Argument); ok {
+			arg.Value = n.extract(arg.Value)
+			return false
+		}
+		return true
+	}})
+}
+
+// extract replaces the scalar literals in v with new variables.
+func (n *normalizer) extract(v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	switch v.Kind {
+	case "Int", "Float", "String", "Boolean":
+		name := "_" + strconv.Itoa(len(n.variables))
+		n.variables[name] = buildValue(v, nil)
+		n.defs = append(n.defs, VariableDefinition{Variable: name, Type: Type{Name: v.Kind, NonNull: true}})
+		return &Value{Kind: "Variable", Literal: name}
+	case "Object":
+		keys := make([]string, 0, len(v.ObjectFields))
+		for key := range v.ObjectFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		for _, key := range keys {
+			v.ObjectFields[key] = n.extract(v.ObjectFields[key])
+		}
+	case "Array":
+		for i, item := range v.List {
+			v.List[i] = n.extract(item)
+		}
+	}
+	return v
+}
+
+// sortSelections orders fields, arguments and directives by name so that
+// equivalent operations normalize identically.
+func sortSelections(ss *SelectionSet) {
+	if ss == nil {
+		return
+	}
+	sort.SliceStable(ss.Selections, func(i, j int) bool {
+		return selectionSortKey(ss.Selections[i]) < selectionSortKey(ss.Selections[j])
+	})
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *Field:
+			sort.SliceStable(sel.Arguments, func(i, j int) bool { return sel.Arguments[i].Name < sel.Arguments[j].Name })
+			sortDirectives(sel.Directives)
+			sortSelections(sel.SelectionSet)
+		case *InlineFragment:
+			sortDirectives(sel.Directives)
+			sortSelections(sel.SelectionSet)
+		case *FragmentSpread:
+			sortDirectives(sel.Directives)
+		}
+	}
+}
+
+func sortDirectives(directives []Directive) {
+	sort.SliceStable(directives, func(i, j int) bool { return directives[i].Name < directives[j].Name })
+}
+
+// selectionSortKey orders fields by response key, followed by fragment
+// spreads and inline fragments.
+func selectionSortKey(sel Selection) string {
+	switch sel := sel.(type) {
+	case *Field:
+		return sel.ResponseKey()
+	case *FragmentSpread:
+		return "~..." + sel.Name
+	case *InlineFragment:
+		return "~... on " + sel.TypeCondition
+	}
+	return ""
+}
+
+// copySelectionSet deep-copies a selection set.
+func copySelectionSet(ss *SelectionSet) *SelectionSet {
+	if ss == nil {
+		return nil
+	}
+	out := &SelectionSet{Selections: make([]Selection, 0, len(ss.Selections))}
+	for _, sel := range ss.Selections {
+		switch sel := sel.(type) {
+		case *Field:
+			fc := *sel
+			fc.Arguments = copyArguments(sel.Arguments)
+			fc.Directives = copyDirectives(sel.Directives)
+			fc.SelectionSet = copySelectionSet(sel.SelectionSet)
+			out.Selections = append(out.Selections, &fc)
+		case *InlineFragment:
+			fc := *sel
+			fc.Directives = copyDirectives(sel.Directives)
+			fc.SelectionSet = copySelectionSet(sel.SelectionSet)
+			out.Selections = append(out.Selections, &fc)
+		case *FragmentSpread:
+			sc := *sel
+			sc.Directives = copyDirectives(sel.Directives)
+			out.Selections = append(out.Selections, &sc)
+		default:
+			out.Selections = append(out.Selections, sel)
+		}
+	}
+	return out
+}
+
+func copyDirectives(directives []Directive) []Directive {
+	out := make([]Directive, len(directives))
+	for i, d := range directives {
+		out[i] = Directive{Name: d.Name, Arguments: copyArguments(d.Arguments)}
+	}
+	return out
+}
+
+func copyArguments(args []Argument) []Argument {
+	if args == nil {
+		return nil
+	}
+	out := make([]Argument, len(args))
+	for i, arg := range args {
+		out[i] = Argument{Name: arg.Name, Value: copyValue(arg.Value)}
+	}
+	return out
+}
+
+func copyValue(v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	out := &Value{Kind: v.Kind, Literal: v.Literal}
+	if v.ObjectFields != nil {
+		out.ObjectFields = make(map[string]*Value, len(v.ObjectFields))
+		for key, field := range v.ObjectFields {
+			out.ObjectFields[key] = copyValue(field)
+		}
+	}
+	for _, item := range v.List {
+		out.List = append(out.List, copyValue(item))
+	}
+	return out
+}
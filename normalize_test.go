@@ -0,0 +1,67 @@
+package vibeGraphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestNormalize(t *testing.T) {
+	doc := parseQuery(`query Search($limit: Int) {
+		search(term: "secret", limit: $limit, filter: {tags: ["a", "b"], kind: BOOK}) @include(if: true) {
+			title
+			author { name }
+		}
+		me { id }
+	}`)
+	before := PrintDocument(doc)
+	n := Normalize(doc)
+
+	wantQuery := `query Search($limit: Int, $_0: String!, $_1: String!, $_2: String!, $_3: Boolean!) {me {id} search(filter: {kind: BOOK, tags: [$_0, $_1]}, limit: $limit, term: $_2) @include(if: $_3) {author {name} title}}`
+	if n.Query != wantQuery {
+		t.Errorf("Query =\n%s\nwant\n%s", n.Query, wantQuery)
+	}
+	wantVars := map[string]interface{}{"_0": "a", "_1": "b", "_2": "secret", "_3": true}
+	if !reflect.DeepEqual(n.Variables, wantVars) {
+		t.Errorf("Variables = %v, want %v", n.Variables, wantVars)
+	}
+	if PrintDocument(doc) != before {
+		t.Error("Normalize modified the input document")
+	}
+	if len(n.Signature) != 64 {
+		t.Errorf("unexpected signature %q", n.Signature)
+	}
+}
+
+func TestNormalize_SignatureIgnoresLiteralsAndOrder(t *testing.T) {
+	a := Normalize(parseQuery(`{ user(id: 1) { name email } posts(first: 10) { id } }`))
+	b := Normalize(parseQuery("# different formatting\n{\n  posts(first: 50) { id }\n  user(id: 2) { email name }\n}"))
+	if a.Signature != b.Signature {
+		t.Errorf("expected equal signatures:\n%s\n%s", a.Query, b.Query)
+	}
+	c := Normalize(parseQuery(`{ user(id: 1) { name } }`))
+	if a.Signature == c.Signature {
+		t.Error("expected different selections to have different signatures")
+	}
+}
+
+func TestNormalize_Fragments(t *testing.T) {
+	doc := parseQuery(`query {
+		... on Query { version }
+		user { ...b name ...a }
+	}
+	fragment b on User { avatar(size: 64) }
+	fragment a on User { id }`)
+	before := PrintDocument(doc)
+	n := Normalize(doc)
+
+	want := "query($_0: Int!) {user {name ...a ...b} ... on Query {version}}\n\nfragment a on User {id}\n\nfragment b on User {avatar(size: $_0)}"
+	if n.Query != want {
+		t.Errorf("Query =\n%s\nwant\n%s", n.Query, want)
+	}
+	if !reflect.DeepEqual(n.Variables, map[string]interface{}{"_0": 64}) {
+		t.Errorf("unexpected variables %v", n.Variables)
+	}
+	if PrintDocument(doc) != before {
+		t.Error("Normalize modified the input document")
+	}
+}
@@ -0,0 +1,253 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// maxTrackedOperations bounds the operations an OperationRegistry
+	// tracks; executions of further operations are not recorded.
+	maxTrackedOperations = 1000
+	// maxLatencySamples is the number of recent latencies kept per
+	// operation to compute its percentiles.
+	maxLatencySamples = 1000
+)
+
+// OperationRegistry tracks the operations executed by handlers configured
+// with WithOperationRegistry, keyed by their normalized signature, so that
+// the same operation sent with different literals or formatting is counted
+// once. Together with UnusedFields it shows which fields are still in use
+// before they are removed. The zero value is ready to use.
+type OperationRegistry struct {
+	mu         sync.Mutex
+	operations map[string]*trackedOperation
+}
+
+// OperationUsage is a snapshot of the executions of one operation.
+type OperationUsage struct {
+	// Signature identifies the operation; see Normalize.
+	Signature     string
+	OperationName string
+	// OperationType is "query", "mutation" or "subscription".
+	OperationType string
+	// Query is the normalized text of the operation.
+	Query string
+	// Fields are the schema coordinates the operation selects, such as
+	// "User" and "User.name"; see UsageReporter.
+	Fields []string
+	// Count is the number of executions and Errors the number of those
+	// that reported at least one error.
+	Count  int
+	Errors int
+	// LatencyP50, LatencyP95 and LatencyP99 are percentiles of the time
+	// taken by the most recent executions.
+	LatencyP50 time.Duration
+	LatencyP95 time.Duration
+	LatencyP99 time.Duration
+	FirstSeen  time.Time
+	LastSeen   time.Time
+}
+
+// ErrorRate is the fraction of executions that reported errors.
+func (u OperationUsage) ErrorRate() float64 {
+	if u.Count == 0 {
+		return 0
+	}
+	return float64(u.Errors) / float64(u.Count)
+}
+
+type trackedOperation struct {
+	usage     OperationUsage
+	latencies []time.Duration // ring buffer of the latest latencies
+	next      int
+}
+
+// WithOperationRegistry records every operation the handler executes in
+// registry. One OperationRegistry can be shared by several handlers.
+func WithOperationRegistry(registry *OperationRegistry) HandlerOption {
+	return func(h *handler) {
+		h.operations = registry
+	}
+}
+
+// record adds an execution of op, an operation of doc, to the registry.
+func (o *OperationRegistry) record(doc *Document, op *OperationDefinition, entry RequestLog) {
+	if op == nil || entry.Signature == "" {
+		return
+	}
+	now := time.Now()
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tracked := o.operations[entry.Signature]
+	if tracked == nil {
+		if len(o.operations) >= maxTrackedOperations {
+			return
+		}
+		if o.operations == nil {
+			o.operations = make(map[string]*trackedOperation)
+		}
+		tracked = &trackedOperation{usage: OperationUsage{
+			Signature:     entry.Signature,
+			OperationName: op.Name,
+			OperationType: op.Operation,
+			Query:         Normalize(doc).Query,
+			Fields:        schemaCoordinates(CurrentSchema(), op),
+			FirstSeen:     now,
+		}}
+		o.operations[entry.Signature] = tracked
+	}
+	tracked.usage.Count++
+	if entry.ErrorCount > 0 {
+		tracked.usage.Errors++
+	}
+	tracked.usage.LastSeen = now
+	if len(tracked.latencies) < maxLatencySamples {
+		tracked.latencies = append(tracked.latencies, entry.Duration)
+	} else {
+		tracked.latencies[tracked.next] = entry.Duration
+		tracked.next = (tracked.next + 1) % maxLatencySamples
+	}
+}
+
+// snapshot returns the usage of t with its latency percentiles.
+func (t *trackedOperation) snapshot() OperationUsage {
+	usage := t.usage
+	usage.Fields = append([]string(nil), usage.Fields...)
+	sorted := append([]time.Duration(nil), t.latencies...)
+	sort.Slice(sorted, func(i, j int) bool { return sorted[i] < sorted[j] })
+	usage.LatencyP50 = latencyPercentile(sorted, 0.50)
+	usage.LatencyP95 = latencyPercentile(sorted, 0.95)
+	usage.LatencyP99 = latencyPercentile(sorted, 0.99)
+	return usage
+}
+
+// latencyPercentile returns the p-th percentile of sorted, or 0 if it is empty.
+func latencyPercentile(sorted []time.Duration, p float64) time.Duration {
+	if len(sorted) == 0 {
+		return 0
+	}
+	return sorted[int(float64(len(sorted)-1)*p)]
+}
+
+// Operations returns the usage of every tracked operation, the most
+// executed first.
+func (o *OperationRegistry) Operations() []OperationUsage {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	operations := make([]OperationUsage, 0, len(o.operations))
+	for _, tracked := range o.operations {
+		operations = append(operations, tracked.snapshot())
+	}
+	sort.Slice(operations, func(i, j int) bool {
+		if operations[i].Count != operations[j].Count {
+			return operations[i].Count > operations[j].Count
+		}
+		return operations[i].Signature < operations[j].Signature
+	})
+	return operations
+}
+
+// Operation returns the usage of the operation with the given signature.
+func (o *OperationRegistry) Operation(signature string) (OperationUsage, bool) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	tracked := o.operations[signature]
+	if tracked == nil {
+		return OperationUsage{}, false
+	}
+	return tracked.snapshot(), true
+}
+
+// UnusedFields returns the sorted coordinates ("Type.field") of the fields of
+// object and interface types in s that no tracked operation has selected.
+// Fields selected only through an interface are reported on the
+// implementing types.
+func (o *OperationRegistry) UnusedFields(s *Schema) []string {
+	if s == nil {
+		return nil
+	}
+	used := make(map[string]bool)
+	o.mu.Lock()
+	for _, tracked := range o.operations {
+		for _, coordinate := range tracked.usage.Fields {
+			used[coordinate] = true
+		}
+	}
+	o.mu.Unlock()
+	var unused []string
+	for name, td := range s.Types {
+		if strings.HasPrefix(name, "__") || (td.kind() != KindObject && td.kind() != KindInterface) {
+			continue
+		}
+		for _, f := range td.Fields {
+			if coordinate := name + "." + f.Name; !used[coordinate] {
+				unused = append(unused, coordinate)
+			}
+		}
+	}
+	sort.Strings(unused)
+	return unused
+}
+
+// Reset forgets every tracked operation.
+func (o *OperationRegistry) Reset() {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	o.operations = nil
+}
+
+// Handler serves the tracked operations, and the unused fields of the
+// current schema, as JSON. It is meant for operators and should not be
+// exposed publicly.
+func (o *OperationRegistry) Handler() http.Handler {
+	type operation struct {
+		Signature     string   `json:"signature"`
+		OperationName string   `json:"operationName,omitempty"`
+		OperationType string   `json:"operationType"`
+		Query         string   `json:"query"`
+		Fields        []string `json:"fields"`
+		Count         int      `json:"count"`
+		Errors        int      `json:"errors"`
+		ErrorRate     float64  `json:"errorRate"`
+		LatencyP50Ms  float64  `json:"latencyP50Ms"`
+		LatencyP95Ms  float64  `json:"latencyP95Ms"`
+		LatencyP99Ms  float64  `json:"latencyP99Ms"`
+		FirstSeen     string   `json:"firstSeen"`
+		LastSeen      string   `json:"lastSeen"`
+	}
+	ms := func(d time.Duration) float64 { return float64(d) / float64(time.Millisecond) }
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		operations := []operation{}
+		for _, u := range o.Operations() {
+			operations = append(operations, operation{
+				Signature:     u.Signature,
+				OperationName: u.OperationName,
+				OperationType: u.OperationType,
+				Query:         u.Query,
+				Fields:        u.Fields,
+				Count:         u.Count,
+				Errors:        u.Errors,
+				ErrorRate:     u.ErrorRate(),
+				LatencyP50Ms:  ms(u.LatencyP50),
+				LatencyP95Ms:  ms(u.LatencyP95),
+				LatencyP99Ms:  ms(u.LatencyP99),
+				FirstSeen:     u.FirstSeen.UTC().Format(time.RFC3339),
+				LastSeen:      u.LastSeen.UTC().Format(time.RFC3339),
+			})
+		}
+		unused := o.UnusedFields(CurrentSchema())
+		if unused == nil {
+			unused = []string{}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(map[string]interface{}{
+			"operations":   operations,
+			"unusedFields": unused,
+		})
+	})
+}
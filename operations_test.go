@@ -0,0 +1,96 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestOperationRegistry(t *testing.T) {
+	type user struct{ Name string }
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return user{Name: "ada"}, nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { user(id: ID): User version: String } type User { name: String email: String }`)
+
+	registry := &OperationRegistry{}
+	h := NewHandler(WithOperationRegistry(registry))
+	for _, query := range []string{`query Me { user(id: 1) { name } }`, `query Me { user(id: 2) { name } }`, `{ nope }`} {
+		body, _ := json.Marshal(map[string]string{"query": query})
+		h.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+	}
+
+	operations := registry.Operations()
+	if len(operations) != 2 {
+		t.Fatalf("expected two operations, got %+v", operations)
+	}
+	me := operations[0]
+	if me.Signature != Normalize(parseQuery(`query Me { user(id: 1) { name } }`)).Signature || me.OperationName != "Me" || me.OperationType != "query" {
+		t.Errorf("unexpected operation %+v", me)
+	}
+	if me.Count != 2 || me.Errors != 0 || me.ErrorRate() != 0 || me.LatencyP50 <= 0 || me.LatencyP99 < me.LatencyP50 {
+		t.Errorf("unexpected counters %+v", me)
+	}
+	want := []string{"Query", "Query.user", "Query.user.id", "User", "User.name"}
+	if !reflect.DeepEqual(me.Fields, want) {
+		t.Errorf("got fields %v, want %v", me.Fields, want)
+	}
+	if nope, ok := registry.Operation(operations[1].Signature); !ok || nope.Count != 1 || nope.ErrorRate() != 1 {
+		t.Errorf("unexpected failing operation %+v", nope)
+	}
+
+	unused := registry.UnusedFields(CurrentSchema())
+	if !reflect.DeepEqual(unused, []string{"Query.version", "User.email"}) {
+		t.Errorf("unexpected unused fields %v", unused)
+	}
+
+	rr := httptest.NewRecorder()
+	registry.Handler().ServeHTTP(rr, httptest.NewRequest("GET", "/operations", nil))
+	var payload struct {
+		Operations []struct {
+			OperationName string  `json:"operationName"`
+			Count         int     `json:"count"`
+			ErrorRate     float64 `json:"errorRate"`
+		} `json:"operations"`
+		UnusedFields []string `json:"unusedFields"`
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &payload); err != nil {
+		t.Fatalf("invalid JSON %s", rr.Body.String())
+	}
+	if len(payload.Operations) != 2 || payload.Operations[0].OperationName != "Me" || payload.Operations[0].Count != 2 || payload.Operations[1].ErrorRate != 1 {
+		t.Errorf("unexpected operations %+v", payload.Operations)
+	}
+	if !reflect.DeepEqual(payload.UnusedFields, unused) {
+		t.Errorf("unexpected unused fields %v", payload.UnusedFields)
+	}
+
+	registry.Reset()
+	if len(registry.Operations()) != 0 {
+		t.Error("expected Reset to forget the operations")
+	}
+}
+
+func TestOperationRegistry_Percentiles(t *testing.T) {
+	registry := &OperationRegistry{}
+	doc := parseQuery(`query Q { version }`)
+	op := doc.Definitions[0].(*OperationDefinition)
+	// Only the latest maxLatencySamples executions are kept.
+	for i := 0; i < maxLatencySamples; i++ {
+		registry.record(doc, op, RequestLog{Signature: "q", Duration: time.Hour})
+	}
+	for i := 1; i <= maxLatencySamples; i++ {
+		registry.record(doc, op, RequestLog{Signature: "q", Duration: time.Duration(i) * time.Millisecond})
+	}
+	usage, _ := registry.Operation("q")
+	if usage.Count != 2*maxLatencySamples {
+		t.Errorf("unexpected count %d", usage.Count)
+	}
+	if usage.LatencyP50 != 500*time.Millisecond || usage.LatencyP95 != 950*time.Millisecond || usage.LatencyP99 != 990*time.Millisecond {
+		t.Errorf("unexpected percentiles %v %v %v", usage.LatencyP50, usage.LatencyP95, usage.LatencyP99)
+	}
+}
@@ -0,0 +1,86 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"path"
+)
+
+// CodeForbiddenOperation is the code of the error an operation blocked by an
+// OperationFilter is rejected with.
+const CodeForbiddenOperation = "FORBIDDEN_OPERATION"
+
+// OperationFilter allows or blocks operations by their name or by the root
+// fields they select, for example to block deleteAllUsers in staging.
+// Patterns use the syntax of path.Match, so "delete*" matches every name
+// starting with "delete". Deny patterns take precedence over allow patterns.
+type OperationFilter struct {
+	// AllowNames, when not empty, only allows operations whose name matches
+	// one of its patterns. Anonymous operations are then rejected unless ""
+	// is listed.
+	AllowNames []string
+	// DenyNames blocks operations whose name matches one of its patterns.
+	DenyNames []string
+	// AllowFields, when not empty, only allows operations whose root fields
+	// all match one of its patterns. A pattern matches a field by its name,
+	// such as "user", or by its operation type and name, such as
+	// "mutation.deleteUser".
+	AllowFields []string
+	// DenyFields blocks operations selecting a root field that matches one
+	// of its patterns.
+	DenyFields []string
+}
+
+// WithOperationFilter rejects the operations blocked by filter with a
+// FORBIDDEN_OPERATION error before they execute. It panics if a pattern is
+// malformed.
+func WithOperationFilter(filter OperationFilter) HandlerOption {
+	for _, patterns := range [][]string{filter.AllowNames, filter.DenyNames, filter.AllowFields, filter.DenyFields} {
+		for _, pattern := range patterns {
+			if _, err := path.Match(pattern, ""); err != nil {
+				panic(fmt.Sprintf("vibeGraphql: invalid operation filter pattern %q", pattern))
+			}
+		}
+	}
+	return func(h *handler) {
+		h.filter = &filter
+	}
+}
+
+// check returns a FORBIDDEN_OPERATION error if op may not run.
+func (f *OperationFilter) check(op *OperationDefinition) *Error {
+	if f == nil || op == nil {
+		return nil
+	}
+	if matchAny(f.DenyNames, op.Name) || len(f.AllowNames) > 0 && !matchAny(f.AllowNames, op.Name) {
+		if op.Name == "" {
+			return NewError(CodeForbiddenOperation, "Anonymous operations are not allowed.")
+		}
+		err := NewError(CodeForbiddenOperation, fmt.Sprintf("Operation %q is not allowed.", op.Name))
+		err.Extensions["operationName"] = op.Name
+		return err
+	}
+	if op.SelectionSet == nil {
+		return nil
+	}
+	for _, field := range fieldsOf(op.SelectionSet) {
+		qualified := op.Operation + "." + field.Name
+		denied := matchAny(f.DenyFields, field.Name) || matchAny(f.DenyFields, qualified)
+		allowed := len(f.AllowFields) == 0 || matchAny(f.AllowFields, field.Name) || matchAny(f.AllowFields, qualified)
+		if denied || !allowed {
+			err := NewError(CodeForbiddenOperation, fmt.Sprintf("Field %q is not allowed in a %s.", field.Name, op.Operation))
+			err.Extensions["field"] = field.Name
+			return err
+		}
+	}
+	return nil
+}
+
+// matchAny reports whether name matches one of patterns.
+func matchAny(patterns []string, name string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, name); ok {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,87 @@
+package vibeGraphql
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOperationFilterCheck(t *testing.T) {
+	filter := OperationFilter{
+		AllowNames: []string{"", "Get*", "Delete*"},
+		DenyNames:  []string{"DeleteEverything"},
+		DenyFields: []string{"mutation.deleteAll*", "__schema"},
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user }`, ""},
+		{`query GetUser { user }`, ""},
+		{`query ListUsers { users }`, `Operation "ListUsers" is not allowed.`},
+		{`mutation DeleteEverything { deleteUser }`, `Operation "DeleteEverything" is not allowed.`},
+		{`mutation DeleteUser { deleteUser }`, ""},
+		{`mutation DeleteUsers { deleteUser deleteAllUsers }`, `Field "deleteAllUsers" is not allowed in a mutation.`},
+		{`query DeleteAll { deleteAllUsers }`, ""},
+		{`{ __schema { types { name } } }`, `Field "__schema" is not allowed in a query.`},
+	}
+	for _, tt := range tests {
+		doc := parseQuery(tt.query)
+		err := filter.check(operationsOf(doc)[0])
+		switch {
+		case tt.want == "" && err != nil:
+			t.Errorf("%s: unexpected error %v", tt.query, err)
+		case tt.want != "" && (err == nil || err.Message != tt.want || err.Extensions["code"] != CodeForbiddenOperation):
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, err)
+		}
+	}
+
+	allowFields := OperationFilter{AllowFields: []string{"user", "query.version"}}
+	if err := allowFields.check(operationsOf(parseQuery(`{ user version }`))[0]); err != nil {
+		t.Errorf("unexpected error %v", err)
+	}
+	if err := allowFields.check(operationsOf(parseQuery(`mutation { version }`))[0]); err == nil {
+		t.Error("expected mutation.version to be rejected")
+	}
+	if err := (&OperationFilter{AllowNames: []string{"Get*"}}).check(operationsOf(parseQuery(`{ user }`))[0]); err == nil || err.Message != "Anonymous operations are not allowed." {
+		t.Errorf("unexpected error %v", err)
+	}
+}
+
+func TestWithOperationFilter(t *testing.T) {
+	QueryResolvers["filterQuery"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	MutationResolvers["deleteAllFilterUsers"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("blocked mutation should not run")
+		return true, nil
+	}
+	defer delete(QueryResolvers, "filterQuery")
+	defer delete(MutationResolvers, "deleteAllFilterUsers")
+
+	h := newHandler([]HandlerOption{WithOperationFilter(OperationFilter{DenyFields: []string{"deleteAll*", "filterEvents"}})})
+	send := func(query string) string {
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`)))
+		return rr.Body.String()
+	}
+	if body := send("{ filterQuery }"); !strings.Contains(body, `"filterQuery":"ok"`) {
+		t.Errorf("unexpected query response %s", body)
+	}
+	if body := send("mutation { deleteAllFilterUsers }"); !strings.Contains(body, `"code":"FORBIDDEN_OPERATION"`) {
+		t.Errorf("unexpected mutation response %s", body)
+	}
+
+	conn := &messageConn{request: `{"query": "subscription { filterEvents }"}`}
+	h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.messages) != 1 || conn.messages[0] != `Field "filterEvents" is not allowed in a subscription.` {
+		t.Errorf("unexpected subscription messages %q", conn.messages)
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected a malformed pattern to panic")
+		}
+	}()
+	WithOperationFilter(OperationFilter{DenyNames: []string{"["}})
+}
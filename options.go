@@ -0,0 +1,401 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"mime"
+	"net/http"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+)
+
+// HandlerOption configures a handler created by NewHandler or
+// NewSubscriptionHandler.
+type HandlerOption func(*handler)
+
+// handler serves GraphQL requests over HTTP. The zero value behaves like
+// GraphqlHandler, GraphqlUploadHandler and SubscriptionHandler.
+type handler struct {
+	logger    RequestLogger
+	redact    RedactFunc
+	limiter   *rateLimiter
+	readOnly  *ReadOnlyMode
+	filter    *OperationFilter
+	limits    *OperationLimits
+	cache     *ResponseCache
+	audit     *AuditLog
+	usage     *UsageReporter
+	arenas    *sync.Pool
+	plans     *planCache
+	documents DocumentLoader
+
+	uploadMemory int64
+	upgrader     Upgrader
+	compression  *Compression
+	quota        *subscriptionQuota
+	metrics      *SubscriptionMetrics
+	operations   *OperationRegistry
+	contextFunc  ContextFunc
+	liveQueries  bool
+
+	requestIDHeader string
+	csrfHeaders     []string
+	cors            *CORS
+	debug           bool
+	tracePhases     bool
+	execLimit       *executionLimiter
+
+	costs     *rateLimiter
+	costLimit CostLimit
+}
+
+// defaultHandler backs the package-level handlers.
+var defaultHandler = &handler{}
+
+func newHandler(opts []HandlerOption) *handler {
+	h := &handler{}
+	for _, opt := range opts {
+		opt(h)
+	}
+	return h
+}
+
+// NewHandler returns a handler that serves GraphQL requests sent as JSON or
+// as multipart uploads, like GraphqlUploadHandler, configured by opts.
+func NewHandler(opts ...HandlerOption) http.Handler {
+	return http.HandlerFunc(newHandler(opts).serveUpload)
+}
+
+// NewSubscriptionHandler returns a handler that serves subscriptions over
+// WebSocket, like SubscriptionHandler, configured by opts.
+func NewSubscriptionHandler(opts ...HandlerOption) http.Handler {
+	return http.HandlerFunc(newHandler(opts).serveSubscription)
+}
+
+// ContextFunc derives the context a request is served in from the HTTP
+// request, for example to add the authenticated user, tenant or request ID
+// found in its headers.
+type ContextFunc func(ctx context.Context, r *http.Request) context.Context
+
+// WithContextFunc makes the handler serve each request in the context f
+// returns, so that the values f adds are available to resolvers, directives,
+// loggers and the other handler options through ctx. For subscriptions, f
+// runs before the connection is upgraded.
+func WithContextFunc(f ContextFunc) HandlerOption {
+	return func(h *handler) {
+		h.contextFunc = f
+	}
+}
+
+// withContext returns r with the context derived by the handler's
+// ContextFunc, if any.
+func (h *handler) withContext(r *http.Request) *http.Request {
+	if h.contextFunc == nil {
+		return r
+	}
+	return r.WithContext(h.contextFunc(r.Context(), r))
+}
+
+// execute parses and executes a request and writes the response to w.
+func (h *handler) execute(w http.ResponseWriter, r *http.Request, req graphqlRequest) {
+	start := time.Now()
+	r = h.withContext(h.withRequestID(w, r))
+	ctx := context.WithValue(r.Context(), requestKey{}, &req)
+	if h.audit != nil {
+		ctx = context.WithValue(ctx, auditLogKey{}, h.audit)
+	}
+	if h.debug {
+		ctx = context.WithValue(ctx, debugTraceKey{}, &debugTrace{start: start})
+	}
+	var phases *PhaseTimings
+	if h.tracePhases || h.logger != nil {
+		phases = &PhaseTimings{ReadBody: req.readBody}
+		ctx = context.WithValue(ctx, phasesKey{}, phases)
+	}
+	r = r.WithContext(withResponseHeader(WithResponseExtensions(ctx)))
+	r, release, ok := h.limitExecution(w, r)
+	if !ok {
+		return
+	}
+	defer release()
+	if missing := h.loadDocument(r.Context(), &req); missing != nil {
+		writeResponse(w, r, http.StatusOK, encodeResponse(map[string]interface{}{"errors": withRequestIDs(r.Context(), []*Error{missing})}))
+		return
+	}
+	query, variables := req.Query, req.Variables
+	status := http.StatusOK
+	var plan *executionPlan
+	var doc *Document
+	var errs []*Error
+	parseStart := time.Now()
+	if h.plans != nil {
+		// Cached documents outlive the request, so they are never
+		// allocated in an arena.
+		plan = h.plans.get(query, req.OperationName)
+		doc, errs = plan.doc, plan.parseErrors
+	} else {
+		arena := h.getArena()
+		if arena != nil {
+			defer h.putArena(arena)
+			r = r.WithContext(context.WithValue(r.Context(), arenaKey{}, arena))
+		}
+		doc, errs = parseRequestIn(query, arena)
+	}
+	if phases != nil {
+		phases.Parse = time.Since(parseStart)
+	}
+	op := selectOperation(doc, req.OperationName)
+	if len(errs) == 0 {
+		var rejected *Error
+		if rejected, status = h.admit(w, r, op); rejected != nil {
+			errs = []*Error{rejected}
+		}
+	}
+	var policy CachePolicy
+	var cacheKey string
+	if len(errs) == 0 && h.cache != nil {
+		policy = CachePolicyOf(CurrentSchema(), op)
+		cacheKey = h.cache.key(r, policy, query, req.OperationName, variables)
+		if cached, ok := h.cacheGet(r, cacheKey); ok {
+			if h.tracePhases {
+				SetResponseExtension(r.Context(), "phases", phases.extension())
+			}
+			h.observe(r, doc, op, variables, start, nil, nil)
+			w.Header().Set("Cache-Control", policy.CacheControl())
+			writeResponse(w, r, status, withExtensions(cached, responseExtensionsOf(r.Context())))
+			return
+		}
+	}
+
+	var result map[string]interface{}
+	var err error
+	if len(errs) > 0 {
+		result = map[string]interface{}{"errors": errs}
+	} else {
+		result, err = executePlanned(r.Context(), doc, plan, req.OperationName, variables)
+		if trace := debugTraceFrom(r.Context()); trace != nil {
+			SetResponseExtension(r.Context(), "debug", trace.extension())
+		}
+	}
+	if h.tracePhases {
+		SetResponseExtension(r.Context(), "phases", phases.extension())
+	}
+	if extensions := responseExtensionsOf(r.Context()); extensions != nil && result != nil {
+		result["extensions"] = extensions
+	}
+	if err != nil {
+		h.observe(r, doc, op, variables, start, result, err)
+		if errors.Is(err, context.Canceled) && r.Context().Err() != nil {
+			// The client went away; there is no one to respond to.
+			logger().DebugContext(r.Context(), "graphql: request cancelled", "error", err)
+			return
+		}
+		writeInternalError(w, r, err)
+		return
+	}
+	if h.cache != nil {
+		if _, failed := result["errors"]; failed {
+			policy = CachePolicy{}
+		} else if cacheKey != "" {
+			// Extensions describe this request rather than the data, so they
+			// are not cached.
+			data := map[string]interface{}{"data": result["data"]}
+			h.cache.Store.Set(r.Context(), cacheKey, encodeResponse(data), policy.MaxAge)
+		}
+		w.Header().Set("Cache-Control", policy.CacheControl())
+	}
+	if errs, ok := result["errors"].([]*Error); ok {
+		result["errors"] = withRequestIDs(r.Context(), errs)
+	}
+	serializeStart := time.Now()
+	body := encodeResponse(result)
+	if phases != nil {
+		phases.Serialize = time.Since(serializeStart)
+	}
+	h.observe(r, doc, op, variables, start, result, nil)
+	writeResponse(w, r, status, body)
+}
+
+func encodeResponse(result map[string]interface{}) []byte {
+	var buf bytes.Buffer
+	json.NewEncoder(&buf).Encode(result)
+	return buf.Bytes()
+}
+
+// withExtensions returns the encoded response body with its "extensions"
+// member set to extensions.
+func withExtensions(body []byte, extensions map[string]interface{}) []byte {
+	if extensions == nil {
+		return body
+	}
+	var result map[string]interface{}
+	dec := json.NewDecoder(bytes.NewReader(body))
+	dec.UseNumber()
+	if err := dec.Decode(&result); err != nil {
+		return body
+	}
+	result["extensions"] = extensions
+	return encodeResponse(result)
+}
+
+// observe reports a finished request executing op to the handler's logger,
+// usage reporter and operation registry.
+func (h *handler) observe(r *http.Request, doc *Document, op *OperationDefinition, variables map[string]interface{}, start time.Time, result map[string]interface{}, err error) {
+	if h.logger == nil && h.usage == nil && h.operations == nil {
+		return
+	}
+	entry := h.requestLog(doc, op, variables, time.Since(start), result, err)
+	entry.DocumentID = RequestDocumentID(r.Context())
+	entry.RequestID = RequestID(r.Context())
+	if phases := phasesFrom(r.Context()); phases != nil {
+		entry.Phases = *phases
+	}
+	if h.logger != nil {
+		h.logger(r.Context(), entry)
+	}
+	if h.usage != nil {
+		h.usage.record(doc, op, entry)
+	}
+	if h.operations != nil {
+		h.operations.record(doc, op, entry)
+	}
+}
+
+// cacheGet returns the cached response stored under key.
+func (h *handler) cacheGet(r *http.Request, key string) ([]byte, bool) {
+	if key == "" {
+		return nil, false
+	}
+	return h.cache.Store.Get(r.Context(), key)
+}
+
+// writeResponse writes a JSON response with the headers set by resolvers,
+// indented if requested. Successful responses to GET requests carry an ETag, and are answered with
+// 304 Not Modified when the client's If-None-Match header matches it.
+func writeResponse(w http.ResponseWriter, r *http.Request, status int, body []byte) {
+	writeResponseHeader(w, r)
+	if prettyRequested(r) {
+		body = indentJSON(body)
+	}
+	if r.Method == http.MethodGet && status == http.StatusOK {
+		etag := responseETag(body)
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+	}
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	w.Write(body)
+}
+
+// accept answers OPTIONS requests and rejects requests with an unsupported
+// method or content type, or that may be cross-site request forgeries. It
+// returns whether r should be served. multipart reports whether the handler
+// accepts multipart uploads.
+func (h *handler) accept(w http.ResponseWriter, r *http.Request, multipart bool) bool {
+	h.cors.writeHeaders(w, r)
+	switch r.Method {
+	case http.MethodOptions:
+		w.Header().Set("Allow", allowedMethods)
+		w.WriteHeader(http.StatusNoContent)
+		return false
+	case http.MethodGet, http.MethodPost:
+	default:
+		w.Header().Set("Allow", allowedMethods)
+		http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+		return false
+	}
+	if h.blockCSRF(w, r) {
+		return false
+	}
+	if r.Method == http.MethodPost && !supportedContentType(r.Header.Get("Content-Type"), multipart) {
+		msg := "unsupported content type; send application/json"
+		if multipart {
+			msg += " or multipart/form-data"
+		}
+		http.Error(w, msg, http.StatusUnsupportedMediaType)
+		return false
+	}
+	return true
+}
+
+// supportedContentType reports whether a POST body of contentType can be
+// served. Bodies without a content type are read as JSON.
+func supportedContentType(contentType string, multipart bool) bool {
+	if contentType == "" {
+		return true
+	}
+	mediaType, _, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return false
+	}
+	switch {
+	case mediaType == "application/json", strings.HasPrefix(mediaType, "application/") && strings.HasSuffix(mediaType, "+json"):
+		return true
+	case mediaType == "multipart/form-data":
+		return multipart
+	}
+	return false
+}
+
+// admit returns an error if op may not run along with the status to respond
+// with, setting any headers the error calls for.
+func (h *handler) admit(w http.ResponseWriter, r *http.Request, op *OperationDefinition) (*Error, int) {
+	if r.Method == http.MethodGet && op != nil && op.Operation != "query" {
+		w.Header().Set("Allow", http.MethodPost)
+		return &Error{Message: "Can only perform a query operation from a GET request."}, http.StatusMethodNotAllowed
+	}
+	if rejected := h.readOnly.check(op); rejected != nil {
+		return rejected, http.StatusOK
+	}
+	if rejected := h.filter.check(op); rejected != nil {
+		return rejected, http.StatusOK
+	}
+	if rejected := h.checkLimits(op); rejected != nil {
+		return rejected, http.StatusOK
+	}
+	if limited := h.rateLimit(r, op); limited != nil {
+		w.Header().Set("Retry-After", strconv.Itoa(limited.Extensions["retryAfter"].(int)))
+		return limited, http.StatusTooManyRequests
+	}
+	return h.chargeCost(w, r, op)
+}
+
+// operationsOf returns the operation definitions of doc, skipping type
+// system definitions.
+func operationsOf(doc *Document) []*OperationDefinition {
+	if doc == nil {
+		return nil
+	}
+	var ops []*OperationDefinition
+	for _, def := range doc.Definitions {
+		if op, ok := def.(*OperationDefinition); ok {
+			ops = append(ops, op)
+		}
+	}
+	return ops
+}
+
+// selectOperation returns the operation of doc named name or, if name is
+// empty, its only operation. It returns nil if there is no such operation.
+func selectOperation(doc *Document, name string) *OperationDefinition {
+	ops := operationsOf(doc)
+	if name == "" {
+		if len(ops) != 1 {
+			return nil
+		}
+		return ops[0]
+	}
+	for _, op := range ops {
+		if op.Name == name {
+			return op
+		}
+	}
+	return nil
+}
@@ -0,0 +1,44 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+type tenantKey struct{}
+
+func TestWithContextFunc(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Query": {"tenant": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return ctx.Value(tenantKey{}), nil
+		}},
+	})
+	useTestSchema(t, `type Query { tenant: String }`)
+	var logged interface{}
+	h := newHandler([]HandlerOption{
+		WithContextFunc(func(ctx context.Context, r *http.Request) context.Context {
+			return context.WithValue(ctx, tenantKey{}, r.Header.Get("X-Tenant"))
+		}),
+		WithLogger(func(ctx context.Context, entry RequestLog) { logged = ctx.Value(tenantKey{}) }),
+	})
+
+	req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ tenant }"}`))
+	req.Header.Set("X-Tenant", "acme")
+	rr := httptest.NewRecorder()
+	h.serveUpload(rr, req)
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"data":{"tenant":"acme"}}` {
+		t.Errorf("unexpected response %s", body)
+	}
+	if logged != "acme" {
+		t.Errorf("expected the logger to see the derived context, got %v", logged)
+	}
+
+	rr = httptest.NewRecorder()
+	h.serveGet(rr, httptest.NewRequest("GET", "/graphql?query=%7B+tenant+%7D", nil))
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"data":{"tenant":""}}` {
+		t.Errorf("unexpected GET response %s", body)
+	}
+}
@@ -0,0 +1,70 @@
+package vibeGraphql
+
+import (
+	"errors"
+	"reflect"
+	"sync"
+	"sync/atomic"
+)
+
+// ListConcurrency is the number of items of a list resolved at the same time
+// when the list has a selection set, such as the nested fields of a list of
+// users. Results keep the order of the list. The default of 1 resolves items
+// one after another; raise it only when the resolvers of the nested fields
+// are safe for concurrent use.
+var ListConcurrency = 1
+
+// resolveListParallel resolves ss on every item of list, a slice or array,
+// using up to ListConcurrency goroutines, including the calling one.
+func (e *executor) resolveListParallel(list reflect.Value, ss *SelectionSet) (interface{}, error) {
+	n := list.Len()
+	results := make([]interface{}, n)
+	forks := make([]*executor, n)
+	errs := make([]error, n)
+	workers := ListConcurrency
+	if workers > n {
+		workers = n
+	}
+	// The calling goroutine resolves items too, so that the list is still
+	// resolved when no further goroutine may be started.
+	next := int64(-1)
+	work := func() {
+		for i := int(atomic.AddInt64(&next, 1)); i < n; i = int(atomic.AddInt64(&next, 1)) {
+			fork := e.fork()
+			fork.path = append(fork.path, i)
+			forks[i] = fork
+			results[i], errs[i] = fork.resolveNestedSelection(list.Index(i).Interface(), ss)
+		}
+	}
+	var wg sync.WaitGroup
+	for w := 1; w < workers; w++ {
+		release, ok := goroutine(e.ctx)
+		if !ok {
+			break
+		}
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer release()
+			work()
+		}()
+	}
+	work()
+	wg.Wait()
+
+	for i := 0; i < n; i++ {
+		if errs[i] == nil {
+			e.errors = append(e.errors, forks[i].errors...)
+			continue
+		}
+		// As when resolving serially, a GraphQL error nulls only the
+		// affected item, while any other error fails the whole list.
+		var gqlErr *Error
+		if !errors.As(errs[i], &gqlErr) {
+			return nil, forks[i].failed(errs[i])
+		}
+		e.errors = append(e.errors, forks[i].errors...)
+		e.errors = append(e.errors, forks[i].located(gqlErr))
+	}
+	return results, nil
+}
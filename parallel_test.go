@@ -0,0 +1,68 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestListConcurrency(t *testing.T) {
+	type user struct{ ID int }
+	users := make([]*user, 20)
+	for i := range users {
+		users[i] = &user{ID: i}
+	}
+	var mu sync.Mutex
+	running, maxRunning := 0, 0
+	useResolvers(t, map[string]ResolverFunc{
+		"users": func(source interface{}, args map[string]interface{}) (interface{}, error) { return users, nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"user": {"name": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			mu.Lock()
+			running++
+			if running > maxRunning {
+				maxRunning = running
+			}
+			mu.Unlock()
+			time.Sleep(5 * time.Millisecond)
+			mu.Lock()
+			running--
+			mu.Unlock()
+			id := source.(*user).ID
+			if id%5 == 0 {
+				return nil, NewError("NOT_FOUND", fmt.Sprintf("no name for %d", id))
+			}
+			return fmt.Sprint("user", id), nil
+		}},
+	})
+	defer func(prev int) { ListConcurrency = prev }(ListConcurrency)
+
+	ListConcurrency = 1
+	serial, err := executeRequest(context.Background(), `{ users { name } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning != 1 {
+		t.Errorf("expected serial resolution, got %d at once", maxRunning)
+	}
+
+	ListConcurrency = 4
+	maxRunning = 0
+	parallel, err := executeRequest(context.Background(), `{ users { name } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if maxRunning < 2 || maxRunning > 4 {
+		t.Errorf("expected up to 4 items at once, got %d", maxRunning)
+	}
+	if !reflect.DeepEqual(serial, parallel) {
+		t.Errorf("parallel result differs:\n%v\n%v", serial, parallel)
+	}
+	errs := parallel["errors"].([]*Error)
+	if len(errs) != 4 || errs[1].Message != "no name for 5" {
+		t.Errorf("unexpected errors %+v", errs)
+	}
+}
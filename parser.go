@@ -1,74 +1,172 @@
 package vibeGraphql
 
+import "fmt"
+
+// Default parser limits, applied to every Parser unless overridden with
+// WithMaxTokens or WithMaxDepth. They protect servers against adversarial
+// documents; zero disables a limit.
+var (
+	DefaultMaxTokens = 100000
+	DefaultMaxDepth  = 100
+)
+
+// Parser builds a Document from the tokens of a Lexer. It records syntax
+// errors, located in the input, instead of stopping at the first one; see
+// Errors.
 type Parser struct {
 	l         *Lexer
 	curToken  Token
 	peekToken Token
+
+	maxTokens int
+	maxDepth  int
+	tokens    int
+	depth     int
+	halted    bool
+	errors    []*Error
+	arena     *Arena
+	fragments bool // whether the document has fragments to link
+}
+
+// ParserOption configures a Parser.
+type ParserOption func(*Parser)
+
+// WithMaxTokens limits the number of tokens a document may contain.
+func WithMaxTokens(n int) ParserOption {
+	return func(p *Parser) { p.maxTokens = n }
 }
 
-func NewParser(l *Lexer) *Parser {
-	p := &Parser{l: l}
+// WithMaxDepth limits how deeply selection sets, values and list types may nest.
+func WithMaxDepth(n int) ParserOption {
+	return func(p *Parser) { p.maxDepth = n }
+}
+
+// NewParser returns a parser reading tokens from l, limited by
+// DefaultMaxTokens and DefaultMaxDepth unless opts say otherwise.
+func NewParser(l *Lexer, opts ...ParserOption) *Parser {
+	p := &Parser{l: l, maxTokens: DefaultMaxTokens, maxDepth: DefaultMaxDepth}
+	for _, opt := range opts {
+		opt(p)
+	}
 	// initialize two tokens
 	p.nextToken()
 	p.nextToken()
 	return p
 }
 
+// reset prepares a pooled parser, and its lexer, to parse input with the
+// default limits, allocating nodes in arena if it is not nil.
+func (p *Parser) reset(input string, arena *Arena) {
+	l := p.l
+	l.reset(input)
+	*p = Parser{l: l, maxTokens: DefaultMaxTokens, maxDepth: DefaultMaxDepth, arena: arena}
+	p.nextToken()
+	p.nextToken()
+}
+
+// Errors returns the syntax errors found while parsing, in the order they
+// were found, each with the location of the token it was found at.
+func (p *Parser) Errors() []*Error {
+	return p.errors
+}
+
+// CurrentToken returns the token the parser is looking at: before parsing,
+// the first token of the input; after ParseDocument, EOF.
+func (p *Parser) CurrentToken() Token {
+	return p.curToken
+}
+
+// PeekToken returns the token after CurrentToken.
+func (p *Parser) PeekToken() Token {
+	return p.peekToken
+}
+
 func (p *Parser) nextToken() {
+	if p.halted {
+		return
+	}
 	p.curToken = p.peekToken
 	p.peekToken = p.l.NextToken()
-}
-
-func (p *Parser) ParseDocument() *Document {
-	doc := &Document{}
-	for p.curToken.Type != EOF {
-		def := p.parseDefinition()
-		if def != nil {
-			doc.Definitions = append(doc.Definitions, def)
+	if p.curToken.Message != "" {
+		p.halt("Syntax Error: %s.", p.curToken.Message)
+		return
+	}
+	if p.peekToken.Type != EOF {
+		p.tokens++
+		if p.maxTokens > 0 && p.tokens > p.maxTokens {
+			p.halt("Syntax Error: Document contains more than %d tokens.", p.maxTokens)
 		}
-		// Optionally, only advance if parseDefinition did not advance.
-		// (Remove or comment out the following unconditional advancement.)
-		// if p.curToken.Type != EOF {
-		//     p.nextToken()
-		// }
 	}
-	return doc
 }
 
-// In parser.go, add:
+// halt records a fatal error and stops parsing by moving to the end of input.
+// Once halted, only the first fatal error is recorded.
+func (p *Parser) halt(format string, args ...interface{}) {
+	if p.halted {
+		return
+	}
+	p.errorf(format, args...)
+	p.halted = true
+	p.curToken = Token{Type: EOF}
+	p.peekToken = Token{Type: EOF}
+}
 
-func (p *Parser) parseTypeDefinition() Definition {
-	// Assume the current token is "type"
-	p.nextToken() // Skip "type"
-	if p.curToken.Type != IDENT {
-		// Error handling could go here.
-		return nil
+// expect moves past the current token if it has type t, and otherwise halts
+// with a syntax error saying what was expected instead.
+func (p *Parser) expect(t TokenType, what string) bool {
+	if p.curToken.Type != t {
+		p.halt("Syntax Error: Expected %s, found %s.", what, describeToken(p.curToken))
+		return false
 	}
-	typeName := p.curToken.Literal
-	p.nextToken() // Move past type name
+	p.nextToken()
+	return true
+}
 
-	// Expect an opening brace
-	if p.curToken.Type != LBRACE {
-		return nil // or handle error
+// errorf records a syntax error at the current token.
+func (p *Parser) errorf(format string, args ...interface{}) {
+	p.errors = append(p.errors, &Error{
+		Message:   fmt.Sprintf(format, args...),
+		Locations: []Location{p.locate(p.curToken.Start)},
+	})
+}
+
+// locate returns the location of offset in the input. Offsets must be located
+// in increasing order.
+func (p *Parser) locate(offset int) Location {
+	return p.l.locate(offset)
+}
+
+// enter increases the nesting depth, halting when it exceeds the limit.
+// Every successful enter must be paired with leave.
+func (p *Parser) enter() bool {
+	p.depth++
+	if p.maxDepth > 0 && p.depth > p.maxDepth {
+		p.depth--
+		p.halt("Syntax Error: Document is nested more than %d levels deep.", p.maxDepth)
+		return false
 	}
-	p.nextToken() // Skip '{'
+	return true
+}
 
-	var fields []*Field
-	for p.curToken.Type != RBRACE && p.curToken.Type != EOF {
-		field := p.parseField()
-		if field != nil {
-			fields = append(fields, field)
-		}
-		if p.curToken.Type == COMMA {
-			p.nextToken()
+func (p *Parser) leave() {
+	p.depth--
+}
+
+func (p *Parser) ParseDocument() *Document {
+	doc := &Document{}
+	for p.curToken.Type != EOF {
+		def := p.parseDefinition()
+		if def != nil {
+			doc.Definitions = append(doc.Definitions, def)
 		}
 	}
-	p.nextToken() // Skip '}'
-
-	return &TypeDefinition{
-		Name:   typeName,
-		Fields: fields,
+	if err := p.l.Err(); err != nil {
+		p.errors = append(p.errors, &Error{Message: "Could not read the document: " + err.Error()})
+	}
+	if p.fragments {
+		linkFragments(doc)
 	}
+	return doc
 }
 
 func (p *Parser) skipParenBlock() {
@@ -98,110 +196,210 @@ func (p *Parser) parseDefinition() Definition {
 	if p.curToken.Type == LBRACE {
 		return p.parseOperationDefinition()
 	}
-	// When a "type" keyword is encountered, use skipTypeDefinition to parse it.
-	if p.curToken.Literal == "type" {
-		return p.skipTypeDefinition()
+	if p.curToken.Literal == "fragment" {
+		return p.parseFragmentDefinition()
 	}
-	// If the token isn't recognized, advance and return nil.
-	p.nextToken()
+	if p.curToken.Literal == "extend" {
+		return p.parseTypeExtension()
+	}
+	// Type system definitions, optionally preceded by a description.
+	description := p.parseDescription()
+	if _, ok := typeDefinitionKinds[p.curToken.Literal]; ok {
+		return p.parseTypeDefinition(description)
+	}
+	if p.curToken.Literal == "directive" {
+		return p.parseDirectiveDefinition(description)
+	}
+	// Anything else, including tokens left over after the last definition,
+	// is an error.
+	p.halt("Syntax Error: Unexpected %s.", describeToken(p.curToken))
 	return nil
 }
 
-// skipTypeAnnotation assumes the current token is COLON and skips a type annotation.
-// It handles simple and list types.
-func (p *Parser) skipTypeAnnotation() {
-	if p.curToken.Type != COLON {
-		return
+// typeDefinitionKinds maps SDL keywords to the kind of type they define.
+var typeDefinitionKinds = map[string]string{
+	"type":      KindObject,
+	"interface": KindInterface,
+	"union":     KindUnion,
+	"enum":      KindEnum,
+	"input":     KindInputObject,
+	"scalar":    KindScalar,
+}
+
+// parseTypeDefinition parses a type system definition such as
+// "type User implements Node { ... }", "enum Role { ... }" or "union U = A | B".
+func (p *Parser) parseTypeDefinition(description string) Definition {
+	kind := typeDefinitionKinds[p.curToken.Literal]
+	p.nextToken() // Skip the keyword.
+	if p.curToken.Type != IDENT {
+		p.halt("Syntax Error: Expected a type name, found %s.", describeToken(p.curToken))
+		return nil
 	}
-	p.nextToken() // Skip the colon
+	td := &TypeDefinition{Kind: kind, Name: p.curToken.Literal, Description: description}
+	p.nextToken() // Move past the type name.
 
-	// Check for list type: if the annotation starts with '['.
-	if p.curToken.Type == LBRACKET {
-		// Consume '['
+	if p.curToken.Type == IDENT && p.curToken.Literal == "implements" {
 		p.nextToken()
-		// Consume the inner type (which we assume is a simple type)
-		if p.curToken.Type == IDENT {
+		for p.curToken.Type == IDENT || p.curToken.Type == AMP {
+			if p.curToken.Type == IDENT {
+				td.Interfaces = append(td.Interfaces, p.curToken.Literal)
+			}
 			p.nextToken()
-			// Optionally skip a trailing BANG for the inner type.
-			if p.curToken.Type == BANG {
+		}
+	}
+	td.Directives = p.parseDirectives()
+
+	switch kind {
+	case KindScalar:
+		return td
+	case KindUnion:
+		if p.curToken.Type == ASSIGN {
+			p.nextToken()
+			if p.curToken.Type == PIPE {
+				p.nextToken()
+			}
+			for p.curToken.Type == IDENT {
+				td.Types = append(td.Types, p.curToken.Literal)
+				p.nextToken()
+				if p.curToken.Type != PIPE {
+					break
+				}
+				p.nextToken()
+			}
+		}
+		return td
+	}
+
+	// Expect an opening brace.
+	if p.curToken.Type != LBRACE {
+		return td
+	}
+	p.nextToken() // Skip '{'
+
+	for p.curToken.Type != RBRACE && p.curToken.Type != EOF {
+		progressed := false
+		switch kind {
+		case KindEnum:
+			description := p.parseDescription()
+			if p.curToken.Type == IDENT {
+				value := &EnumValueDefinition{Name: p.curToken.Literal, Description: description}
 				p.nextToken()
+				value.Directives = p.parseDirectives()
+				td.EnumValues = append(td.EnumValues, value)
+				progressed = true
+			}
+		case KindInputObject:
+			if input := p.parseInputValueDefinition(); input != nil {
+				td.InputFields = append(td.InputFields, input)
+				progressed = true
+			}
+		default:
+			if field := p.parseTypeField(); field != nil {
+				td.Fields = append(td.Fields, field)
+				progressed = true
 			}
 		}
-		// Expect a closing bracket.
-		if p.curToken.Type == RBRACKET {
+		if !progressed {
+			p.errorf("Syntax Error: Unexpected %s in definition of %s.", describeToken(p.curToken), td.Name)
 			p.nextToken()
 		}
-		// Optionally, skip a trailing BANG for the list type.
-		if p.curToken.Type == BANG {
+		if p.curToken.Type == COMMA {
 			p.nextToken()
 		}
-		return
 	}
+	p.expect(RBRACE, `"}"`)
+	return td
+}
 
-	// Otherwise, assume a simple type: one IDENT, optionally followed by a BANG.
-	if p.curToken.Type == IDENT {
+// parseTypeExtension parses a type extension such as
+// "extend type Query { users: [User] }".
+func (p *Parser) parseTypeExtension() Definition {
+	p.nextToken() // Skip "extend"
+	if _, ok := typeDefinitionKinds[p.curToken.Literal]; !ok {
+		p.errorf("Syntax Error: Unexpected %s after \"extend\".", describeToken(p.curToken))
 		p.nextToken()
+		return nil
 	}
-	if p.curToken.Type == BANG {
-		p.nextToken()
+	def := p.parseTypeDefinition("")
+	if td, ok := def.(*TypeDefinition); ok {
+		td.Extension = true
 	}
+	return def
 }
 
-// skipTypeDefinition advances the parser past a type definition block.
-func (p *Parser) skipTypeDefinition() Definition {
-	// Skip the "type" keyword.
-	p.nextToken()
-	if p.curToken.Type != IDENT {
-		return nil // Expected a type name.
+// parseDirectiveDefinition parses a definition such as
+// "directive @auth(role: String!) repeatable on FIELD_DEFINITION | OBJECT".
+func (p *Parser) parseDirectiveDefinition(description string) Definition {
+	p.nextToken() // Skip "directive"
+	if p.curToken.Type != AT {
+		p.errorf("Syntax Error: Expected \"@\", found %s.", describeToken(p.curToken))
+		return nil
 	}
-	typeName := p.curToken.Literal
-	p.nextToken() // Move past the type name.
-
-	// Expect an opening brace.
-	if p.curToken.Type != LBRACE {
+	p.nextToken() // Skip '@'
+	if p.curToken.Type != IDENT {
+		p.errorf("Syntax Error: Expected a directive name, found %s.", describeToken(p.curToken))
 		return nil
 	}
-	p.nextToken() // Skip '{'
-
-	var fields []*Field
-	iterations := 0
-	maxIterations := 10000 // safeguard
-	for p.curToken.Type != RBRACE && p.curToken.Type != EOF {
-		iterations++
-		if iterations > maxIterations {
-			break
+	dd := &DirectiveDefinition{Name: p.curToken.Literal, Description: description}
+	p.nextToken()
+	if p.curToken.Type == LPAREN {
+		p.nextToken() // Skip '('
+		for p.curToken.Type != RPAREN && p.curToken.Type != EOF {
+			arg := p.parseInputValueDefinition()
+			if arg == nil {
+				p.nextToken()
+				continue
+			}
+			dd.Arguments = append(dd.Arguments, arg)
+			if p.curToken.Type == COMMA {
+				p.nextToken()
+			}
 		}
-		field := p.parseTypeField()
-		if field != nil {
-			fields = append(fields, field)
-		} else {
-			// Advance token to ensure progress.
-			p.nextToken()
+		p.expect(RPAREN, `")"`)
+	}
+	if p.curToken.Type == IDENT && p.curToken.Literal == "repeatable" {
+		dd.Repeatable = true
+		p.nextToken()
+	}
+	if p.curToken.Type != IDENT || p.curToken.Literal != "on" {
+		p.errorf("Syntax Error: Expected \"on\", found %s.", describeToken(p.curToken))
+		return nil
+	}
+	p.nextToken() // Skip "on"
+	if p.curToken.Type == PIPE {
+		p.nextToken()
+	}
+	for {
+		if p.curToken.Type != IDENT || !directiveLocations[p.curToken.Literal] {
+			p.errorf("Syntax Error: Expected a directive location, found %s.", describeToken(p.curToken))
+			return nil
 		}
-		if p.curToken.Type == COMMA {
-			p.nextToken()
+		dd.Locations = append(dd.Locations, p.curToken.Literal)
+		p.nextToken()
+		if p.curToken.Type != PIPE {
+			break
 		}
+		p.nextToken()
 	}
-	if p.curToken.Type == RBRACE {
-		p.nextToken() // Skip the closing brace.
-	}
-	return &TypeDefinition{
-		Name:   typeName,
-		Fields: fields,
+	return dd
+}
+
+// parseDescription parses the string or block string describing the
+// definition that follows it, returning "" if there is none.
+func (p *Parser) parseDescription() string {
+	if p.curToken.Type != STRING {
+		return ""
 	}
+	description := p.curToken.Literal
+	p.nextToken()
+	return description
 }
 
 // skipBlock skips over a block delimited by '{' and '}'.
 func (p *Parser) skipBlock() {
 	// Assume the current token is LBRACE.
 	depth := 0
-	iterations := 0
-	maxIterations := 10000 // safeguard to prevent infinite loop
 	for p.curToken.Type != EOF {
-		if iterations > maxIterations {
-			// Break out if we've looped too many times.
-			break
-		}
 		if p.curToken.Type == LBRACE {
 			depth++
 		} else if p.curToken.Type == RBRACE {
@@ -212,12 +410,11 @@ func (p *Parser) skipBlock() {
 			}
 		}
 		p.nextToken()
-		iterations++
 	}
 }
 
 func (p *Parser) parseOperationDefinition() *OperationDefinition {
-	op := &OperationDefinition{}
+	op := &OperationDefinition{Loc: p.locate(p.curToken.Start)}
 	if p.curToken.Literal == "query" ||
 		p.curToken.Literal == "mutation" ||
 		p.curToken.Literal == "subscription" {
@@ -230,96 +427,252 @@ func (p *Parser) parseOperationDefinition() *OperationDefinition {
 		if p.curToken.Type == LPAREN {
 			op.VariableDefinitions = p.parseVariableDefinitions()
 		}
+		op.Directives = p.parseDirectives()
 	} else {
 		op.Operation = "query"
 	}
-	if p.curToken.Type == LBRACE {
-		op.SelectionSet = p.parseSelectionSet()
+	if p.curToken.Type != LBRACE {
+		p.halt("Syntax Error: Expected \"{\", found %s.", describeToken(p.curToken))
+		return op
 	}
+	op.SelectionSet = p.parseSelectionSet()
 	return op
 }
 
 func (p *Parser) parseTypeField() *Field {
+	description := p.parseDescription()
 	// Expect an IDENT for the field name.
 	if p.curToken.Type != IDENT {
 		return nil
 	}
 	field := &Field{
-		Name: p.curToken.Literal,
+		Name:        p.curToken.Literal,
+		Description: description,
 	}
 	p.nextToken() // Consume the field name
 
-	// If there's an argument list, skip it.
+	// Parse the argument definitions, if any.
 	if p.curToken.Type == LPAREN {
-		p.skipParenBlock()
+		p.nextToken() // Skip '('
+		for p.curToken.Type != RPAREN && p.curToken.Type != EOF {
+			arg := p.parseInputValueDefinition()
+			if arg == nil {
+				p.nextToken()
+				continue
+			}
+			field.ArgumentDefinitions = append(field.ArgumentDefinitions, arg)
+			if p.curToken.Type == COMMA {
+				p.nextToken()
+			}
+		}
+		if !p.expect(RPAREN, `")"`) {
+			return field
+		}
 	}
 
-	// If a colon is present, skip the type annotation.
-	if p.curToken.Type == COLON {
-		p.skipTypeAnnotation()
+	// Parse the type annotation.
+	if !p.expect(COLON, `":"`) {
+		return field
 	}
+	field.Type = p.parseType()
+	field.Directives = p.parseDirectives()
 	return field
 }
 
+// parseInputValueDefinition parses an argument or input field definition
+// such as "limit: Int = 10 @deprecated", optionally preceded by a description.
+func (p *Parser) parseInputValueDefinition() *InputValueDefinition {
+	description := p.parseDescription()
+	if p.curToken.Type != IDENT {
+		return nil
+	}
+	input := &InputValueDefinition{Name: p.curToken.Literal, Description: description}
+	p.nextToken()
+	if !p.expect(COLON, `":"`) {
+		return input
+	}
+	input.Type = p.parseType()
+	if p.curToken.Type == ASSIGN {
+		p.nextToken()
+		input.DefaultValue = p.parseValue()
+	}
+	input.Directives = p.parseDirectives()
+	return input
+}
+
+// parseDirectives parses zero or more directive usages such as @deprecated(reason: "x").
+func (p *Parser) parseDirectives() []Directive {
+	var directives []Directive
+	for p.curToken.Type == AT {
+		loc := p.locate(p.curToken.Start)
+		p.nextToken() // Skip '@'
+		if p.curToken.Type != IDENT {
+			p.halt("Syntax Error: Expected a directive name, found %s.", describeToken(p.curToken))
+			break
+		}
+		d := Directive{Name: p.curToken.Literal, Loc: loc}
+		p.nextToken()
+		if p.curToken.Type == LPAREN {
+			d.Arguments = p.parseArguments()
+		}
+		directives = append(directives, d)
+	}
+	return directives
+}
+
 func (p *Parser) parseVariableDefinitions() []VariableDefinition {
 	var vars []VariableDefinition
 	p.nextToken() // Skip '('
 	for p.curToken.Type != RPAREN && p.curToken.Type != EOF {
-		if p.curToken.Type == DOLLAR {
-			p.nextToken() // Skip '$'
-			if p.curToken.Type != IDENT {
-				return vars
-			}
-			varDef := VariableDefinition{}
-			varDef.Variable = p.curToken.Literal
-			p.nextToken()
-			if p.curToken.Type == COLON {
-				p.nextToken()
-				typeParsed := p.parseType()
-				if typeParsed != nil {
-					varDef.Type = *typeParsed
-				}
-			}
-			vars = append(vars, varDef)
+		if p.curToken.Type != DOLLAR {
+			p.halt("Syntax Error: Expected \"$\" or \")\", found %s.", describeToken(p.curToken))
+			return vars
+		}
+		loc := p.locate(p.curToken.Start)
+		p.nextToken() // Skip '$'
+		if p.curToken.Type != IDENT {
+			p.halt("Syntax Error: Expected a variable name, found %s.", describeToken(p.curToken))
+			return vars
+		}
+		varDef := VariableDefinition{Loc: loc}
+		varDef.Variable = p.curToken.Literal
+		p.nextToken()
+		if !p.expect(COLON, `":"`) {
+			return vars
 		}
+		typeParsed := p.parseType()
+		if typeParsed == nil {
+			return vars
+		}
+		varDef.Type = *typeParsed
+		vars = append(vars, varDef)
 		if p.curToken.Type == COMMA {
 			p.nextToken()
 		}
 	}
-	p.nextToken() // Skip ')'
+	p.expect(RPAREN, `")"`)
 	return vars
 }
 
 func (p *Parser) parseSelectionSet() *SelectionSet {
-	ss := &SelectionSet{}
+	ss := p.newSelectionSet()
+	if !p.enter() {
+		return ss
+	}
+	defer p.leave()
 	p.nextToken() // skip '{'
 	for p.curToken.Type != RBRACE && p.curToken.Type != EOF {
 		sel := p.parseSelection()
 		if sel != nil {
 			ss.Selections = append(ss.Selections, sel)
+		} else {
+			p.errorf("Syntax Error: Unexpected %s in selection set.", describeToken(p.curToken))
+			p.nextToken()
 		}
 		if p.curToken.Type == COMMA {
 			p.nextToken()
 		}
 	}
-	p.nextToken() // skip '}'
+	p.expect(RBRACE, `"}"`)
 	return ss
 }
 
 func (p *Parser) parseSelection() Selection {
-	return p.parseField()
+	if p.curToken.Type == SPREAD {
+		return p.parseFragment()
+	}
+	if field := p.parseField(); field != nil {
+		return field
+	}
+	return nil
+}
+
+// parseFragmentDefinition parses a fragment definition such as
+// "fragment userFields on User { id name }".
+func (p *Parser) parseFragmentDefinition() Definition {
+	def := &FragmentDefinition{Loc: p.locate(p.curToken.Start)}
+	p.nextToken() // Skip "fragment"
+	if p.curToken.Type != IDENT || p.curToken.Literal == "on" {
+		p.errorf("Syntax Error: Expected a fragment name, found %s.", describeToken(p.curToken))
+		return nil
+	}
+	def.Name = p.curToken.Literal
+	p.nextToken()
+	if p.curToken.Type != IDENT || p.curToken.Literal != "on" {
+		p.errorf("Syntax Error: Expected \"on\", found %s.", describeToken(p.curToken))
+		return nil
+	}
+	p.nextToken() // Skip "on"
+	if p.curToken.Type != IDENT {
+		p.errorf("Syntax Error: Expected a type name, found %s.", describeToken(p.curToken))
+		return nil
+	}
+	def.TypeCondition = p.curToken.Literal
+	p.nextToken()
+	def.Directives = p.parseDirectives()
+	if p.curToken.Type != LBRACE {
+		p.errorf("Syntax Error: Expected \"{\", found %s.", describeToken(p.curToken))
+		return nil
+	}
+	def.SelectionSet = p.parseSelectionSet()
+	p.fragments = true
+	return def
+}
+
+// parseFragment parses a fragment spread such as "...userFields" or an
+// inline fragment such as "... on User { name }".
+func (p *Parser) parseFragment() Selection {
+	loc := p.locate(p.curToken.Start)
+	p.nextToken() // Skip "..."
+	if p.curToken.Type == IDENT && p.curToken.Literal != "on" {
+		spread := &FragmentSpread{Name: p.curToken.Literal, Loc: loc}
+		p.nextToken()
+		spread.Directives = p.parseDirectives()
+		p.fragments = true
+		return spread
+	}
+	fragment := &InlineFragment{Loc: loc}
+	if p.curToken.Type == IDENT {
+		p.nextToken() // Skip "on"
+		if p.curToken.Type != IDENT {
+			p.errorf("Syntax Error: Expected a type name, found %s.", describeToken(p.curToken))
+			return fragment
+		}
+		fragment.TypeCondition = p.curToken.Literal
+		p.nextToken()
+	}
+	fragment.Directives = p.parseDirectives()
+	if p.curToken.Type != LBRACE {
+		p.errorf("Syntax Error: Expected \"{\", found %s.", describeToken(p.curToken))
+		return fragment
+	}
+	fragment.SelectionSet = p.parseSelectionSet()
+	return fragment
 }
 
 func (p *Parser) parseField() *Field {
-	field := &Field{}
 	if p.curToken.Type != IDENT {
 		return nil
 	}
+	field := p.newField()
 	field.Name = p.curToken.Literal
+	field.Loc = p.locate(p.curToken.Start)
 	p.nextToken()
+	if p.curToken.Type == COLON {
+		// "alias: name"
+		p.nextToken()
+		if p.curToken.Type != IDENT {
+			p.errorf("Syntax Error: Expected a field name after alias %q, found %s.", field.Name, describeToken(p.curToken))
+			return field
+		}
+		field.Alias = field.Name
+		field.Name = p.curToken.Literal
+		p.nextToken()
+	}
 	if p.curToken.Type == LPAREN {
 		field.Arguments = p.parseArguments()
 	}
+	field.Directives = p.parseDirectives()
 	if p.curToken.Type == LBRACE {
 		field.SelectionSet = p.parseSelectionSet()
 	}
@@ -329,44 +682,50 @@ func (p *Parser) parseField() *Field {
 func (p *Parser) parseArguments() []Argument {
 	var args []Argument
 	p.nextToken() // skip '('
-	for p.curToken.Type != RPAREN && p.curToken.Type != EOF {
+	for !closing(p.curToken.Type) {
 		arg := Argument{}
 		if p.curToken.Type == IDENT {
 			arg.Name = p.curToken.Literal
 			p.nextToken()
-			if p.curToken.Type == COLON {
-				p.nextToken()
-				arg.Value = p.parseValue()
+			if !p.expect(COLON, `":"`) {
+				return args
 			}
+			arg.Value = p.parseValue()
 			args = append(args, arg)
+		} else if p.curToken.Type != COMMA {
+			p.errorf("Syntax Error: Unexpected %s in arguments.", describeToken(p.curToken))
+			p.nextToken()
 		}
 		if p.curToken.Type == COMMA {
 			p.nextToken()
 		}
 	}
-	p.nextToken() // skip ')'
+	p.expect(RPAREN, `")"`)
 	return args
 }
 
 // parseObject parses a GraphQL object literal.
 // It assumes the current token is the opening '{'.
 func (p *Parser) parseObject() *Value {
+	if !p.enter() {
+		return p.newValue(Value{Kind: "Illegal", Literal: "nesting limit exceeded"})
+	}
+	defer p.leave()
 	objFields := make(map[string]*Value)
 	// Skip the '{'
 	p.nextToken()
-	for p.curToken.Type != RBRACE && p.curToken.Type != EOF {
+	for !closing(p.curToken.Type) {
 		// Expect a field name (identifier) for the key.
 		if p.curToken.Type != IDENT {
-			// Error handling can be improved.
-			return &Value{Kind: "Illegal", Literal: "expected object key"}
+			p.halt("Syntax Error: Expected an object field name, found %s.", describeToken(p.curToken))
+			return p.newValue(Value{Kind: "Illegal", Literal: "expected object key"})
 		}
 		key := p.curToken.Literal
 		p.nextToken()
 		// Expect a colon.
-		if p.curToken.Type != COLON {
-			return &Value{Kind: "Illegal", Literal: "expected colon in object"}
+		if !p.expect(COLON, `":"`) {
+			return p.newValue(Value{Kind: "Illegal", Literal: "expected colon in object"})
 		}
-		p.nextToken() // skip colon
 		// Parse the value recursively.
 		value := p.parseValue()
 		objFields[key] = value
@@ -375,26 +734,29 @@ func (p *Parser) parseObject() *Value {
 			p.nextToken()
 		}
 	}
-	// Skip the closing '}'
-	p.nextToken()
-	return &Value{
+	p.expect(RBRACE, `"}"`)
+	return p.newValue(Value{
 		Kind:         "Object",
 		ObjectFields: objFields,
-	}
+	})
 }
 
 func (p *Parser) parseArray() *Value {
+	if !p.enter() {
+		return p.newValue(Value{Kind: "Illegal", Literal: "nesting limit exceeded"})
+	}
+	defer p.leave()
 	arr := []*Value{}
 	p.nextToken() // skip '['
-	for p.curToken.Type != RBRACKET && p.curToken.Type != EOF {
+	for !closing(p.curToken.Type) {
 		val := p.parseValue()
 		arr = append(arr, val)
 		if p.curToken.Type == COMMA {
 			p.nextToken()
 		}
 	}
-	p.nextToken() // skip ']'
-	return &Value{Kind: "Array", List: arr}
+	p.expect(RBRACKET, `"]"`)
+	return p.newValue(Value{Kind: "Array", List: arr})
 }
 
 // Update parseValue to handle objects.
@@ -407,12 +769,16 @@ func (p *Parser) parseValue() *Value {
 		return p.parseArray()
 	}
 
-	val := &Value{}
+	val := p.newValue(Value{})
 	switch p.curToken.Type {
 	case INT:
 		val.Kind = "Int"
 		val.Literal = p.curToken.Literal
 		p.nextToken()
+	case FLOAT:
+		val.Kind = "Float"
+		val.Literal = p.curToken.Literal
+		p.nextToken()
 	case STRING:
 		val.Kind = "String"
 		val.Literal = p.curToken.Literal
@@ -436,12 +802,13 @@ func (p *Parser) parseValue() *Value {
 			// No identifier after '$'; mark as a variable with an empty literal.
 			val.Kind = "Variable"
 			val.Literal = ""
+			p.halt("Syntax Error: Expected a variable name, found %s.", describeToken(p.curToken))
 		}
 
 	default:
 		val.Kind = "Illegal"
 		val.Literal = p.curToken.Literal
-		p.nextToken()
+		p.halt("Syntax Error: Expected a value, found %s.", describeToken(p.curToken))
 	}
 	return val
 }
@@ -450,13 +817,16 @@ func (p *Parser) parseType() *Type {
 	var t Type
 	if p.curToken.Type == LBRACKET {
 		// This is a list type.
+		if !p.enter() {
+			return nil
+		}
+		defer p.leave()
 		p.nextToken()              // Skip '['
 		innerType := p.parseType() // Recursively parse the inner type.
-		t = Type{IsList: true, Elem: innerType}
-		if p.curToken.Type != RBRACKET {
-			// Handle error: expected closing bracket.
+		if innerType == nil || !p.expect(RBRACKET, `"]"`) {
+			return nil
 		}
-		p.nextToken() // Skip ']'
+		t = Type{IsList: true, Elem: innerType}
 		// Check for non-null on the list type.
 		if p.curToken.Type == BANG {
 			t.NonNull = true
@@ -474,5 +844,25 @@ func (p *Parser) parseType() *Type {
 		}
 		return &t
 	}
+	p.halt("Syntax Error: Expected a type, found %s.", describeToken(p.curToken))
 	return nil
 }
+
+// closing reports whether t ends a block, so that a list or object closed
+// with the wrong token reports the token it was missing.
+func closing(t TokenType) bool {
+	return t == RBRACE || t == RBRACKET || t == RPAREN || t == EOF
+}
+
+// describeToken formats a token for syntax error messages.
+func describeToken(tok Token) string {
+	switch tok.Type {
+	case EOF:
+		return "<EOF>"
+	case IDENT, INT, FLOAT:
+		return fmt.Sprintf("%s %q", tok.Type, tok.Literal)
+	case STRING:
+		return fmt.Sprintf("string %q", tok.Literal)
+	}
+	return fmt.Sprintf("%q", tok.Literal)
+}
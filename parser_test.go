@@ -1,7 +1,9 @@
 package vibeGraphql
 
 import (
+	"strings"
 	"testing"
+	"time"
 )
 
 func TestParser_OperationWithVariables(t *testing.T) {
@@ -519,3 +521,127 @@ func TestParser_ParseBooleanValue(t *testing.T) {
 		t.Errorf("expected literal 'false', got %q", val2.Literal)
 	}
 }
+
+// TestParser_MaxTokens verifies that documents over the token limit are rejected.
+func TestParser_MaxTokens(t *testing.T) {
+	p := NewParser(NewLexer(`{ a b c d e f }`), WithMaxTokens(5))
+	p.ParseDocument()
+	errs := p.Errors()
+	if len(errs) != 1 || errs[0].Message != "Syntax Error: Document contains more than 5 tokens." {
+		t.Fatalf("unexpected errors: %v", errs)
+	}
+
+	p = NewParser(NewLexer(`{ a b c d e f }`), WithMaxTokens(8))
+	doc := p.ParseDocument()
+	if len(p.Errors()) != 0 {
+		t.Fatalf("unexpected errors: %v", p.Errors())
+	}
+	if n := len(doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections); n != 6 {
+		t.Errorf("expected 6 selections, got %d", n)
+	}
+}
+
+// TestParser_MaxDepth verifies that deeply nested selections, values and types are rejected.
+func TestParser_MaxDepth(t *testing.T) {
+	deep := strings.Repeat("{ a ", 50) + strings.Repeat("}", 50)
+	tests := []string{
+		deep,
+		`{ f(x: ` + strings.Repeat("[", 50) + strings.Repeat("]", 50) + `) }`,
+		`{ f(x: ` + strings.Repeat("{a: ", 50) + "1" + strings.Repeat("}", 50) + `) }`,
+		`type T { f: ` + strings.Repeat("[", 50) + "Int" + strings.Repeat("]", 50) + ` }`,
+	}
+	for _, input := range tests {
+		p := NewParser(NewLexer(input), WithMaxDepth(10))
+		p.ParseDocument()
+		errs := p.Errors()
+		if len(errs) != 1 || errs[0].Message != "Syntax Error: Document is nested more than 10 levels deep." {
+			t.Errorf("%.20s...: unexpected errors: %v", input, errs)
+		}
+	}
+
+	p := NewParser(NewLexer(deep))
+	p.ParseDocument()
+	if len(p.Errors()) != 0 {
+		t.Errorf("default limit rejected 50 levels: %v", p.Errors())
+	}
+}
+
+// TestParser_UnexpectedTokens verifies that unsupported syntax is reported
+// instead of stalling the parser.
+func TestParser_UnexpectedTokens(t *testing.T) {
+	tests := map[string]string{
+		`{ a: 1 }`:     `Syntax Error: Expected a field name after alias "a", found INT "1".`,
+		`{ ...on }`:    `Syntax Error: Expected a type name, found "}".`,
+		`{ f(1) }`:     `Syntax Error: Unexpected INT "1" in arguments.`,
+		`type T { ! }`: `Syntax Error: Unexpected "!" in definition of T.`,
+		// Malformed variable definitions.
+		`query Q($x Int) { a }`:  `Syntax Error: Expected ":", found IDENT "Int".`,
+		`query Q($x: Int { a }`:  `Syntax Error: Expected "$" or ")", found "{".`,
+		`query ($x: [Int) { a }`: `Syntax Error: Expected "]", found ")".`,
+		`query Q($x: ) {a}`:      `Syntax Error: Expected a type, found ")".`,
+		// Missing closing tokens and names.
+		`{ ok `:           `Syntax Error: Expected "}", found <EOF>.`,
+		`{ a { b }`:       `Syntax Error: Expected "}", found <EOF>.`,
+		`{ a @ }`:         `Syntax Error: Expected a directive name, found "}".`,
+		`{ f(x: 1 }`:      `Syntax Error: Expected ")", found "}".`,
+		`{ f(x: [1, 2) }`: `Syntax Error: Expected "]", found ")".`,
+		`{ f(x: {y: 1) }`: `Syntax Error: Expected "}", found ")".`,
+		`{ f(x: ) }`:      `Syntax Error: Expected a value, found ")".`,
+		`query Q`:         `Syntax Error: Expected "{", found <EOF>.`,
+		`type Q { a: }`:   `Syntax Error: Expected a type, found "}".`,
+		`enum E { A`:      `Syntax Error: Expected "}", found <EOF>.`,
+		`input I { a }`:   `Syntax Error: Expected ":", found "}".`,
+		// Tokens left over after the last definition.
+		`{ a } }`: `Syntax Error: Unexpected "}".`,
+	}
+	for input, want := range tests {
+		done := make(chan []*Error)
+		go func() {
+			p := NewParser(NewLexer(input))
+			p.ParseDocument()
+			done <- p.Errors()
+		}()
+		select {
+		case errs := <-done:
+			if len(errs) == 0 || errs[0].Message != want {
+				t.Errorf("%s: got %v, want %q", input, errs, want)
+			}
+		case <-time.After(time.Second):
+			t.Fatalf("%s: parser did not terminate", input)
+		}
+	}
+}
+
+const benchmarkQuery = `query Dashboard($id: ID!, $first: Int) {
+	user(id: $id) {
+		id
+		name
+		posts(first: $first, orderBy: {field: CREATED_AT, direction: DESC}) {
+			id
+			title
+			tags
+			comments(first: 5) { id body author { id name } }
+		}
+	}
+	stats { users posts comments }
+}`
+
+func BenchmarkParse(b *testing.B) {
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		if _, errs := parseRequest(benchmarkQuery); len(errs) > 0 {
+			b.Fatal(errs)
+		}
+	}
+}
+
+func TestParser_Tokens(t *testing.T) {
+	p := NewParser(NewLexer(`{ user }`))
+	if cur, peek := p.CurrentToken(), p.PeekToken(); cur.Type != LBRACE || peek.Type != IDENT || peek.Literal != "user" || peek.Start != 2 {
+		t.Errorf("unexpected tokens %+v %+v before parsing", cur, peek)
+	}
+	p.ParseDocument()
+	if cur := p.CurrentToken(); cur.Type != EOF || cur.Start != 8 {
+		t.Errorf("expected EOF after parsing, got %+v", cur)
+	}
+}
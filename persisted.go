@@ -0,0 +1,39 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+)
+
+// CodePersistedDocumentNotFound is reported when a request refers to a
+// persisted document the handler does not know.
+const CodePersistedDocumentNotFound = "PERSISTED_DOCUMENT_NOT_FOUND"
+
+// DocumentLoader returns the query of the persisted document id, and false
+// if there is no such document.
+type DocumentLoader func(ctx context.Context, id string) (query string, ok bool)
+
+// WithPersistedDocuments lets clients send the "documentId" (or "id") of a
+// document known to load instead of its query. Requests that also send a
+// query execute the query. Without this option, document IDs are only
+// reported to RequestDocumentID and the request logger.
+func WithPersistedDocuments(load DocumentLoader) HandlerOption {
+	return func(h *handler) {
+		h.documents = load
+	}
+}
+
+// loadDocument sets the query of req from its persisted document, if it has
+// one and sent no query.
+func (h *handler) loadDocument(ctx context.Context, req *graphqlRequest) *Error {
+	id := req.documentID()
+	if h.documents == nil || req.Query != "" || id == "" {
+		return nil
+	}
+	query, ok := h.documents(ctx, id)
+	if !ok {
+		return NewError(CodePersistedDocumentNotFound, fmt.Sprintf("Unknown persisted document %q.", id))
+	}
+	req.Query = query
+	return nil
+}
@@ -0,0 +1,36 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestPersistedDocuments(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"hello": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "world", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	documents := map[string]string{"sha256:abc": `{ hello }`}
+	h := NewHandler(WithPersistedDocuments(func(ctx context.Context, id string) (string, bool) {
+		query, ok := documents[id]
+		return query, ok
+	}))
+
+	send := func(body string) string {
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+		return rr.Body.String()
+	}
+	for _, body := range []string{`{"documentId": "sha256:abc"}`, `{"id": "sha256:abc"}`} {
+		if got := send(body); !strings.Contains(got, `"hello":"world"`) {
+			t.Errorf("%s: expected the persisted document to run, got %s", body, got)
+		}
+	}
+	if got := send(`{"documentId": "sha256:nope"}`); !strings.Contains(got, CodePersistedDocumentNotFound) {
+		t.Errorf("expected an unknown document error, got %s", got)
+	}
+	if got := send(`{"documentId": "sha256:nope", "query": "{ hello }"}`); !strings.Contains(got, `"hello":"world"`) {
+		t.Errorf("expected the query to take precedence, got %s", got)
+	}
+}
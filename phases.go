@@ -0,0 +1,56 @@
+package vibeGraphql
+
+import (
+	"context"
+	"time"
+)
+
+// PhaseTimings is the time a request spent in each phase of its handling,
+// showing whether latency comes from parsing or from resolvers.
+type PhaseTimings struct {
+	// ReadBody is the time taken to read and decode the request body,
+	// including uploaded files. It is zero for GET requests.
+	ReadBody time.Duration
+	// Parse is the time taken to parse the document. When execution plans
+	// are cached, it includes the validation of documents seen for the first
+	// time.
+	Parse time.Duration
+	// Validate is the time taken to validate the document and coerce the
+	// variables.
+	Validate time.Duration
+	// Execute is the time taken by the resolvers.
+	Execute time.Duration
+	// Serialize is the time taken to encode the response. As it ends after
+	// the response's extensions are written, it is only reported in the
+	// RequestLog.
+	Serialize time.Duration
+}
+
+// WithPhaseTracing reports the time spent reading, parsing, validating and
+// executing each request in a "phases" response extension, in nanoseconds.
+// The timings are also reported to the handler's logger in
+// RequestLog.Phases, with or without this option.
+func WithPhaseTracing() HandlerOption {
+	return func(h *handler) {
+		h.tracePhases = true
+	}
+}
+
+type phasesKey struct{}
+
+// phasesFrom returns the timings of the request of ctx, or nil if they are
+// not being recorded.
+func phasesFrom(ctx context.Context) *PhaseTimings {
+	phases, _ := ctx.Value(phasesKey{}).(*PhaseTimings)
+	return phases
+}
+
+// extension returns the value of the "phases" response extension.
+func (p *PhaseTimings) extension() map[string]interface{} {
+	return map[string]interface{}{
+		"readBody": p.ReadBody.Nanoseconds(),
+		"parse":    p.Parse.Nanoseconds(),
+		"validate": p.Validate.Nanoseconds(),
+		"execute":  p.Execute.Nanoseconds(),
+	}
+}
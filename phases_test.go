@@ -0,0 +1,57 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithPhaseTracing(t *testing.T) {
+	RegisterQueryResolver("slowPhase", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		time.Sleep(5 * time.Millisecond)
+		return "ok", nil
+	})
+	defer delete(QueryResolvers, "slowPhase")
+
+	var entries []RequestLog
+	h := NewHandler(WithPhaseTracing(), WithLogger(func(ctx context.Context, entry RequestLog) { entries = append(entries, entry) }))
+	rr := httptest.NewRecorder()
+	h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ slowPhase }"}`)))
+	var resp struct {
+		Data       map[string]interface{}
+		Extensions map[string]map[string]int64
+	}
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+		t.Fatal(err, rr.Body.String())
+	}
+	phases := resp.Extensions["phases"]
+	for _, phase := range []string{"readBody", "parse", "validate", "execute"} {
+		if _, ok := phases[phase]; !ok {
+			t.Errorf("missing phase %q in %v", phase, phases)
+		}
+	}
+	if phases["execute"] < int64(5*time.Millisecond) || phases["parse"] <= 0 || phases["readBody"] <= 0 {
+		t.Errorf("unexpected phases %v", phases)
+	}
+
+	if len(entries) != 1 {
+		t.Fatalf("expected one log entry, got %d", len(entries))
+	}
+	logged := entries[0].Phases
+	if logged.Execute < 5*time.Millisecond || logged.Serialize <= 0 || logged.Parse <= 0 {
+		t.Errorf("unexpected logged phases %+v", logged)
+	}
+	if logged.Parse+logged.Validate+logged.Execute+logged.Serialize > entries[0].Duration {
+		t.Errorf("phases %+v exceed the duration %v", logged, entries[0].Duration)
+	}
+
+	// Without the option, the extension is not reported.
+	rr = httptest.NewRecorder()
+	NewHandler().ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ slowPhase }"}`)))
+	if strings.Contains(rr.Body.String(), "phases") {
+		t.Errorf("unexpected phases in %s", rr.Body.String())
+	}
+}
@@ -0,0 +1,272 @@
+package vibeGraphql
+
+import (
+	"sync"
+	"time"
+)
+
+// executionPlan is the work done for a query that does not depend on its
+// variables or data: the parsed document, its validation, and, filled in as
+// execution reaches them, the collected fields of its selection sets and the
+// directives, timeouts, result types and constant arguments of its fields.
+type executionPlan struct {
+	schema      *Schema
+	doc         *Document
+	parseErrors []*Error
+
+	// The result of checkDocument.
+	operation *OperationDefinition
+	errors    []*Error
+
+	selections   sync.Map // selectionKey -> []*Field
+	conditionals sync.Map // *SelectionSet -> bool
+	fields       sync.Map // planFieldKey -> *fieldPlan
+}
+
+type planFieldKey struct {
+	parentType string
+	field      *Field
+}
+
+type fieldPlan struct {
+	field      *Field
+	parentType string
+	directives []Directive
+	timeout    time.Duration
+	id         bool
+	// args holds the coerced arguments of fields whose arguments use no
+	// variables; it is nil otherwise.
+	args map[string]interface{}
+}
+
+// newExecutionPlan parses query and checks its operation named
+// operationName against s.
+func newExecutionPlan(s *Schema, query, operationName string) *executionPlan {
+	plan := &executionPlan{schema: s}
+	plan.doc, plan.parseErrors = parseRequest(query)
+	if len(plan.parseErrors) == 0 {
+		plan.operation, plan.errors = checkDocument(s, plan.doc, operationName)
+	}
+	return plan
+}
+
+// field returns the plan of field on parentType.
+func (p *executionPlan) field(parentType string, field *Field) *fieldPlan {
+	key := planFieldKey{parentType, field}
+	if fp, ok := p.fields.Load(key); ok {
+		return fp.(*fieldPlan)
+	}
+	fp := &fieldPlan{
+		field:      field,
+		parentType: parentType,
+		directives: fieldDirectives(parentType, field),
+		timeout:    lookupFieldTimeout(parentType, field),
+		id:         isIDField(p.schema, parentType, field),
+	}
+	if !argumentsUseVariables(field.Arguments) {
+		fp.args = coercedArgs(parentType, field, nil)
+	}
+	actual, _ := p.fields.LoadOrStore(key, fp)
+	return actual.(*fieldPlan)
+}
+
+// arguments returns the field's arguments for a request with variables.
+// Resolvers may modify the map they receive, so constant arguments are
+// copied.
+func (fp *fieldPlan) arguments(variables map[string]interface{}) map[string]interface{} {
+	if fp.args == nil {
+		return coercedArgs(fp.parentType, fp.field, variables)
+	}
+	args := make(map[string]interface{}, len(fp.args))
+	for name, value := range fp.args {
+		args[name] = value
+	}
+	return args
+}
+
+// collect returns the fields to resolve for ss on a value of parentType, as
+// merged by collectFields. With a plan, the fields are collected once for
+// each type and combination of fields and fragments that @skip and @include
+// leave in, so that merged fields, too, are the same across requests.
+func (e *executor) collect(ss *SelectionSet, parentType string) []*Field {
+	if e.plan == nil {
+		return mergedFields(collectFields(ss, parentType, e.variables))
+	}
+	key := selectionKey{ss: ss, parentType: parentType}
+	if e.plan.conditional(ss) {
+		key.included = includedMask(ss, e.variables)
+	}
+	if fields, ok := e.plan.selections.Load(key); ok {
+		return fields.([]*Field)
+	}
+	fields, _ := e.plan.selections.LoadOrStore(key, mergedFields(collectFields(ss, parentType, e.variables)))
+	return fields.([]*Field)
+}
+
+type selectionKey struct {
+	ss         *SelectionSet
+	parentType string
+	included   string // which selections are included, if that varies
+}
+
+func mergedFields(groups []collectedField) []*Field {
+	fields := make([]*Field, len(groups))
+	for i := range groups {
+		fields[i] = groups[i].field()
+	}
+	return fields
+}
+
+// conditional reports whether the fields included in ss depend on variables.
+func (p *executionPlan) conditional(ss *SelectionSet) bool {
+	if conditional, ok := p.conditionals.Load(ss); ok {
+		return conditional.(bool)
+	}
+	conditional := conditionsUseVariables(ss)
+	p.conditionals.Store(ss, conditional)
+	return conditional
+}
+
+// includedMask returns a string with a 1 for every selection of ss, and of
+// the fragments within it, that @skip and @include leave in, and a 0 for
+// every other.
+func includedMask(ss *SelectionSet, variables map[string]interface{}) string {
+	mask := make([]byte, 0, len(ss.Selections))
+	visitConditions(ss, func(directives []Directive) {
+		if shouldInclude(directives, variables) {
+			mask = append(mask, '1')
+		} else {
+			mask = append(mask, '0')
+		}
+	})
+	return string(mask)
+}
+
+// visitConditions calls visit with the directives of each selection of ss,
+// and of the selections of the fragments within it, in order. The fields of
+// a fragment spread more than once are visited once, as collectFields
+// collects them once.
+func visitConditions(ss *SelectionSet, visit func(directives []Directive)) {
+	var spread map[*FragmentDefinition]bool
+	var walk func(ss *SelectionSet)
+	walk = func(ss *SelectionSet) {
+		for _, sel := range ss.Selections {
+			if field, ok := sel.(*Field); ok {
+				visit(field.Directives)
+				continue
+			}
+			fragment, _, directives := expandFragment(sel)
+			visit(directives)
+			if fragment == nil {
+				continue
+			}
+			if s, ok := sel.(*FragmentSpread); ok {
+				if spread[s.Fragment] {
+					continue
+				}
+				if spread == nil {
+					spread = make(map[*FragmentDefinition]bool)
+				}
+				spread[s.Fragment] = true
+			}
+			walk(fragment)
+		}
+	}
+	walk(ss)
+}
+
+// argumentsUseVariables reports whether any of arguments refers to a variable.
+func argumentsUseVariables(arguments []Argument) bool {
+	for _, arg := range arguments {
+		if valueUsesVariables(arg.Value) {
+			return true
+		}
+	}
+	return false
+}
+
+func valueUsesVariables(v *Value) bool {
+	if v == nil {
+		return false
+	}
+	switch v.Kind {
+	case "Variable":
+		return true
+	case "Array":
+		for _, item := range v.List {
+			if valueUsesVariables(item) {
+				return true
+			}
+		}
+	case "Object":
+		for _, field := range v.ObjectFields {
+			if valueUsesVariables(field) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// conditionsUseVariables reports whether the @skip or @include directives of
+// the selections of ss, or of the fragments within it, refer to variables.
+func conditionsUseVariables(ss *SelectionSet) bool {
+	conditional := false
+	visitConditions(ss, func(directives []Directive) {
+		for _, d := range directives {
+			if (d.Name == "skip" || d.Name == "include") && argumentsUseVariables(d.Arguments) {
+				conditional = true
+			}
+		}
+	})
+	return conditional
+}
+
+// planCache holds the execution plans of recent queries.
+type planCache struct {
+	mu         sync.Mutex
+	maxEntries int
+	plans      map[planKey]*executionPlan
+}
+
+// planKey identifies the plan of an operation: documents with several
+// operations have a plan for each.
+type planKey struct {
+	query         string
+	operationName string
+}
+
+// WithPlanCache keeps the execution plans of up to size queries, so that
+// repeated queries, such as persisted ones, are parsed and validated once
+// and skip most of the walk over their document. Plans are discarded when
+// the schema changes; field timeouts registered after a query first ran do
+// not apply to its plan.
+func WithPlanCache(size int) HandlerOption {
+	return func(h *handler) {
+		h.plans = &planCache{maxEntries: size, plans: make(map[planKey]*executionPlan)}
+	}
+}
+
+// get returns the plan of the operation of query named operationName for the
+// current schema.
+func (c *planCache) get(query, operationName string) *executionPlan {
+	s := CurrentSchema()
+	key := planKey{query, operationName}
+	c.mu.Lock()
+	plan, ok := c.plans[key]
+	c.mu.Unlock()
+	if ok && plan.schema == s {
+		return plan
+	}
+	plan = newExecutionPlan(s, query, operationName)
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, exists := c.plans[key]; !exists && c.maxEntries > 0 && len(c.plans) >= c.maxEntries {
+		for key := range c.plans {
+			delete(c.plans, key)
+			break
+		}
+	}
+	c.plans[key] = plan
+	return plan
+}
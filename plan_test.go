@@ -0,0 +1,96 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestWithPlanCache(t *testing.T) {
+	type user struct {
+		Name  string `json:"name"`
+		Email string `json:"email"`
+	}
+	var received []map[string]interface{}
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			received = append(received, args)
+			args["id"] = "modified"
+			return &user{Name: "Ada", Email: "ada@example.com"}, nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { user(id: ID, limit: Int): user } type user { name: String email: String }`)
+
+	h := newHandler([]HandlerOption{WithPlanCache(10)})
+	send := func(body string) string {
+		rr := httptest.NewRecorder()
+		h.serveJSON(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(body)))
+		return strings.TrimSpace(rr.Body.String())
+	}
+	query := `"query Q($mail: Boolean!) { user(id: 1, limit: 2) { name } user(id: 1, limit: 2) { email @include(if: $mail) } }"`
+	for _, tt := range []struct{ mail, want string }{
+		{"true", `{"data":{"user":{"email":"ada@example.com","name":"Ada"}}}`},
+		{"false", `{"data":{"user":{"name":"Ada"}}}`},
+		{"true", `{"data":{"user":{"email":"ada@example.com","name":"Ada"}}}`},
+	} {
+		if got := send(`{"query": ` + query + `, "variables": {"mail": ` + tt.mail + `}}`); got != tt.want {
+			t.Errorf("mail=%s: got %s, want %s", tt.mail, got, tt.want)
+		}
+	}
+	for _, args := range received[1:] {
+		if !reflect.DeepEqual(args, map[string]interface{}{"id": "modified", "limit": 2}) {
+			t.Errorf("constant arguments leaked a resolver's changes: %v", args)
+		}
+	}
+	if len(h.plans.plans) != 1 {
+		t.Errorf("expected one cached plan, got %d", len(h.plans.plans))
+	}
+	plan := h.plans.get(strings.Trim(query, `"`), "")
+	var selections int
+	plan.selections.Range(func(key, value interface{}) bool { selections++; return true })
+	// The root selection set, and the merged selection set of user once with
+	// and once without email.
+	if selections != 3 {
+		t.Errorf("expected the fields of each inclusion to be collected once, got %d entries", selections)
+	}
+
+	// Plans are rebuilt for a new schema.
+	if got := send(`{"query": "{ user(id: 1) { name } }"}`); !strings.Contains(got, `"Ada"`) {
+		t.Fatalf("unexpected response %s", got)
+	}
+	useTestSchema(t, `type Query { me: String }`)
+	if got := send(`{"query": "{ user(id: 1) { name } }"}`); !strings.Contains(got, `Cannot query field`) {
+		t.Errorf("expected the query to be validated against the new schema, got %s", got)
+	}
+}
+
+func TestPlanCacheEviction(t *testing.T) {
+	c := &planCache{maxEntries: 2, plans: make(map[planKey]*executionPlan)}
+	for _, q := range []string{"{ a }", "{ b }", "{ c }"} {
+		c.get(q, "")
+	}
+	if len(c.plans) != 2 || c.plans[planKey{query: "{ c }"}] == nil {
+		t.Errorf("unexpected plans %v", c.plans)
+	}
+}
+
+func BenchmarkExecutePlanCache(b *testing.B) {
+	useBenchmarkResolvers(b, 0, 20)
+	query := `{ users(first: 10) { id name friends { id name posts { id title } } } }`
+	b.Run("uncached", func(b *testing.B) {
+		runBenchmarkQuery(b, query)
+	})
+	b.Run("cached", func(b *testing.B) {
+		plans := &planCache{plans: make(map[planKey]*executionPlan)}
+		b.ReportAllocs()
+		for i := 0; i < b.N; i++ {
+			plan := plans.get(query, "")
+			result, err := executePlanned(context.Background(), plan.doc, plan, "", nil)
+			if err != nil || result["errors"] != nil {
+				b.Fatal(err, result["errors"])
+			}
+		}
+	})
+}
@@ -0,0 +1,393 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"sort"
+	"strings"
+)
+
+// PrintDocument serializes a parsed document back into GraphQL source. The
+// output is canonical: insignificant whitespace and comments are dropped,
+// so equivalent documents print identically. Operations are printed on one
+// line each; type system definitions are printed as SDL.
+func PrintDocument(doc *Document) string {
+	var parts []string
+	for _, def := range doc.Definitions {
+		var sb strings.Builder
+		switch def := def.(type) {
+		case *OperationDefinition:
+			writeOperation(&sb, def)
+		case *FragmentDefinition:
+			writeFragmentDefinition(&sb, def)
+		case *TypeDefinition:
+			writeTypeDefinition(&sb, def)
+		case *DirectiveDefinition:
+			writeDirectiveDefinition(&sb, def)
+		default:
+			continue
+		}
+		parts = append(parts, strings.TrimSuffix(sb.String(), "\n"))
+	}
+	return strings.Join(parts, "\n\n")
+}
+
+// writeOperation serializes an operation. An anonymous query without
+// variables uses the shorthand "{ ... }" form.
+func writeOperation(sb *strings.Builder, op *OperationDefinition) {
+	writeOperationHeader(sb, op)
+	writeSelectionSet(sb, op.SelectionSet)
+}
+
+// writeOperationHeader serializes what precedes the selection set of an
+// operation, such as "query Q($id: ID!) ", or nothing for the shorthand form.
+func writeOperationHeader(sb *strings.Builder, op *OperationDefinition) {
+	if op.Operation == "query" && op.Name == "" && len(op.VariableDefinitions) == 0 && len(op.Directives) == 0 {
+		return
+	}
+	sb.WriteString(op.Operation)
+	if op.Name != "" {
+		sb.WriteString(" " + op.Name)
+	}
+	writeVariableDefinitions(sb, op.VariableDefinitions)
+	writeDirectives(sb, op.Directives)
+	sb.WriteString(" ")
+}
+
+// writeFragmentDefinition serializes a fragment definition such as
+// "fragment userFields on User {id name}".
+func writeFragmentDefinition(sb *strings.Builder, def *FragmentDefinition) {
+	writeFragmentHeader(sb, def)
+	writeSelectionSet(sb, def.SelectionSet)
+}
+
+// writeFragmentHeader serializes what precedes the selection set of a
+// fragment definition, such as "fragment userFields on User ".
+func writeFragmentHeader(sb *strings.Builder, def *FragmentDefinition) {
+	sb.WriteString("fragment " + def.Name + " on " + def.TypeCondition)
+	writeDirectives(sb, def.Directives)
+	sb.WriteString(" ")
+}
+
+// writeVariableDefinitions serializes a variable list such as "($id: ID!)".
+func writeVariableDefinitions(sb *strings.Builder, defs []VariableDefinition) {
+	if len(defs) == 0 {
+		return
+	}
+	sb.WriteString("(")
+	for i, vd := range defs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		sb.WriteString("$" + vd.Variable + ": ")
+		writeType(sb, &vd.Type)
+	}
+	sb.WriteString(")")
+}
+
+// writeSelectionSet serializes a selection set in compact GraphQL syntax.
+func writeSelectionSet(sb *strings.Builder, ss *SelectionSet) {
+	if ss == nil {
+		sb.WriteString("{}")
+		return
+	}
+	sb.WriteString("{")
+	for i, sel := range ss.Selections {
+		if i > 0 {
+			sb.WriteString(" ")
+		}
+		writeSelection(sb, sel, writeSelectionSet)
+	}
+	sb.WriteString("}")
+}
+
+// writeSelection serializes a selection, writing the selection sets within
+// it with writeSet.
+func writeSelection(sb *strings.Builder, sel Selection, writeSet func(*strings.Builder, *SelectionSet)) {
+	switch sel := sel.(type) {
+	case *Field:
+		writeFieldHeader(sb, sel)
+		if sel.SelectionSet != nil {
+			sb.WriteString(" ")
+			writeSet(sb, sel.SelectionSet)
+		}
+	case *FragmentSpread:
+		sb.WriteString("..." + sel.Name)
+		writeDirectives(sb, sel.Directives)
+	case *InlineFragment:
+		sb.WriteString("...")
+		if sel.TypeCondition != "" {
+			sb.WriteString(" on " + sel.TypeCondition)
+		}
+		writeDirectives(sb, sel.Directives)
+		sb.WriteString(" ")
+		writeSet(sb, sel.SelectionSet)
+	}
+}
+
+// writeField serializes a field together with its arguments and sub-selections.
+func writeField(sb *strings.Builder, field *Field) {
+	writeSelection(sb, field, writeSelectionSet)
+}
+
+// writeFieldHeader serializes a field without its sub-selections.
+func writeFieldHeader(sb *strings.Builder, field *Field) {
+	if field.Alias != "" {
+		sb.WriteString(field.Alias)
+		sb.WriteString(": ")
+	}
+	sb.WriteString(field.Name)
+	if len(field.Arguments) > 0 {
+		sb.WriteString("(")
+		for i, arg := range field.Arguments {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(arg.Name)
+			sb.WriteString(": ")
+			writeValue(sb, arg.Value)
+		}
+		sb.WriteString(")")
+	}
+	writeDirectives(sb, field.Directives)
+}
+
+// writeValue serializes an input value literal.
+func writeValue(sb *strings.Builder, val *Value) {
+	if val == nil {
+		sb.WriteString("null")
+		return
+	}
+	switch val.Kind {
+	case "String":
+		quoted, _ := json.Marshal(val.Literal)
+		sb.Write(quoted)
+	case "Variable":
+		sb.WriteString("$")
+		sb.WriteString(val.Literal)
+	case "Object":
+		keys := make([]string, 0, len(val.ObjectFields))
+		for key := range val.ObjectFields {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		sb.WriteString("{")
+		for i, key := range keys {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			sb.WriteString(key)
+			sb.WriteString(": ")
+			writeValue(sb, val.ObjectFields[key])
+		}
+		sb.WriteString("}")
+	case "Array":
+		sb.WriteString("[")
+		for i, elem := range val.List {
+			if i > 0 {
+				sb.WriteString(", ")
+			}
+			writeValue(sb, elem)
+		}
+		sb.WriteString("]")
+	default:
+		sb.WriteString(val.Literal)
+	}
+}
+
+// writeType serializes a type reference such as [String!]!.
+func writeType(sb *strings.Builder, t *Type) {
+	if t == nil {
+		return
+	}
+	if t.IsList {
+		sb.WriteString("[")
+		writeType(sb, t.Elem)
+		sb.WriteString("]")
+	} else {
+		sb.WriteString(t.Name)
+	}
+	if t.NonNull {
+		sb.WriteString("!")
+	}
+}
+
+// collectVariables records the names of all variables referenced by a
+// selection set, including those nested in argument values, directives and
+// fragments.
+func collectVariables(ss *SelectionSet, into map[string]bool) {
+	visitVariables(ss, func(field *Field, name string) {
+		into[name] = true
+	})
+}
+
+func collectValueVariables(val *Value, into map[string]bool) {
+	if val == nil {
+		return
+	}
+	switch val.Kind {
+	case "Variable":
+		into[val.Literal] = true
+	case "Object":
+		for _, v := range val.ObjectFields {
+			collectValueVariables(v, into)
+		}
+	case "Array":
+		for _, v := range val.List {
+			collectValueVariables(v, into)
+		}
+	}
+}
+
+// PrintSchema serializes a schema back into SDL. Built-in scalars are omitted.
+func PrintSchema(s *Schema) string {
+	var sb strings.Builder
+	for _, dd := range s.directiveDefinitions() {
+		writeDirectiveDefinition(&sb, dd)
+		sb.WriteString("\n\n")
+	}
+	for _, name := range s.typeNames {
+		td := s.Types[name]
+		if td.kind() == KindScalar && isBuiltinScalar(name) || strings.HasPrefix(name, "__") {
+			continue
+		}
+		writeTypeDefinition(&sb, td)
+		sb.WriteString("\n")
+	}
+	return strings.TrimSuffix(sb.String(), "\n")
+}
+
+func writeDirectiveDefinition(sb *strings.Builder, dd *DirectiveDefinition) {
+	writeDescription(sb, dd.Description, "")
+	sb.WriteString("directive @" + dd.Name)
+	writeInputValueDefinitions(sb, dd.Arguments, "(", ")")
+	if dd.Repeatable {
+		sb.WriteString(" repeatable")
+	}
+	sb.WriteString(" on " + strings.Join(dd.Locations, " | "))
+}
+
+var kindKeywords = map[string]string{
+	KindObject:      "type",
+	KindInterface:   "interface",
+	KindUnion:       "union",
+	KindEnum:        "enum",
+	KindInputObject: "input",
+	KindScalar:      "scalar",
+}
+
+func writeTypeDefinition(sb *strings.Builder, td *TypeDefinition) {
+	writeDescription(sb, td.Description, "")
+	if td.Extension {
+		sb.WriteString("extend ")
+	}
+	sb.WriteString(kindKeywords[td.kind()] + " " + td.Name)
+	if len(td.Interfaces) > 0 {
+		sb.WriteString(" implements " + strings.Join(td.Interfaces, " & "))
+	}
+	writeDirectives(sb, td.Directives)
+	switch td.kind() {
+	case KindScalar:
+		sb.WriteString("\n")
+	case KindUnion:
+		sb.WriteString(" = " + strings.Join(td.Types, " | ") + "\n")
+	case KindEnum:
+		sb.WriteString(" {\n")
+		for _, v := range td.EnumValues {
+			writeDescription(sb, v.Description, "  ")
+			sb.WriteString("  " + v.Name)
+			writeDirectives(sb, v.Directives)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	case KindInputObject:
+		sb.WriteString(" {\n")
+		for _, f := range td.InputFields {
+			writeDescription(sb, f.Description, "  ")
+			sb.WriteString("  ")
+			writeInputValueDefinition(sb, f)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	default:
+		sb.WriteString(" {\n")
+		for _, f := range td.Fields {
+			writeDescription(sb, f.Description, "  ")
+			sb.WriteString("  " + f.Name)
+			writeInputValueDefinitions(sb, f.ArgumentDefinitions, "(", ")")
+			sb.WriteString(": ")
+			writeType(sb, f.Type)
+			writeDirectives(sb, f.Directives)
+			sb.WriteString("\n")
+		}
+		sb.WriteString("}\n")
+	}
+}
+
+func writeInputValueDefinitions(sb *strings.Builder, defs []*InputValueDefinition, open, close string) {
+	if len(defs) == 0 {
+		return
+	}
+	sb.WriteString(open)
+	for i, def := range defs {
+		if i > 0 {
+			sb.WriteString(", ")
+		}
+		if def.Description != "" {
+			quoted, _ := json.Marshal(def.Description)
+			sb.Write(quoted)
+			sb.WriteString(" ")
+		}
+		writeInputValueDefinition(sb, def)
+	}
+	sb.WriteString(close)
+}
+
+func writeInputValueDefinition(sb *strings.Builder, def *InputValueDefinition) {
+	sb.WriteString(def.Name + ": ")
+	writeType(sb, def.Type)
+	if def.DefaultValue != nil {
+		sb.WriteString(" = ")
+		writeValue(sb, def.DefaultValue)
+	}
+	writeDirectives(sb, def.Directives)
+}
+
+// writeDescription writes description on a line of its own before the
+// definition it describes, as a block string if it spans several lines.
+func writeDescription(sb *strings.Builder, description, indent string) {
+	if description == "" {
+		return
+	}
+	if !strings.Contains(description, "\n") {
+		quoted, _ := json.Marshal(description)
+		sb.WriteString(indent)
+		sb.Write(quoted)
+		sb.WriteString("\n")
+		return
+	}
+	sb.WriteString(indent + `"""` + "\n")
+	for _, line := range strings.Split(strings.ReplaceAll(description, `"""`, `\"""`), "\n") {
+		if line != "" {
+			sb.WriteString(indent + line)
+		}
+		sb.WriteString("\n")
+	}
+	sb.WriteString(indent + `"""` + "\n")
+}
+
+func writeDirectives(sb *strings.Builder, directives []Directive) {
+	for _, d := range directives {
+		sb.WriteString(" @" + d.Name)
+		if len(d.Arguments) > 0 {
+			sb.WriteString("(")
+			for i, arg := range d.Arguments {
+				if i > 0 {
+					sb.WriteString(", ")
+				}
+				sb.WriteString(arg.Name + ": ")
+				writeValue(sb, arg.Value)
+			}
+			sb.WriteString(")")
+		}
+	}
+}
@@ -0,0 +1,31 @@
+package vibeGraphql
+
+import "testing"
+
+func TestPrintDocument(t *testing.T) {
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user { name } }`, `{user {name}}`},
+		{`query { a }`, `{a}`},
+		{`{ me: user(id: 1) { n : name } }`, `{me: user(id: 1) {n: name}}`},
+		{
+			"query   GetUser($id: ID!, $tags: [String!]) {\n  user(id: $id, filter: {b: 1, a: \"x\\\"y\"}) @include(if: true) {\n    name # comment\n    friends { id }\n  }\n}",
+			`query GetUser($id: ID!, $tags: [String!]) {user(id: $id, filter: {a: "x\"y", b: 1}) @include(if: true) {name friends {id}}}`,
+		},
+		{`mutation { like(id: 1, on: [true, false]) }`, `mutation {like(id: 1, on: [true, false])}`},
+		{`query @trace(sample: 0.5) { a }`, `query @trace(sample: 0.5) {a}`},
+		{"type User { id: ID! }\ndirective @auth on FIELD_DEFINITION", "type User {\n  id: ID!\n}\n\ndirective @auth on FIELD_DEFINITION"},
+	}
+	for _, tt := range tests {
+		got := PrintDocument(parseQuery(tt.query))
+		if got != tt.want {
+			t.Errorf("PrintDocument(%q) =\n%s\nwant\n%s", tt.query, got, tt.want)
+			continue
+		}
+		if again := PrintDocument(parseQuery(got)); again != got {
+			t.Errorf("printing is not stable: %q became %q", got, again)
+		}
+	}
+}
@@ -0,0 +1,70 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"sync"
+)
+
+// CodeSubscriptionQuotaExceeded is the code of the error a subscription
+// request over its user's quota is rejected with.
+const CodeSubscriptionQuotaExceeded = "SUBSCRIPTION_QUOTA_EXCEEDED"
+
+// SubscriptionQuota limits the subscriptions each user may have open at
+// once, protecting the server from clients that fan out many event streams.
+// Each WebSocket connection serves a single subscription, so the quota also
+// bounds the connections a user holds open.
+type SubscriptionQuota struct {
+	// User identifies the user a subscription counts against, such as a user
+	// ID stored in the request context by authentication middleware.
+	// Subscriptions with an empty user are not limited.
+	User func(ctx context.Context) string
+	// PerUser is the number of subscriptions a user may have open. Zero is
+	// unlimited.
+	PerUser int
+}
+
+// WithSubscriptionQuota limits the subscriptions each user may have open.
+// Subscription requests over quota are answered with an error message and
+// the connection is closed.
+func WithSubscriptionQuota(quota SubscriptionQuota) HandlerOption {
+	return func(h *handler) {
+		h.quota = &subscriptionQuota{limit: quota, open: make(map[string]int)}
+	}
+}
+
+// subscriptionQuota counts the open subscriptions of each user.
+type subscriptionQuota struct {
+	limit SubscriptionQuota
+	mu    sync.Mutex
+	open  map[string]int
+}
+
+// acquire counts a subscription against the user of ctx and returns a
+// function that releases it, or a SUBSCRIPTION_QUOTA_EXCEEDED error if the
+// user has no subscriptions left.
+func (q *subscriptionQuota) acquire(ctx context.Context) (func(), *Error) {
+	if q == nil || q.limit.User == nil || q.limit.PerUser <= 0 {
+		return func() {}, nil
+	}
+	user := q.limit.User(ctx)
+	if user == "" {
+		return func() {}, nil
+	}
+	q.mu.Lock()
+	defer q.mu.Unlock()
+	if q.open[user] >= q.limit.PerUser {
+		return nil, NewError(CodeSubscriptionQuotaExceeded, fmt.Sprintf("Too many open subscriptions; the limit is %d.", q.limit.PerUser))
+	}
+	q.open[user]++
+	var once sync.Once
+	return func() {
+		once.Do(func() {
+			q.mu.Lock()
+			defer q.mu.Unlock()
+			if q.open[user]--; q.open[user] == 0 {
+				delete(q.open, user)
+			}
+		})
+	}, nil
+}
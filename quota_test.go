@@ -0,0 +1,70 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+)
+
+type quotaUserKey struct{}
+
+func TestWithSubscriptionQuota(t *testing.T) {
+	events := make(chan interface{})
+	RegisterSubscriptionResolver("quotaEvents", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return events, nil
+	})
+	defer delete(SubscriptionResolvers, "quotaEvents")
+
+	h := newHandler([]HandlerOption{WithSubscriptionQuota(SubscriptionQuota{
+		User: func(ctx context.Context) string {
+			user, _ := ctx.Value(quotaUserKey{}).(string)
+			return user
+		},
+		PerUser: 1,
+	})})
+	subscribe := func(user string) *messageConn {
+		r := httptest.NewRequest("GET", "/graphql/ws", nil)
+		if user != "" {
+			r = r.WithContext(context.WithValue(r.Context(), quotaUserKey{}, user))
+		}
+		conn := &messageConn{request: `{"query": "subscription { quotaEvents }"}`}
+		h.subscribe(r, conn)
+		return conn
+	}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		subscribe("ada")
+	}()
+	for {
+		h.quota.mu.Lock()
+		n := h.quota.open["ada"]
+		h.quota.mu.Unlock()
+		if n == 1 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	if conn := subscribe("ada"); len(conn.messages) != 1 || conn.messages[0] != "Too many open subscriptions; the limit is 1." {
+		t.Errorf("expected the second subscription to be rejected, got %q", conn.messages)
+	}
+	// Other users and anonymous subscriptions are not affected. Their
+	// subscriptions end when the event channel is closed.
+	others := make(chan []string, 2)
+	for _, user := range []string{"grace", ""} {
+		go func(user string) { others <- subscribe(user).messages }(user)
+	}
+	close(events)
+	<-done
+	for i := 0; i < 2; i++ {
+		if messages := <-others; len(messages) != 0 {
+			t.Errorf("expected the subscription to be served, got %q", messages)
+		}
+	}
+	if len(h.quota.open) != 0 {
+		t.Errorf("expected ended subscriptions to be released, got %v", h.quota.open)
+	}
+}
@@ -0,0 +1,141 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"math"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// Rate allows Limit operations per Period. A zero Rate is unlimited.
+type Rate struct {
+	Limit  int
+	Period time.Duration
+}
+
+// RateLimit configures per-client rate limiting with separate budgets for
+// queries, mutations and subscription starts. Operations over budget fail
+// with a RATE_LIMITED error whose "retryAfter" extension holds the number of
+// seconds to wait; HTTP responses also carry a Retry-After header.
+type RateLimit struct {
+	// Key identifies the client a request is counted against, such as its
+	// IP address, API key or a user ID stored in its context. Requests with
+	// an empty key are not limited.
+	Key           func(r *http.Request) string
+	Queries       Rate
+	Mutations     Rate
+	Subscriptions Rate
+}
+
+// WithRateLimit limits the operations each client may run.
+func WithRateLimit(limit RateLimit) HandlerOption {
+	return func(h *handler) {
+		h.limiter = newRateLimiter()
+		h.limiter.limit = limit
+	}
+}
+
+func newRateLimiter() *rateLimiter {
+	return &rateLimiter{
+		buckets:   make(map[rateKey]*rateBucket),
+		now:       time.Now,
+		nextSweep: minRateSweep,
+	}
+}
+
+// rateLimit returns a RATE_LIMITED error if the client sending r has used up
+// its budget for op, and charges op against the budget otherwise.
+func (h *handler) rateLimit(r *http.Request, op *OperationDefinition) *Error {
+	if h.limiter == nil || op == nil || h.limiter.limit.Key == nil {
+		return nil
+	}
+	key := h.limiter.limit.Key(r)
+	if key == "" {
+		return nil
+	}
+	var rate Rate
+	var what string
+	switch op.Operation {
+	case "mutation":
+		rate, what = h.limiter.limit.Mutations, "mutations"
+	case "subscription":
+		rate, what = h.limiter.limit.Subscriptions, "subscriptions"
+	default:
+		rate, what = h.limiter.limit.Queries, "queries"
+	}
+	wait, _ := h.limiter.take(rateKey{op: op.Operation, client: key}, rate, 1)
+	if wait <= 0 {
+		return nil
+	}
+	seconds := int(math.Ceil(wait.Seconds()))
+	err := NewError(CodeRateLimited, fmt.Sprintf("Too many %s, retry after %d seconds.", what, seconds))
+	err.Extensions["retryAfter"] = seconds
+	return err
+}
+
+// minRateSweep is the number of buckets kept before idle ones are removed.
+const minRateSweep = 1024
+
+type rateKey struct {
+	op, client string
+}
+
+// rateBucket is a token bucket holding up to Rate.Limit tokens.
+type rateBucket struct {
+	tokens float64
+	last   time.Time
+	period time.Duration
+}
+
+type rateLimiter struct {
+	limit     RateLimit
+	now       func() time.Time
+	mu        sync.Mutex
+	buckets   map[rateKey]*rateBucket
+	nextSweep int
+}
+
+// take removes n tokens from the bucket for key and returns zero, or returns
+// how long to wait until n tokens are available. It also returns the number of
+// tokens left in the bucket.
+func (l *rateLimiter) take(key rateKey, rate Rate, n float64) (time.Duration, float64) {
+	if rate.Limit <= 0 || rate.Period <= 0 {
+		return 0, 0
+	}
+	perToken := float64(rate.Period) / float64(rate.Limit)
+	now := l.now()
+
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	b, ok := l.buckets[key]
+	if !ok {
+		l.sweep(now)
+		b = &rateBucket{tokens: float64(rate.Limit), last: now, period: rate.Period}
+		l.buckets[key] = b
+	}
+	b.tokens = math.Min(float64(rate.Limit), b.tokens+float64(now.Sub(b.last))/perToken)
+	b.last = now
+	if b.tokens < n {
+		return time.Duration((n - b.tokens) * perToken), b.tokens
+	}
+	b.tokens -= n
+	return 0, b.tokens
+}
+
+// sweep removes buckets that have been idle long enough to refill completely
+// once the number of buckets reaches nextSweep.
+func (l *rateLimiter) sweep(now time.Time) {
+	if len(l.buckets) < l.nextSweep {
+		return
+	}
+	for key, b := range l.buckets {
+		if now.Sub(b.last) >= b.period {
+			delete(l.buckets, key)
+		}
+	}
+	l.nextSweep = 2 * len(l.buckets)
+	if l.nextSweep < minRateSweep {
+		l.nextSweep = minRateSweep
+	}
+}
@@ -0,0 +1,90 @@
+package vibeGraphql
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestWithRateLimit(t *testing.T) {
+	QueryResolvers["limitedQuery"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	MutationResolvers["limitedMutation"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	defer delete(QueryResolvers, "limitedQuery")
+	defer delete(MutationResolvers, "limitedMutation")
+
+	h := newHandler([]HandlerOption{WithRateLimit(RateLimit{
+		Key:       func(r *http.Request) string { return r.Header.Get("X-API-Key") },
+		Queries:   Rate{Limit: 2, Period: time.Minute},
+		Mutations: Rate{Limit: 1, Period: time.Minute},
+	})})
+	now := time.Unix(0, 0)
+	h.limiter.now = func() time.Time { return now }
+
+	send := func(key, query string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`))
+		req.Header.Set("X-API-Key", key)
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, req)
+		return rr
+	}
+
+	for i := 0; i < 2; i++ {
+		if rr := send("a", "{ limitedQuery }"); rr.Code != http.StatusOK {
+			t.Fatalf("query %d: unexpected response %d %s", i, rr.Code, rr.Body.String())
+		}
+	}
+	rr := send("a", "{ limitedQuery }")
+	if rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "30" {
+		t.Fatalf("expected 429 with Retry-After 30, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	var resp struct{ Errors []*Error }
+	if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil || len(resp.Errors) != 1 {
+		t.Fatalf("unexpected body %s", rr.Body.String())
+	}
+	if e := resp.Errors[0]; e.Extensions["code"] != CodeRateLimited || e.Extensions["retryAfter"] != float64(30) ||
+		e.Message != "Too many queries, retry after 30 seconds." {
+		t.Errorf("unexpected error %+v", e)
+	}
+
+	// Mutations and other clients have their own budgets.
+	if rr := send("a", "mutation { limitedMutation }"); rr.Code != http.StatusOK {
+		t.Errorf("unexpected mutation response %d %s", rr.Code, rr.Body.String())
+	}
+	if rr := send("a", "mutation { limitedMutation }"); rr.Code != http.StatusTooManyRequests || rr.Header().Get("Retry-After") != "60" {
+		t.Errorf("expected second mutation to be limited, got %d %q", rr.Code, rr.Header().Get("Retry-After"))
+	}
+	if rr := send("b", "{ limitedQuery }"); rr.Code != http.StatusOK {
+		t.Errorf("unexpected response for another client %d", rr.Code)
+	}
+	if rr := send("", "{ limitedQuery }"); rr.Code != http.StatusOK {
+		t.Errorf("requests without a key should not be limited, got %d", rr.Code)
+	}
+
+	now = now.Add(30 * time.Second)
+	if rr := send("a", "{ limitedQuery }"); rr.Code != http.StatusOK {
+		t.Errorf("expected budget to refill, got %d %s", rr.Code, rr.Body.String())
+	}
+}
+
+func TestRateLimiterSweep(t *testing.T) {
+	h := newHandler([]HandlerOption{WithRateLimit(RateLimit{})})
+	l := h.limiter
+	l.limit.Queries = Rate{Limit: 1, Period: time.Second}
+	now := time.Unix(0, 0)
+	l.now = func() time.Time { return now }
+	for i := 0; i < minRateSweep; i++ {
+		l.take(rateKey{op: "query", client: string(rune(i))}, l.limit.Queries, 1)
+	}
+	now = now.Add(time.Second)
+	l.take(rateKey{op: "query", client: "new"}, l.limit.Queries, 1)
+	if len(l.buckets) != 1 {
+		t.Errorf("expected idle buckets to be removed, have %d", len(l.buckets))
+	}
+}
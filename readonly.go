@@ -0,0 +1,71 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"sync"
+)
+
+// ReadOnlyMode rejects mutations, and optionally subscriptions, while it is
+// enabled, for use during migrations, incident response or on read replicas.
+// Queries are served as usual. It can be toggled while requests are served.
+// The zero value is disabled.
+type ReadOnlyMode struct {
+	mu            sync.RWMutex
+	enabled       bool
+	subscriptions bool
+	reason        string
+}
+
+// Enable starts rejecting mutations, and subscriptions too if
+// includeSubscriptions is set. reason, if not empty, is reported to clients.
+func (m *ReadOnlyMode) Enable(reason string, includeSubscriptions bool) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enabled, m.subscriptions, m.reason = true, includeSubscriptions, reason
+}
+
+// Disable resumes serving all operations.
+func (m *ReadOnlyMode) Disable() {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.enabled, m.subscriptions, m.reason = false, false, ""
+}
+
+// Enabled reports whether mutations are being rejected.
+func (m *ReadOnlyMode) Enabled() bool {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	return m.enabled
+}
+
+// check returns a READ_ONLY error if op may not run.
+func (m *ReadOnlyMode) check(op *OperationDefinition) *Error {
+	if m == nil || op == nil {
+		return nil
+	}
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+	var what string
+	switch {
+	case !m.enabled:
+		return nil
+	case op.Operation == "mutation":
+		what = "Mutations"
+	case op.Operation == "subscription" && m.subscriptions:
+		what = "Subscriptions"
+	default:
+		return nil
+	}
+	msg := fmt.Sprintf("%s are disabled because the server is in read-only mode.", what)
+	if m.reason != "" {
+		msg = fmt.Sprintf("%s are disabled because the server is in read-only mode: %s.", what, m.reason)
+	}
+	return NewError(CodeReadOnly, msg)
+}
+
+// WithReadOnly rejects operations according to mode.
+func WithReadOnly(mode *ReadOnlyMode) HandlerOption {
+	return func(h *handler) {
+		h.readOnly = mode
+	}
+}
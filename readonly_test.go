@@ -0,0 +1,76 @@
+package vibeGraphql
+
+import (
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+// messageConn is a SubscriptionConn that reads request and records what is
+// written to it.
+type messageConn struct {
+	request  string
+	messages []string
+}
+
+func (c *messageConn) ReadMessage() (int, []byte, error) { return 1, []byte(c.request), nil }
+func (c *messageConn) WriteMessage(messageType int, data []byte) error {
+	c.messages = append(c.messages, string(data))
+	return nil
+}
+func (c *messageConn) WriteJSON(v interface{}) error { return nil }
+
+func TestWithReadOnly(t *testing.T) {
+	QueryResolvers["roQuery"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "ok", nil
+	}
+	MutationResolvers["roMutation"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("mutation should not run in read-only mode")
+		return "ok", nil
+	}
+	defer delete(QueryResolvers, "roQuery")
+	defer delete(MutationResolvers, "roMutation")
+
+	var mode ReadOnlyMode
+	h := newHandler([]HandlerOption{WithReadOnly(&mode)})
+	send := func(query string) string {
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`)))
+		return rr.Body.String()
+	}
+
+	mode.Enable("database migration in progress", false)
+	if !mode.Enabled() {
+		t.Fatal("expected mode to be enabled")
+	}
+	if body := send("{ roQuery }"); !strings.Contains(body, `"roQuery":"ok"`) {
+		t.Errorf("queries should still be served, got %s", body)
+	}
+	body := send("mutation { roMutation }")
+	if !strings.Contains(body, `"code":"READ_ONLY"`) ||
+		!strings.Contains(body, "Mutations are disabled because the server is in read-only mode: database migration in progress.") {
+		t.Errorf("unexpected mutation response %s", body)
+	}
+
+	conn := &messageConn{request: `{"query": "subscription { roEvents }"}`}
+	h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.messages) == 0 || strings.Contains(conn.messages[0], "read-only") {
+		t.Errorf("subscriptions should not be rejected, got %q", conn.messages)
+	}
+
+	mode.Enable("", true)
+	conn = &messageConn{request: `{"query": "subscription { roEvents }"}`}
+	h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.messages) != 1 || conn.messages[0] != "Subscriptions are disabled because the server is in read-only mode." {
+		t.Errorf("unexpected subscription messages %q", conn.messages)
+	}
+
+	mode.Disable()
+	delete(MutationResolvers, "roMutation")
+	MutationResolvers["roMutation"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "done", nil
+	}
+	if body := send("mutation { roMutation }"); !strings.Contains(body, `"roMutation":"done"`) {
+		t.Errorf("expected mutations after Disable, got %s", body)
+	}
+}
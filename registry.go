@@ -1,13 +1,23 @@
 package vibeGraphql
 
+import "context"
+
 // ResolverFunc defines the function signature for all resolvers.
 type ResolverFunc func(source interface{}, args map[string]interface{}) (interface{}, error)
 
+// ContextResolverFunc is a resolver that also receives the request context.
+// Details about the field being resolved are available via ResolveInfoFromContext.
+type ContextResolverFunc func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error)
+
 // Global resolver registries.
 var QueryResolvers = make(map[string]ResolverFunc)
 var MutationResolvers = make(map[string]ResolverFunc)
 var SubscriptionResolvers = make(map[string]ResolverFunc)
 
+// FieldResolvers maps a type name to its field resolvers. Root fields are
+// registered under "Query", "Mutation" and "Subscription".
+var FieldResolvers = make(map[string]map[string]ContextResolverFunc)
+
 // Register functions.
 func RegisterQueryResolver(field string, resolver ResolverFunc) {
 	QueryResolvers[field] = resolver
@@ -20,3 +30,30 @@ func RegisterMutationResolver(field string, resolver ResolverFunc) {
 func RegisterSubscriptionResolver(field string, resolver ResolverFunc) {
 	SubscriptionResolvers[field] = resolver
 }
+
+// RegisterFieldResolver registers a context-aware resolver for field on typeName.
+func RegisterFieldResolver(typeName, field string, resolver ContextResolverFunc) {
+	if FieldResolvers[typeName] == nil {
+		FieldResolvers[typeName] = make(map[string]ContextResolverFunc)
+	}
+	FieldResolvers[typeName][field] = resolver
+}
+
+// TypeNameResolver returns the name of the concrete object type of value, a
+// value of an interface or union type, or "" to fall back to the name of
+// value's Go type.
+type TypeNameResolver func(value interface{}) string
+
+// TypeNameResolvers maps interface and union type names to the function
+// determining the concrete type of their values.
+var TypeNameResolvers = make(map[string]TypeNameResolver)
+
+// RegisterTypeNameResolver registers the function determining the concrete
+// type of the values of the interface or union typeName, for when the name
+// of their Go type does not tell, as with maps or structs shared by several
+// types. The concrete type is reported by __typename and selects the field
+// resolvers of the value. A schema must be loaded for the declared types of
+// fields to be known.
+func RegisterTypeNameResolver(typeName string, resolver TypeNameResolver) {
+	TypeNameResolvers[typeName] = resolver
+}
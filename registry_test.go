@@ -1,6 +1,10 @@
 package vibeGraphql
 
-import "testing"
+import (
+	"context"
+	"strings"
+	"testing"
+)
 
 // dummyResolver remains unchanged
 func dummyResolvers(source interface{}, args map[string]interface{}) (interface{}, error) {
@@ -60,3 +64,45 @@ func TestRegisterSubscriptionResolver(t *testing.T) {
 		t.Errorf("expected result 'dummy success', got %v", result)
 	}
 }
+
+func TestRegisterTypeNameResolver(t *testing.T) {
+	pets := []interface{}{
+		map[string]interface{}{"kind": "dog", "name": "Rex"},
+		map[string]interface{}{"kind": "cat", "name": "Tom"},
+	}
+	sound := func(s string) ContextResolverFunc {
+		return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) { return s, nil }
+	}
+	useResolvers(t, map[string]ResolverFunc{
+		"pets": func(source interface{}, args map[string]interface{}) (interface{}, error) { return pets, nil },
+		"pet":  func(source interface{}, args map[string]interface{}) (interface{}, error) { return pets[1], nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"Dog": {"sound": sound("woof")},
+		"Cat": {"sound": sound("meow")},
+	})
+	useTestSchema(t, `
+		interface Pet { name: String sound: String }
+		type Dog implements Pet { name: String sound: String }
+		type Cat implements Pet { name: String sound: String }
+		type Query { pets: [Pet] pet: Pet }
+	`)
+	defer delete(TypeNameResolvers, "Pet")
+	RegisterTypeNameResolver("Pet", func(value interface{}) string {
+		switch value.(map[string]interface{})["kind"] {
+		case "dog":
+			return "Dog"
+		case "cat":
+			return "Cat"
+		}
+		return ""
+	})
+
+	result, err := executeRequest(context.Background(), `{ pets { __typename name sound } pet { __typename } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"data":{"pet":{"__typename":"Cat"},"pets":[{"__typename":"Dog","name":"Rex","sound":"woof"},{"__typename":"Cat","name":"Tom","sound":"meow"}]}}`
+	if got := strings.TrimSpace(string(encodeResponse(result))); got != want {
+		t.Errorf("unexpected result\n got %s\nwant %s", got, want)
+	}
+}
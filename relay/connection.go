@@ -0,0 +1,219 @@
+// Package relay provides helpers for building Relay-style connections
+// (edges, nodes, cursors and pageInfo) for paginated list fields.
+package relay
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// ConnectionArgs holds the standard Relay pagination arguments.
+type ConnectionArgs struct {
+	First  *int
+	After  string
+	Last   *int
+	Before string
+}
+
+// Connection is a Relay connection result.
+type Connection struct {
+	Edges      []*Edge  `json:"edges"`
+	PageInfo   PageInfo `json:"pageInfo"`
+	TotalCount int      `json:"totalCount"`
+}
+
+// Edge wraps a node together with its cursor.
+type Edge struct {
+	Node   interface{} `json:"node"`
+	Cursor string      `json:"cursor"`
+}
+
+// PageInfo describes the window returned by a connection.
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor"`
+	EndCursor       *string `json:"endCursor"`
+}
+
+// ParseConnectionArgs coerces first/after/last/before from resolver arguments.
+// Integers may arrive as int (literals) or float64 (JSON variables).
+func ParseConnectionArgs(args map[string]interface{}) (ConnectionArgs, error) {
+	var ca ConnectionArgs
+	var err error
+	if ca.First, err = intArg(args, "first"); err != nil {
+		return ca, err
+	}
+	if ca.Last, err = intArg(args, "last"); err != nil {
+		return ca, err
+	}
+	if ca.After, err = stringArg(args, "after"); err != nil {
+		return ca, err
+	}
+	if ca.Before, err = stringArg(args, "before"); err != nil {
+		return ca, err
+	}
+	return ca, nil
+}
+
+func intArg(args map[string]interface{}, name string) (*int, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return nil, nil
+	}
+	var n int
+	switch v := raw.(type) {
+	case int:
+		n = v
+	case int32:
+		n = int(v)
+	case int64:
+		n = int(v)
+	case json.Number:
+		i, err := v.Int64()
+		if err != nil {
+			return nil, fmt.Errorf("argument %s must be an integer, got %v", name, v)
+		}
+		n = int(i)
+	case float64:
+		if v != math.Trunc(v) {
+			return nil, fmt.Errorf("argument %s must be an integer, got %v", name, v)
+		}
+		n = int(v)
+	default:
+		return nil, fmt.Errorf("argument %s must be an integer, got %T", name, raw)
+	}
+	if n < 0 {
+		return nil, fmt.Errorf("argument %s must be non-negative, got %d", name, n)
+	}
+	return &n, nil
+}
+
+func stringArg(args map[string]interface{}, name string) (string, error) {
+	raw, ok := args[name]
+	if !ok || raw == nil {
+		return "", nil
+	}
+	s, ok := raw.(string)
+	if !ok {
+		return "", fmt.Errorf("argument %s must be a string, got %T", name, raw)
+	}
+	return s, nil
+}
+
+const offsetCursorPrefix = "offset:"
+
+// OffsetToCursor encodes a slice offset as an opaque cursor.
+func OffsetToCursor(offset int) string {
+	return base64.StdEncoding.EncodeToString([]byte(offsetCursorPrefix + strconv.Itoa(offset)))
+}
+
+// CursorToOffset decodes a cursor produced by OffsetToCursor.
+func CursorToOffset(cursor string) (int, error) {
+	raw, err := base64.StdEncoding.DecodeString(cursor)
+	if err != nil || !strings.HasPrefix(string(raw), offsetCursorPrefix) {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	offset, err := strconv.Atoi(strings.TrimPrefix(string(raw), offsetCursorPrefix))
+	if err != nil || offset < 0 {
+		return 0, fmt.Errorf("invalid cursor %q", cursor)
+	}
+	return offset, nil
+}
+
+// ConnectionFromSlice builds a connection over an in-memory slice using
+// offset-based cursors. items must be a slice or array.
+func ConnectionFromSlice(items interface{}, args ConnectionArgs) (*Connection, error) {
+	val := reflect.ValueOf(items)
+	for val.Kind() == reflect.Ptr {
+		val = val.Elem()
+	}
+	if items == nil {
+		val = reflect.ValueOf([]interface{}{})
+	}
+	if val.Kind() != reflect.Slice && val.Kind() != reflect.Array {
+		return nil, fmt.Errorf("ConnectionFromSlice expects a slice, got %T", items)
+	}
+	total := val.Len()
+
+	start, end := 0, total
+	if args.After != "" {
+		offset, err := CursorToOffset(args.After)
+		if err != nil {
+			return nil, err
+		}
+		if offset+1 > start {
+			start = min(offset+1, total)
+		}
+	}
+	if args.Before != "" {
+		offset, err := CursorToOffset(args.Before)
+		if err != nil {
+			return nil, err
+		}
+		if offset < end {
+			end = max(offset, start)
+		}
+	}
+	if args.First != nil && end-start > *args.First {
+		end = start + *args.First
+	}
+	if args.Last != nil && end-start > *args.Last {
+		start = end - *args.Last
+	}
+
+	conn := &Connection{Edges: []*Edge{}, TotalCount: total}
+	for i := start; i < end; i++ {
+		conn.Edges = append(conn.Edges, &Edge{Node: val.Index(i).Interface(), Cursor: OffsetToCursor(i)})
+	}
+	conn.PageInfo = pageInfo(conn.Edges, end < total, start > 0)
+	return conn, nil
+}
+
+// Page is a window of results produced by a cursor-based FetchFunc.
+type Page struct {
+	Edges           []*Edge
+	HasNextPage     bool
+	HasPreviousPage bool
+	TotalCount      int
+}
+
+// FetchFunc loads one page of results for the given pagination arguments,
+// typically from a database using keyset pagination.
+type FetchFunc func(ctx context.Context, args ConnectionArgs) (*Page, error)
+
+// ConnectionFromFetch builds a connection from a cursor-based fetch function.
+func ConnectionFromFetch(ctx context.Context, args ConnectionArgs, fetch FetchFunc) (*Connection, error) {
+	page, err := fetch(ctx, args)
+	if err != nil {
+		return nil, err
+	}
+	if page == nil {
+		page = &Page{}
+	}
+	edges := page.Edges
+	if edges == nil {
+		edges = []*Edge{}
+	}
+	return &Connection{
+		Edges:      edges,
+		PageInfo:   pageInfo(edges, page.HasNextPage, page.HasPreviousPage),
+		TotalCount: page.TotalCount,
+	}, nil
+}
+
+func pageInfo(edges []*Edge, hasNext, hasPrev bool) PageInfo {
+	info := PageInfo{HasNextPage: hasNext, HasPreviousPage: hasPrev}
+	if len(edges) > 0 {
+		first, last := edges[0].Cursor, edges[len(edges)-1].Cursor
+		info.StartCursor = &first
+		info.EndCursor = &last
+	}
+	return info
+}
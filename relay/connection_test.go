@@ -0,0 +1,88 @@
+package relay
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+)
+
+func TestParseConnectionArgs(t *testing.T) {
+	args, err := ParseConnectionArgs(map[string]interface{}{"first": float64(2), "after": "abc"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args.First == nil || *args.First != 2 || args.After != "abc" || args.Last != nil {
+		t.Errorf("unexpected args: %+v", args)
+	}
+	// Variables decoded with UseNumber arrive as json.Number.
+	args, err = ParseConnectionArgs(map[string]interface{}{"first": json.Number("3"), "last": int64(4)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if args.First == nil || *args.First != 3 || args.Last == nil || *args.Last != 4 {
+		t.Errorf("unexpected args: %+v", args)
+	}
+	if _, err := ParseConnectionArgs(map[string]interface{}{"first": json.Number("1.5")}); err == nil {
+		t.Error("expected error for fractional json.Number first")
+	}
+	if _, err := ParseConnectionArgs(map[string]interface{}{"first": 1.5}); err == nil {
+		t.Error("expected error for fractional first")
+	}
+	if _, err := ParseConnectionArgs(map[string]interface{}{"last": -1}); err == nil {
+		t.Error("expected error for negative last")
+	}
+	if _, err := ParseConnectionArgs(map[string]interface{}{"before": 3}); err == nil {
+		t.Error("expected error for non-string before")
+	}
+}
+
+func TestConnectionFromSlice(t *testing.T) {
+	items := []string{"a", "b", "c", "d", "e"}
+	two := 2
+
+	conn, err := ConnectionFromSlice(items, ConnectionArgs{First: &two})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.Edges) != 2 || conn.Edges[0].Node != "a" || !conn.PageInfo.HasNextPage || conn.PageInfo.HasPreviousPage {
+		t.Errorf("unexpected first page: %+v", conn)
+	}
+	if conn.TotalCount != 5 {
+		t.Errorf("expected total count 5, got %d", conn.TotalCount)
+	}
+
+	conn, err = ConnectionFromSlice(items, ConnectionArgs{First: &two, After: *conn.PageInfo.EndCursor})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.Edges) != 2 || conn.Edges[0].Node != "c" || !conn.PageInfo.HasPreviousPage {
+		t.Errorf("unexpected second page: %+v", conn)
+	}
+
+	conn, err = ConnectionFromSlice(items, ConnectionArgs{Last: &two, Before: OffsetToCursor(4)})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(conn.Edges) != 2 || conn.Edges[0].Node != "c" || conn.Edges[1].Node != "d" || !conn.PageInfo.HasNextPage {
+		t.Errorf("unexpected backward page: %+v", conn)
+	}
+
+	if _, err := ConnectionFromSlice(items, ConnectionArgs{After: "bogus"}); err == nil {
+		t.Error("expected error for invalid cursor")
+	}
+	if _, err := ConnectionFromSlice(42, ConnectionArgs{}); err == nil {
+		t.Error("expected error for non-slice input")
+	}
+}
+
+func TestConnectionFromFetch(t *testing.T) {
+	conn, err := ConnectionFromFetch(context.Background(), ConnectionArgs{}, func(ctx context.Context, args ConnectionArgs) (*Page, error) {
+		return &Page{Edges: []*Edge{{Node: 1, Cursor: "x"}, {Node: 2, Cursor: "y"}}, HasNextPage: true}, nil
+	})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if *conn.PageInfo.StartCursor != "x" || *conn.PageInfo.EndCursor != "y" || !conn.PageInfo.HasNextPage {
+		t.Errorf("unexpected page info: %+v", conn.PageInfo)
+	}
+}
@@ -0,0 +1,69 @@
+package relay
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+	"sync"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+// ToGlobalID encodes a type name and a type-specific ID into an opaque global ID.
+func ToGlobalID(typeName, id string) string {
+	return base64.StdEncoding.EncodeToString([]byte(typeName + ":" + id))
+}
+
+// FromGlobalID decodes a global ID produced by ToGlobalID.
+func FromGlobalID(globalID string) (typeName, id string, err error) {
+	raw, err := base64.StdEncoding.DecodeString(globalID)
+	if err != nil {
+		return "", "", fmt.Errorf("invalid global ID %q", globalID)
+	}
+	typeName, id, ok := strings.Cut(string(raw), ":")
+	if !ok || typeName == "" {
+		return "", "", fmt.Errorf("invalid global ID %q", globalID)
+	}
+	return typeName, id, nil
+}
+
+// NodeFetcher loads the object of a given type by its type-specific ID.
+type NodeFetcher func(ctx context.Context, id string) (interface{}, error)
+
+var (
+	nodeMu       sync.RWMutex
+	nodeFetchers = make(map[string]NodeFetcher)
+	nodeOnce     sync.Once
+)
+
+// RegisterNodeFetcher registers the fetcher for typeName and installs the
+// built-in node(id: ID!) root field on first use.
+func RegisterNodeFetcher(typeName string, fetch NodeFetcher) {
+	nodeMu.Lock()
+	nodeFetchers[typeName] = fetch
+	nodeMu.Unlock()
+	nodeOnce.Do(func() {
+		graphql.RegisterFieldResolver("Query", "node", resolveNode)
+	})
+}
+
+// resolveNode resolves the node root field by decoding the global ID and
+// dispatching to the fetcher registered for its type.
+func resolveNode(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+	globalID, ok := args["id"].(string)
+	if !ok {
+		return nil, fmt.Errorf("node: id argument missing or not a string")
+	}
+	typeName, id, err := FromGlobalID(globalID)
+	if err != nil {
+		return nil, err
+	}
+	nodeMu.RLock()
+	fetch, ok := nodeFetchers[typeName]
+	nodeMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("node: no fetcher registered for type %s", typeName)
+	}
+	return fetch(ctx, id)
+}
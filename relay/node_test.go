@@ -0,0 +1,56 @@
+package relay
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"testing"
+
+	graphql "github.com/Raezil/vibeGraphql"
+)
+
+type testUser struct {
+	ID   string `json:"id"`
+	Name string `json:"name"`
+}
+
+func TestGlobalIDRoundTrip(t *testing.T) {
+	gid := ToGlobalID("User", "42")
+	typeName, id, err := FromGlobalID(gid)
+	if err != nil || typeName != "User" || id != "42" {
+		t.Errorf("unexpected decode result: %q %q %v", typeName, id, err)
+	}
+	if _, _, err := FromGlobalID("not base64!"); err == nil {
+		t.Error("expected error for invalid global ID")
+	}
+}
+
+func TestNodeField(t *testing.T) {
+	RegisterNodeFetcher("User", func(ctx context.Context, id string) (interface{}, error) {
+		if id != "42" {
+			return nil, fmt.Errorf("user %s not found", id)
+		}
+		return &testUser{ID: ToGlobalID("User", id), Name: "Ada"}, nil
+	})
+
+	body, _ := json.Marshal(map[string]interface{}{
+		"query":     `query ($id: ID!) { node(id: $id) { __typename name } }`,
+		"variables": map[string]interface{}{"id": ToGlobalID("User", "42")},
+	})
+	w := httptest.NewRecorder()
+	graphql.GraphqlHandler(w, httptest.NewRequest("POST", "/graphql", bytes.NewReader(body)))
+
+	var resp struct {
+		Data struct {
+			Node map[string]interface{} `json:"node"`
+		} `json:"data"`
+	}
+	if err := json.NewDecoder(w.Body).Decode(&resp); err != nil {
+		t.Fatalf("invalid response: %v", err)
+	}
+	if resp.Data.Node["name"] != "Ada" || resp.Data.Node["__typename"] != "testUser" {
+		t.Errorf("unexpected node: %v", resp.Data.Node)
+	}
+}
@@ -0,0 +1,127 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ReloadSchema parses sdl and makes it the schema used for new requests.
+// Requests already executing keep the schema they started with. The settings
+// of the current schema, such as Authorize and DefaultResolver, carry over.
+// The reload is rejected, leaving the current schema in place, if sdl is
+// invalid or if a registered resolver has no field in the new schema.
+func ReloadSchema(sdl string) (*Schema, error) {
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		return nil, fmt.Errorf("schema reload rejected: %w", err)
+	}
+	if unbound := unboundResolvers(s); len(unbound) > 0 {
+		return nil, fmt.Errorf("schema reload rejected: no field for the resolvers of %s", strings.Join(unbound, ", "))
+	}
+	if current := CurrentSchema(); current != nil {
+		s.Authorize = current.Authorize
+		s.DefaultResolver = current.DefaultResolver
+		s.DisableIntrospection = current.DisableIntrospection
+		s.DisableSuggestions = current.DisableSuggestions
+		s.Visible = current.Visible
+		s.ValidationRules = current.ValidationRules
+	}
+	UseSchema(s)
+	return s, nil
+}
+
+// unboundResolvers returns the sorted "Type.field" names of the registered
+// resolvers whose fields s does not define.
+func unboundResolvers(s *Schema) []string {
+	var unbound []string
+	check := func(typeName, field string) {
+		if s.Field(typeName, field) == nil {
+			unbound = append(unbound, typeName+"."+field)
+		}
+	}
+	roots := map[string]string{"Query": s.QueryType, "Mutation": s.MutationType, "Subscription": s.SubscriptionType}
+	for field := range QueryResolvers {
+		check(s.QueryType, field)
+	}
+	for field := range MutationResolvers {
+		check(s.MutationType, field)
+	}
+	for field := range SubscriptionResolvers {
+		check(s.SubscriptionType, field)
+	}
+	for typeName, fields := range FieldResolvers {
+		if root, ok := roots[typeName]; ok {
+			typeName = root
+		}
+		for field := range fields {
+			check(typeName, field)
+		}
+	}
+	sort.Strings(unbound)
+	return unbound
+}
+
+// SchemaSource returns the current schema SDL.
+type SchemaSource func(ctx context.Context) (string, error)
+
+// SchemaFiles returns a SchemaSource reading and concatenating the SDL files
+// at paths.
+func SchemaFiles(paths ...string) SchemaSource {
+	return func(ctx context.Context) (string, error) {
+		var sb strings.Builder
+		for _, path := range paths {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			sb.Write(data)
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	}
+}
+
+// WatchSchema loads the schema from source and then checks source every
+// interval until ctx is done, reloading the schema with ReloadSchema whenever
+// the SDL changes. It returns an error if the initial load fails. Rejected
+// reloads are logged and the previous schema stays in use.
+func WatchSchema(ctx context.Context, source SchemaSource, interval time.Duration) error {
+	sdl, err := source(ctx)
+	if err != nil {
+		return err
+	}
+	if _, err := ReloadSchema(sdl); err != nil {
+		return err
+	}
+	go func() {
+		ticker := time.NewTicker(interval)
+		defer ticker.Stop()
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case <-ticker.C:
+			}
+			next, err := source(ctx)
+			if err != nil {
+				logger().ErrorContext(ctx, "graphql: failed to load schema", "error", err)
+				continue
+			}
+			if next == sdl {
+				continue
+			}
+			// A rejected SDL is not retried until it changes again.
+			sdl = next
+			if _, err := ReloadSchema(next); err != nil {
+				logger().ErrorContext(ctx, "graphql: "+err.Error())
+				continue
+			}
+			logger().InfoContext(ctx, "graphql: schema reloaded")
+		}
+	}()
+	return nil
+}
@@ -0,0 +1,84 @@
+package vibeGraphql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+// useResolvers replaces the resolver registries for the duration of the test.
+func useResolvers(t testing.TB, query map[string]ResolverFunc, fields map[string]map[string]ContextResolverFunc) {
+	t.Helper()
+	prevQuery, prevMutation, prevSubscription, prevFields := QueryResolvers, MutationResolvers, SubscriptionResolvers, FieldResolvers
+	QueryResolvers, MutationResolvers, SubscriptionResolvers, FieldResolvers = query, map[string]ResolverFunc{}, map[string]ResolverFunc{}, fields
+	t.Cleanup(func() {
+		QueryResolvers, MutationResolvers, SubscriptionResolvers, FieldResolvers = prevQuery, prevMutation, prevSubscription, prevFields
+	})
+}
+
+func TestReloadSchema(t *testing.T) {
+	resolve := func(source interface{}, args map[string]interface{}) (interface{}, error) { return "x", nil }
+	useResolvers(t, map[string]ResolverFunc{"hello": resolve}, map[string]map[string]ContextResolverFunc{
+		"User": {"name": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return "x", nil
+		}},
+	})
+	current := useTestSchema(t, `type Query { hello: String } type User { name: String }`)
+	current.DisableIntrospection = true
+
+	s, err := ReloadSchema(`type Query { hello: String goodbye: String } type User { name: String }`)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if CurrentSchema() != s || s.Field("Query", "goodbye") == nil || !s.DisableIntrospection {
+		t.Errorf("expected the new schema to be current with the previous settings")
+	}
+
+	_, err = ReloadSchema(`type Query { goodbye: String } type User { id: ID }`)
+	if err == nil || err.Error() != "schema reload rejected: no field for the resolvers of Query.hello, User.name" {
+		t.Errorf("unexpected error: %v", err)
+	}
+	if _, err := ReloadSchema(`type Query {`); err == nil {
+		t.Error("expected invalid SDL to be rejected")
+	}
+	if CurrentSchema() != s {
+		t.Error("rejected reloads should keep the current schema")
+	}
+}
+
+func TestWatchSchema(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{})
+	t.Cleanup(func() { UseSchema(nil) })
+	dir := t.TempDir()
+	path := filepath.Join(dir, "schema.graphql")
+	if err := os.WriteFile(path, []byte(`type Query { a: String }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	if err := WatchSchema(ctx, SchemaFiles(path), 5*time.Millisecond); err != nil {
+		t.Fatal(err)
+	}
+	if CurrentSchema().Field("Query", "a") == nil {
+		t.Fatal("expected the initial schema to be loaded")
+	}
+
+	if err := os.WriteFile(path, []byte(`type Query { b: String }`), 0o644); err != nil {
+		t.Fatal(err)
+	}
+	deadline := time.Now().Add(2 * time.Second)
+	for CurrentSchema().Field("Query", "b") == nil {
+		if time.Now().After(deadline) {
+			t.Fatal("schema was not reloaded")
+		}
+		time.Sleep(5 * time.Millisecond)
+	}
+
+	if err := WatchSchema(ctx, SchemaFiles(filepath.Join(dir, "missing.graphql")), time.Second); err == nil ||
+		!strings.Contains(err.Error(), "missing.graphql") {
+		t.Errorf("expected initial load to fail, got %v", err)
+	}
+}
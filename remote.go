@@ -0,0 +1,242 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strings"
+)
+
+// remoteIntrospectionQuery fetches the root operation types and their fields.
+const remoteIntrospectionQuery = `query {
+  __schema {
+    queryType { name fields { name } }
+    mutationType { name fields { name } }
+  }
+}`
+
+// RemoteSchema delegates root fields to a downstream GraphQL endpoint,
+// turning this server into a lightweight gateway.
+type RemoteSchema struct {
+	URL    string
+	Client *http.Client
+	Header http.Header
+
+	// Root fields discovered by Introspect.
+	QueryFields    []string
+	MutationFields []string
+}
+
+// NewRemoteSchema creates a RemoteSchema for the endpoint at url.
+func NewRemoteSchema(url string) *RemoteSchema {
+	return &RemoteSchema{URL: url, Client: http.DefaultClient, Header: http.Header{}}
+}
+
+// RemoteError is returned when the downstream endpoint reports GraphQL errors.
+type RemoteError struct {
+	Messages []string
+}
+
+func (e *RemoteError) Error() string {
+	return "remote: " + strings.Join(e.Messages, "; ")
+}
+
+// Introspect queries the downstream endpoint for its root fields.
+func (rs *RemoteSchema) Introspect(ctx context.Context) error {
+	data, err := rs.do(ctx, remoteIntrospectionQuery, nil)
+	if err != nil {
+		return err
+	}
+	var result struct {
+		Schema struct {
+			QueryType    *remoteRootType `json:"queryType"`
+			MutationType *remoteRootType `json:"mutationType"`
+		} `json:"__schema"`
+	}
+	if err := json.Unmarshal(data, &result); err != nil {
+		return fmt.Errorf("remote: invalid introspection result: %v", err)
+	}
+	rs.QueryFields = result.Schema.QueryType.fieldNames()
+	rs.MutationFields = result.Schema.MutationType.fieldNames()
+	return nil
+}
+
+type remoteRootType struct {
+	Name   string `json:"name"`
+	Fields []struct {
+		Name string `json:"name"`
+	} `json:"fields"`
+}
+
+func (t *remoteRootType) fieldNames() []string {
+	if t == nil {
+		return nil
+	}
+	names := make([]string, 0, len(t.Fields))
+	for _, f := range t.Fields {
+		names = append(names, f.Name)
+	}
+	return names
+}
+
+// Delegate registers pass-through resolvers for the given root fields. When no
+// fields are given, every field discovered by Introspect is delegated.
+func (rs *RemoteSchema) Delegate(fields ...string) error {
+	selected := make(map[string]bool, len(fields))
+	for _, f := range fields {
+		selected[f] = true
+	}
+	found := make(map[string]bool, len(fields))
+	register := func(typeName, operation string, names []string) {
+		for _, name := range names {
+			if len(selected) > 0 && !selected[name] {
+				continue
+			}
+			found[name] = true
+			RegisterFieldResolver(typeName, name, rs.resolver(operation))
+		}
+	}
+	register("Query", "query", rs.QueryFields)
+	register("Mutation", "mutation", rs.MutationFields)
+	for _, f := range fields {
+		if !found[f] {
+			return fmt.Errorf("remote: field %s not found on remote schema", f)
+		}
+	}
+	return nil
+}
+
+// resolver returns a resolver forwarding the current field, its arguments,
+// its selection set and the variables it references to the remote endpoint.
+func (rs *RemoteSchema) resolver(operation string) ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		info := ResolveInfoFromContext(ctx)
+		if info == nil {
+			return nil, fmt.Errorf("remote: missing resolve info")
+		}
+		query, variables := buildRemoteQuery(operation, info)
+		data, err := rs.do(ctx, query, variables)
+		if err != nil {
+			return nil, err
+		}
+		var result map[string]interface{}
+		if err := json.Unmarshal(data, &result); err != nil {
+			return nil, fmt.Errorf("remote: invalid response data: %v", err)
+		}
+		return remoteValue(result[info.Field.ResponseKey()]), nil
+	}
+}
+
+// remoteObject is an object of a remote response. The forwarded query keeps
+// the aliases of nested fields, so its fields resolve by response key rather
+// than by name.
+type remoteObject map[string]interface{}
+
+// graphqlTypeName returns the __typename the remote endpoint gave the
+// object, if it was selected.
+func (o remoteObject) graphqlTypeName() string {
+	name, _ := o["__typename"].(string)
+	return name
+}
+
+// remoteValue converts the objects of a decoded remote response to
+// remoteObjects.
+func remoteValue(v interface{}) interface{} {
+	switch v := v.(type) {
+	case map[string]interface{}:
+		obj := make(remoteObject, len(v))
+		for key, item := range v {
+			obj[key] = remoteValue(item)
+		}
+		return obj
+	case []interface{}:
+		for i, item := range v {
+			v[i] = remoteValue(item)
+		}
+	}
+	return v
+}
+
+// buildRemoteQuery prints a single-field operation for the field in info,
+// declaring only the variables that the field actually references.
+func buildRemoteQuery(operation string, info *ResolveInfo) (string, map[string]interface{}) {
+	used := make(map[string]bool)
+	for _, arg := range info.Field.Arguments {
+		collectValueVariables(arg.Value, used)
+	}
+	collectVariables(info.Field.SelectionSet, used)
+
+	var sb strings.Builder
+	sb.WriteString(operation)
+	variables := make(map[string]interface{})
+	if info.Operation != nil && len(used) > 0 {
+		var defs []VariableDefinition
+		for _, vd := range info.Operation.VariableDefinitions {
+			if !used[vd.Variable] {
+				continue
+			}
+			defs = append(defs, vd)
+			if v, ok := info.Variables[vd.Variable]; ok {
+				variables[vd.Variable] = v
+			}
+		}
+		writeVariableDefinitions(&sb, defs)
+	}
+	sb.WriteString(" {")
+	writeField(&sb, info.Field)
+	sb.WriteString("}")
+	for _, def := range spreadFragments(info.Field.SelectionSet) {
+		sb.WriteString(" ")
+		writeFragmentDefinition(&sb, def)
+	}
+	return sb.String(), variables
+}
+
+// do posts a query to the remote endpoint and returns the raw "data" member.
+func (rs *RemoteSchema) do(ctx context.Context, query string, variables map[string]interface{}) (json.RawMessage, error) {
+	body, err := json.Marshal(map[string]interface{}{
+		"query":     query,
+		"variables": variables,
+	})
+	if err != nil {
+		return nil, err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, rs.URL, bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	for key, values := range rs.Header {
+		for _, v := range values {
+			req.Header.Add(key, v)
+		}
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := rs.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("remote: %v", err)
+	}
+	defer resp.Body.Close()
+	var payload struct {
+		Data   json.RawMessage `json:"data"`
+		Errors []struct {
+			Message string `json:"message"`
+		} `json:"errors"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&payload); err != nil {
+		return nil, fmt.Errorf("remote: invalid response (status %d): %v", resp.StatusCode, err)
+	}
+	if len(payload.Errors) > 0 {
+		remoteErr := &RemoteError{}
+		for _, e := range payload.Errors {
+			remoteErr.Messages = append(remoteErr.Messages, e.Message)
+		}
+		return nil, remoteErr
+	}
+	return payload.Data, nil
+}
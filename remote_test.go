@@ -0,0 +1,110 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestRemoteSchemaDelegation(t *testing.T) {
+	var forwarded struct {
+		Query     string                 `json:"query"`
+		Variables map[string]interface{} `json:"variables"`
+	}
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query     string                 `json:"query"`
+			Variables map[string]interface{} `json:"variables"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		w.Header().Set("Content-Type", "application/json")
+		if strings.Contains(req.Query, "__schema") {
+			w.Write([]byte(`{"data":{"__schema":{"queryType":{"name":"Query","fields":[{"name":"remoteUser"},{"name":"remoteOther"}]},"mutationType":null}}}`))
+			return
+		}
+		forwarded = req
+		w.Write([]byte(`{"data":{"remoteUser":{"id":"7","name":"Remote"}}}`))
+	}))
+	defer downstream.Close()
+
+	rs := NewRemoteSchema(downstream.URL)
+	if err := rs.Introspect(context.Background()); err != nil {
+		t.Fatalf("Introspect error: %v", err)
+	}
+	if len(rs.QueryFields) != 2 {
+		t.Fatalf("expected 2 query fields, got %v", rs.QueryFields)
+	}
+	if err := rs.Delegate("remoteUser"); err != nil {
+		t.Fatalf("Delegate error: %v", err)
+	}
+	if err := rs.Delegate("missing"); err == nil {
+		t.Error("expected error delegating unknown field")
+	}
+
+	query := `query Get($id: ID!, $skip: Boolean) { remoteUser(id: $id) { id ...userName } __typename @skip(if: $skip) }
+		fragment userName on User { name }`
+	doc := NewParser(NewLexer(query)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, map[string]interface{}{"id": "7", "skip": true})
+	if err != nil {
+		t.Fatalf("executeDocument error: %v", err)
+	}
+	user := resp["data"].(map[string]interface{})["remoteUser"].(map[string]interface{})
+	if user["name"] != "Remote" {
+		t.Errorf("expected delegated name 'Remote', got %v", user["name"])
+	}
+	if forwarded.Query != `query($id: ID!) {remoteUser(id: $id) {id ...userName}} fragment userName on User {name}` {
+		t.Errorf("unexpected forwarded query: %s", forwarded.Query)
+	}
+	if forwarded.Variables["id"] != "7" || len(forwarded.Variables) != 1 {
+		t.Errorf("expected only the id variable to be forwarded, got %v", forwarded.Variables)
+	}
+}
+
+func TestRemoteSchemaErrors(t *testing.T) {
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Write([]byte(`{"errors":[{"message":"boom"}]}`))
+	}))
+	defer downstream.Close()
+
+	rs := NewRemoteSchema(downstream.URL)
+	err := rs.Introspect(context.Background())
+	remoteErr, ok := err.(*RemoteError)
+	if !ok || remoteErr.Messages[0] != "boom" {
+		t.Fatalf("expected RemoteError with 'boom', got %v", err)
+	}
+}
+
+func TestRemoteSchemaDelegation_AliasedNestedFields(t *testing.T) {
+	var forwarded string
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Query string `json:"query"`
+		}
+		json.NewDecoder(r.Body).Decode(&req)
+		forwarded = req.Query
+		w.Write([]byte(`{"data":{"u":{"n":"Remote","small":"s.png","large":"l.png","friends":[{"f":"Friend"}]}}}`))
+	}))
+	defer downstream.Close()
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `
+		type Query { remoteUser: User }
+		type User { name: String avatar(size: Int): String friends: [User] }
+	`)
+	rs := NewRemoteSchema(downstream.URL)
+	rs.QueryFields = []string{"remoteUser"}
+	if err := rs.Delegate(); err != nil {
+		t.Fatal(err)
+	}
+
+	data := executeQuery(t, `{ u: remoteUser { n: name small: avatar(size: 1) large: avatar(size: 2) friends { f: name } } }`, nil)
+	if forwarded != `query {u: remoteUser {n: name small: avatar(size: 1) large: avatar(size: 2) friends {f: name}}}` {
+		t.Errorf("unexpected forwarded query: %s", forwarded)
+	}
+	got, _ := json.Marshal(data)
+	if want := `{"u":{"friends":[{"f":"Friend"}],"large":"l.png","n":"Remote","small":"s.png"}}`; string(got) != want {
+		t.Errorf("got %s, want %s", got, want)
+	}
+}
@@ -0,0 +1,85 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http"
+)
+
+// DefaultRequestIDHeader is the header WithRequestID uses without one.
+const DefaultRequestIDHeader = "X-Request-ID"
+
+// maxRequestIDLength bounds the request IDs adopted from clients.
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+
+// WithRequestID gives every request an ID, making the requests behind
+// support tickets easy to find. The ID is taken from the request header
+// named header (X-Request-ID if empty) when the client or a proxy sent a
+// valid one, and generated otherwise. It is available to resolvers through
+// RequestID, reported in RequestLog.RequestID and in a "requestId" extension
+// of the response's errors, and echoed in the same response header.
+func WithRequestID(header string) HandlerOption {
+	if header == "" {
+		header = DefaultRequestIDHeader
+	}
+	return func(h *handler) {
+		h.requestIDHeader = header
+	}
+}
+
+// RequestID returns the ID of the request being served, or "" if the
+// handler does not assign IDs; see WithRequestID.
+func RequestID(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// withRequestID returns r with an ID in its context and sets the ID on the
+// response header, if the handler assigns IDs.
+func (h *handler) withRequestID(w http.ResponseWriter, r *http.Request) *http.Request {
+	if h.requestIDHeader == "" {
+		return r
+	}
+	id := r.Header.Get(h.requestIDHeader)
+	if !validRequestID(id) {
+		id = newCorrelationID()
+	}
+	w.Header().Set(h.requestIDHeader, id)
+	return r.WithContext(context.WithValue(r.Context(), requestIDKey{}, id))
+}
+
+// validRequestID reports whether id is short and made of printable ASCII
+// characters, so that it is safe to log and echo.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] <= ' ' || id[i] > '~' {
+			return false
+		}
+	}
+	return true
+}
+
+// withRequestIDs returns copies of errs with a "requestId" extension holding
+// the ID of the request in ctx. Errors can be shared between requests, as
+// with cached parse errors, so they are not changed in place.
+func withRequestIDs(ctx context.Context, errs []*Error) []*Error {
+	id := RequestID(ctx)
+	if id == "" {
+		return errs
+	}
+	tagged := make([]*Error, len(errs))
+	for i, err := range errs {
+		copied := *err
+		copied.Extensions = make(map[string]interface{}, len(err.Extensions)+1)
+		for key, value := range err.Extensions {
+			copied.Extensions[key] = value
+		}
+		copied.Extensions["requestId"] = id
+		tagged[i] = &copied
+	}
+	return tagged
+}
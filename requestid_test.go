@@ -0,0 +1,71 @@
+package vibeGraphql
+
+import (
+	"context"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestWithRequestID(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Query": {"requestId": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			return RequestID(ctx), nil
+		}},
+	})
+	useTestSchema(t, `type Query { requestId: String }`)
+	var logged string
+	h := newHandler([]HandlerOption{
+		WithRequestID(""),
+		WithLogger(func(ctx context.Context, entry RequestLog) { logged = entry.RequestID }),
+	})
+	send := func(query, id string) *httptest.ResponseRecorder {
+		req := httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "`+query+`"}`))
+		if id != "" {
+			req.Header.Set("X-Request-ID", id)
+		}
+		rr := httptest.NewRecorder()
+		h.serveUpload(rr, req)
+		return rr
+	}
+
+	rr := send("{ requestId }", "support-42")
+	if body := strings.TrimSpace(rr.Body.String()); body != `{"data":{"requestId":"support-42"}}` {
+		t.Errorf("unexpected response %s", body)
+	}
+	if rr.Header().Get("X-Request-ID") != "support-42" || logged != "support-42" {
+		t.Errorf("expected the ID to be echoed and logged, got %q and %q", rr.Header().Get("X-Request-ID"), logged)
+	}
+
+	for _, id := range []string{"", "has space", strings.Repeat("x", 129)} {
+		rr = send("{ requestId }", id)
+		generated := rr.Header().Get("X-Request-ID")
+		if generated == "" || generated == id || !strings.Contains(rr.Body.String(), `"requestId":"`+generated+`"`) {
+			t.Errorf("sent %q: expected a generated ID, got %q with %s", id, generated, rr.Body.String())
+		}
+	}
+
+	rr = send("{ nope }", "support-43")
+	if !strings.Contains(rr.Body.String(), `"requestId":"support-43"`) {
+		t.Errorf("expected errors to carry the request ID, got %s", rr.Body.String())
+	}
+
+	// Without the option no ID is assigned.
+	rr = httptest.NewRecorder()
+	GraphqlHandler(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ requestId }"}`)))
+	if rr.Header().Get("X-Request-ID") != "" || !strings.Contains(rr.Body.String(), `"requestId":""`) {
+		t.Errorf("unexpected response without request IDs %v %s", rr.Header(), rr.Body.String())
+	}
+}
+
+func TestWithRequestIDs_SharedErrors(t *testing.T) {
+	shared := []*Error{NewError("BAD", "bad")}
+	ctx := context.WithValue(context.Background(), requestIDKey{}, "abc")
+	tagged := withRequestIDs(ctx, shared)
+	if tagged[0].Extensions["requestId"] != "abc" || tagged[0].Extensions["code"] != "BAD" {
+		t.Errorf("unexpected extensions %v", tagged[0].Extensions)
+	}
+	if _, changed := shared[0].Extensions["requestId"]; changed {
+		t.Error("expected the original error to be left unchanged")
+	}
+}
@@ -0,0 +1,103 @@
+package vibeGraphql
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"time"
+)
+
+// Cache statuses reported in the "resolverCache" response extension.
+const (
+	ResolverCacheHit  = "HIT"
+	ResolverCacheMiss = "MISS"
+)
+
+// ResolverCache caches the results of expensive resolvers whose results
+// depend only on their arguments, such as exchange rates. Results are stored
+// as JSON, so a cached result reaches child fields as decoded JSON: objects
+// become maps and numbers float64. Errors are never cached.
+type ResolverCache struct {
+	// Store holds the cached results; NewMemoryCache returns an in-process
+	// store. Nothing is cached when it is nil.
+	Store CacheStore
+	// TTL is how long a result is cached. Nothing is cached when it is zero.
+	TTL time.Duration
+	// Key returns the key a call is cached under, or "" to skip the cache.
+	// It defaults to the field's "Type.field" and its arguments, which
+	// ignores the parent object; provide Key when caching fields of types
+	// other than the root types, or when results depend on the user.
+	Key func(ctx context.Context, source interface{}, args map[string]interface{}) string
+}
+
+// Wrap returns resolver cached according to c:
+//
+//	rates := &ResolverCache{Store: NewMemoryCache(1000), TTL: time.Minute}
+//	RegisterFieldResolver("Query", "exchangeRate", rates.Wrap(fetchRate))
+//
+// Whether each call was served from the cache is reported in the
+// "resolverCache" response extension.
+func (c *ResolverCache) Wrap(resolver ContextResolverFunc) ContextResolverFunc {
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		key := c.key(ctx, source, args)
+		if key == "" {
+			return resolver(ctx, source, args)
+		}
+		if cached, ok := c.Store.Get(ctx, key); ok {
+			var res interface{}
+			if err := json.Unmarshal(cached, &res); err == nil {
+				traceResolverCache(ctx, ResolverCacheHit)
+				return res, nil
+			}
+		}
+		res, err := resolver(ctx, source, args)
+		traceResolverCache(ctx, ResolverCacheMiss)
+		if err != nil {
+			return res, err
+		}
+		if value, err := json.Marshal(res); err == nil {
+			c.Store.Set(ctx, key, value, c.TTL)
+		}
+		return res, nil
+	}
+}
+
+// key returns the store key of a call, or "" if it may not be cached.
+func (c *ResolverCache) key(ctx context.Context, source interface{}, args map[string]interface{}) string {
+	if c.Store == nil || c.TTL <= 0 {
+		return ""
+	}
+	var key string
+	if c.Key != nil {
+		key = c.Key(ctx, source, args)
+		if key == "" {
+			return ""
+		}
+	} else {
+		encoded, err := json.Marshal(args)
+		if err != nil {
+			return ""
+		}
+		key = fieldCoordinate(ctx) + "\x00" + string(encoded)
+	}
+	// Hashing keeps the keys apart from those of the response cache when
+	// both share a store.
+	sum := sha256.Sum256([]byte("resolver\x00" + key))
+	return hex.EncodeToString(sum[:])
+}
+
+// traceResolverCache adds the cache status of the field being resolved to
+// the "resolverCache" extension.
+func traceResolverCache(ctx context.Context, status string) {
+	entry := map[string]interface{}{"status": status}
+	if info := ResolveInfoFromContext(ctx); info != nil {
+		entry["path"] = info.Path
+	}
+	updateResponseExtension(ctx, "resolverCache", func(value interface{}) interface{} {
+		// The list is copied rather than appended to in place, since a
+		// response may already be encoding the previous value.
+		entries, _ := value.([]interface{})
+		return append(entries[:len(entries):len(entries)], entry)
+	})
+}
@@ -0,0 +1,98 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestResolverCache(t *testing.T) {
+	type rate struct {
+		Currency string  `json:"currency"`
+		Value    float64 `json:"value"`
+	}
+	calls := 0
+	fail := false
+	rates := &ResolverCache{Store: NewMemoryCache(0), TTL: time.Minute}
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Query": {
+			"rate": rates.Wrap(func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				calls++
+				if fail {
+					return nil, NewError(CodeUnavailable, "backend down")
+				}
+				return rate{Currency: args["currency"].(string), Value: 1.25}, nil
+			}),
+		},
+	})
+
+	send := func(query string) (map[string]interface{}, []interface{}) {
+		rr := httptest.NewRecorder()
+		body, _ := json.Marshal(map[string]string{"query": query})
+		GraphqlHandler.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+		var resp struct {
+			Data       map[string]interface{}
+			Extensions map[string]interface{}
+		}
+		if err := json.Unmarshal(rr.Body.Bytes(), &resp); err != nil {
+			t.Fatal(err, rr.Body.String())
+		}
+		statuses, _ := resp.Extensions["resolverCache"].([]interface{})
+		return resp.Data, statuses
+	}
+
+	want := map[string]interface{}{"rate": map[string]interface{}{"currency": "EUR", "value": 1.25}}
+	data, statuses := send(`{ rate(currency: "EUR") { currency value } }`)
+	if !reflect.DeepEqual(data, want) || len(statuses) != 1 || statuses[0].(map[string]interface{})["status"] != ResolverCacheMiss {
+		t.Fatalf("unexpected first response %v %v", data, statuses)
+	}
+	data, statuses = send(`{ rate(currency: "EUR") { currency value } }`)
+	if !reflect.DeepEqual(data, want) || len(statuses) != 1 || statuses[0].(map[string]interface{})["status"] != ResolverCacheHit {
+		t.Fatalf("unexpected cached response %v %v", data, statuses)
+	}
+	if calls != 1 {
+		t.Errorf("expected a single call, got %d", calls)
+	}
+
+	// Other arguments are cached separately, and errors are not cached.
+	fail = true
+	send(`{ rate(currency: "USD") { value } }`)
+	send(`{ rate(currency: "USD") { value } }`)
+	if calls != 3 {
+		t.Errorf("expected errors to be retried, got %d calls", calls)
+	}
+}
+
+func TestResolverCacheKey(t *testing.T) {
+	store := NewMemoryCache(0)
+	calls := 0
+	cache := &ResolverCache{Store: store, TTL: time.Minute, Key: func(ctx context.Context, source interface{}, args map[string]interface{}) string {
+		user, _ := source.(string)
+		return user
+	}}
+	count := func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return "value", nil
+	}
+	resolver := cache.Wrap(count)
+	for _, source := range []string{"alice", "alice", "bob", ""} {
+		if res, err := resolver(context.Background(), source, nil); err != nil || res != "value" {
+			t.Fatalf("unexpected result %v, %v", res, err)
+		}
+	}
+	// alice is served from the cache once; an empty key is never cached.
+	if calls != 3 {
+		t.Errorf("expected 3 calls, got %d", calls)
+	}
+
+	uncached := (&ResolverCache{Store: store}).Wrap(count)
+	uncached(context.Background(), "carol", nil)
+	uncached(context.Background(), "carol", nil)
+	if calls != 5 {
+		t.Errorf("expected no caching without a TTL, got %d calls", calls)
+	}
+}
@@ -0,0 +1,92 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// RetryPolicy retries a resolver whose downstream calls fail intermittently.
+type RetryPolicy struct {
+	// MaxAttempts is the number of times the resolver runs at most,
+	// including the first. It defaults to 3.
+	MaxAttempts int
+	// Backoff is the delay before the first retry; it doubles for every
+	// further retry, up to MaxBackoff. It defaults to 100ms.
+	Backoff    time.Duration
+	MaxBackoff time.Duration
+	// Retryable reports whether a failed attempt should be retried. By
+	// default every error is retried except GraphQL errors, such as a denied
+	// permission, and context cancellation.
+	Retryable func(err error) bool
+}
+
+// Retry returns resolver wrapped with policy:
+//
+//	RegisterFieldResolver("User", "orders", Retry(RetryPolicy{MaxAttempts: 4}, fetchOrders))
+//
+// Retries stop when the field's context is done. The number of attempts is
+// reported for each field in the "retries" list of the response's "tracing"
+// extension.
+func Retry(policy RetryPolicy, resolver ContextResolverFunc) ContextResolverFunc {
+	if policy.MaxAttempts <= 0 {
+		policy.MaxAttempts = 3
+	}
+	if policy.Backoff <= 0 {
+		policy.Backoff = 100 * time.Millisecond
+	}
+	if policy.Retryable == nil {
+		policy.Retryable = retryable
+	}
+	return func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		delay := policy.Backoff
+		attempts := 0
+		for {
+			attempts++
+			res, err := resolver(ctx, source, args)
+			if err == nil || attempts >= policy.MaxAttempts || !policy.Retryable(err) {
+				traceAttempts(ctx, attempts)
+				return res, err
+			}
+			timer := time.NewTimer(delay)
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				traceAttempts(ctx, attempts)
+				return res, err
+			case <-timer.C:
+			}
+			delay *= 2
+			if policy.MaxBackoff > 0 && delay > policy.MaxBackoff {
+				delay = policy.MaxBackoff
+			}
+		}
+	}
+}
+
+// retryable is the default RetryPolicy.Retryable.
+func retryable(err error) bool {
+	var gqlErr *Error
+	return !errors.As(err, &gqlErr) && !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// traceAttempts adds the number of attempts made for the field being resolved
+// to the "tracing" extension.
+func traceAttempts(ctx context.Context, attempts int) {
+	entry := map[string]interface{}{"attempts": attempts}
+	if info := ResolveInfoFromContext(ctx); info != nil {
+		entry["path"] = info.Path
+	}
+	updateResponseExtension(ctx, "tracing", func(value interface{}) interface{} {
+		// The entry is copied rather than modified, since a response may
+		// already be encoding the previous value.
+		previous, _ := value.(map[string]interface{})
+		tracing := make(map[string]interface{}, len(previous)+1)
+		for k, v := range previous {
+			tracing[k] = v
+		}
+		retries, _ := previous["retries"].([]interface{})
+		tracing["retries"] = append(retries[:len(retries):len(retries)], entry)
+		return tracing
+	})
+}
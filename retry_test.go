@@ -0,0 +1,77 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestRetry(t *testing.T) {
+	calls := map[string]int{}
+	flaky := func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		name := ResolveInfoFromContext(ctx).FieldName
+		calls[name]++
+		switch name {
+		case "flaky":
+			if calls[name] < 3 {
+				return nil, errors.New("connection reset")
+			}
+			return "ok", nil
+		case "denied":
+			return nil, ErrPermissionDenied()
+		}
+		return nil, NewError("UNAVAILABLE", "down")
+	}
+	policy := RetryPolicy{Backoff: time.Millisecond}
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Query": {
+			"flaky":  Retry(policy, flaky),
+			"denied": Retry(policy, flaky),
+			"down":   Retry(RetryPolicy{MaxAttempts: 2, Backoff: time.Millisecond, Retryable: func(err error) bool { return true }}, flaky),
+		},
+	})
+
+	rr := httptest.NewRecorder()
+	GraphqlHandler.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "{ flaky denied down }"}`)))
+	var resp struct {
+		Data       map[string]interface{}
+		Errors     []*Error
+		Extensions map[string]interface{}
+	}
+	if err := json.NewDecoder(rr.Body).Decode(&resp); err != nil {
+		t.Fatal(err)
+	}
+	if resp.Data["flaky"] != "ok" || len(resp.Errors) != 2 {
+		t.Errorf("unexpected response %+v", resp)
+	}
+	if !reflect.DeepEqual(calls, map[string]int{"flaky": 3, "denied": 1, "down": 2}) {
+		t.Errorf("unexpected calls %v", calls)
+	}
+	retries := resp.Extensions["tracing"].(map[string]interface{})["retries"].([]interface{})
+	attempts := map[string]float64{}
+	for _, r := range retries {
+		entry := r.(map[string]interface{})
+		attempts[entry["path"].([]interface{})[0].(string)] = entry["attempts"].(float64)
+	}
+	if !reflect.DeepEqual(attempts, map[string]float64{"flaky": 3, "denied": 1, "down": 2}) {
+		t.Errorf("unexpected traced attempts %v", attempts)
+	}
+}
+
+func TestRetryStopsWhenContextDone(t *testing.T) {
+	calls := 0
+	resolver := Retry(RetryPolicy{MaxAttempts: 10, Backoff: time.Hour}, func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		calls++
+		return nil, errors.New("down")
+	})
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+	if _, err := resolver(ctx, nil, nil); err == nil || calls != 1 {
+		t.Errorf("expected one failed attempt, got %d calls and %v", calls, err)
+	}
+}
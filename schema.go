@@ -0,0 +1,332 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"sort"
+	"sync"
+	"sync/atomic"
+)
+
+// builtinScalars are the scalar types every schema provides.
+var builtinScalars = []string{"Int", "Float", "String", "Boolean", "ID"}
+
+// Schema is the type system described by SDL type definitions.
+type Schema struct {
+	Types            map[string]*TypeDefinition
+	QueryType        string
+	MutationType     string
+	SubscriptionType string
+	Directives       map[string]*DirectiveDefinition
+
+	// Authorize, when set, is consulted before every field is resolved.
+	Authorize AuthorizeFunc
+
+	// DefaultResolver, when set, resolves fields of non-root types that have
+	// no registered resolver, replacing ReflectResolver. The field being
+	// resolved is available through ResolveInfoFromContext. Use it to read
+	// fields from maps, protobuf messages or other dynamic records.
+	DefaultResolver ContextResolverFunc
+
+	// DisableIntrospection rejects operations querying __schema or __type,
+	// as is common in production. __typename remains available.
+	DisableIntrospection bool
+	// DisableSuggestions keeps validation errors from naming similar types,
+	// fields or arguments, so that error messages do not reveal the schema.
+	DisableSuggestions bool
+	// Visible, when set, limits the types and fields exposed by introspection.
+	// It only hides them; use Authorize to deny access. Set it before the
+	// schema is first introspected.
+	Visible VisibilityFunc
+	// ValidationRules are run by Validate after SpecifiedRules, letting
+	// applications reject documents before they execute.
+	ValidationRules []ValidationRule
+
+	typeNames []string // type names in definition order
+
+	introspectionOnce  sync.Once
+	introspectionModel *introspectionSchema
+
+	constraintsOnce sync.Once
+	constrained     bool // whether any argument or input field has a @constraint
+}
+
+// NewSchema builds a Schema from the type definitions in doc. Type
+// extensions, such as "extend type Query { ... }", are merged into the types
+// they extend, wherever those are defined in doc.
+func NewSchema(doc *Document) (*Schema, error) {
+	s := &Schema{
+		Types:      make(map[string]*TypeDefinition),
+		Directives: make(map[string]*DirectiveDefinition),
+	}
+	for _, name := range builtinScalars {
+		s.addType(&TypeDefinition{Kind: KindScalar, Name: name})
+	}
+	var extensions []*TypeDefinition
+	for _, def := range doc.Definitions {
+		if dd, ok := def.(*DirectiveDefinition); ok {
+			if _, exists := s.Directives[dd.Name]; exists || isBuiltinDirective(dd.Name) {
+				return nil, fmt.Errorf("directive @%s is defined more than once", dd.Name)
+			}
+			s.Directives[dd.Name] = dd
+			continue
+		}
+		td, ok := def.(*TypeDefinition)
+		if !ok {
+			continue
+		}
+		if td.Extension {
+			extensions = append(extensions, td)
+			continue
+		}
+		if _, ok := s.Types[td.Name]; ok {
+			// Redeclaring a built-in scalar is harmless; anything else is a conflict.
+			if td.kind() == KindScalar && isBuiltinScalar(td.Name) {
+				continue
+			}
+			return nil, fmt.Errorf("type %s is defined more than once", td.Name)
+		}
+		s.addType(td)
+	}
+	for _, ext := range extensions {
+		if err := s.extendType(ext); err != nil {
+			return nil, err
+		}
+	}
+	for _, def := range introspectionTypes {
+		s.addType(def.(*TypeDefinition))
+	}
+	if _, ok := s.Types["Query"]; ok {
+		s.QueryType = "Query"
+	}
+	if _, ok := s.Types["Mutation"]; ok {
+		s.MutationType = "Mutation"
+	}
+	if _, ok := s.Types["Subscription"]; ok {
+		s.SubscriptionType = "Subscription"
+	}
+	if err := s.checkTypeReferences(); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// ParseSchema parses SDL source and builds a Schema from it.
+func ParseSchema(sdl string) (*Schema, error) {
+	return ParseSchemas(sdl)
+}
+
+// ParseSchemas parses several SDL sources, such as one per feature package,
+// and builds a single Schema from their definitions. Sources can extend the
+// types of other sources with "extend type", "extend input" and so on;
+// defining a type, field or enum value twice is an error.
+func ParseSchemas(sources ...string) (*Schema, error) {
+	doc := &Document{}
+	for _, sdl := range sources {
+		parser := NewParser(NewLexer(sdl))
+		parsed := parser.ParseDocument()
+		if errs := parser.Errors(); len(errs) > 0 {
+			return nil, errs[0]
+		}
+		doc.Definitions = append(doc.Definitions, parsed.Definitions...)
+	}
+	return NewSchema(doc)
+}
+
+// extendType merges the extension ext into the type it extends. The merged
+// definition replaces the original, which is left unmodified.
+func (s *Schema) extendType(ext *TypeDefinition) error {
+	base, ok := s.Types[ext.Name]
+	if !ok {
+		return fmt.Errorf("cannot extend %s %s: it is not defined", kindKeywords[ext.kind()], ext.Name)
+	}
+	if base.kind() != ext.kind() {
+		return fmt.Errorf("cannot extend %s %s: it is defined as %s %s", kindKeywords[ext.kind()], ext.Name, kindKeywords[base.kind()], ext.Name)
+	}
+	merged := *base
+	merged.Directives = append(base.Directives[:len(base.Directives):len(base.Directives)], ext.Directives...)
+	merged.Interfaces = appendMissing(base.Interfaces, ext.Interfaces)
+	merged.Types = appendMissing(base.Types, ext.Types)
+	merged.Fields = base.Fields[:len(base.Fields):len(base.Fields)]
+	for _, f := range ext.Fields {
+		for _, existing := range merged.Fields {
+			if existing.Name == f.Name {
+				return fmt.Errorf("field %s.%s is defined more than once", ext.Name, f.Name)
+			}
+		}
+		merged.Fields = append(merged.Fields, f)
+	}
+	merged.InputFields = base.InputFields[:len(base.InputFields):len(base.InputFields)]
+	for _, f := range ext.InputFields {
+		for _, existing := range merged.InputFields {
+			if existing.Name == f.Name {
+				return fmt.Errorf("field %s.%s is defined more than once", ext.Name, f.Name)
+			}
+		}
+		merged.InputFields = append(merged.InputFields, f)
+	}
+	merged.EnumValues = base.EnumValues[:len(base.EnumValues):len(base.EnumValues)]
+	for _, v := range ext.EnumValues {
+		for _, existing := range merged.EnumValues {
+			if existing.Name == v.Name {
+				return fmt.Errorf("enum value %s.%s is defined more than once", ext.Name, v.Name)
+			}
+		}
+		merged.EnumValues = append(merged.EnumValues, v)
+	}
+	s.Types[ext.Name] = &merged
+	return nil
+}
+
+// appendMissing appends the names of more missing from names to a copy of
+// names.
+func appendMissing(names, more []string) []string {
+	names = names[:len(names):len(names)]
+	for _, name := range more {
+		found := false
+		for _, existing := range names {
+			found = found || existing == name
+		}
+		if !found {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+func (s *Schema) addType(td *TypeDefinition) {
+	if _, ok := s.Types[td.Name]; !ok {
+		s.typeNames = append(s.typeNames, td.Name)
+	}
+	s.Types[td.Name] = td
+}
+
+// checkTypeReferences ensures every referenced type is defined, defining
+// the JSON and Upload scalars if they are referenced.
+func (s *Schema) checkTypeReferences() error {
+	check := func(owner string, t *Type) error {
+		if t == nil {
+			return nil
+		}
+		name := namedType(t)
+		if _, ok := s.Types[name]; ok {
+			return nil
+		}
+		if name == "JSON" || name == "Upload" {
+			// JSON and Upload are declared on first use, so schemas need
+			// not define them.
+			s.addType(&TypeDefinition{Kind: KindScalar, Name: name})
+			return nil
+		}
+		return fmt.Errorf("unknown type %s referenced by %s", name, owner)
+	}
+	for _, name := range s.typeNames {
+		td := s.Types[name]
+		for _, f := range td.Fields {
+			if err := check(td.Name+"."+f.Name, f.Type); err != nil {
+				return err
+			}
+			for _, arg := range f.ArgumentDefinitions {
+				if err := check(td.Name+"."+f.Name+"("+arg.Name+")", arg.Type); err != nil {
+					return err
+				}
+			}
+		}
+		for _, f := range td.InputFields {
+			if err := check(td.Name+"."+f.Name, f.Type); err != nil {
+				return err
+			}
+		}
+		for _, member := range append(append([]string{}, td.Interfaces...), td.Types...) {
+			if _, ok := s.Types[member]; !ok {
+				return fmt.Errorf("unknown type %s referenced by %s", member, td.Name)
+			}
+		}
+	}
+	for _, dd := range s.Directives {
+		for _, arg := range dd.Arguments {
+			if err := check("@"+dd.Name+"("+arg.Name+")", arg.Type); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+func isBuiltinScalar(name string) bool {
+	for _, scalar := range builtinScalars {
+		if scalar == name {
+			return true
+		}
+	}
+	return false
+}
+
+// Type returns the named type definition, or nil.
+func (s *Schema) Type(name string) *TypeDefinition {
+	return s.Types[name]
+}
+
+// Field returns the definition of fieldName on typeName, or nil.
+func (s *Schema) Field(typeName, fieldName string) *Field {
+	td := s.Types[typeName]
+	if td == nil {
+		return nil
+	}
+	for _, f := range td.Fields {
+		if f.Name == fieldName {
+			return f
+		}
+	}
+	return nil
+}
+
+// Directive returns the definition of the directive name, including the
+// built-in @skip, @include and @deprecated, or nil.
+func (s *Schema) Directive(name string) *DirectiveDefinition {
+	if dd := specifiedDirectives[name]; dd != nil {
+		return dd
+	}
+	return s.Directives[name]
+}
+
+// kind returns the definition kind, treating an unset kind as an object type.
+func (t *TypeDefinition) kind() string {
+	if t.Kind == "" {
+		return KindObject
+	}
+	return t.Kind
+}
+
+// namedType unwraps list types down to the underlying named type.
+func namedType(t *Type) string {
+	for t != nil && t.IsList {
+		t = t.Elem
+	}
+	if t == nil {
+		return ""
+	}
+	return t.Name
+}
+
+var activeSchema atomic.Pointer[Schema]
+
+// UseSchema sets the schema used for introspection and schema-aware execution.
+// Passing nil disables schema-aware features.
+func UseSchema(s *Schema) {
+	activeSchema.Store(s)
+}
+
+// CurrentSchema returns the schema set with UseSchema, or nil.
+func CurrentSchema() *Schema {
+	return activeSchema.Load()
+}
+
+// directiveDefinitions returns the custom directive definitions sorted by name.
+func (s *Schema) directiveDefinitions() []*DirectiveDefinition {
+	defs := make([]*DirectiveDefinition, 0, len(s.Directives))
+	for _, dd := range s.Directives {
+		defs = append(defs, dd)
+	}
+	sort.Slice(defs, func(i, j int) bool { return defs[i].Name < defs[j].Name })
+	return defs
+}
@@ -0,0 +1,185 @@
+package vibeGraphql
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSchema_Kinds(t *testing.T) {
+	sdl := `
+		# Comments are ignored.
+		interface Node { id: ID! }
+		type User implements Node {
+			id: ID!
+			name(upper: Boolean = false): String @deprecated(reason: "use fullName")
+			role: Role
+		}
+		enum Role { ADMIN GUEST @deprecated }
+		union SearchResult = User | Node
+		input UserFilter { role: Role, limit: Int = 10 }
+		scalar Time
+		type Query { users(filter: UserFilter): [User!]! }
+	`
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	if s.QueryType != "Query" || s.MutationType != "" {
+		t.Errorf("unexpected root types: %q %q", s.QueryType, s.MutationType)
+	}
+	user := s.Type("User")
+	if user.Kind != KindObject || len(user.Interfaces) != 1 || user.Interfaces[0] != "Node" {
+		t.Errorf("unexpected User definition: %+v", user)
+	}
+	name := s.Field("User", "name")
+	if name == nil || name.Type.Name != "String" || len(name.ArgumentDefinitions) != 1 {
+		t.Fatalf("unexpected User.name definition: %+v", name)
+	}
+	if reason, ok := name.Deprecation(); !ok || reason != "use fullName" {
+		t.Errorf("expected User.name to be deprecated, got %q %v", reason, ok)
+	}
+	role := s.Type("Role")
+	if len(role.EnumValues) != 2 {
+		t.Fatalf("expected 2 enum values, got %d", len(role.EnumValues))
+	}
+	if reason, ok := role.EnumValues[1].Deprecation(); !ok || reason != defaultDeprecationReason {
+		t.Errorf("expected GUEST to use the default deprecation reason, got %q %v", reason, ok)
+	}
+	if union := s.Type("SearchResult"); len(union.Types) != 2 {
+		t.Errorf("expected 2 union members, got %v", union.Types)
+	}
+	if input := s.Type("UserFilter"); len(input.InputFields) != 2 || input.InputFields[1].DefaultValue.Literal != "10" {
+		t.Errorf("unexpected input fields: %+v", input.InputFields)
+	}
+	if s.Type("Time").Kind != KindScalar {
+		t.Error("expected Time to be a scalar")
+	}
+}
+
+func TestParseSchema_Errors(t *testing.T) {
+	if _, err := ParseSchema(`type A { b: Missing }`); err == nil {
+		t.Error("expected error for unknown type reference")
+	}
+	if _, err := ParseSchema(`type A { b: Int } type A { c: Int }`); err == nil {
+		t.Error("expected error for duplicate type")
+	}
+	if _, err := ParseSchema(`scalar String type A { b: String }`); err != nil {
+		t.Errorf("redeclaring a built-in scalar should be allowed: %v", err)
+	}
+}
+
+func TestParseSchemas_Extensions(t *testing.T) {
+	users := `
+		type User { id: ID! }
+		extend type Query { users: [User] }
+		extend enum Role { EDITOR }
+	`
+	base := `
+		type Query { me: User }
+		enum Role { ADMIN }
+		input Filter { role: Role }
+		interface Node { id: ID! }
+	`
+	more := `
+		extend type User implements Node @key(fields: "id") { name: String }
+		extend input Filter { name: String }
+	`
+	original := NewParser(NewLexer(base)).ParseDocument()
+	s, err := NewSchema(&Document{Definitions: append(NewParser(NewLexer(users)).ParseDocument().Definitions, original.Definitions...)})
+	if err != nil {
+		t.Fatalf("NewSchema error: %v", err)
+	}
+	if f := s.Field("Query", "users"); f == nil || s.Field("Query", "me") == nil {
+		t.Errorf("expected Query to have both fields, got %+v", s.Types["Query"].Fields)
+	}
+	if len(original.Definitions[0].(*TypeDefinition).Fields) != 1 {
+		t.Error("extending a type modified its original definition")
+	}
+
+	s, err = ParseSchemas(users, base, more)
+	if err != nil {
+		t.Fatalf("ParseSchemas error: %v", err)
+	}
+	user := s.Types["User"]
+	if len(user.Fields) != 2 || len(user.Interfaces) != 1 || len(user.Directives) != 1 {
+		t.Errorf("unexpected User %+v", user)
+	}
+	if values := s.Types["Role"].EnumValues; len(values) != 2 || values[1].Name != "EDITOR" {
+		t.Errorf("unexpected Role values %+v", values)
+	}
+	if fields := s.Types["Filter"].InputFields; len(fields) != 2 {
+		t.Errorf("unexpected Filter fields %+v", fields)
+	}
+
+	for _, tt := range []struct {
+		sources []string
+		want    string
+	}{
+		{[]string{base, `extend type Query { me: User }`, users}, "field Query.me is defined more than once"},
+		{[]string{base, `extend enum Role { ADMIN }`}, "enum value Role.ADMIN is defined more than once"},
+		{[]string{base, `extend input Filter { role: Role }`}, "field Filter.role is defined more than once"},
+		{[]string{`type Query { a: Int }`, `extend type Mutation { b: Int }`}, "cannot extend type Mutation: it is not defined"},
+		{[]string{base, `extend input Query { b: Int }`}, "cannot extend input Query: it is defined as type Query"},
+	} {
+		if _, err := ParseSchemas(tt.sources...); err == nil || err.Error() != tt.want {
+			t.Errorf("got error %v, want %q", err, tt.want)
+		}
+	}
+}
+
+func TestPrintSchema_RoundTrip(t *testing.T) {
+	sdl := `"Requires a role."
+directive @auth(role: String = "admin") on FIELD_DEFINITION
+
+interface Node {
+  id: ID!
+}
+
+"""
+A person with an account.
+
+Users log in with their email.
+"""
+type User implements Node {
+  id: ID!
+  "The display name."
+  name("Whether to uppercase the name." upper: Boolean = false): String @deprecated(reason: "use fullName")
+}
+
+enum Role {
+  "Can do anything."
+  ADMIN
+  USER
+}
+
+input Filter {
+  roles: [Role!]
+}
+
+union Result = User
+
+type Query {
+  users(filter: Filter): [User!]! @auth
+}
+`
+	s, err := ParseSchema(sdl)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	printed := PrintSchema(s)
+	reparsed, err := ParseSchema(printed)
+	if err != nil {
+		t.Fatalf("printed schema does not parse: %v\n%s", err, printed)
+	}
+	if again := PrintSchema(reparsed); again != printed {
+		t.Errorf("printing is not stable:\n%s\n---\n%s", printed, again)
+	}
+	for _, want := range []string{"type User implements Node", "union Result = User", `@deprecated(reason: "use fullName")`, "directive @auth", "\"The display name.\"\n  name", "\n\nUsers log in"} {
+		if !strings.Contains(printed, want) {
+			t.Errorf("expected %q in printed schema:\n%s", want, printed)
+		}
+	}
+	if strings.Contains(printed, "__Schema") || strings.Contains(printed, "scalar String") {
+		t.Errorf("builtin types should not be printed:\n%s", printed)
+	}
+}
@@ -0,0 +1,129 @@
+package vibeGraphql
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+)
+
+// importComment matches an import comment such as `#import "./user.graphql"`.
+var importComment = regexp.MustCompile(`^\s*#\s*import\s+"([^"]+)"`)
+
+// schemaFile is an SDL file read by LoadSchemaGlob.
+type schemaFile struct {
+	path, sdl string
+}
+
+// LoadSchemaGlob reads the SDL files matching the glob patterns, such as
+// "schema/*.graphql", and builds a single Schema from them. Files extend
+// each other's types with "extend type" and the like, as with ParseSchemas.
+//
+// A file may import others with comments such as
+//
+//	#import "./common/*.graphql"
+//
+// at the start of a line. Paths are relative to the importing file, and
+// imported files are loaded before the file importing them. Each file is
+// loaded once, however often it is matched or imported. Syntax errors are
+// reported with the file, line and column they occur at, for example
+// "schema/user.graphql:3:14: Syntax Error: ...".
+func LoadSchemaGlob(patterns ...string) (*Schema, error) {
+	files, err := readSchemaGlob(patterns)
+	if err != nil {
+		return nil, err
+	}
+	doc := &Document{}
+	for _, file := range files {
+		parser := NewParser(NewLexer(file.sdl))
+		parsed := parser.ParseDocument()
+		if errs := parser.Errors(); len(errs) > 0 {
+			return nil, fileError(file.path, errs[0])
+		}
+		doc.Definitions = append(doc.Definitions, parsed.Definitions...)
+	}
+	return NewSchema(doc)
+}
+
+// SchemaGlob returns a SchemaSource reading and concatenating the SDL files
+// matching the glob patterns, resolving #import comments as LoadSchemaGlob
+// does. New files matching the patterns are picked up on the next read.
+func SchemaGlob(patterns ...string) SchemaSource {
+	return func(ctx context.Context) (string, error) {
+		files, err := readSchemaGlob(patterns)
+		if err != nil {
+			return "", err
+		}
+		var sb strings.Builder
+		for _, file := range files {
+			sb.WriteString(file.sdl)
+			sb.WriteString("\n")
+		}
+		return sb.String(), nil
+	}
+}
+
+// fileError prefixes the message of err with path and the first location
+// of err.
+func fileError(path string, err *Error) error {
+	if len(err.Locations) == 0 {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	loc := err.Locations[0]
+	return fmt.Errorf("%s:%d:%d: %w", path, loc.Line, loc.Column, err)
+}
+
+// readSchemaGlob reads the files matching patterns and the files they
+// import, in the order they should be parsed.
+func readSchemaGlob(patterns []string) ([]schemaFile, error) {
+	var files []schemaFile
+	seen := make(map[string]bool)
+	var load func(pattern, importer string) error
+	load = func(pattern, importer string) error {
+		paths, err := filepath.Glob(pattern)
+		if err != nil {
+			return err
+		}
+		if len(paths) == 0 {
+			if importer != "" {
+				return fmt.Errorf("%s: no schema files match import %q", importer, pattern)
+			}
+			return fmt.Errorf("no schema files match %q", pattern)
+		}
+		for _, path := range paths {
+			path = filepath.Clean(path)
+			if seen[path] {
+				continue
+			}
+			seen[path] = true
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return err
+			}
+			sdl := string(data)
+			for _, line := range strings.Split(sdl, "\n") {
+				m := importComment.FindStringSubmatch(line)
+				if m == nil {
+					continue
+				}
+				imported := m[1]
+				if !filepath.IsAbs(imported) {
+					imported = filepath.Join(filepath.Dir(path), imported)
+				}
+				if err := load(imported, path); err != nil {
+					return err
+				}
+			}
+			files = append(files, schemaFile{path: path, sdl: sdl})
+		}
+		return nil
+	}
+	for _, pattern := range patterns {
+		if err := load(pattern, ""); err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
@@ -0,0 +1,67 @@
+package vibeGraphql
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func writeSchemaFiles(t *testing.T, files map[string]string) string {
+	t.Helper()
+	dir := t.TempDir()
+	for name, sdl := range files {
+		path := filepath.Join(dir, name)
+		if err := os.MkdirAll(filepath.Dir(path), 0o755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(path, []byte(sdl), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+	return dir
+}
+
+func TestLoadSchemaGlob(t *testing.T) {
+	dir := writeSchemaFiles(t, map[string]string{
+		"schema/a_orders.graphql": "#import \"../common/*.graphql\"\ntype Order { id: ID! buyer: User }\nextend type Query { orders: [Order] }\n",
+		"schema/query.graphql":    "type Query { me: User }\n",
+		"schema/users.graphql":    "# import \"../common/scalars.graphql\"\ntype User { id: ID! joined: Time }\n",
+		"common/scalars.graphql":  "#import \"./scalars.graphql\"\nscalar Time\n",
+		"common/notes.txt":        "not a schema",
+	})
+	s, err := LoadSchemaGlob(filepath.Join(dir, "schema", "*.graphql"))
+	if err != nil {
+		t.Fatalf("LoadSchemaGlob error: %v", err)
+	}
+	if s.Field("Query", "orders") == nil || s.Field("Query", "me") == nil || s.Types["Time"] == nil {
+		t.Errorf("unexpected schema:\n%s", PrintSchema(s))
+	}
+
+	sdl, err := SchemaGlob(filepath.Join(dir, "schema", "*.graphql"))(context.Background())
+	if err != nil {
+		t.Fatalf("SchemaGlob error: %v", err)
+	}
+	if strings.Count(sdl, "scalar Time") != 1 || !strings.HasPrefix(sdl, "#import \"./scalars.graphql\"") {
+		t.Errorf("expected imports first and once, got:\n%s", sdl)
+	}
+}
+
+func TestLoadSchemaGlob_Errors(t *testing.T) {
+	dir := writeSchemaFiles(t, map[string]string{
+		"ok.graphql":      "type Query { a: Int }\n",
+		"broken.graphql":  "type User {\n  name: String\n  age: = 3\n}\n",
+		"imports.graphql": "#import \"./missing.graphql\"\n",
+	})
+	_, err := LoadSchemaGlob(filepath.Join(dir, "ok.graphql"), filepath.Join(dir, "broken.graphql"))
+	if want := filepath.Join(dir, "broken.graphql") + ":3:8: Syntax Error:"; err == nil || !strings.HasPrefix(err.Error(), want) {
+		t.Errorf("got %v, want an error starting with %q", err, want)
+	}
+	if _, err := LoadSchemaGlob(filepath.Join(dir, "imports.graphql")); err == nil || !strings.Contains(err.Error(), `no schema files match import`) {
+		t.Errorf("expected an error for the missing import, got %v", err)
+	}
+	if _, err := LoadSchemaGlob(filepath.Join(dir, "*.gql")); err == nil || !strings.Contains(err.Error(), "no schema files match") {
+		t.Errorf("expected an error for a pattern matching nothing, got %v", err)
+	}
+}
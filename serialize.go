@@ -0,0 +1,179 @@
+package vibeGraphql
+
+import (
+	"database/sql/driver"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"strconv"
+	"time"
+)
+
+// Marshaler is implemented by types that control their own GraphQL
+// representation, much like json.Marshaler. MarshalGQL returns the value to
+// report instead: a scalar for leaf fields, or a map or struct whose fields
+// are selected for object fields. The GraphQL type name, used for
+// __typename and field resolver lookup, remains that of the original value.
+type Marshaler interface {
+	MarshalGQL() (interface{}, error)
+}
+
+// SerializerFunc converts a resolver result of a particular Go type into the
+// value reported in the response, typically a string, number or bool.
+type SerializerFunc func(value interface{}) (interface{}, error)
+
+// Serializers maps Go types to the functions serializing them as leaf
+// values. time.Time is reported as an RFC 3339 string and time.Duration in
+// Go duration syntax; register a function to change either or to add types.
+var Serializers = map[reflect.Type]SerializerFunc{
+	reflect.TypeOf(time.Time{}): func(value interface{}) (interface{}, error) {
+		return value.(time.Time).Format(time.RFC3339Nano), nil
+	},
+	reflect.TypeOf(time.Duration(0)): func(value interface{}) (interface{}, error) {
+		return value.(time.Duration).String(), nil
+	},
+}
+
+// RegisterSerializer registers fn for values of the same type as example:
+//
+//	RegisterSerializer(decimal.Decimal{}, func(v interface{}) (interface{}, error) {
+//		return v.(decimal.Decimal).String(), nil
+//	})
+func RegisterSerializer(example interface{}, fn SerializerFunc) {
+	Serializers[reflect.TypeOf(example)] = fn
+}
+
+// serializeLeaf converts the result of a field without a selection set into
+// its response value. Marshalers are called first; then pointers are
+// dereferenced, registered serializers are applied, and database types such as sql.NullString or uuid.UUID are
+// reported through their driver.Valuer implementation, so a null column
+// becomes null. Slices are serialized element by element.
+func serializeLeaf(res interface{}) (interface{}, error) {
+	if res == nil {
+		return nil, nil
+	}
+	val := reflect.ValueOf(res)
+	if m, ok := res.(Marshaler); ok {
+		if val.Kind() == reflect.Ptr && val.IsNil() {
+			return nil, nil
+		}
+		marshaled, err := m.MarshalGQL()
+		if err != nil {
+			return nil, err
+		}
+		if _, again := marshaled.(Marshaler); again {
+			return marshaled, nil
+		}
+		return serializeLeaf(marshaled)
+	}
+	for val.Kind() == reflect.Ptr {
+		if val.IsNil() {
+			return nil, nil
+		}
+		if _, ok := Serializers[val.Type()]; ok {
+			break
+		}
+		val = val.Elem()
+	}
+	if fn, ok := Serializers[val.Type()]; ok {
+		return fn(val.Interface())
+	}
+	if valuer, ok := val.Interface().(driver.Valuer); ok {
+		v, err := valuer.Value()
+		if err != nil {
+			return nil, err
+		}
+		if _, same := v.(driver.Valuer); same {
+			return nil, fmt.Errorf("%T.Value returned another driver.Valuer", valuer)
+		}
+		return serializeLeaf(v)
+	}
+	if val.Kind() == reflect.Slice && val.Type().Elem().Kind() != reflect.Uint8 && !isBasicKind(val.Type().Elem().Kind()) {
+		if val.IsNil() {
+			return nil, nil
+		}
+		list := make([]interface{}, val.Len())
+		for i := range list {
+			item, err := serializeLeaf(val.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	}
+	return val.Interface(), nil
+}
+
+// isIDField reports whether the schema declares field of parentType as an ID
+// or a list of IDs.
+func (e *executor) isIDField(parentType string, field *Field) bool {
+	if e.plan != nil {
+		return e.plan.field(parentType, field).id
+	}
+	return isIDField(CurrentSchema(), parentType, field)
+}
+
+func isIDField(s *Schema, parentType string, field *Field) bool {
+	if s == nil {
+		return false
+	}
+	def := s.Field(parentType, field.Name)
+	return def != nil && namedType(def.Type) == "ID"
+}
+
+// serializeID reports the serialized result of an ID field as a string,
+// whether the resolver returned a string, an integer or a type with a String
+// method. Lists are converted element by element.
+func serializeID(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case nil:
+		return nil, nil
+	case string:
+		return v, nil
+	case json.Number:
+		return v.String(), nil
+	case fmt.Stringer:
+		return v.String(), nil
+	}
+	val := reflect.ValueOf(value)
+	switch val.Kind() {
+	case reflect.String:
+		return val.String(), nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(val.Int(), 10), nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return strconv.FormatUint(val.Uint(), 10), nil
+	case reflect.Float32, reflect.Float64:
+		if f := val.Float(); f == math.Trunc(f) && math.Abs(f) <= maxSafeInteger {
+			return strconv.FormatInt(int64(f), 10), nil
+		}
+	case reflect.Slice, reflect.Array:
+		if val.Kind() == reflect.Slice && val.IsNil() {
+			return nil, nil
+		}
+		list := make([]interface{}, val.Len())
+		for i := range list {
+			item, err := serializeID(val.Index(i).Interface())
+			if err != nil {
+				return nil, err
+			}
+			list[i] = item
+		}
+		return list, nil
+	}
+	return nil, fmt.Errorf("ID cannot represent value: %s", jsonString(value))
+}
+
+// isBasicKind reports whether values of kind k need no serialization.
+func isBasicKind(k reflect.Kind) bool {
+	switch k {
+	case reflect.Bool, reflect.String,
+		reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Float32, reflect.Float64:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,181 @@
+package vibeGraphql
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"encoding/hex"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// testUUID mimics uuid.UUID, which is an array reported through driver.Valuer.
+type testUUID [4]byte
+
+func (u testUUID) Value() (driver.Value, error) { return hex.EncodeToString(u[:]), nil }
+
+type money int64
+
+type serializedRow struct {
+	CreatedAt time.Time
+	UpdatedAt *time.Time
+	DeletedAt sql.NullTime
+	Timeout   time.Duration
+	Nickname  sql.NullString
+	Age       sql.NullInt64
+	ID        testUUID
+	Price     money
+	Tags      []sql.NullString
+}
+
+func TestSerializeLeaf_CommonTypes(t *testing.T) {
+	created := time.Date(2024, 5, 1, 12, 30, 0, 0, time.UTC)
+	RegisterSerializer(money(0), func(v interface{}) (interface{}, error) {
+		return fmt.Sprintf("$%d.%02d", v.(money)/100, v.(money)%100), nil
+	})
+	defer delete(Serializers, reflect.TypeOf(money(0)))
+	QueryResolvers["row"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return &serializedRow{
+			CreatedAt: created,
+			UpdatedAt: &created,
+			Timeout:   90 * time.Second,
+			Nickname:  sql.NullString{String: "ace", Valid: true},
+			ID:        testUUID{0xde, 0xad, 0xbe, 0xef},
+			Price:     1999,
+			Tags:      []sql.NullString{{String: "a", Valid: true}, {}},
+		}, nil
+	}
+	defer delete(QueryResolvers, "row")
+
+	data := executeQuery(t, `{ row { createdAt updatedAt deletedAt timeout nickname age id price tags } }`, nil)
+	want := map[string]interface{}{
+		"createdAt": "2024-05-01T12:30:00Z",
+		"updatedAt": "2024-05-01T12:30:00Z",
+		"deletedAt": nil,
+		"timeout":   "1m30s",
+		"nickname":  "ace",
+		"age":       nil,
+		"id":        "deadbeef",
+		"price":     "$19.99",
+		"tags":      []interface{}{"a", nil},
+	}
+	if got := data["row"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("row =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestSerializeLeaf_Error(t *testing.T) {
+	RegisterSerializer(money(0), func(v interface{}) (interface{}, error) {
+		return nil, fmt.Errorf("cannot serialize money")
+	})
+	defer delete(Serializers, reflect.TypeOf(money(0)))
+	QueryResolvers["price"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return money(5), nil
+	}
+	defer delete(QueryResolvers, "price")
+
+	resp, err := executeDocument(context.Background(), parseQuery(`{ price }`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := resp["errors"].([]*Error)
+	if data := resp["data"].(map[string]interface{}); data["price"] != nil || len(errs) != 1 {
+		t.Errorf("expected a null price and one error, got %v", resp)
+	}
+}
+
+type testCurrency string
+
+func (c testCurrency) MarshalGQL() (interface{}, error) { return "CUR_" + string(c), nil }
+
+type testMoney struct {
+	cents    int64
+	currency testCurrency
+}
+
+func (m *testMoney) MarshalGQL() (interface{}, error) {
+	if m.cents < 0 {
+		return nil, fmt.Errorf("negative amount")
+	}
+	return map[string]interface{}{"amount": float64(m.cents) / 100, "currency": m.currency}, nil
+}
+
+func TestMarshaler(t *testing.T) {
+	RegisterFieldResolver("testMoney", "formatted", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		m := source.(map[string]interface{})
+		return fmt.Sprintf("%.2f %s", m["amount"], m["currency"]), nil
+	})
+	defer delete(FieldResolvers, "testMoney")
+	QueryResolvers["prices"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return []*testMoney{{cents: 1250, currency: "EUR"}, nil, {cents: -1}}, nil
+	}
+	QueryResolvers["currency"] = func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return testCurrency("USD"), nil
+	}
+	defer delete(QueryResolvers, "prices")
+	defer delete(QueryResolvers, "currency")
+
+	resp, err := executeDocument(context.Background(), parseQuery(`{ currency prices { __typename amount currency formatted } }`), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	data := resp["data"].(map[string]interface{})
+	if data["currency"] != "CUR_USD" {
+		t.Errorf("unexpected currency %v", data["currency"])
+	}
+	want := []interface{}{
+		map[string]interface{}{"__typename": "testMoney", "amount": 12.5, "currency": "CUR_EUR", "formatted": "12.50 EUR"},
+		nil,
+		nil,
+	}
+	if !reflect.DeepEqual(data["prices"], want) {
+		t.Errorf("prices =\n%#v\nwant\n%#v", data["prices"], want)
+	}
+	if errs, _ := resp["errors"].([]*Error); len(errs) != 1 || errs[0].Message != "negative amount" {
+		t.Errorf("unexpected errors %v", resp["errors"])
+	}
+}
+
+func TestSerializeID(t *testing.T) {
+	type User struct {
+		ID      int64
+		Friends []int
+	}
+	var arg interface{}
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			arg = args["id"]
+			return &User{ID: 42, Friends: []int{1, 2}}, nil
+		},
+		"legacy": func(source interface{}, args map[string]interface{}) (interface{}, error) { return 7.0, nil },
+		"broken": func(source interface{}, args map[string]interface{}) (interface{}, error) { return 1.5, nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { user(id: ID!): User legacy: ID broken: ID } type User { id: ID! friends: [ID!] }`)
+
+	for _, query := range []string{`{ user(id: 5) { id friends } legacy }`, `query ($id: ID!) { user(id: $id) { id friends } legacy }`} {
+		result, err := executeRequest(context.Background(), query, map[string]interface{}{"id": 5.0})
+		if err != nil {
+			t.Fatal(err)
+		}
+		want := map[string]interface{}{
+			"user":   map[string]interface{}{"id": "42", "friends": []interface{}{"1", "2"}},
+			"legacy": "7",
+		}
+		if !reflect.DeepEqual(result["data"], want) || result["errors"] != nil {
+			t.Errorf("%s: got %#v, want %#v", query, result, want)
+		}
+		if arg != "5" {
+			t.Errorf("%s: expected the argument as a string, got %#v", query, arg)
+		}
+	}
+
+	result, err := executeRequest(context.Background(), `{ broken }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := result["errors"].([]*Error); len(errs) != 1 || errs[0].Message != "ID cannot represent value: 1.5" {
+		t.Errorf("expected an error for a fractional ID, got %v", result)
+	}
+}
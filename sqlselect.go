@@ -0,0 +1,172 @@
+package vibeGraphql
+
+import (
+	"context"
+	"reflect"
+	"strings"
+	"unicode"
+)
+
+// SelectedFields returns the sub-fields requested on the field being
+// resolved, in query order and without duplicates. A field selected under
+// several aliases is returned once, with their selection sets merged. Fields
+// excluded by @skip or @include and meta-fields such as __typename are left
+// out.
+func (info *ResolveInfo) SelectedFields() []*Field {
+	if info == nil || info.Field == nil {
+		return nil
+	}
+	return selectedFields(info.Field.SelectionSet, info.Variables)
+}
+
+func selectedFields(ss *SelectionSet, variables map[string]interface{}) []*Field {
+	if ss == nil {
+		return nil
+	}
+	// Group the fields by name rather than response key, so the selections
+	// of every alias are kept.
+	var groups []*collectedField
+	index := make(map[string]int)
+	for _, group := range collectFields(ss, "", variables) {
+		name := group.fields[0].Name
+		if strings.HasPrefix(name, "__") {
+			continue
+		}
+		if i, ok := index[name]; ok {
+			groups[i].fields = append(groups[i].fields, group.fields...)
+			continue
+		}
+		index[name] = len(groups)
+		groups = append(groups, &collectedField{key: name, fields: group.fields})
+	}
+	fields := make([]*Field, 0, len(groups))
+	for _, group := range groups {
+		fields = append(fields, group.field())
+	}
+	return fields
+}
+
+// SQLSelection lists what a database query needs to load to answer the
+// current selection set.
+type SQLSelection struct {
+	// Columns are the column names of the selected scalar fields.
+	Columns []string
+	// Preloads are the selected relations as dotted Go field paths, e.g.
+	// "Author" or "Author.Posts", in the form GORM's Preload expects.
+	Preloads []string
+}
+
+// SelectClause joins the columns for use in a SELECT statement.
+func (s SQLSelection) SelectClause() string {
+	return strings.Join(s.Columns, ", ")
+}
+
+// SQLSelectionFromContext maps the selection set of the field being resolved
+// onto model, a struct (or pointer to one) describing a table row, so the
+// resolver can select only the columns it needs instead of SELECT *.
+//
+// GraphQL fields match struct fields the same way results are resolved: by
+// json tag or case-insensitive name. Column names come from the `db` tag,
+// the `column:` setting of the `gorm` tag, or the snake_cased field name.
+// Struct and slice-of-struct fields are relations and become preloads.
+// keyColumns are always included, so related rows can still be joined.
+func SQLSelectionFromContext(ctx context.Context, model interface{}, keyColumns ...string) SQLSelection {
+	sel := SQLSelection{}
+	sel.Columns = append(sel.Columns, keyColumns...)
+	info := ResolveInfoFromContext(ctx)
+	if info == nil || info.Field == nil {
+		return sel
+	}
+	t := reflect.TypeOf(model)
+	for t != nil && t.Kind() == reflect.Ptr {
+		t = t.Elem()
+	}
+	if t == nil || t.Kind() != reflect.Struct {
+		return sel
+	}
+	collectSQLSelection(&sel, t, info.Field.SelectionSet, info.Variables, "")
+	return sel
+}
+
+func collectSQLSelection(sel *SQLSelection, t reflect.Type, ss *SelectionSet, variables map[string]interface{}, prefix string) {
+	for _, field := range selectedFields(ss, variables) {
+		sf, ok := structFieldFor(t, field.Name)
+		if !ok {
+			continue
+		}
+		if related := relationType(sf.Type); related != nil {
+			path := prefix + sf.Name
+			sel.Preloads = appendUnique(sel.Preloads, path)
+			collectSQLSelection(sel, related, field.SelectionSet, variables, path+".")
+			continue
+		}
+		if prefix == "" {
+			if col := columnName(sf); col != "" {
+				sel.Columns = appendUnique(sel.Columns, col)
+			}
+		}
+	}
+}
+
+// relationType returns the struct type behind a relation field, or nil for
+// plain columns. time.Time and similar value structs are treated as columns.
+func relationType(t reflect.Type) reflect.Type {
+	for t.Kind() == reflect.Ptr || t.Kind() == reflect.Slice {
+		t = t.Elem()
+	}
+	if t.Kind() != reflect.Struct || t.PkgPath() == "time" || strings.HasPrefix(t.PkgPath(), "database/sql") {
+		return nil
+	}
+	return t
+}
+
+func columnName(sf reflect.StructField) string {
+	if tag, ok := sf.Tag.Lookup("db"); ok {
+		name := strings.Split(tag, ",")[0]
+		if name == "-" {
+			return ""
+		}
+		if name != "" {
+			return name
+		}
+	}
+	if tag, ok := sf.Tag.Lookup("gorm"); ok {
+		for _, setting := range strings.Split(tag, ";") {
+			setting = strings.TrimSpace(setting)
+			if setting == "-" {
+				return ""
+			}
+			if strings.HasPrefix(strings.ToLower(setting), "column:") {
+				return setting[len("column:"):]
+			}
+		}
+	}
+	return snakeCase(sf.Name)
+}
+
+// snakeCase converts a Go identifier to snake_case, keeping initialisms
+// together: "UserID" becomes "user_id".
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var sb strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) && i > 0 {
+			prevLower := unicode.IsLower(runes[i-1]) || unicode.IsDigit(runes[i-1])
+			nextLower := i+1 < len(runes) && unicode.IsLower(runes[i+1])
+			if prevLower || (unicode.IsUpper(runes[i-1]) && nextLower) {
+				sb.WriteByte('_')
+			}
+		}
+		sb.WriteRune(unicode.ToLower(r))
+	}
+	return sb.String()
+}
+
+func appendUnique(list []string, s string) []string {
+	for _, existing := range list {
+		if existing == s {
+			return list
+		}
+	}
+	return append(list, s)
+}
@@ -0,0 +1,112 @@
+package vibeGraphql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+	"time"
+)
+
+type sqlAuthor struct {
+	ID    int
+	Name  string
+	Posts []sqlPost
+}
+
+type sqlPost struct {
+	ID    int
+	Title string
+}
+
+type sqlUser struct {
+	ID        int       `db:"id"`
+	FullName  string    `json:"name" db:"full_name"`
+	Email     string    `gorm:"column:email_address;not null"`
+	UserID    string    // snake_cased
+	Password  string    `db:"-"`
+	CreatedAt time.Time `json:"createdAt"`
+	Author    *sqlAuthor
+	Friends   []sqlUser
+}
+
+func TestSQLSelectionFromContext(t *testing.T) {
+	var got SQLSelection
+	RegisterFieldResolver("Query", "sqlUsers", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = SQLSelectionFromContext(ctx, &sqlUser{}, "id")
+		return nil, nil
+	})
+
+	query := `query ($skip: Boolean!) {
+		sqlUsers {
+			__typename
+			id
+			name
+			email
+			userID
+			password
+			createdAt
+			computed
+			email @skip(if: $skip)
+			author { name posts { title } }
+			friends @skip(if: $skip) { id }
+		}
+	}`
+	executeQuery(t, query, map[string]interface{}{"skip": true})
+
+	want := SQLSelection{
+		Columns:  []string{"id", "full_name", "email_address", "user_id", "created_at"},
+		Preloads: []string{"Author", "Author.Posts"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SQLSelectionFromContext =\n%#v\nwant\n%#v", got, want)
+	}
+	if clause := got.SelectClause(); clause != "id, full_name, email_address, user_id, created_at" {
+		t.Errorf("unexpected select clause %q", clause)
+	}
+}
+
+func TestSQLSelectionFromContext_Aliases(t *testing.T) {
+	var got SQLSelection
+	RegisterFieldResolver("Query", "sqlAliasedUsers", func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = SQLSelectionFromContext(ctx, &sqlUser{}, "id")
+		return nil, nil
+	})
+	defer delete(FieldResolvers["Query"], "sqlAliasedUsers")
+
+	executeQuery(t, `{
+		sqlAliasedUsers {
+			a: author { name }
+			b: author { posts { title } }
+			writer: author { id }
+		}
+	}`, nil)
+
+	want := SQLSelection{
+		Columns:  []string{"id"},
+		Preloads: []string{"Author", "Author.Posts"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("SQLSelectionFromContext =\n%#v\nwant\n%#v", got, want)
+	}
+}
+
+func TestSQLSelectionFromContext_NoResolveInfo(t *testing.T) {
+	sel := SQLSelectionFromContext(context.Background(), sqlUser{}, "id")
+	if !reflect.DeepEqual(sel.Columns, []string{"id"}) || sel.Preloads != nil {
+		t.Errorf("unexpected selection without resolve info: %#v", sel)
+	}
+}
+
+func TestSnakeCase(t *testing.T) {
+	for in, want := range map[string]string{
+		"ID":        "id",
+		"UserID":    "user_id",
+		"HTTPCode":  "http_code",
+		"CreatedAt": "created_at",
+		"Address2":  "address2",
+	} {
+		if got := snakeCase(in); got != want {
+			t.Errorf("snakeCase(%q) = %q, want %q", in, got, want)
+		}
+	}
+}
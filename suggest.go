@@ -0,0 +1,94 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// maxSuggestions is the number of alternatives named in a "Did you mean" hint.
+const maxSuggestions = 5
+
+// suggestionList returns the options similar enough to input to be likely
+// typos of it, closest first.
+func suggestionList(input string, options []string) []string {
+	threshold := len(input)*4/10 + 1
+	distances := make(map[string]int)
+	var matches []string
+	for _, option := range options {
+		if _, seen := distances[option]; seen {
+			continue
+		}
+		d := lexicalDistance(input, option)
+		if d <= threshold {
+			distances[option] = d
+			matches = append(matches, option)
+		}
+	}
+	sort.Slice(matches, func(i, j int) bool {
+		di, dj := distances[matches[i]], distances[matches[j]]
+		if di != dj {
+			return di < dj
+		}
+		return matches[i] < matches[j]
+	})
+	return matches
+}
+
+// lexicalDistance is the Damerau-Levenshtein (optimal string alignment)
+// distance between a and b. Strings differing only in case have distance 1.
+func lexicalDistance(a, b string) int {
+	if a == b {
+		return 0
+	}
+	a, b = strings.ToLower(a), strings.ToLower(b)
+	if a == b {
+		return 1
+	}
+	s, t := []rune(a), []rune(b)
+	rows := make([][]int, len(s)+1)
+	for i := range rows {
+		rows[i] = make([]int, len(t)+1)
+		rows[i][0] = i
+	}
+	for j := range rows[0] {
+		rows[0][j] = j
+	}
+	for i := 1; i <= len(s); i++ {
+		for j := 1; j <= len(t); j++ {
+			cost := 1
+			if s[i-1] == t[j-1] {
+				cost = 0
+			}
+			d := min(rows[i-1][j]+1, rows[i][j-1]+1, rows[i-1][j-1]+cost)
+			if i > 1 && j > 1 && s[i-1] == t[j-2] && s[i-2] == t[j-1] {
+				d = min(d, rows[i-2][j-2]+1)
+			}
+			rows[i][j] = d
+		}
+	}
+	return rows[len(s)][len(t)]
+}
+
+// didYouMean formats suggestions as a sentence to append to an error
+// message, e.g. ` Did you mean "name" or "names"?`. It returns "" when there
+// is nothing to suggest.
+func didYouMean(suggestions []string) string {
+	if len(suggestions) == 0 {
+		return ""
+	}
+	if len(suggestions) > maxSuggestions {
+		suggestions = suggestions[:maxSuggestions]
+	}
+	quoted := make([]string, len(suggestions))
+	for i, s := range suggestions {
+		quoted[i] = fmt.Sprintf("%q", s)
+	}
+	switch len(quoted) {
+	case 1:
+		return " Did you mean " + quoted[0] + "?"
+	case 2:
+		return " Did you mean " + quoted[0] + " or " + quoted[1] + "?"
+	}
+	return " Did you mean " + strings.Join(quoted[:len(quoted)-1], ", ") + ", or " + quoted[len(quoted)-1] + "?"
+}
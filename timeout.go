@@ -0,0 +1,113 @@
+package vibeGraphql
+
+import (
+	"context"
+	"errors"
+	"time"
+)
+
+// FieldTimeouts maps a type name to the timeouts of its fields. Root fields
+// are registered under "Query", "Mutation" and "Subscription".
+var FieldTimeouts = make(map[string]map[string]time.Duration)
+
+// RegisterFieldTimeout limits the time field on typeName may take to resolve.
+// Fields can also declare a timeout in the schema with the @timeout directive:
+//
+//	type Query { search(text: String): [Result] @timeout(ms: 500) }
+//
+// When the timeout expires, the field's context is cancelled and the field
+// resolves to null with a TIMEOUT error, while the rest of the query goes on.
+// A registered timeout takes precedence over the directive.
+func RegisterFieldTimeout(typeName, field string, timeout time.Duration) {
+	if FieldTimeouts[typeName] == nil {
+		FieldTimeouts[typeName] = make(map[string]time.Duration)
+	}
+	FieldTimeouts[typeName][field] = timeout
+}
+
+// fieldTimeout returns the timeout of field on parentType, or zero if it has
+// none.
+func (e *executor) fieldTimeout(parentType string, field *Field) time.Duration {
+	if e.plan != nil {
+		return e.plan.field(parentType, field).timeout
+	}
+	return lookupFieldTimeout(parentType, field)
+}
+
+func lookupFieldTimeout(parentType string, field *Field) time.Duration {
+	if timeout, ok := FieldTimeouts[parentType][field.Name]; ok {
+		return timeout
+	}
+	s := CurrentSchema()
+	if s == nil {
+		return 0
+	}
+	def := s.Field(parentType, field.Name)
+	if def == nil {
+		return 0
+	}
+	for _, d := range def.Directives {
+		if d.Name != "timeout" {
+			continue
+		}
+		if ms, ok := buildArgumentValues(d.Arguments, nil)["ms"].(int); ok {
+			return time.Duration(ms) * time.Millisecond
+		}
+	}
+	return 0
+}
+
+type fieldResolveFunc func(ctx context.Context, source interface{}, parentType string, field *Field) (interface{}, error)
+
+// withFieldTimeout returns resolve limited to timeout. resolve runs in its own
+// goroutine so that a resolver ignoring its context does not hold up the
+// query; its eventual result is discarded.
+func withFieldTimeout(timeout time.Duration, resolve fieldResolveFunc) fieldResolveFunc {
+	return func(ctx context.Context, source interface{}, parentType string, field *Field) (interface{}, error) {
+		ctx, cancel := context.WithTimeout(ctx, timeout)
+		defer cancel()
+		release, ok := goroutine(ctx)
+		if !ok {
+			// Without a goroutine to spare, the resolver runs inline and
+			// only its context enforces the timeout.
+			res, err := resolve(ctx, source, parentType, field)
+			if err != nil && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, timeoutError(ctx, parentType, field, timeout)
+			}
+			return res, err
+		}
+		type outcome struct {
+			res interface{}
+			err error
+		}
+		done := make(chan outcome, 1)
+		go func() {
+			defer release()
+			res, err := resolve(ctx, source, parentType, field)
+			done <- outcome{res, err}
+		}()
+		select {
+		case o := <-done:
+			if o.err != nil && errors.Is(o.err, context.DeadlineExceeded) && errors.Is(ctx.Err(), context.DeadlineExceeded) {
+				return nil, timeoutError(ctx, parentType, field, timeout)
+			}
+			return o.res, o.err
+		case <-ctx.Done():
+			// resolve may still be reading the document.
+			abandonArena(ctx)
+			if err := ctx.Err(); !errors.Is(err, context.DeadlineExceeded) {
+				// The request itself was cancelled.
+				return nil, err
+			}
+			return nil, timeoutError(ctx, parentType, field, timeout)
+		}
+	}
+}
+
+func timeoutError(ctx context.Context, parentType string, field *Field, timeout time.Duration) *Error {
+	err := NewError(CodeTimeout, "Field \""+parentType+"."+field.Name+"\" timed out after "+timeout.String()+".")
+	if info := ResolveInfoFromContext(ctx); info != nil {
+		err.Path = info.Path
+	}
+	return err
+}
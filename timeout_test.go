@@ -0,0 +1,79 @@
+package vibeGraphql
+
+import (
+	"context"
+	"reflect"
+	"sync"
+	"testing"
+	"time"
+)
+
+func TestFieldTimeout(t *testing.T) {
+	type post struct{ Title string }
+	release := make(chan struct{})
+	var stuck sync.WaitGroup
+	cancelled := make(chan struct{})
+	useResolvers(t, map[string]ResolverFunc{
+		"posts": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return []*post{{Title: "a"}, {Title: "b"}}, nil
+		},
+	}, map[string]map[string]ContextResolverFunc{
+		"post": {
+			"slow": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				if source.(*post).Title == "a" {
+					return "fast", nil
+				}
+				<-ctx.Done()
+				close(cancelled)
+				return nil, ctx.Err()
+			},
+			"stuck": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+				defer stuck.Done()
+				<-release // ignores its context
+				return "late", nil
+			},
+		},
+	})
+	// Let the stuck resolvers finish before the registries are restored.
+	t.Cleanup(func() {
+		close(release)
+		stuck.Wait()
+	})
+	useTestSchema(t, `type Query { posts: [post] } type post { title: String slow: String @timeout(ms: 20) stuck: String }`)
+	RegisterFieldTimeout("post", "stuck", 20*time.Millisecond)
+	defer delete(FieldTimeouts, "post")
+
+	result, err := executeRequest(context.Background(), `{ posts { title slow } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []interface{}{
+		map[string]interface{}{"title": "a", "slow": "fast"},
+		map[string]interface{}{"title": "b", "slow": nil},
+	}
+	if got := result["data"].(map[string]interface{})["posts"]; !reflect.DeepEqual(got, want) {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	errs, _ := result["errors"].([]*Error)
+	if len(errs) != 1 || errs[0].Extensions["code"] != CodeTimeout || !reflect.DeepEqual(errs[0].Path, []interface{}{"posts", 1, "slow"}) {
+		t.Fatalf("unexpected errors %+v", errs)
+	}
+	select {
+	case <-cancelled:
+	case <-time.After(time.Second):
+		t.Error("expected the field's context to be cancelled")
+	}
+
+	stuck.Add(2)
+	start := time.Now()
+	result, err = executeRequest(context.Background(), `{ posts { title stuck } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if elapsed := time.Since(start); elapsed > time.Second {
+		t.Errorf("a stuck resolver held up the query for %v", elapsed)
+	}
+	if errs, _ := result["errors"].([]*Error); len(errs) != 2 {
+		t.Errorf("expected a timeout per item, got %+v", result["errors"])
+	}
+}
@@ -3,6 +3,9 @@ package vibeGraphql
 // ----------------------
 // Token Definitions
 // ----------------------
+
+// TokenType is the kind of a token. The types of punctuation are the
+// punctuation itself, such as "{".
 type TokenType string
 
 const (
@@ -13,6 +16,7 @@ const (
 	// Identifiers and literals
 	IDENT  TokenType = "IDENT"
 	INT    TokenType = "INT"
+	FLOAT  TokenType = "FLOAT"
 	STRING TokenType = "STRING"
 
 	// Symbols
@@ -30,9 +34,22 @@ const (
 	// GraphQL extras
 	DOLLAR TokenType = "$"
 	BANG   TokenType = "!"
+	AT     TokenType = "@"
+	PIPE   TokenType = "|"
+	AMP    TokenType = "&"
+	SPREAD TokenType = "..."
 )
 
+// Token is a token of a document, as returned by Lexer.NextToken.
 type Token struct {
-	Type    TokenType
+	Type TokenType
+	// Literal is the text of the token. For strings, it is their value, with
+	// escape sequences decoded.
 	Literal string
+	// Start and End are the byte offsets of the token in the input. Use
+	// Lexer.Locate to find their line and column.
+	Start, End int
+	// Message explains why an ILLEGAL token is invalid, such as
+	// "Unterminated string". It is empty for unexpected characters.
+	Message string
 }
@@ -0,0 +1,179 @@
+package vibeGraphql
+
+import (
+	"strconv"
+	"sync"
+	"time"
+)
+
+// ReplayOptions bounds the replay buffer of a Topic. Events are kept until
+// more than Size newer ones have been published or they are older than
+// MaxAge. A zero Size keeps no events; a zero MaxAge keeps them regardless of
+// age.
+type ReplayOptions struct {
+	Size   int
+	MaxAge time.Duration
+}
+
+// TopicEvent is the message a subscription to a Topic receives for each
+// event. Clients that reconnect send the ID of the last event they received
+// as the "lastEventId" member of their subscription request, and receive the
+// buffered events published after it before any new ones.
+type TopicEvent struct {
+	ID      string      `json:"id"`
+	Payload interface{} `json:"payload"`
+}
+
+// topicBacklog is the number of events a subscriber can fall behind before
+// it is dropped.
+const topicBacklog = 64
+
+// Topic fans published events out to subscriptions, keeping recent events so
+// that clients that briefly disconnect do not lose them. A subscription
+// resolver returns a Topic in place of a channel to serve its events.
+//
+// Subscribers that fall behind by more than 64 events are dropped: their
+// subscription ends, and they can reconnect and catch up from the replay
+// buffer.
+type Topic struct {
+	mu          sync.Mutex
+	replay      ReplayOptions
+	seq         uint64
+	buffer      []topicEntry
+	subscribers map[chan TopicEvent]struct{}
+	closed      bool
+}
+
+type topicEntry struct {
+	seq   uint64
+	at    time.Time
+	event TopicEvent
+}
+
+// NewTopic returns a topic whose replay buffer is bounded by replay.
+func NewTopic(replay ReplayOptions) *Topic {
+	return &Topic{replay: replay, subscribers: make(map[chan TopicEvent]struct{})}
+}
+
+// Publish sends payload to the topic's subscriptions and returns the ID of
+// the event. Publishing to a closed topic does nothing.
+func (t *Topic) Publish(payload interface{}) string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return ""
+	}
+	t.seq++
+	event := TopicEvent{ID: strconv.FormatUint(t.seq, 10), Payload: payload}
+	now := time.Now()
+	if t.replay.Size > 0 {
+		t.buffer = append(t.buffer, topicEntry{seq: t.seq, at: now, event: event})
+		if len(t.buffer) > t.replay.Size {
+			t.buffer = append(t.buffer[:0], t.buffer[len(t.buffer)-t.replay.Size:]...)
+		}
+	}
+	t.expire(now)
+	for ch := range t.subscribers {
+		select {
+		case ch <- event:
+		default:
+			delete(t.subscribers, ch)
+			close(ch)
+		}
+	}
+	return event.ID
+}
+
+// Close ends the topic's subscriptions.
+func (t *Topic) Close() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.closed {
+		return
+	}
+	t.closed = true
+	for ch := range t.subscribers {
+		close(ch)
+	}
+	t.subscribers = nil
+}
+
+// expire drops the buffered events older than MaxAge.
+func (t *Topic) expire(now time.Time) {
+	if t.replay.MaxAge <= 0 {
+		return
+	}
+	i := 0
+	for i < len(t.buffer) && now.Sub(t.buffer[i].at) > t.replay.MaxAge {
+		i++
+	}
+	t.buffer = t.buffer[i:]
+}
+
+// subscribe returns the buffered events published after lastEventID and a
+// channel of the events published from now on. An empty or unknown
+// lastEventID replays nothing.
+func (t *Topic) subscribe(lastEventID string) ([]TopicEvent, chan TopicEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	ch := make(chan TopicEvent, topicBacklog)
+	if t.closed {
+		close(ch)
+		return nil, ch
+	}
+	t.subscribers[ch] = struct{}{}
+	last, err := strconv.ParseUint(lastEventID, 10, 64)
+	if err != nil || last > t.seq {
+		return nil, ch
+	}
+	t.expire(time.Now())
+	var missed []TopicEvent
+	for _, entry := range t.buffer {
+		if entry.seq > last {
+			missed = append(missed, entry.event)
+		}
+	}
+	return missed, ch
+}
+
+func (t *Topic) unsubscribe(ch chan TopicEvent) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if _, ok := t.subscribers[ch]; ok {
+		delete(t.subscribers, ch)
+		close(ch)
+	}
+}
+
+// events returns the events of a subscription that last received
+// lastEventID, starting with the missed ones, and a function that ends the
+// subscription.
+func (t *Topic) events(lastEventID string) (<-chan interface{}, func()) {
+	missed, ch := t.subscribe(lastEventID)
+	out := make(chan interface{})
+	done := make(chan struct{})
+	go func() {
+		defer close(out)
+		for _, event := range missed {
+			select {
+			case out <- event:
+			case <-done:
+				return
+			}
+		}
+		for event := range ch {
+			select {
+			case out <- event:
+			case <-done:
+				return
+			}
+		}
+	}()
+	var once sync.Once
+	return out, func() {
+		once.Do(func() {
+			close(done)
+			t.unsubscribe(ch)
+		})
+	}
+}
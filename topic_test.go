@@ -0,0 +1,148 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"net/http/httptest"
+	"reflect"
+	"testing"
+	"time"
+)
+
+// jsonConn is a messageConn that records the JSON it writes.
+type jsonConn struct {
+	messageConn
+	events []string
+}
+
+func (c *jsonConn) WriteJSON(v interface{}) error {
+	data, err := json.Marshal(v)
+	c.events = append(c.events, string(data))
+	return err
+}
+
+func payloads(events []TopicEvent) []interface{} {
+	var values []interface{}
+	for _, event := range events {
+		values = append(values, event.Payload)
+	}
+	return values
+}
+
+func TestTopicReplay(t *testing.T) {
+	topic := NewTopic(ReplayOptions{Size: 3})
+	for i := 1; i <= 5; i++ {
+		topic.Publish(i)
+	}
+	for _, tt := range []struct {
+		last string
+		want []interface{}
+	}{
+		{"", nil},
+		{"1", []interface{}{3, 4, 5}},
+		{"3", []interface{}{4, 5}},
+		{"5", nil},
+		{"6", nil},
+		{"bogus", nil},
+	} {
+		missed, ch := topic.subscribe(tt.last)
+		if got := payloads(missed); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("last %q: replayed %v, want %v", tt.last, got, tt.want)
+		}
+		topic.unsubscribe(ch)
+	}
+
+	missed, ch := topic.subscribe("4")
+	if id := topic.Publish(6); id != "6" {
+		t.Errorf("unexpected event ID %q", id)
+	}
+	if got := append(payloads(missed), (<-ch).Payload); !reflect.DeepEqual(got, []interface{}{5, 6}) {
+		t.Errorf("expected the live event after the missed one, got %v", got)
+	}
+	topic.Close()
+	if _, ok := <-ch; ok {
+		t.Error("expected closing the topic to end the subscription")
+	}
+	if id := topic.Publish(7); id != "" {
+		t.Errorf("expected publishing to a closed topic to do nothing, got ID %q", id)
+	}
+}
+
+func TestTopicReplayMaxAge(t *testing.T) {
+	topic := NewTopic(ReplayOptions{Size: 10, MaxAge: 20 * time.Millisecond})
+	topic.Publish("old")
+	time.Sleep(40 * time.Millisecond)
+	topic.Publish("new")
+	if missed, _ := topic.subscribe("0"); !reflect.DeepEqual(payloads(missed), []interface{}{"new"}) {
+		t.Errorf("expected expired events to be dropped, got %v", payloads(missed))
+	}
+}
+
+func TestTopicDropsSlowSubscribers(t *testing.T) {
+	topic := NewTopic(ReplayOptions{})
+	_, ch := topic.subscribe("")
+	for i := 0; i <= topicBacklog; i++ {
+		topic.Publish(i)
+	}
+	n := 0
+	for range ch {
+		n++
+	}
+	if n != topicBacklog {
+		t.Errorf("expected the subscriber to be dropped after %d events, got %d", topicBacklog, n)
+	}
+}
+
+func TestSubscribeTopic(t *testing.T) {
+	topic := NewTopic(ReplayOptions{Size: 10})
+	RegisterSubscriptionResolver("topicEvents", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return topic, nil
+	})
+	defer delete(SubscriptionResolvers, "topicEvents")
+	topic.Publish("a")
+	topic.Publish("b")
+	topic.Publish("c")
+
+	conn := &jsonConn{messageConn: messageConn{request: `{"query": "subscription { topicEvents }", "lastEventId": "1"}`}}
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		defaultHandler.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	}()
+	// Wait for the subscription before publishing and closing the topic.
+	for {
+		topic.mu.Lock()
+		n := len(topic.subscribers)
+		topic.mu.Unlock()
+		if n > 0 {
+			break
+		}
+		time.Sleep(time.Millisecond)
+	}
+	topic.Publish("d")
+	topic.Close()
+	<-done
+	want := []string{`{"id":"2","payload":"b"}`, `{"id":"3","payload":"c"}`, `{"id":"4","payload":"d"}`}
+	if !reflect.DeepEqual(conn.events, want) {
+		t.Errorf("got events %v, want %v", conn.events, want)
+	}
+}
+
+func TestSubscribeFieldResolverContext(t *testing.T) {
+	type ctxKey struct{}
+	var got interface{}
+	useResolvers(t, map[string]ResolverFunc{}, map[string]map[string]ContextResolverFunc{
+		"Subscription": {"events": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			got = ctx.Value(ctxKey{})
+			ch := make(chan interface{})
+			close(ch)
+			return ch, nil
+		}},
+	})
+	conn := &jsonConn{messageConn: messageConn{request: `{"query": "subscription { events }"}`}}
+	r := httptest.NewRequest("GET", "/graphql/ws", nil)
+	defaultHandler.subscribe(r.WithContext(context.WithValue(r.Context(), ctxKey{}, "user")), conn)
+	if got != "user" {
+		t.Errorf("expected the request context to reach the resolver, got %v", got)
+	}
+}
@@ -0,0 +1,194 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Upload is a file sent with a multipart request, following the GraphQL
+// multipart request spec. Variables declared as Upload or [Upload] reach
+// resolvers as Upload and []Upload; variables of other types receive the
+// file as a map with "filename", "contentType", "size", "data" and "file"
+// entries.
+type Upload struct {
+	Filename    string
+	ContentType string
+	Size        int64
+	// Data holds the contents of files kept in memory. It is nil for files
+	// spilled to disk; see WithUploadMemory.
+	Data []byte
+	// File reads the contents of the file, whether it is held in memory or
+	// on disk. Files on disk are closed and removed when the request is done.
+	File io.ReadSeeker
+}
+
+// uploadValue returns the variable value a file is mapped to.
+func uploadValue(u Upload) map[string]interface{} {
+	return map[string]interface{}{
+		"filename":    u.Filename,
+		"contentType": u.ContentType,
+		"size":        u.Size,
+		"data":        u.Data,
+		"file":        u.File,
+	}
+}
+
+// coerceUpload converts a file mapped into the variables to an Upload.
+func coerceUpload(value interface{}) (interface{}, error) {
+	switch v := value.(type) {
+	case Upload:
+		return v, nil
+	case *Upload:
+		return *v, nil
+	case map[string]interface{}:
+		filename, ok := v["filename"].(string)
+		file, isFile := v["file"].(io.ReadSeeker)
+		if ok && isFile {
+			contentType, _ := v["contentType"].(string)
+			size, _ := v["size"].(int64)
+			data, _ := v["data"].([]byte)
+			return Upload{Filename: filename, ContentType: contentType, Size: size, Data: data, File: file}, nil
+		}
+	}
+	return nil, fmt.Errorf("Upload cannot represent value: %s; send files as multipart requests", jsonString(value))
+}
+
+// WithUploadMemory keeps up to max bytes of the files of a multipart request
+// in memory. Files that do not fit are written to temporary files and are
+// not read into Upload.Data; resolvers read them through Upload.File, so
+// memory use stays flat for large uploads. The temporary files are removed
+// when the request is done. Without this option, 32 MiB are kept in memory
+// and every file is read into Upload.Data.
+func WithUploadMemory(max int64) HandlerOption {
+	return func(h *handler) {
+		h.uploadMemory = max
+	}
+}
+
+// defaultUploadMemory is the memory used for the files of a multipart
+// request without WithUploadMemory.
+const defaultUploadMemory = 32 << 20
+
+// readUpload returns the Upload for a file of a multipart request. Files
+// spilled to disk are read into memory only if readAll is set.
+func readUpload(file multipart.File, header *multipart.FileHeader, readAll bool) (Upload, error) {
+	upload := Upload{
+		Filename:    header.Filename,
+		ContentType: header.Header.Get("Content-Type"),
+		Size:        header.Size,
+		File:        file,
+	}
+	if _, onDisk := file.(*os.File); onDisk && !readAll {
+		return upload, nil
+	}
+	data, err := io.ReadAll(file)
+	if err != nil {
+		return Upload{}, err
+	}
+	upload.Data, upload.File = data, bytes.NewReader(data)
+	return upload, nil
+}
+
+// isUploadList reports whether t is a list of uploads.
+func isUploadList(t *Type) bool {
+	return t.IsList && t.Elem != nil && !t.Elem.IsList && t.Elem.Name == "Upload"
+}
+
+// uploadList converts the coerced items of a list of uploads to []Upload.
+func uploadList(items []interface{}) ([]Upload, error) {
+	uploads := make([]Upload, len(items))
+	for i, item := range items {
+		upload, ok := item.(Upload)
+		if !ok {
+			return nil, fmt.Errorf("Upload cannot represent value: null")
+		}
+		uploads[i] = upload
+	}
+	return uploads, nil
+}
+
+// uploadPlacer maps the files of a multipart request into its variables.
+// Lists are extended as needed; the placer remembers the indexes skipped when
+// extending them, so that a map with sparse indexes can be rejected.
+type uploadPlacer struct {
+	variables map[string]interface{}
+	holes     map[string]bool
+}
+
+func newUploadPlacer(variables map[string]interface{}) *uploadPlacer {
+	return &uploadPlacer{variables: variables, holes: make(map[string]bool)}
+}
+
+// place sets the value at path, a map path such as "variables.files.0".
+func (p *uploadPlacer) place(path string, value interface{}) error {
+	if !strings.HasPrefix(path, "variables.") {
+		return fmt.Errorf("invalid map path %q: files can only be mapped into variables", path)
+	}
+	keys := strings.Split(strings.TrimPrefix(path, "variables."), ".")
+	if _, err := strconv.Atoi(keys[0]); err == nil {
+		return fmt.Errorf("invalid map path %q", path)
+	}
+	_, err := p.set(p.variables, "variables", keys, value)
+	return err
+}
+
+// set returns container with value set at keys, creating objects and growing
+// lists as needed. prefix is the map path of container.
+func (p *uploadPlacer) set(container interface{}, prefix string, keys []string, value interface{}) (interface{}, error) {
+	if len(keys) == 0 {
+		return value, nil
+	}
+	key, path := keys[0], prefix+"."+keys[0]
+	if index, err := strconv.Atoi(key); err == nil {
+		list, ok := container.([]interface{})
+		if (container != nil && !ok) || index < 0 {
+			return nil, fmt.Errorf("invalid map path %q: %q is not a list", path, prefix)
+		}
+		if index >= len(list) {
+			for skipped := len(list); skipped < index; skipped++ {
+				p.holes[prefix+"."+strconv.Itoa(skipped)] = true
+			}
+			grown := make([]interface{}, index+1)
+			copy(grown, list)
+			list = grown
+		}
+		item, err := p.set(list[index], path, keys[1:], value)
+		if err != nil {
+			return nil, err
+		}
+		list[index] = item
+		delete(p.holes, path)
+		return list, nil
+	}
+	object, ok := container.(map[string]interface{})
+	if container == nil {
+		object = make(map[string]interface{})
+	} else if !ok {
+		return nil, fmt.Errorf("invalid map path %q: %q is not an object", path, prefix)
+	}
+	item, err := p.set(object[key], path, keys[1:], value)
+	if err != nil {
+		return nil, err
+	}
+	object[key] = item
+	return object, nil
+}
+
+// check returns an error naming a list index skipped by the map.
+func (p *uploadPlacer) check() error {
+	paths := make([]string, 0, len(p.holes))
+	for path := range p.holes {
+		paths = append(paths, path)
+	}
+	if len(paths) == 0 {
+		return nil
+	}
+	sort.Strings(paths)
+	return fmt.Errorf("no file is mapped to %q; list indexes in the map must be contiguous", paths[0])
+}
@@ -0,0 +1,127 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"net/http/httptest"
+	"net/textproto"
+	"os"
+	"strings"
+	"testing"
+)
+
+// multipartRequest builds a multipart request following the GraphQL
+// multipart request spec, with a text file for every entry of files.
+func multipartRequest(t *testing.T, operations, fileMap string, files map[string]string) *http.Request {
+	t.Helper()
+	var buf bytes.Buffer
+	writer := multipart.NewWriter(&buf)
+	writer.WriteField("operations", operations)
+	writer.WriteField("map", fileMap)
+	for key, content := range files {
+		header := make(textproto.MIMEHeader)
+		header.Set("Content-Disposition", `form-data; name="`+key+`"; filename="`+key+`.txt"`)
+		header.Set("Content-Type", "text/plain")
+		part, err := writer.CreatePart(header)
+		if err != nil {
+			t.Fatal(err)
+		}
+		part.Write([]byte(content))
+	}
+	writer.Close()
+	req := httptest.NewRequest("POST", "/graphql", &buf)
+	req.Header.Set("Content-Type", writer.FormDataContentType())
+	return req
+}
+
+func TestUploadList(t *testing.T) {
+	var got []Upload
+	var avatar Upload
+	RegisterMutationResolver("attach", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		got = args["files"].([]Upload)
+		if a, ok := args["avatar"].(Upload); ok {
+			avatar = a
+		}
+		return len(got), nil
+	})
+	defer delete(MutationResolvers, "attach")
+
+	operations := `{"query": "mutation ($files: [Upload!]!, $avatar: Upload) { attach(files: $files, avatar: $avatar) }", "variables": {"files": [null, null, null], "avatar": null}}`
+	fileMap := `{"c": ["variables.files.2"], "a": ["variables.files.0"], "b": ["variables.files.1"], "d": ["variables.avatar"]}`
+	rr := httptest.NewRecorder()
+	GraphqlUploadHandler(rr, multipartRequest(t, operations, fileMap, map[string]string{"a": "first", "b": "second", "c": "third", "d": "me"}))
+	if rr.Code != http.StatusOK || !strings.Contains(rr.Body.String(), `"attach":3`) {
+		t.Fatalf("unexpected response %d %s", rr.Code, rr.Body.String())
+	}
+	for i, want := range []string{"first", "second", "third"} {
+		if string(got[i].Data) != want || got[i].ContentType != "text/plain" {
+			t.Errorf("file %d: got %+v, want %q", i, got[i], want)
+		}
+	}
+	if avatar.Filename != "d.txt" || string(avatar.Data) != "me" {
+		t.Errorf("unexpected avatar %+v", avatar)
+	}
+
+	// Without placeholders in the operations, lists are built from the map,
+	// which must not skip indexes.
+	operations = `{"query": "mutation ($files: [Upload!]!) { attach(files: $files) }"}`
+	rr = httptest.NewRecorder()
+	GraphqlUploadHandler(rr, multipartRequest(t, operations, `{"a": ["variables.files.0"], "c": ["variables.files.2"]}`, map[string]string{"a": "first", "c": "third"}))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `"variables.files.1"`) {
+		t.Errorf("expected sparse indexes to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	rr = httptest.NewRecorder()
+	GraphqlUploadHandler(rr, multipartRequest(t, operations, `{"a": ["variables.files.0"], "b": ["variables.files.1"]}`, map[string]string{"a": "first"}))
+	if rr.Code != http.StatusBadRequest || !strings.Contains(rr.Body.String(), `missing file "b"`) {
+		t.Errorf("expected a missing file to be rejected, got %d %s", rr.Code, rr.Body.String())
+	}
+
+	// Strings are not files.
+	rr = httptest.NewRecorder()
+	GraphqlUploadHandler(rr, multipartRequest(t, `{"query": "mutation ($files: [Upload!]!) { attach(files: $files) }", "variables": {"files": ["nope"]}}`, `{}`, nil))
+	if !strings.Contains(rr.Body.String(), `Upload cannot represent value: \"nope\"`) {
+		t.Errorf("expected a string to be rejected as an upload, got %s", rr.Body.String())
+	}
+}
+
+func TestUploadMemory(t *testing.T) {
+	var big, small Upload
+	var spilled string
+	RegisterMutationResolver("store", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		big, small = args["big"].(Upload), args["small"].(Upload)
+		if f, ok := big.File.(*os.File); ok {
+			spilled = f.Name()
+		}
+		data, err := io.ReadAll(big.File)
+		return len(data), err
+	})
+	defer delete(MutationResolvers, "store")
+
+	content := strings.Repeat("x", 4096)
+	operations := `{"query": "mutation ($big: Upload!, $small: Upload!) { store(big: $big, small: $small) }"}`
+	fileMap := `{"a": ["variables.big"], "b": ["variables.small"]}`
+	rr := httptest.NewRecorder()
+	NewHandler(WithUploadMemory(1024)).ServeHTTP(rr, multipartRequest(t, operations, fileMap, map[string]string{"a": content, "b": "tiny"}))
+	if !strings.Contains(rr.Body.String(), `"store":4096`) {
+		t.Fatalf("unexpected response %s", rr.Body.String())
+	}
+	if spilled == "" || big.Data != nil || big.Size != 4096 {
+		t.Errorf("expected the large file to be spilled to disk, got %+v", big)
+	}
+	if _, err := os.Stat(spilled); !os.IsNotExist(err) {
+		t.Errorf("expected the temporary file to be removed, got %v", err)
+	}
+	if string(small.Data) != "tiny" {
+		t.Errorf("expected the small file in memory, got %+v", small)
+	}
+
+	// By default every file is read into memory.
+	rr = httptest.NewRecorder()
+	GraphqlUploadHandler(rr, multipartRequest(t, operations, fileMap, map[string]string{"a": content, "b": "tiny"}))
+	if len(big.Data) != 4096 {
+		t.Errorf("expected the file to be read into memory, got %d bytes", len(big.Data))
+	}
+}
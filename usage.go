@@ -0,0 +1,258 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+)
+
+// maxUsageRecords bounds the executions buffered between two reports.
+const maxUsageRecords = 10000
+
+// UsageReporter periodically pushes operation usage statistics, and the
+// running schema, to a schema registry such as GraphQL Hive. Field usage
+// recorded this way shows which fields are still in use before they are
+// deprecated or removed.
+//
+// Reports use Hive's JSON usage format: each operation is identified by its
+// normalized signature and lists the schema coordinates ("Type",
+// "Type.field", "Type.field.argument") it selects.
+type UsageReporter struct {
+	// Endpoint receives the usage reports.
+	Endpoint string
+	// SchemaEndpoint, when set, receives the running schema as
+	// {"sdl": "..."} whenever it changes.
+	SchemaEndpoint string
+	// Token is sent as a bearer token with every report.
+	Token    string
+	Interval time.Duration
+	Client   *http.Client
+
+	mu         sync.Mutex
+	operations map[string]usageOperation
+	records    []usageRecord
+	lastSDL    string
+	now        func() time.Time
+}
+
+type usageOperation struct {
+	OperationName string   `json:"operationName,omitempty"`
+	Operation     string   `json:"operation"`
+	Fields        []string `json:"fields"`
+}
+
+type usageRecord struct {
+	OperationMapKey string         `json:"operationMapKey"`
+	Timestamp       int64          `json:"timestamp"`
+	Execution       usageExecution `json:"execution"`
+}
+
+type usageExecution struct {
+	OK          bool  `json:"ok"`
+	Duration    int64 `json:"duration"`
+	ErrorsTotal int   `json:"errorsTotal"`
+}
+
+// NewUsageReporter creates a UsageReporter sending reports to endpoint
+// every minute.
+func NewUsageReporter(endpoint, token string) *UsageReporter {
+	return &UsageReporter{Endpoint: endpoint, Token: token, Interval: time.Minute, Client: http.DefaultClient}
+}
+
+// WithUsageReporter records every operation the handler executes in reporter.
+// Call reporter.Run to send the reports.
+func WithUsageReporter(reporter *UsageReporter) HandlerOption {
+	return func(h *handler) {
+		h.usage = reporter
+	}
+}
+
+// record adds an execution of op, an operation of doc, to the next report.
+func (u *UsageReporter) record(doc *Document, op *OperationDefinition, entry RequestLog) {
+	if op == nil || entry.Signature == "" {
+		return
+	}
+	u.mu.Lock()
+	defer u.mu.Unlock()
+	if len(u.records) >= maxUsageRecords {
+		return
+	}
+	if u.operations == nil {
+		u.operations = make(map[string]usageOperation)
+	}
+	if _, ok := u.operations[entry.Signature]; !ok {
+		u.operations[entry.Signature] = usageOperation{
+			OperationName: op.Name,
+			Operation:     Normalize(doc).Query,
+			Fields:        schemaCoordinates(CurrentSchema(), op),
+		}
+	}
+	now := time.Now
+	if u.now != nil {
+		now = u.now
+	}
+	u.records = append(u.records, usageRecord{
+		OperationMapKey: entry.Signature,
+		Timestamp:       now().UnixMilli(),
+		Execution: usageExecution{
+			OK:          entry.ErrorCount == 0,
+			Duration:    entry.Duration.Nanoseconds(),
+			ErrorsTotal: entry.ErrorCount,
+		},
+	})
+}
+
+// schemaCoordinates returns the sorted schema coordinates selected by op.
+// Without a schema, only the root fields are known.
+func schemaCoordinates(s *Schema, op *OperationDefinition) []string {
+	root := "Query"
+	switch op.Operation {
+	case "mutation":
+		root = "Mutation"
+	case "subscription":
+		root = "Subscription"
+	}
+	if s != nil {
+		root = map[string]string{"Query": s.QueryType, "Mutation": s.MutationType, "Subscription": s.SubscriptionType}[root]
+	}
+	seen := make(map[string]bool)
+	spread := make(map[*FragmentDefinition]bool)
+	var walk func(typeName string, ss *SelectionSet)
+	walk = func(typeName string, ss *SelectionSet) {
+		if ss == nil || typeName == "" {
+			return
+		}
+		seen[typeName] = true
+		for _, sel := range ss.Selections {
+			field, ok := sel.(*Field)
+			if !ok {
+				// Fragments select fields of their type condition, wherever
+				// they are spread.
+				fragment, typeCondition, _ := expandFragment(sel)
+				if s, ok := sel.(*FragmentSpread); ok {
+					if spread[s.Fragment] {
+						continue
+					}
+					spread[s.Fragment] = true
+				}
+				if typeCondition == "" {
+					typeCondition = typeName
+				}
+				walk(typeCondition, fragment)
+				continue
+			}
+			if field.Name == "__typename" {
+				continue
+			}
+			coordinate := typeName + "." + field.Name
+			seen[coordinate] = true
+			for _, arg := range field.Arguments {
+				seen[coordinate+"."+arg.Name] = true
+			}
+			if s == nil {
+				continue
+			}
+			if def := s.Field(typeName, field.Name); def != nil {
+				walk(namedType(def.Type), field.SelectionSet)
+			}
+		}
+	}
+	walk(root, op.SelectionSet)
+	coordinates := make([]string, 0, len(seen))
+	for coordinate := range seen {
+		coordinates = append(coordinates, coordinate)
+	}
+	sort.Strings(coordinates)
+	return coordinates
+}
+
+// Run sends a report every Interval until ctx is done, then sends a final
+// one. Failed reports are logged and their data is dropped.
+func (u *UsageReporter) Run(ctx context.Context) {
+	interval := u.Interval
+	if interval <= 0 {
+		interval = time.Minute
+	}
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			if err := u.Flush(context.Background()); err != nil {
+				logger().Error("graphql: failed to send usage report", "error", err)
+			}
+			return
+		case <-ticker.C:
+			if err := u.Flush(ctx); err != nil {
+				logger().ErrorContext(ctx, "graphql: failed to send usage report", "error", err)
+			}
+		}
+	}
+}
+
+// Flush sends the schema, if it changed, and the usage recorded since the
+// last report.
+func (u *UsageReporter) Flush(ctx context.Context) error {
+	if u.SchemaEndpoint != "" {
+		if s := CurrentSchema(); s != nil {
+			sdl := PrintSchema(s)
+			u.mu.Lock()
+			changed := sdl != u.lastSDL
+			u.mu.Unlock()
+			if changed {
+				if err := u.post(ctx, u.SchemaEndpoint, map[string]string{"sdl": sdl}); err != nil {
+					return err
+				}
+				u.mu.Lock()
+				u.lastSDL = sdl
+				u.mu.Unlock()
+			}
+		}
+	}
+
+	u.mu.Lock()
+	operations, records := u.operations, u.records
+	u.operations, u.records = nil, nil
+	u.mu.Unlock()
+	if len(records) == 0 {
+		return nil
+	}
+	return u.post(ctx, u.Endpoint, map[string]interface{}{
+		"size":       len(records),
+		"map":        operations,
+		"operations": records,
+	})
+}
+
+func (u *UsageReporter) post(ctx context.Context, endpoint string, payload interface{}) error {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if u.Token != "" {
+		req.Header.Set("Authorization", "Bearer "+u.Token)
+	}
+	client := u.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return fmt.Errorf("%s responded with %s", endpoint, resp.Status)
+	}
+	return nil
+}
@@ -0,0 +1,94 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestUsageReporter(t *testing.T) {
+	type user struct{ Name string }
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) {
+			return user{Name: "ada"}, nil
+		},
+	}, map[string]map[string]ContextResolverFunc{})
+	useTestSchema(t, `type Query { user(id: ID): User version: String } type User { name: String email: String }`)
+
+	var usage []map[string]interface{}
+	var sdls []string
+	registry := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.Header.Get("Authorization") != "Bearer secret" {
+			t.Errorf("unexpected authorization %q", r.Header.Get("Authorization"))
+		}
+		body, _ := io.ReadAll(r.Body)
+		var payload map[string]interface{}
+		if err := json.Unmarshal(body, &payload); err != nil {
+			t.Errorf("invalid report %s", body)
+		}
+		if r.URL.Path == "/schema" {
+			sdls = append(sdls, payload["sdl"].(string))
+			return
+		}
+		usage = append(usage, payload)
+	}))
+	defer registry.Close()
+
+	reporter := NewUsageReporter(registry.URL+"/usage", "secret")
+	reporter.SchemaEndpoint = registry.URL + "/schema"
+	h := NewHandler(WithUsageReporter(reporter))
+	for _, query := range []string{`query Me { user(id: 1) { name } }`, `query Me { user(id: 2) { name } }`, `{ nope }`} {
+		body, _ := json.Marshal(map[string]string{"query": query})
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(string(body))))
+	}
+
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(sdls) != 1 || !strings.Contains(sdls[0], "email: String") {
+		t.Errorf("unexpected schema reports %q", sdls)
+	}
+	if len(usage) != 1 {
+		t.Fatalf("expected one usage report, got %d", len(usage))
+	}
+	report := usage[0]
+	if report["size"] != float64(3) {
+		t.Errorf("unexpected size %v", report["size"])
+	}
+	operations := report["map"].(map[string]interface{})
+	if len(operations) != 2 {
+		t.Fatalf("expected two operations, got %v", operations)
+	}
+	me := operations[Normalize(parseQuery(`query Me { user(id: 1) { name } }`)).Signature].(map[string]interface{})
+	if me["operationName"] != "Me" {
+		t.Errorf("unexpected operation %v", me)
+	}
+	want := []interface{}{"Query", "Query.user", "Query.user.id", "User", "User.name"}
+	if !reflect.DeepEqual(me["fields"], want) {
+		t.Errorf("got fields %v, want %v", me["fields"], want)
+	}
+	var failed int
+	for _, record := range report["operations"].([]interface{}) {
+		execution := record.(map[string]interface{})["execution"].(map[string]interface{})
+		if execution["ok"] == false {
+			failed++
+		}
+	}
+	if failed != 1 {
+		t.Errorf("expected one failed execution, got %d", failed)
+	}
+
+	// Nothing new is sent when nothing happened and the schema is unchanged.
+	if err := reporter.Flush(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if len(usage) != 1 || len(sdls) != 1 {
+		t.Errorf("unexpected reports %d %d", len(usage), len(sdls))
+	}
+}
@@ -0,0 +1,972 @@
+package vibeGraphql
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Validate checks the operations in doc against the schema and returns every
+// problem found. It runs SpecifiedRules followed by the schema's
+// ValidationRules. Type system definitions in doc are ignored.
+func Validate(s *Schema, doc *Document) []*Error {
+	rules := SpecifiedRules
+	if len(s.ValidationRules) > 0 {
+		rules = append(append([]ValidationRule{}, SpecifiedRules...), s.ValidationRules...)
+	}
+	return ValidateWithRules(s, doc, rules)
+}
+
+// ValidateWithRules checks the operations in doc against the schema using
+// only rules.
+func ValidateWithRules(s *Schema, doc *Document, rules []ValidationRule) []*Error {
+	ctx := &ValidationContext{Schema: s, Document: doc}
+	visitors := make([]RuleVisitor, len(rules))
+	for i, rule := range rules {
+		visitors[i] = rule(ctx)
+	}
+	w := &ruleWalker{ctx: ctx, visitors: visitors}
+	for _, op := range operationsOf(doc) {
+		for _, v := range visitors {
+			if v.EnterOperation != nil {
+				v.EnterOperation(op)
+			}
+		}
+		w.enterDirectives(strings.ToUpper(op.Operation), op.Directives)
+		if root := rootType(s, op); root != "" {
+			w.walkSelectionSet(root, op.SelectionSet)
+		}
+	}
+	for _, def := range fragmentDefinitions(doc) {
+		for _, v := range visitors {
+			if v.EnterFragment != nil {
+				v.EnterFragment(def)
+			}
+		}
+		w.enterDirectives("FRAGMENT_DEFINITION", def.Directives)
+		if s.Type(def.TypeCondition) != nil {
+			w.walkSelectionSet(def.TypeCondition, def.SelectionSet)
+		}
+	}
+	return ctx.errors
+}
+
+// ValidationContext is shared by the rules of a validation. It gives access
+// to the schema and document being validated and collects the errors the
+// rules report.
+type ValidationContext struct {
+	Schema   *Schema
+	Document *Document
+
+	errors []*Error
+}
+
+// Report records err as a validation error.
+func (c *ValidationContext) Report(err *Error) {
+	c.errors = append(c.errors, err)
+}
+
+// Reportf records a validation error located at field, which may be nil.
+func (c *ValidationContext) Reportf(field *Field, format string, args ...interface{}) {
+	err := &Error{Message: fmt.Sprintf(format, args...)}
+	if field != nil && field.Loc.Line > 0 {
+		err.Locations = []Location{field.Loc}
+	}
+	c.Report(err)
+}
+
+// reportAt records a validation error located at loc, unless loc is unknown.
+func (c *ValidationContext) reportAt(loc Location, format string, args ...interface{}) {
+	err := &Error{Message: fmt.Sprintf(format, args...)}
+	if loc.Line > 0 {
+		err.Locations = []Location{loc}
+	}
+	c.Report(err)
+}
+
+// Errors returns the errors reported so far.
+func (c *ValidationContext) Errors() []*Error {
+	return c.errors
+}
+
+// RuleVisitor holds the callbacks a validation rule wants called while the
+// document is walked. Nil callbacks are skipped.
+type RuleVisitor struct {
+	// EnterOperation is called for each operation, before its fields.
+	EnterOperation func(op *OperationDefinition)
+	// EnterField is called for each field selected on parentType, before
+	// its subfields. def is the field's definition, or nil if parentType
+	// has no such field or it may not be queried. Subfields are only
+	// visited when def is known.
+	EnterField func(parentType string, field, def *Field)
+	// EnterFragment is called for each fragment definition, after the
+	// operations and before the fields of the fragment. Fields are visited
+	// where fragments are defined rather than where they are spread.
+	EnterFragment func(def *FragmentDefinition)
+	// EnterDirectives is called with the directives of each operation,
+	// field, fragment definition, fragment spread and inline fragment that
+	// has any, and the location they are used at, such as "QUERY" or
+	// "FIELD".
+	EnterDirectives func(location string, directives []Directive)
+}
+
+// ValidationRule checks one aspect of a document. It is called once per
+// validation and returns the callbacks that inspect the document, reporting
+// problems through ctx.
+type ValidationRule func(ctx *ValidationContext) RuleVisitor
+
+// SpecifiedRules are the rules Validate always runs, in order.
+var SpecifiedRules = []ValidationRule{
+	OperationNamesRule,
+	SingleFieldSubscriptionsRule,
+	NoUndefinedVariablesRule,
+	NoUnusedVariablesRule,
+	RootTypesRule,
+	FieldsOnCorrectTypeRule,
+	KnownArgumentsRule,
+	ArgumentValuesRule,
+	RequiredArgumentsRule,
+	ScalarLeafsRule,
+	KnownDirectivesRule,
+	UniqueDirectivesPerLocationRule,
+	DirectiveArgumentsRule,
+	UniqueFragmentNamesRule,
+	KnownFragmentNamesRule,
+	NoFragmentCyclesRule,
+	NoUnusedFragmentsRule,
+	OverlappingFieldsRule,
+}
+
+// ruleWalker walks the fields of a document, calling the rule visitors.
+// Inline fragments are walked with the type they apply to; fragment spreads
+// are skipped, as the fragments they spread are walked on their own.
+type ruleWalker struct {
+	ctx      *ValidationContext
+	visitors []RuleVisitor
+}
+
+func (w *ruleWalker) walkSelectionSet(typeName string, ss *SelectionSet) {
+	if ss == nil {
+		return
+	}
+	for _, sel := range ss.Selections {
+		field, ok := sel.(*Field)
+		if !ok {
+			switch sel := sel.(type) {
+			case *FragmentSpread:
+				w.enterDirectives("FRAGMENT_SPREAD", sel.Directives)
+			case *InlineFragment:
+				w.enterDirectives("INLINE_FRAGMENT", sel.Directives)
+				switch {
+				case sel.TypeCondition == "":
+					w.walkSelectionSet(typeName, sel.SelectionSet)
+				case w.ctx.Schema.Type(sel.TypeCondition) != nil:
+					w.walkSelectionSet(sel.TypeCondition, sel.SelectionSet)
+				}
+			}
+			continue
+		}
+		def := fieldDefinition(w.ctx.Schema, typeName, field.Name)
+		for _, v := range w.visitors {
+			if v.EnterField != nil {
+				v.EnterField(typeName, field, def)
+			}
+		}
+		w.enterDirectives("FIELD", field.Directives)
+		if def == nil || field.SelectionSet == nil {
+			continue
+		}
+		if named := w.ctx.Schema.Type(namedType(def.Type)); named != nil && named.kind() != KindScalar && named.kind() != KindEnum {
+			w.walkSelectionSet(named.Name, field.SelectionSet)
+		}
+	}
+}
+
+func (w *ruleWalker) enterDirectives(location string, directives []Directive) {
+	if len(directives) == 0 {
+		return
+	}
+	for _, v := range w.visitors {
+		if v.EnterDirectives != nil {
+			v.EnterDirectives(location, directives)
+		}
+	}
+}
+
+// fieldDefinition returns the definition of fieldName on typeName, including
+// the introspection meta-fields unless introspection is disabled, or nil.
+func fieldDefinition(s *Schema, typeName, fieldName string) *Field {
+	switch {
+	case fieldName == "__typename":
+		return &Field{Name: fieldName, Type: &Type{Name: "String", NonNull: true}}
+	case typeName != s.QueryType || s.DisableIntrospection:
+	case fieldName == "__schema":
+		return &Field{Name: fieldName, Type: &Type{Name: "__Schema", NonNull: true}}
+	case fieldName == "__type":
+		return &Field{Name: fieldName, Type: &Type{Name: "__Type"}, ArgumentDefinitions: []*InputValueDefinition{
+			{Name: "name", Type: &Type{Name: "String", NonNull: true}},
+		}}
+	}
+	return s.Field(typeName, fieldName)
+}
+
+func rootType(s *Schema, op *OperationDefinition) string {
+	switch op.Operation {
+	case "mutation":
+		return s.MutationType
+	case "subscription":
+		return s.SubscriptionType
+	}
+	return s.QueryType
+}
+
+// operationRules are the rules of SpecifiedRules that need no schema, and
+// only look at operations as a whole.
+var operationRules = []ValidationRule{
+	OperationNamesRule,
+	SingleFieldSubscriptionsRule,
+	NoUndefinedVariablesRule,
+	NoUnusedVariablesRule,
+	UniqueFragmentNamesRule,
+	KnownFragmentNamesRule,
+	NoFragmentCyclesRule,
+	NoUnusedFragmentsRule,
+	OverlappingFieldsRule,
+}
+
+// validateOperations checks doc with operationRules, which need no schema.
+func validateOperations(doc *Document) []*Error {
+	ctx := &ValidationContext{Document: doc}
+	visitors := make([]RuleVisitor, len(operationRules))
+	for i, rule := range operationRules {
+		visitors[i] = rule(ctx)
+	}
+	for _, op := range operationsOf(doc) {
+		for _, v := range visitors {
+			if v.EnterOperation != nil {
+				v.EnterOperation(op)
+			}
+		}
+	}
+	for _, def := range fragmentDefinitions(doc) {
+		for _, v := range visitors {
+			if v.EnterFragment != nil {
+				v.EnterFragment(def)
+			}
+		}
+	}
+	return ctx.errors
+}
+
+// OperationNamesRule checks that operation names are unique, and that an
+// anonymous operation is the only operation of its document.
+func OperationNamesRule(ctx *ValidationContext) RuleVisitor {
+	count := len(operationsOf(ctx.Document))
+	seen := make(map[string]bool, count)
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		switch {
+		case op.Name == "" && count > 1:
+			ctx.Reportf(nil, "This anonymous operation must be the only defined operation.")
+		case op.Name == "":
+		case seen[op.Name]:
+			ctx.Reportf(nil, "There can be only one operation named \"%s\".", op.Name)
+		default:
+			seen[op.Name] = true
+		}
+	}}
+}
+
+// SingleFieldSubscriptionsRule checks that each subscription selects a
+// single root field, the one whose events it streams.
+func SingleFieldSubscriptionsRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		if op.Operation != "subscription" {
+			return
+		}
+		fields := fieldsOf(op.SelectionSet)
+		if len(fields) < 2 {
+			return
+		}
+		extra := fields[1]
+		if op.Name == "" {
+			ctx.Reportf(extra, "Anonymous Subscription must select only one top level field.")
+			return
+		}
+		ctx.Reportf(extra, "Subscription \"%s\" must select only one top level field.", op.Name)
+	}}
+}
+
+// NoUndefinedVariablesRule checks that every variable used by an operation
+// is declared by it.
+func NoUndefinedVariablesRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		defined := make(map[string]bool, len(op.VariableDefinitions))
+		for _, def := range op.VariableDefinitions {
+			defined[def.Variable] = true
+		}
+		visitVariables(op.SelectionSet, func(field *Field, name string) {
+			if defined[name] {
+				return
+			}
+			defined[name] = true // report each variable once
+			if op.Name == "" {
+				ctx.Reportf(field, "Variable \"$%s\" is not defined.", name)
+				return
+			}
+			ctx.Reportf(field, "Variable \"$%s\" is not defined by operation \"%s\".", name, op.Name)
+		})
+	}}
+}
+
+// NoUnusedVariablesRule checks that every variable declared by an operation
+// is used by it.
+func NoUnusedVariablesRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		if len(op.VariableDefinitions) == 0 {
+			return
+		}
+		used := make(map[string]bool)
+		visitVariables(op.SelectionSet, func(field *Field, name string) {
+			used[name] = true
+		})
+		for _, def := range op.VariableDefinitions {
+			if used[def.Variable] {
+				continue
+			}
+			err := &Error{Message: fmt.Sprintf("Variable \"$%s\" is never used.", def.Variable)}
+			if op.Name != "" {
+				err.Message = fmt.Sprintf("Variable \"$%s\" is never used in operation \"%s\".", def.Variable, op.Name)
+			}
+			if def.Loc.Line > 0 {
+				err.Locations = []Location{def.Loc}
+			}
+			ctx.Report(err)
+		}
+	}}
+}
+
+// UniqueFragmentNamesRule checks that fragment names are unique.
+func UniqueFragmentNamesRule(ctx *ValidationContext) RuleVisitor {
+	seen := make(map[string]bool)
+	return RuleVisitor{EnterFragment: func(def *FragmentDefinition) {
+		if seen[def.Name] {
+			ctx.reportAt(def.Loc, "There can be only one fragment named \"%s\".", def.Name)
+			return
+		}
+		seen[def.Name] = true
+	}}
+}
+
+// KnownFragmentNamesRule checks that every fragment spread names a fragment
+// defined in the document.
+func KnownFragmentNamesRule(ctx *ValidationContext) RuleVisitor {
+	defs := fragmentsOf(ctx.Document)
+	check := func(spread *FragmentSpread) {
+		if defs[spread.Name] == nil {
+			ctx.reportAt(spread.Loc, "Unknown fragment \"%s\".", spread.Name)
+		}
+	}
+	return RuleVisitor{
+		EnterOperation: func(op *OperationDefinition) {
+			forEachSpread(op.SelectionSet, check)
+		},
+		EnterFragment: func(def *FragmentDefinition) {
+			forEachSpread(def.SelectionSet, check)
+		},
+	}
+}
+
+// NoFragmentCyclesRule checks that no fragment spreads itself, directly or
+// through other fragments. Each cycle is reported once, located at the
+// spreads forming it.
+func NoFragmentCyclesRule(ctx *ValidationContext) RuleVisitor {
+	defs := fragmentsOf(ctx.Document)
+	visited := make(map[string]bool)
+	var path []*FragmentSpread
+	pathIndex := make(map[string]int) // fragment name -> index of its spread in path
+	var detect func(def *FragmentDefinition)
+	detect = func(def *FragmentDefinition) {
+		if visited[def.Name] {
+			return
+		}
+		visited[def.Name] = true
+		pathIndex[def.Name] = len(path)
+		forEachSpread(def.SelectionSet, func(spread *FragmentSpread) {
+			path = append(path, spread)
+			if i, ok := pathIndex[spread.Name]; ok {
+				cycle := path[i:]
+				err := &Error{Message: fmt.Sprintf("Cannot spread fragment \"%s\" within itself.", spread.Name)}
+				if len(cycle) > 1 {
+					via := make([]string, len(cycle)-1)
+					for j, s := range cycle[:len(cycle)-1] {
+						via[j] = strconv.Quote(s.Name)
+					}
+					err.Message = fmt.Sprintf("Cannot spread fragment \"%s\" within itself via %s.", spread.Name, strings.Join(via, ", "))
+				}
+				for _, s := range cycle {
+					if s.Loc.Line > 0 {
+						err.Locations = append(err.Locations, s.Loc)
+					}
+				}
+				ctx.Report(err)
+			} else if target := defs[spread.Name]; target != nil {
+				detect(target)
+			}
+			path = path[:len(path)-1]
+		})
+		delete(pathIndex, def.Name)
+	}
+	return RuleVisitor{EnterFragment: detect}
+}
+
+// NoUnusedFragmentsRule checks that every fragment definition is spread by
+// an operation, directly or through other fragments.
+func NoUnusedFragmentsRule(ctx *ValidationContext) RuleVisitor {
+	var used map[string]bool
+	return RuleVisitor{EnterFragment: func(def *FragmentDefinition) {
+		if used == nil {
+			used = usedFragments(ctx.Document)
+		}
+		if !used[def.Name] {
+			ctx.reportAt(def.Loc, "Fragment \"%s\" is never used.", def.Name)
+		}
+	}}
+}
+
+// usedFragments returns the names of the fragments the operations of doc
+// spread, directly or through other fragments.
+func usedFragments(doc *Document) map[string]bool {
+	defs := fragmentsOf(doc)
+	used := make(map[string]bool)
+	var use func(spread *FragmentSpread)
+	use = func(spread *FragmentSpread) {
+		if used[spread.Name] {
+			return
+		}
+		used[spread.Name] = true
+		if def := defs[spread.Name]; def != nil {
+			forEachSpread(def.SelectionSet, use)
+		}
+	}
+	for _, op := range operationsOf(doc) {
+		forEachSpread(op.SelectionSet, use)
+	}
+	return used
+}
+
+// OverlappingFieldsRule checks that fields sharing a response key, such as
+// "avatar" in { avatar(size: 64) avatar(size: 512) }, select the same field
+// with the same arguments, as their results would otherwise be merged into
+// one. Fields within fragments on different types never resolve together
+// and are not compared. Aliases resolve the same field more than once:
+// { small: avatar(size: 64) big: avatar(size: 512) }.
+func OverlappingFieldsRule(ctx *ValidationContext) RuleVisitor {
+	reported := make(map[[2]*Field]bool)
+	var check func(fields []scopedField)
+	check = func(fields []scopedField) {
+		groups := make(map[string][]scopedField)
+		var keys []string
+		for _, f := range fields {
+			key := f.field.ResponseKey()
+			if _, ok := groups[key]; !ok {
+				keys = append(keys, key)
+			}
+			groups[key] = append(groups[key], f)
+		}
+		for _, key := range keys {
+			group := groups[key]
+			for i, a := range group {
+				for _, b := range group[i+1:] {
+					if a.exclusiveWith(b) || reported[[2]*Field{a.field, b.field}] {
+						continue
+					}
+					if reason := fieldConflict(a.field, b.field); reason != "" {
+						reported[[2]*Field{a.field, b.field}] = true
+						err := &Error{Message: fmt.Sprintf("Fields \"%s\" conflict because %s. Use different aliases on the fields to fetch both if this was intended.", key, reason)}
+						for _, f := range []*Field{a.field, b.field} {
+							if f.Loc.Line > 0 {
+								err.Locations = append(err.Locations, f.Loc)
+							}
+						}
+						ctx.Report(err)
+					}
+				}
+			}
+			// The subfields of a group are merged, so they are checked
+			// together.
+			var subfields []scopedField
+			for _, f := range group {
+				subfields = append(subfields, scopedFieldsOf(f.field.SelectionSet, f.scope)...)
+			}
+			check(subfields)
+		}
+	}
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		check(scopedFieldsOf(op.SelectionSet, ""))
+	}}
+}
+
+// scopedField is a field with the type condition of the outermost fragment
+// it is selected in, or "" if it is selected outside of fragments.
+type scopedField struct {
+	field *Field
+	scope string
+}
+
+// exclusiveWith reports whether f and other are selected in fragments on
+// different types, and so never resolve on the same object.
+func (f scopedField) exclusiveWith(other scopedField) bool {
+	return f.scope != "" && other.scope != "" && f.scope != other.scope
+}
+
+// scopedFieldsOf returns the fields of ss like fieldsOf, with the type
+// condition of the outermost fragment they are selected in, or scope if
+// that is not "".
+func scopedFieldsOf(ss *SelectionSet, scope string) []scopedField {
+	var fields []scopedField
+	spread := make(map[*FragmentDefinition]bool)
+	var walk func(ss *SelectionSet, scope string)
+	walk = func(ss *SelectionSet, scope string) {
+		if ss == nil {
+			return
+		}
+		for _, sel := range ss.Selections {
+			if field, ok := sel.(*Field); ok {
+				fields = append(fields, scopedField{field: field, scope: scope})
+				continue
+			}
+			if s, ok := sel.(*FragmentSpread); ok {
+				if spread[s.Fragment] {
+					continue
+				}
+				spread[s.Fragment] = true
+			}
+			fragment, typeCondition, _ := expandFragment(sel)
+			if scope == "" {
+				walk(fragment, typeCondition)
+			} else {
+				walk(fragment, scope)
+			}
+		}
+	}
+	walk(ss, scope)
+	return fields
+}
+
+// fieldConflict describes why a and b, sharing a response key, cannot be
+// merged, or returns "" if they can.
+func fieldConflict(a, b *Field) string {
+	if a.Name != b.Name {
+		return fmt.Sprintf("\"%s\" and \"%s\" are different fields", a.Name, b.Name)
+	}
+	if !sameArguments(a.Arguments, b.Arguments) {
+		return "they have differing arguments"
+	}
+	if (a.SelectionSet == nil) != (b.SelectionSet == nil) {
+		return fmt.Sprintf("\"%s\" is selected with and without subfields", a.Name)
+	}
+	return ""
+}
+
+// sameArguments reports whether a and b pass the same values to the same
+// arguments, in any order.
+func sameArguments(a, b []Argument) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for _, arg := range a {
+		other := findArgument(b, arg.Name)
+		if other == nil || literalString(arg.Value) != literalString(other.Value) {
+			return false
+		}
+	}
+	return true
+}
+
+// visitVariables calls visit with the name of each variable used in the
+// arguments of the fields of ss, or of their directives, and the field
+// using it. Variables in the directives of fragments are reported with a
+// nil field. The fragments within ss are visited once each.
+func visitVariables(ss *SelectionSet, visit func(field *Field, name string)) {
+	spread := make(map[*FragmentDefinition]bool)
+	var walk func(ss *SelectionSet)
+	walk = func(ss *SelectionSet) {
+		if ss == nil {
+			return
+		}
+		for _, sel := range ss.Selections {
+			field, ok := sel.(*Field)
+			if !ok {
+				fragment, _, directives := expandFragment(sel)
+				if s, ok := sel.(*FragmentSpread); ok && s.Fragment != nil {
+					if spread[s.Fragment] {
+						fragment = nil
+					} else {
+						spread[s.Fragment] = true
+						directives = append(directives[:len(directives):len(directives)], s.Fragment.Directives...)
+					}
+				}
+				for _, d := range directives {
+					for _, arg := range d.Arguments {
+						visitValueVariables(nil, arg.Value, visit)
+					}
+				}
+				walk(fragment)
+				continue
+			}
+			for _, arg := range field.Arguments {
+				visitValueVariables(field, arg.Value, visit)
+			}
+			for _, d := range field.Directives {
+				for _, arg := range d.Arguments {
+					visitValueVariables(field, arg.Value, visit)
+				}
+			}
+			walk(field.SelectionSet)
+		}
+	}
+	walk(ss)
+}
+
+// visitValueVariables calls visit with field and the name of each variable
+// used in val.
+func visitValueVariables(field *Field, val *Value, visit func(field *Field, name string)) {
+	if val == nil {
+		return
+	}
+	switch val.Kind {
+	case "Variable":
+		visit(field, val.Literal)
+	case "Object":
+		for _, v := range val.ObjectFields {
+			visitValueVariables(field, v, visit)
+		}
+	case "Array":
+		for _, v := range val.List {
+			visitValueVariables(field, v, visit)
+		}
+	}
+}
+
+// RootTypesRule checks that the schema supports the type of each operation.
+func RootTypesRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+		if rootType(ctx.Schema, op) == "" {
+			ctx.Reportf(nil, "Schema is not configured for %ss.", op.Operation)
+		}
+	}}
+}
+
+// FieldsOnCorrectTypeRule checks that every selected field is defined on its
+// parent type, and that introspection fields are only queried when allowed.
+func FieldsOnCorrectTypeRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+		switch {
+		case def != nil:
+		case ctx.Schema.DisableIntrospection && parentType == ctx.Schema.QueryType && (field.Name == "__schema" || field.Name == "__type"):
+			ctx.Reportf(field, "GraphQL introspection is not allowed, but the query contained %s.", field.Name)
+		default:
+			ctx.Reportf(field, "Cannot query field %q on type %q.%s", field.Name, parentType, suggestFields(ctx.Schema, parentType, field.Name))
+		}
+	}}
+}
+
+// KnownArgumentsRule checks that every argument is defined on its field.
+func KnownArgumentsRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+		if def == nil {
+			return
+		}
+		for _, arg := range field.Arguments {
+			if findInputValue(def.ArgumentDefinitions, arg.Name) == nil {
+				ctx.Reportf(field, "Unknown argument %q on field \"%s.%s\".%s", arg.Name, parentType, field.Name, suggestArguments(ctx.Schema, parentType, field.Name, def.ArgumentDefinitions, arg.Name))
+			}
+		}
+	}}
+}
+
+// ArgumentValuesRule checks argument values written in the document against
+// their types. Values given through variables are checked when they are
+// coerced.
+func ArgumentValuesRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+		if def == nil {
+			return
+		}
+		for _, arg := range field.Arguments {
+			if argDef := findInputValue(def.ArgumentDefinitions, arg.Name); argDef != nil {
+				validateLiteral(ctx, field.Loc, argDef.Type, arg.Value)
+			}
+		}
+	}}
+}
+
+// RequiredArgumentsRule checks that every non-null argument without a
+// default value is provided.
+func RequiredArgumentsRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+		if def == nil {
+			return
+		}
+		for _, argDef := range def.ArgumentDefinitions {
+			if argDef.Type != nil && argDef.Type.NonNull && argDef.DefaultValue == nil && findArgument(field.Arguments, argDef.Name) == nil {
+				ctx.Reportf(field, "Field %q argument %q of type %q is required, but it was not provided.", field.Name, argDef.Name, typeString(argDef.Type))
+			}
+		}
+	}}
+}
+
+// ScalarLeafsRule checks that fields of scalar and enum types have no
+// selection, and that fields of other types have one.
+func ScalarLeafsRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+		if def == nil {
+			return
+		}
+		named := ctx.Schema.Type(namedType(def.Type))
+		if named == nil {
+			return
+		}
+		switch named.kind() {
+		case KindScalar, KindEnum:
+			if field.SelectionSet != nil {
+				ctx.Reportf(field, "Field %q must not have a selection since type %q has no subfields.", field.Name, typeString(def.Type))
+			}
+		default:
+			if field.SelectionSet == nil {
+				ctx.Reportf(field, "Field %q of type %q must have a selection of subfields.", field.Name, typeString(def.Type))
+			}
+		}
+	}}
+}
+
+// KnownDirectivesRule checks that every directive is defined, and used at a
+// location its definition allows.
+func KnownDirectivesRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterDirectives: func(location string, directives []Directive) {
+		for _, d := range directives {
+			def := ctx.Schema.Directive(d.Name)
+			switch {
+			case def == nil:
+				ctx.reportAt(d.Loc, "Unknown directive \"@%s\".", d.Name)
+			case !hasLocation(def, location):
+				ctx.reportAt(d.Loc, "Directive \"@%s\" may not be used on %s.", d.Name, location)
+			}
+		}
+	}}
+}
+
+func hasLocation(def *DirectiveDefinition, location string) bool {
+	for _, l := range def.Locations {
+		if l == location {
+			return true
+		}
+	}
+	return false
+}
+
+// UniqueDirectivesPerLocationRule checks that directives not defined as
+// repeatable are used at most once per location.
+func UniqueDirectivesPerLocationRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterDirectives: func(location string, directives []Directive) {
+		seen := make(map[string]bool, len(directives))
+		for _, d := range directives {
+			def := ctx.Schema.Directive(d.Name)
+			if def == nil || def.Repeatable {
+				continue
+			}
+			if seen[d.Name] {
+				ctx.reportAt(d.Loc, "The directive \"@%s\" can only be used once at this location.", d.Name)
+			}
+			seen[d.Name] = true
+		}
+	}}
+}
+
+// DirectiveArgumentsRule checks that the arguments of every directive are
+// defined by it, that the required ones are provided, and that the values
+// written in the document fit their types.
+func DirectiveArgumentsRule(ctx *ValidationContext) RuleVisitor {
+	return RuleVisitor{EnterDirectives: func(location string, directives []Directive) {
+		for _, d := range directives {
+			def := ctx.Schema.Directive(d.Name)
+			if def == nil {
+				continue
+			}
+			for _, arg := range d.Arguments {
+				argDef := findInputValue(def.Arguments, arg.Name)
+				if argDef == nil {
+					ctx.reportAt(d.Loc, "Unknown argument %q on directive \"@%s\".%s", arg.Name, d.Name, suggestDirectiveArguments(ctx.Schema, def, arg.Name))
+					continue
+				}
+				validateLiteral(ctx, d.Loc, argDef.Type, arg.Value)
+			}
+			for _, argDef := range def.Arguments {
+				if argDef.Type != nil && argDef.Type.NonNull && argDef.DefaultValue == nil && findArgument(d.Arguments, argDef.Name) == nil {
+					ctx.reportAt(d.Loc, "Directive \"@%s\" argument %q of type %q is required, but it was not provided.", d.Name, argDef.Name, typeString(argDef.Type))
+				}
+			}
+		}
+	}}
+}
+
+// validateLiteral checks the literal val, written at loc, against t. Values
+// of custom scalars are left to the scalars to check.
+func validateLiteral(ctx *ValidationContext, loc Location, t *Type, val *Value) {
+	if t == nil || val == nil || val.Kind == "Variable" {
+		return
+	}
+	if val.Kind == "Enum" && val.Literal == "null" {
+		if t.NonNull {
+			ctx.reportAt(loc, "Expected value of type %q, found null.", typeString(t))
+		}
+		return
+	}
+	if t.IsList {
+		if val.Kind != "Array" {
+			validateLiteral(ctx, loc, t.Elem, val)
+			return
+		}
+		for _, item := range val.List {
+			validateLiteral(ctx, loc, t.Elem, item)
+		}
+		return
+	}
+	switch t.Name {
+	case "Int":
+		switch val.Kind {
+		case "Int":
+			if _, err := strconv.ParseInt(val.Literal, 10, 32); err != nil {
+				ctx.reportAt(loc, "Int cannot represent non 32-bit signed integer value: %s", val.Literal)
+			}
+		default:
+			ctx.reportAt(loc, "Int cannot represent non-integer value: %s", literalString(val))
+		}
+	case "Float":
+		if val.Kind != "Int" && val.Kind != "Float" {
+			ctx.reportAt(loc, "Float cannot represent non numeric value: %s", literalString(val))
+		}
+	case "String":
+		if val.Kind != "String" {
+			ctx.reportAt(loc, "String cannot represent a non string value: %s", literalString(val))
+		}
+	case "Boolean":
+		if val.Kind != "Boolean" {
+			ctx.reportAt(loc, "Boolean cannot represent a non boolean value: %s", literalString(val))
+		}
+	case "ID", "BigInt":
+		switch val.Kind {
+		case "Int", "String":
+		case "Float":
+			ctx.reportAt(loc, "%s cannot represent non-integer value: %s", t.Name, val.Literal)
+		default:
+			if t.Name == "ID" {
+				ctx.reportAt(loc, "ID cannot represent a non-string and non-integer value: %s", literalString(val))
+			}
+		}
+	default:
+		td := ctx.Schema.Type(t.Name)
+		if td == nil {
+			return
+		}
+		switch td.kind() {
+		case KindEnum:
+			if val.Kind != "Enum" {
+				ctx.reportAt(loc, "Enum %q cannot represent non-enum value: %s.", t.Name, literalString(val))
+			} else if !hasEnumValue(td, val.Literal) {
+				ctx.reportAt(loc, "Value %q does not exist in %q enum.", val.Literal, t.Name)
+			}
+		case KindInputObject:
+			if val.Kind != "Object" {
+				ctx.reportAt(loc, "Expected value of type %q, found %s.", typeString(t), literalString(val))
+				return
+			}
+			for _, def := range td.InputFields {
+				validateLiteral(ctx, loc, def.Type, val.ObjectFields[def.Name])
+			}
+		}
+	}
+}
+
+// literalString prints val as it is written in a document.
+func literalString(val *Value) string {
+	var sb strings.Builder
+	writeValue(&sb, val)
+	return sb.String()
+}
+
+func hasEnumValue(td *TypeDefinition, name string) bool {
+	for _, v := range td.EnumValues {
+		if v.Name == name {
+			return true
+		}
+	}
+	return false
+}
+
+// suggestFields returns a "Did you mean" hint naming the fields of typeName
+// similar to name. Fields hidden from introspection are never suggested.
+func suggestFields(s *Schema, typeName, name string) string {
+	td := s.Type(typeName)
+	if s.DisableSuggestions || td == nil || !s.visible(typeName, "") {
+		return ""
+	}
+	var options []string
+	for _, f := range td.Fields {
+		if s.visible(typeName, f.Name) {
+			options = append(options, f.Name)
+		}
+	}
+	return didYouMean(suggestionList(name, options))
+}
+
+// suggestArguments returns a "Did you mean" hint naming the arguments of
+// typeName.fieldName similar to name.
+func suggestArguments(s *Schema, typeName, fieldName string, defs []*InputValueDefinition, name string) string {
+	if s.DisableSuggestions || !s.visible(typeName, fieldName) {
+		return ""
+	}
+	options := make([]string, len(defs))
+	for i, def := range defs {
+		options[i] = def.Name
+	}
+	return didYouMean(suggestionList(name, options))
+}
+
+// suggestDirectiveArguments returns a "Did you mean" hint naming the
+// arguments of the directive def similar to name.
+func suggestDirectiveArguments(s *Schema, def *DirectiveDefinition, name string) string {
+	if s.DisableSuggestions {
+		return ""
+	}
+	options := make([]string, len(def.Arguments))
+	for i, arg := range def.Arguments {
+		options[i] = arg.Name
+	}
+	return didYouMean(suggestionList(name, options))
+}
+
+func findInputValue(defs []*InputValueDefinition, name string) *InputValueDefinition {
+	for _, def := range defs {
+		if def.Name == name {
+			return def
+		}
+	}
+	return nil
+}
+
+func findArgument(args []Argument, name string) *Argument {
+	for i := range args {
+		if args[i].Name == name {
+			return &args[i]
+		}
+	}
+	return nil
+}
+
+// typeString renders a type reference, e.g. "[String!]!".
+func typeString(t *Type) string {
+	var sb strings.Builder
+	writeType(&sb, t)
+	return sb.String()
+}
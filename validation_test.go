@@ -0,0 +1,436 @@
+package vibeGraphql
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestValidate(t *testing.T) {
+	s, err := ParseSchema(`
+		type User { id: ID! name: String }
+		input Filter { size: Int }
+		scalar BigInt
+		type Query {
+			user(id: ID!): User
+			version: String
+			page(first: Int, ids: [Int], filter: Filter, total: BigInt): String
+		}
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user(id: "1") { id name } version __typename }`, ""},
+		{`{ __type(name: "User") { name } }`, ""},
+		{`{ missing }`, `Cannot query field "missing" on type "Query".`},
+		{`{ user(id: "1") { email } }`, `Cannot query field "email" on type "User".`},
+		{`{ user(id: "1", limit: 2) { id } }`, `Unknown argument "limit" on field "Query.user".`},
+		{`{ user { id } }`, `Field "user" argument "id" of type "ID!" is required, but it was not provided.`},
+		{`{ user(id: "1") }`, `Field "user" of type "User" must have a selection of subfields.`},
+		{`{ version { length } }`, `Field "version" must not have a selection since type "String" has no subfields.`},
+		{`mutation { version }`, `Schema is not configured for mutations.`},
+		{`{ page(first: 2147483647, ids: [1, 2]) }`, ""},
+		{`{ page(first: 2147483648) }`, `Int cannot represent non 32-bit signed integer value: 2147483648`},
+		{`{ page(ids: [1, -2147483649]) }`, `Int cannot represent non 32-bit signed integer value: -2147483649`},
+		{`{ page(filter: {size: 99999999999}) }`, `Int cannot represent non 32-bit signed integer value: 99999999999`},
+		{`{ page(total: 99999999999999999999999) }`, ""},
+		{`{ page(first: 1.5) }`, `Int cannot represent non-integer value: 1.5`},
+		{`query A { version } query B { version }`, ""},
+		{`{ version } query B { version }`, `This anonymous operation must be the only defined operation.`},
+		{`query A { version } query A { version }`, `There can be only one operation named "A".`},
+		{`query ($id: ID!, $show: Boolean!) { user(id: $id) @include(if: $show) { id } }`, ""},
+		{`query ($f: Int) { page(filter: {size: $f}) }`, ""},
+		{`{ user(id: $id) { id } }`, `Variable "$id" is not defined.`},
+		{`query Get { page(ids: [1, $n], first: $n) }`, `Variable "$n" is not defined by operation "Get".`},
+		{`query ($id: ID!) { version }`, `Variable "$id" is never used.`},
+		{`query Get($id: ID!) { version }`, `Variable "$id" is never used in operation "Get".`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, NewParser(NewLexer(tt.query)).ParseDocument())
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+}
+
+func TestExecuteDocument_ValidationErrors(t *testing.T) {
+	useTestSchema(t, `type Query { version: String }`)
+	doc := NewParser(NewLexer(`{ nope }`)).ParseDocument()
+	resp, err := executeDocument(context.Background(), doc, nil)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if _, ok := resp["data"]; ok {
+		t.Errorf("expected no data for an invalid document, got %v", resp)
+	}
+	errs, _ := resp["errors"].([]*Error)
+	if len(errs) != 1 || !strings.Contains(errs[0].Message, `"nope"`) {
+		t.Errorf("unexpected errors: %v", resp["errors"])
+	}
+}
+
+func TestValidate_Suggestions(t *testing.T) {
+	s, err := ParseSchema(`
+		type User { id: ID! name: String names: [String] nickname: String secret: String }
+		type Query { user(id: ID!, limit: Int): User }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ usr(id: "1") { id } }`, `Cannot query field "usr" on type "Query". Did you mean "user"?`},
+		{`{ user(id: "1") { nmae } }`, `Cannot query field "nmae" on type "User". Did you mean "name" or "names"?`},
+		{`{ user(id: "1") { ID } }`, `Cannot query field "ID" on type "User". Did you mean "id"?`},
+		{`{ user(id: "1") { secrets } }`, `Cannot query field "secrets" on type "User". Did you mean "secret"?`},
+		{`{ user(id: "1") { avatar } }`, `Cannot query field "avatar" on type "User".`},
+		{`{ user(id: "1", limt: 2) { id } }`, `Unknown argument "limt" on field "Query.user". Did you mean "limit"?`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, parseQuery(tt.query))
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+
+	s.Visible = AllowList("Query", "User.id", "User.name")
+	errs := Validate(s, parseQuery(`{ user(id: "1") { secrets } }`))
+	if len(errs) != 1 || errs[0].Message != `Cannot query field "secrets" on type "User".` {
+		t.Errorf("hidden fields must not be suggested, got %v", errs)
+	}
+
+	s.Visible = nil
+	s.DisableSuggestions = true
+	errs = Validate(s, parseQuery(`{ usr(id: "1") { id } }`))
+	if len(errs) != 1 || errs[0].Message != `Cannot query field "usr" on type "Query".` {
+		t.Errorf("expected no suggestions, got %v", errs)
+	}
+}
+
+func TestDidYouMean(t *testing.T) {
+	tests := []struct {
+		in   []string
+		want string
+	}{
+		{nil, ""},
+		{[]string{"a"}, ` Did you mean "a"?`},
+		{[]string{"a", "b", "c"}, ` Did you mean "a", "b", or "c"?`},
+		{[]string{"a", "b", "c", "d", "e", "f"}, ` Did you mean "a", "b", "c", "d", or "e"?`},
+	}
+	for _, tt := range tests {
+		if got := didYouMean(tt.in); got != tt.want {
+			t.Errorf("didYouMean(%v) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestExecuteDocument_OperationSelection(t *testing.T) {
+	useResolvers(t, map[string]ResolverFunc{
+		"version": func(source interface{}, args map[string]interface{}) (interface{}, error) { return "1", nil },
+	}, map[string]map[string]ContextResolverFunc{})
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`query A { version } query B { version }`, `Must provide operation name if query contains multiple operations.`},
+		{`{ version } { version }`, `This anonymous operation must be the only defined operation.`},
+		{`query A { version } query A { version }`, `There can be only one operation named "A".`},
+	}
+	for _, tt := range tests {
+		// Without a schema, the rules on operations still apply.
+		result, err := executeRequest(context.Background(), tt.query, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, _ := result["errors"].([]*Error)
+		if len(errs) == 0 || errs[0].Message != tt.want || result["data"] != nil {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, result)
+		}
+	}
+}
+
+func TestValidate_Locations(t *testing.T) {
+	s, err := ParseSchema(`type Query { version: String }`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	errs := Validate(s, parseQuery("{\n  version\n  nope\n}"))
+	if len(errs) != 1 || !reflect.DeepEqual(errs[0].Locations, []Location{{Line: 3, Column: 3}}) {
+		t.Fatalf("expected an error at 3:3, got %+v", errs)
+	}
+	body, _ := json.Marshal(errs[0])
+	if !strings.Contains(string(body), `"locations":[{"line":3,"column":3}]`) {
+		t.Errorf("unexpected JSON %s", body)
+	}
+}
+
+func TestValidate_VariableLocations(t *testing.T) {
+	errs := validateOperations(parseQuery("query (\n  $used: Int,\n  $unused: Int\n) {\n  a(x: $used)\n  b(y: $missing)\n}"))
+	want := map[string]Location{
+		`Variable "$unused" is never used.`:   {Line: 3, Column: 3},
+		`Variable "$missing" is not defined.`: {Line: 6, Column: 3},
+	}
+	if len(errs) != len(want) {
+		t.Fatalf("unexpected errors %v", errs)
+	}
+	for _, err := range errs {
+		if loc, ok := want[err.Message]; !ok || !reflect.DeepEqual(err.Locations, []Location{loc}) {
+			t.Errorf("unexpected error %q at %+v", err.Message, err.Locations)
+		}
+	}
+}
+
+func TestValidate_SingleFieldSubscriptions(t *testing.T) {
+	s, err := ParseSchema(`type Query { a: Int } type Subscription { ticks: Int tocks: Int }`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`subscription { ticks }`, ""},
+		{`subscription { ticks tocks }`, `Anonymous Subscription must select only one top level field.`},
+		{"subscription OnTick {\n  ticks\n  tocks\n}", `Subscription "OnTick" must select only one top level field.`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, parseQuery(tt.query))
+		switch {
+		case tt.want == "" && len(errs) > 0:
+			t.Errorf("%s: unexpected errors %v", tt.query, errs)
+		case tt.want != "" && (len(errs) != 1 || errs[0].Message != tt.want):
+			t.Errorf("%s: got %v, want %q", tt.query, errs, tt.want)
+		}
+	}
+	if errs := Validate(s, parseQuery(tests[2].query)); len(errs) == 1 && !reflect.DeepEqual(errs[0].Locations, []Location{{Line: 3, Column: 3}}) {
+		t.Errorf("expected the error at the second field, got %+v", errs[0].Locations)
+	}
+
+	// Without a schema, the rule still rejects the request over HTTP and
+	// WebSocket rather than serving the first field.
+	rr := httptest.NewRecorder()
+	GraphqlHandler(rr, httptest.NewRequest("POST", "/graphql", strings.NewReader(`{"query": "subscription { ticks tocks }"}`)))
+	if !strings.Contains(rr.Body.String(), `"message":"Anonymous Subscription must select only one top level field."`) {
+		t.Errorf("unexpected HTTP response %s", rr.Body.String())
+	}
+	conn := &messageConn{request: `{"query": "subscription { ticks tocks }"}`}
+	ServeSubscription(conn)
+	if len(conn.messages) != 1 || conn.messages[0] != "Anonymous Subscription must select only one top level field." {
+		t.Errorf("unexpected subscription messages %q", conn.messages)
+	}
+}
+
+func TestValidate_CustomRules(t *testing.T) {
+	s, err := ParseSchema(`type Query { name: String @deprecated(reason: "use fullName") fullName: String }`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+	noDeprecated := func(ctx *ValidationContext) RuleVisitor {
+		return RuleVisitor{EnterField: func(parentType string, field, def *Field) {
+			if def == nil {
+				return
+			}
+			if reason, ok := def.Deprecation(); ok {
+				ctx.Reportf(field, "%s.%s is deprecated: %s", parentType, field.Name, reason)
+			}
+		}}
+	}
+	s.ValidationRules = []ValidationRule{noDeprecated}
+
+	errs := Validate(s, parseQuery("{ fullName\n name }"))
+	if len(errs) != 1 || errs[0].Message != "Query.name is deprecated: use fullName" ||
+		!reflect.DeepEqual(errs[0].Locations, []Location{{Line: 2, Column: 2}}) {
+		t.Errorf("unexpected errors %+v", errs)
+	}
+	// Custom rules run after the specified rules.
+	errs = Validate(s, parseQuery("{ nope name }"))
+	if len(errs) != 2 || !strings.HasPrefix(errs[0].Message, `Cannot query field "nope"`) {
+		t.Errorf("unexpected errors %+v", errs)
+	}
+	// ValidateWithRules runs only the rules it is given.
+	if errs := ValidateWithRules(s, parseQuery("{ nope name }"), []ValidationRule{noDeprecated}); len(errs) != 1 {
+		t.Errorf("unexpected errors %+v", errs)
+	}
+}
+
+func TestValidate_CustomRulesRejectRequests(t *testing.T) {
+	s := useTestSchema(t, `type Query { version: String }`)
+	s.ValidationRules = []ValidationRule{func(ctx *ValidationContext) RuleVisitor {
+		return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+			if op.Name == "" {
+				ctx.Reportf(nil, "Operations must be named.")
+			}
+		}}
+	}}
+	result, err := executeRequest(context.Background(), `{ version }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	errs, _ := result["errors"].([]*Error)
+	if len(errs) != 1 || errs[0].Message != "Operations must be named." || result["data"] != nil {
+		t.Errorf("unexpected result %v", result)
+	}
+}
+
+func TestValidate_CustomRulesRejectSubscriptions(t *testing.T) {
+	s := useTestSchema(t, `type Query { version: String } type Subscription { ticks: Int }`)
+	s.ValidationRules = []ValidationRule{func(ctx *ValidationContext) RuleVisitor {
+		return RuleVisitor{EnterOperation: func(op *OperationDefinition) {
+			if op.Name == "" {
+				ctx.Reportf(nil, "Operations must be named.")
+			}
+		}}
+	}}
+	RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 1)
+		ch <- 1
+		close(ch)
+		return ch, nil
+	})
+	defer delete(SubscriptionResolvers, "ticks")
+
+	h := newHandler([]HandlerOption{WithLiveQueries()})
+	for _, query := range []string{"subscription { ticks }", "{ version }"} {
+		conn := &jsonConn{messageConn: messageConn{request: `{"query": "` + query + `"}`}}
+		h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+		if len(conn.events) != 0 || len(conn.messages) != 1 || conn.messages[0] != "Operations must be named." {
+			t.Errorf("%s: unexpected messages %q %q", query, conn.messages, conn.events)
+		}
+	}
+
+	conn := &jsonConn{messageConn: messageConn{request: `{"query": "subscription Ticks { ticks }"}`}}
+	h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+	if len(conn.messages) != 0 || !reflect.DeepEqual(conn.events, []string{"1"}) {
+		t.Errorf("unexpected messages %q %q", conn.messages, conn.events)
+	}
+}
+
+func TestValidate_Subscriptions(t *testing.T) {
+	useTestSchema(t, `type Query { version: String } type Subscription { ticks: Int }`)
+	RegisterSubscriptionResolver("ticks", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		t.Error("invalid subscriptions should not reach the resolver")
+		ch := make(chan interface{})
+		close(ch)
+		return ch, nil
+	})
+	defer delete(SubscriptionResolvers, "ticks")
+
+	h := newHandler([]HandlerOption{WithLiveQueries()})
+	for request, want := range map[string]string{
+		`{"query": "subscription { ticks(every: 1) }"}`: `Unknown argument "every" on field "Subscription.ticks".`,
+		`{"query": "{ versions }"}`:                     `Cannot query field "versions" on type "Query". Did you mean "version"?`,
+	} {
+		conn := &jsonConn{messageConn: messageConn{request: request}}
+		h.subscribe(httptest.NewRequest("GET", "/graphql/ws", nil), conn)
+		if len(conn.events) != 0 || len(conn.messages) != 1 || conn.messages[0] != want {
+			t.Errorf("%s: unexpected messages %q %q", request, conn.messages, conn.events)
+		}
+	}
+}
+
+func TestValidate_OverlappingFields(t *testing.T) {
+	s, err := ParseSchema(`
+		interface Pet { name: String }
+		type Dog implements Pet { name: String nickname: String owner: User }
+		type Cat implements Pet { name: String nickname: String owner: User }
+		type User { id: ID name: String avatar(size: Int): String }
+		type Query { user: User pets: [Pet] }
+	`)
+	if err != nil {
+		t.Fatalf("ParseSchema error: %v", err)
+	}
+
+	tests := []struct {
+		query string
+		want  string
+	}{
+		{`{ user { small: avatar(size: 64) big: avatar(size: 512) } }`, ""},
+		{`{ user { avatar(size: 64) avatar(size: 64) name name } }`, ""},
+		{`{ user { ...f avatar(size: 64) } } fragment f on User { avatar(size: 64) }`, ""},
+		{`{ pets { ... on Dog { name: nickname } ... on Cat { name } } }`, ""},
+		{`{ pets { ... on Dog { owner { n: name } } ... on Cat { owner { n: id } } } }`, ""},
+		{`{ user { avatar(size: 64) avatar(size: 512) } }`,
+			`Fields "avatar" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intended.`},
+		{`{ user { avatar(size: 64) avatar } }`,
+			`Fields "avatar" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intended.`},
+		{`{ user { name: id name } }`,
+			`Fields "name" conflict because "id" and "name" are different fields. Use different aliases on the fields to fetch both if this was intended.`},
+		{`{ user { ...f avatar(size: 512) } } fragment f on User { avatar(size: 64) }`,
+			`Fields "avatar" conflict because they have differing arguments. Use different aliases on the fields to fetch both if this was intended.`},
+		{`{ pets { name ... on Dog { name: nickname } } }`,
+			`Fields "name" conflict because "name" and "nickname" are different fields. Use different aliases on the fields to fetch both if this was intended.`},
+		{`{ user { id } user { id: name } }`,
+			`Fields "id" conflict because "id" and "name" are different fields. Use different aliases on the fields to fetch both if this was intended.`},
+	}
+	for _, tt := range tests {
+		errs := Validate(s, parseQuery(tt.query))
+		if tt.want == "" {
+			if len(errs) != 0 {
+				t.Errorf("%s: unexpected errors %v", tt.query, errs)
+			}
+			continue
+		}
+		if len(errs) != 1 || errs[0].Message != tt.want {
+			t.Errorf("%s: expected %q, got %v", tt.query, tt.want, errs)
+		}
+	}
+
+	errs := Validate(s, parseQuery("{\n  user {\n    avatar(size: 64)\n    avatar(size: 512)\n  }\n}"))
+	if len(errs) != 1 || !reflect.DeepEqual(errs[0].Locations, []Location{{Line: 3, Column: 5}, {Line: 4, Column: 5}}) {
+		t.Errorf("unexpected errors %v", errs)
+	}
+}
+
+func TestExecuteRequest_AliasedArguments(t *testing.T) {
+	type user struct{ ID string }
+	calls := 0
+	useResolvers(t, map[string]ResolverFunc{
+		"user": func(source interface{}, args map[string]interface{}) (interface{}, error) { return &user{ID: "1"}, nil },
+	}, map[string]map[string]ContextResolverFunc{
+		"user": {"avatar": func(ctx context.Context, source interface{}, args map[string]interface{}) (interface{}, error) {
+			calls++
+			return fmt.Sprintf("/avatars/%s/%v.png", source.(*user).ID, args["size"]), nil
+		}},
+	})
+	MemoizeFieldResults = true
+	defer func() { MemoizeFieldResults = false }()
+
+	result, err := executeRequest(context.Background(), `{ user { small: avatar(size: 64) big: avatar(size: 512) again: avatar(size: 64) } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"data":{"user":{"again":"/avatars/1/64.png","big":"/avatars/1/512.png","small":"/avatars/1/64.png"}}}`
+	if got := strings.TrimSpace(string(encodeResponse(result))); got != want {
+		t.Errorf("got %s\nwant %s", got, want)
+	}
+	if calls != 2 {
+		t.Errorf("expected the resolver to run once per distinct arguments, got %d calls", calls)
+	}
+
+	// Without aliases, the results of both fields would share a key.
+	result, err = executeRequest(context.Background(), `{ user { avatar(size: 64) avatar(size: 512) } }`, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if errs, _ := result["errors"].([]*Error); len(errs) != 1 || result["data"] != nil {
+		t.Errorf("unexpected result %v", result)
+	}
+}
@@ -0,0 +1,57 @@
+package vibeGraphql
+
+import "strings"
+
+// VisibilityFunc decides whether a type, or a field of it, is visible through
+// introspection. It is called with an empty fieldName for the type itself.
+type VisibilityFunc func(typeName, fieldName string) bool
+
+// AllowList returns a VisibilityFunc exposing only the listed entries. An
+// entry is either a type name, which exposes the type with all its fields,
+// enum values and input fields, or "Type.field", which exposes the type with
+// just that member:
+//
+//	schema.Visible = graphql.AllowList("User", "Query.me", "Query.posts", "Post")
+func AllowList(entries ...string) VisibilityFunc {
+	types := make(map[string]bool)
+	members := make(map[string]bool)
+	for _, entry := range entries {
+		typeName, _, ok := strings.Cut(entry, ".")
+		if !ok {
+			types[typeName] = true
+			continue
+		}
+		members[typeName] = true
+		members[entry] = true
+	}
+	return func(typeName, fieldName string) bool {
+		if types[typeName] {
+			return true
+		}
+		if fieldName == "" {
+			return members[typeName]
+		}
+		return members[typeName+"."+fieldName]
+	}
+}
+
+// visible reports whether introspection may expose the named type, or the
+// field of it when fieldName is set. Built-in scalars, introspection types
+// and root types are always visible, although the fields of root types are
+// still filtered.
+func (s *Schema) visible(typeName, fieldName string) bool {
+	if s.Visible == nil || strings.HasPrefix(typeName, "__") {
+		return true
+	}
+	if fieldName == "" {
+		for _, name := range builtinScalars {
+			if name == typeName {
+				return true
+			}
+		}
+		if typeName == s.QueryType || typeName == s.MutationType || typeName == s.SubscriptionType {
+			return true
+		}
+	}
+	return s.Visible(typeName, fieldName)
+}
@@ -0,0 +1,92 @@
+package vibeGraphql
+
+import (
+	"context"
+	"reflect"
+	"testing"
+)
+
+const visibilitySDL = `
+	type Query {
+		me: User
+		admin: Admin
+		search(filter: AdminFilter): [Result]
+	}
+	type User { id: ID! name: String secret: String }
+	type Admin { id: ID! }
+	input AdminFilter { id: ID }
+	union Result = User | Admin
+	enum Role { USER ADMIN }
+`
+
+func introspectedNames(t *testing.T, query, key string) []string {
+	t.Helper()
+	data := executeQuery(t, query, nil)
+	typ, _ := data["__type"].(map[string]interface{})
+	if typ == nil {
+		return nil
+	}
+	var names []string
+	for _, item := range typ[key].([]interface{}) {
+		names = append(names, item.(map[string]interface{})["name"].(string))
+	}
+	return names
+}
+
+func TestSchemaVisible(t *testing.T) {
+	s := useTestSchema(t, visibilitySDL)
+	s.Visible = AllowList("Query.me", "Query.search", "User.id", "User.name", "Result", "Role.USER")
+
+	if got := introspectedNames(t, `{ __type(name: "Query") { fields { name } } }`, "fields"); !reflect.DeepEqual(got, []string{"me", "search"}) {
+		t.Errorf("Query fields = %v", got)
+	}
+	data := executeQuery(t, `{ __type(name: "Query") { fields { args { name } } } }`, nil)
+	for _, f := range data["__type"].(map[string]interface{})["fields"].([]interface{}) {
+		if args := f.(map[string]interface{})["args"].([]interface{}); len(args) != 0 {
+			t.Errorf("expected arguments of hidden types to be dropped, got %v", args)
+		}
+	}
+	if got := introspectedNames(t, `{ __type(name: "User") { fields { name } } }`, "fields"); !reflect.DeepEqual(got, []string{"id", "name"}) {
+		t.Errorf("User fields = %v", got)
+	}
+	if got := introspectedNames(t, `{ __type(name: "Result") { possibleTypes { name } } }`, "possibleTypes"); !reflect.DeepEqual(got, []string{"User"}) {
+		t.Errorf("Result possible types = %v", got)
+	}
+	if got := introspectedNames(t, `{ __type(name: "Role") { enumValues { name } } }`, "enumValues"); !reflect.DeepEqual(got, []string{"USER"}) {
+		t.Errorf("Role values = %v", got)
+	}
+	if data := executeQuery(t, `{ __type(name: "Admin") { name } }`, nil); data["__type"] != nil {
+		t.Errorf("expected hidden type to be null, got %v", data["__type"])
+	}
+
+	data = executeQuery(t, `{ __schema { types { name } } }`, nil)
+	for _, typ := range data["__schema"].(map[string]interface{})["types"].([]interface{}) {
+		switch name := typ.(map[string]interface{})["name"]; name {
+		case "Admin", "AdminFilter":
+			t.Errorf("hidden type %v listed in __schema", name)
+		}
+	}
+}
+
+func TestSchemaDisableIntrospection(t *testing.T) {
+	s := useTestSchema(t, `type Query { hello: String }`)
+	s.DisableIntrospection = true
+	QueryResolvers["hello"] = func(source interface{}, args map[string]interface{}) (interface{}, error) { return "hi", nil }
+	defer delete(QueryResolvers, "hello")
+
+	for _, query := range []string{`{ __schema { queryType { name } } }`, `{ hello __type(name: "Query") { name } }`} {
+		resp, err := executeDocument(context.Background(), parseQuery(query), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		errs, _ := resp["errors"].([]*Error)
+		if len(errs) != 1 || resp["data"] != nil {
+			t.Errorf("%s: expected introspection to be rejected, got %v", query, resp)
+		}
+	}
+
+	data := executeQuery(t, `{ __typename hello }`, nil)
+	if data["__typename"] != "Query" || data["hello"] != "hi" {
+		t.Errorf("unexpected data: %v", data)
+	}
+}
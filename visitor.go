@@ -0,0 +1,214 @@
+package vibeGraphql
+
+import "fmt"
+
+// Visitor is called for every node reached by Walk. Enter runs before a
+// node's children are visited and may return false to skip them; Leave runs
+// afterwards. Either function may be nil.
+type Visitor struct {
+	Enter func(c *Cursor) bool
+	Leave func(c *Cursor)
+}
+
+// Cursor describes the node being visited and allows it to be modified.
+type Cursor struct {
+	node   Node
+	parent Node
+	inList bool
+
+	deleted bool
+	before  []Node
+	after   []Node
+}
+
+// Node returns the current node.
+func (c *Cursor) Node() Node { return c.node }
+
+// Parent returns the node containing the current node, or nil for the root.
+func (c *Cursor) Parent() Node { return c.parent }
+
+// Replace substitutes n for the current node. The replacement's children are
+// visited instead of the original's. Definitions, selections, arguments,
+// directives and values can be replaced; for arguments and directives n must
+// be an *Argument or *Directive respectively.
+func (c *Cursor) Replace(n Node) {
+	if c.parent == nil {
+		panic("vibeGraphql: cannot replace the root of a Walk")
+	}
+	c.node = n
+}
+
+// Delete removes the current node from the list containing it.
+func (c *Cursor) Delete() {
+	c.mustBeInList("Delete")
+	c.deleted = true
+}
+
+// InsertBefore inserts n before the current node. Inserted nodes are not visited.
+func (c *Cursor) InsertBefore(n Node) {
+	c.mustBeInList("InsertBefore")
+	c.before = append(c.before, n)
+}
+
+// InsertAfter inserts n after the current node. Inserted nodes are not visited.
+func (c *Cursor) InsertAfter(n Node) {
+	c.mustBeInList("InsertAfter")
+	c.after = append(c.after, n)
+}
+
+func (c *Cursor) mustBeInList(op string) {
+	if !c.inList {
+		panic(fmt.Sprintf("vibeGraphql: %s called on a %T that is not part of a list", op, c.node))
+	}
+}
+
+// Walk traverses the tree rooted at root depth-first, calling v for each
+// Document, definition, SelectionSet, selection, Argument, Directive and
+// Value. Fragment spreads are visited without entering the fragments they
+// spread, which are visited as definitions of the document.
+// Modifications made through the Cursor are applied in place.
+func Walk(root Node, v Visitor) {
+	w := &walker{visitor: v}
+	w.visit(&Cursor{node: root})
+}
+
+type walker struct {
+	visitor Visitor
+}
+
+// visit calls the visitor for c and walks the children of the resulting node.
+func (w *walker) visit(c *Cursor) {
+	if w.visitor.Enter == nil || w.visitor.Enter(c) {
+		if !c.deleted {
+			w.walkChildren(c.node)
+		}
+	}
+	if w.visitor.Leave != nil && !c.deleted {
+		w.visitor.Leave(c)
+	}
+}
+
+// visitList visits each node of a list and returns the list with deletions,
+// replacements and insertions applied.
+func (w *walker) visitList(parent Node, nodes []Node) []Node {
+	out := make([]Node, 0, len(nodes))
+	for _, n := range nodes {
+		c := &Cursor{node: n, parent: parent, inList: true}
+		w.visit(c)
+		out = append(out, c.before...)
+		if !c.deleted {
+			out = append(out, c.node)
+		}
+		out = append(out, c.after...)
+	}
+	return out
+}
+
+func (w *walker) walkChildren(n Node) {
+	switch n := n.(type) {
+	case *Document:
+		nodes := make([]Node, len(n.Definitions))
+		for i, d := range n.Definitions {
+			nodes[i] = d
+		}
+		defs := w.visitList(n, nodes)
+		n.Definitions = make([]Definition, len(defs))
+		for i, d := range defs {
+			n.Definitions[i] = d
+		}
+	case *OperationDefinition:
+		n.Directives = w.visitDirectives(n, n.Directives)
+		n.SelectionSet = w.visitSelectionSet(n, n.SelectionSet)
+	case *FragmentDefinition:
+		n.Directives = w.visitDirectives(n, n.Directives)
+		n.SelectionSet = w.visitSelectionSet(n, n.SelectionSet)
+	case *InlineFragment:
+		n.Directives = w.visitDirectives(n, n.Directives)
+		n.SelectionSet = w.visitSelectionSet(n, n.SelectionSet)
+	case *FragmentSpread:
+		n.Directives = w.visitDirectives(n, n.Directives)
+	case *SelectionSet:
+		nodes := make([]Node, len(n.Selections))
+		for i, s := range n.Selections {
+			nodes[i] = s
+		}
+		sels := w.visitList(n, nodes)
+		n.Selections = make([]Selection, len(sels))
+		for i, s := range sels {
+			n.Selections[i] = s
+		}
+	case *Field:
+		n.Arguments = w.visitArguments(n, n.Arguments)
+		n.Directives = w.visitDirectives(n, n.Directives)
+		n.SelectionSet = w.visitSelectionSet(n, n.SelectionSet)
+	case *Argument:
+		n.Value = w.visitValue(n, n.Value)
+	case *Directive:
+		n.Arguments = w.visitArguments(n, n.Arguments)
+	case *Value:
+		for key, field := range n.ObjectFields {
+			n.ObjectFields[key] = w.visitValue(n, field)
+		}
+		for i, item := range n.List {
+			n.List[i] = w.visitValue(n, item)
+		}
+	}
+}
+
+func (w *walker) visitSelectionSet(parent Node, ss *SelectionSet) *SelectionSet {
+	if ss == nil {
+		return nil
+	}
+	c := &Cursor{node: ss, parent: parent}
+	w.visit(c)
+	replaced, _ := c.node.(*SelectionSet)
+	return replaced
+}
+
+func (w *walker) visitValue(parent Node, v *Value) *Value {
+	if v == nil {
+		return nil
+	}
+	c := &Cursor{node: v, parent: parent}
+	w.visit(c)
+	replaced, _ := c.node.(*Value)
+	return replaced
+}
+
+func (w *walker) visitArguments(parent Node, args []Argument) []Argument {
+	if len(args) == 0 {
+		return args
+	}
+	nodes := make([]Node, len(args))
+	for i := range args {
+		nodes[i] = &args[i]
+	}
+	var out []Argument
+	for _, n := range w.visitList(parent, nodes) {
+		arg, ok := n.(*Argument)
+		if !ok {
+			panic(fmt.Sprintf("vibeGraphql: cannot use %T as an argument", n))
+		}
+		out = append(out, *arg)
+	}
+	return out
+}
+
+func (w *walker) visitDirectives(parent Node, dirs []Directive) []Directive {
+	if len(dirs) == 0 {
+		return dirs
+	}
+	nodes := make([]Node, len(dirs))
+	for i := range dirs {
+		nodes[i] = &dirs[i]
+	}
+	var out []Directive
+	for _, n := range w.visitList(parent, nodes) {
+		d, ok := n.(*Directive)
+		if !ok {
+			panic(fmt.Sprintf("vibeGraphql: cannot use %T as a directive", n))
+		}
+		out = append(out, *d)
+	}
+	return out
+}
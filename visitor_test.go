@@ -0,0 +1,117 @@
+package vibeGraphql
+
+import (
+	"reflect"
+	"testing"
+)
+
+func parseQuery(query string) *Document {
+	return NewParser(NewLexer(query)).ParseDocument()
+}
+
+// fieldNames returns the names of the fields in ss.
+func fieldNames(ss *SelectionSet) []string {
+	var names []string
+	for _, sel := range ss.Selections {
+		names = append(names, sel.(*Field).Name)
+	}
+	return names
+}
+
+func TestWalk_Order(t *testing.T) {
+	doc := parseQuery(`{ user(id: 1) @include(if: true) { name } }`)
+	var entered, left []string
+	Walk(doc, Visitor{
+		Enter: func(c *Cursor) bool {
+			entered = append(entered, reflect.TypeOf(c.Node()).Elem().Name())
+			return true
+		},
+		Leave: func(c *Cursor) {
+			left = append(left, reflect.TypeOf(c.Node()).Elem().Name())
+		},
+	})
+	wantEntered := []string{"Document", "OperationDefinition", "SelectionSet", "Field", "Argument", "Value", "Directive", "Argument", "Value", "SelectionSet", "Field"}
+	if !reflect.DeepEqual(entered, wantEntered) {
+		t.Errorf("enter order = %v, want %v", entered, wantEntered)
+	}
+	if len(left) != len(entered) || left[len(left)-1] != "Document" {
+		t.Errorf("unexpected leave order: %v", left)
+	}
+}
+
+func TestWalk_SkipChildren(t *testing.T) {
+	doc := parseQuery(`{ a { b { c } } }`)
+	var visited []string
+	Walk(doc, Visitor{Enter: func(c *Cursor) bool {
+		if f, ok := c.Node().(*Field); ok {
+			visited = append(visited, f.Name)
+			return f.Name != "b"
+		}
+		return true
+	}})
+	if !reflect.DeepEqual(visited, []string{"a", "b"}) {
+		t.Errorf("visited %v", visited)
+	}
+}
+
+func TestWalk_Redact(t *testing.T) {
+	doc := parseQuery(`{ user { name password friends { password email } } }`)
+	Walk(doc, Visitor{Enter: func(c *Cursor) bool {
+		if f, ok := c.Node().(*Field); ok && f.Name == "password" {
+			c.Delete()
+		}
+		return true
+	}})
+	user := doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections[0].(*Field)
+	if got := fieldNames(user.SelectionSet); !reflect.DeepEqual(got, []string{"name", "friends"}) {
+		t.Errorf("user fields = %v", got)
+	}
+	friends := user.SelectionSet.Selections[1].(*Field)
+	if got := fieldNames(friends.SelectionSet); !reflect.DeepEqual(got, []string{"email"}) {
+		t.Errorf("friends fields = %v", got)
+	}
+}
+
+func TestWalk_InjectAndReplace(t *testing.T) {
+	doc := parseQuery(`{ user(id: 1) { name } }`)
+	Walk(doc, Visitor{
+		Enter: func(c *Cursor) bool {
+			switch n := c.Node().(type) {
+			case *Field:
+				if n.Name == "name" {
+					c.InsertBefore(&Field{Name: "id"})
+					c.Replace(&Field{Name: "fullName"})
+				}
+			case *Argument:
+				c.Replace(&Argument{Name: n.Name, Value: &Value{Kind: "String", Literal: "1"}})
+			}
+			return true
+		},
+		Leave: func(c *Cursor) {
+			if ss, ok := c.Node().(*SelectionSet); ok && ss.Selections[0].(*Field).Name != "user" {
+				ss.Selections = append(ss.Selections, &Field{Name: "__typename"})
+			}
+		},
+	})
+	user := doc.Definitions[0].(*OperationDefinition).SelectionSet.Selections[0].(*Field)
+	if got := fieldNames(user.SelectionSet); !reflect.DeepEqual(got, []string{"id", "fullName", "__typename"}) {
+		t.Errorf("user fields = %v", got)
+	}
+	if v := user.Arguments[0].Value; v.Kind != "String" || v.Literal != "1" {
+		t.Errorf("argument not replaced: %+v", v)
+	}
+}
+
+func TestCursor_DeleteOutsideListPanics(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("expected Delete on a selection set to panic")
+		}
+	}()
+	Walk(parseQuery(`{ a }`), Visitor{Enter: func(c *Cursor) bool {
+		if _, ok := c.Node().(*SelectionSet); ok {
+			c.Delete()
+		}
+		return true
+	}})
+}
@@ -0,0 +1,140 @@
+package vibeGraphql
+
+import (
+	"bytes"
+	"compress/flate"
+	"encoding/json"
+	"fmt"
+	"net/http"
+)
+
+// TextMessage is the message type of text frames, as numbered by the
+// WebSocket protocol and by gorilla/websocket.
+const TextMessage = 1
+
+// WebSocketConn is an upgraded WebSocket connection a subscription is served
+// on. *websocket.Conn from gorilla/websocket implements it; connections of
+// other WebSocket libraries need a small adapter.
+type WebSocketConn interface {
+	SubscriptionConn
+	Close() error
+}
+
+// Upgrader upgrades HTTP requests to WebSocket connections for the
+// subscription handlers. If Upgrade returns an error, the handler replies
+// with 400 Bad Request.
+type Upgrader interface {
+	Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error)
+}
+
+// WithUpgrader makes a subscription handler upgrade connections with u,
+// letting applications choose their WebSocket library. Without this option
+// connections are upgraded with gorilla/websocket, unless the package is
+// built with the nogorilla build tag, which drops the dependency; handlers
+// then need this option to serve subscriptions.
+func WithUpgrader(u Upgrader) HandlerOption {
+	return func(h *handler) {
+		h.upgrader = u
+	}
+}
+
+// upgraderOf returns the upgrader h uses, or nil if it has none.
+func (h *handler) upgraderOf() Upgrader {
+	if h.upgrader != nil {
+		return h.upgrader
+	}
+	return upgrader
+}
+
+// Compression configures the permessage-deflate compression of subscription
+// messages; see WithCompression.
+type Compression struct {
+	// Level is the flate compression level, from 1 (best speed) to 9 (best
+	// compression), as in compress/flate. Zero selects the WebSocket
+	// library's default.
+	Level int
+	// Threshold is the size in bytes below which messages are sent
+	// uncompressed, as compressing them costs more than it saves. Zero
+	// selects DefaultCompressionThreshold.
+	Threshold int
+}
+
+// DefaultCompressionThreshold is the Threshold of a Compression without one.
+const DefaultCompressionThreshold = 512
+
+func (c Compression) threshold() int {
+	if c.Threshold == 0 {
+		return DefaultCompressionThreshold
+	}
+	return c.Threshold
+}
+
+// CompressionUpgrader is an Upgrader that can negotiate the permessage-deflate
+// extension. Subscription handlers configured with WithCompression upgrade
+// connections with UpgradeCompressed; upgraders that do not implement it
+// serve uncompressed connections.
+type CompressionUpgrader interface {
+	Upgrader
+	// UpgradeCompressed upgrades r like Upgrade, compressing messages as c
+	// describes if the client supports it. If the connection implements
+	// EnableWriteCompression(bool), the handler applies c.Threshold itself;
+	// otherwise the upgrader must.
+	UpgradeCompressed(w http.ResponseWriter, r *http.Request, c Compression) (WebSocketConn, error)
+}
+
+// WithCompression makes a subscription handler compress messages with the
+// permessage-deflate extension when the client supports it. Streams of JSON
+// events compress well, but small messages are sent as they are; see
+// Compression.Threshold. It panics if c.Level is not a compress/flate level.
+func WithCompression(c Compression) HandlerOption {
+	if c.Level < flate.HuffmanOnly || c.Level > flate.BestCompression {
+		panic(fmt.Sprintf("vibeGraphql: invalid compression level %d", c.Level))
+	}
+	return func(h *handler) {
+		h.compression = &c
+	}
+}
+
+// upgrade upgrades r to a WebSocket connection with u, compressing messages
+// if the handler is configured to.
+func (h *handler) upgrade(u Upgrader, w http.ResponseWriter, r *http.Request) (WebSocketConn, error) {
+	cu, ok := u.(CompressionUpgrader)
+	if h.compression == nil || !ok {
+		return u.Upgrade(w, r)
+	}
+	conn, err := cu.UpgradeCompressed(w, r, *h.compression)
+	if err != nil {
+		return nil, err
+	}
+	if toggler, ok := conn.(compressionToggler); ok {
+		return &thresholdConn{WebSocketConn: conn, toggler: toggler, threshold: h.compression.threshold()}, nil
+	}
+	return conn, nil
+}
+
+// compressionToggler is implemented by connections that can turn compression
+// on and off for each message, like those of gorilla/websocket.
+type compressionToggler interface {
+	EnableWriteCompression(enable bool)
+}
+
+// thresholdConn compresses only the messages of at least threshold bytes.
+type thresholdConn struct {
+	WebSocketConn
+	toggler   compressionToggler
+	threshold int
+}
+
+func (c *thresholdConn) WriteMessage(messageType int, data []byte) error {
+	c.toggler.EnableWriteCompression(len(data) >= c.threshold)
+	return c.WebSocketConn.WriteMessage(messageType, data)
+}
+
+// WriteJSON encodes v first, as its size decides whether it is compressed.
+func (c *thresholdConn) WriteJSON(v interface{}) error {
+	var buf bytes.Buffer
+	if err := json.NewEncoder(&buf).Encode(v); err != nil {
+		return err
+	}
+	return c.WriteMessage(TextMessage, buf.Bytes())
+}
@@ -0,0 +1,39 @@
+//go:build !nogorilla
+
+package vibeGraphql
+
+import (
+	"net/http"
+
+	"github.com/gorilla/websocket"
+)
+
+// upgrader is the Upgrader of handlers without WithUpgrader.
+var upgrader Upgrader = gorillaUpgrader{websocket.Upgrader{
+	CheckOrigin: func(r *http.Request) bool { return true },
+}}
+
+// gorillaUpgrader upgrades connections with gorilla/websocket.
+type gorillaUpgrader struct {
+	websocket.Upgrader
+}
+
+func (u gorillaUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error) {
+	return u.Upgrader.Upgrade(w, r, nil)
+}
+
+// UpgradeCompressed negotiates permessage-deflate with clients that support it.
+func (u gorillaUpgrader) UpgradeCompressed(w http.ResponseWriter, r *http.Request, c Compression) (WebSocketConn, error) {
+	u.EnableCompression = true
+	conn, err := u.Upgrader.Upgrade(w, r, nil)
+	if err != nil {
+		return nil, err
+	}
+	if c.Level != 0 {
+		if err := conn.SetCompressionLevel(c.Level); err != nil {
+			conn.Close()
+			return nil, err
+		}
+	}
+	return conn, nil
+}
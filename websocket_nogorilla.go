@@ -0,0 +1,7 @@
+//go:build nogorilla
+
+package vibeGraphql
+
+// upgrader is the Upgrader of handlers without WithUpgrader. Builds with the
+// nogorilla tag have none.
+var upgrader Upgrader
@@ -0,0 +1,141 @@
+package vibeGraphql
+
+import (
+	"compress/flate"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/websocket"
+)
+
+// recordingUpgrader hands out conn, or fails if conn is nil.
+type recordingUpgrader struct {
+	conn *closingConn
+}
+
+func (u recordingUpgrader) Upgrade(w http.ResponseWriter, r *http.Request) (WebSocketConn, error) {
+	if u.conn == nil {
+		return nil, errors.New("upgrade failed")
+	}
+	return u.conn, nil
+}
+
+// closingConn is a messageConn that records whether it was closed.
+type closingConn struct {
+	messageConn
+	closed bool
+}
+
+func (c *closingConn) Close() error {
+	c.closed = true
+	return nil
+}
+
+func TestWithUpgrader(t *testing.T) {
+	RegisterSubscriptionResolver("wsEvents", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		return "not a channel", nil
+	})
+	defer delete(SubscriptionResolvers, "wsEvents")
+
+	conn := &closingConn{messageConn: messageConn{request: `{"query": "subscription { wsEvents }"}`}}
+	h := newHandler([]HandlerOption{WithUpgrader(recordingUpgrader{conn: conn})})
+	h.serveSubscription(httptest.NewRecorder(), httptest.NewRequest("GET", "/graphql/ws", nil))
+	if len(conn.messages) != 1 || conn.messages[0] != "subscription error: subscription resolver for field wsEvents did not return a channel" {
+		t.Errorf("unexpected messages %q", conn.messages)
+	}
+	if !conn.closed {
+		t.Error("expected the connection to be closed")
+	}
+
+	rr := httptest.NewRecorder()
+	newHandler([]HandlerOption{WithUpgrader(recordingUpgrader{})}).serveSubscription(rr, httptest.NewRequest("GET", "/graphql/ws", nil))
+	if rr.Code != http.StatusBadRequest {
+		t.Errorf("expected status 400 for a failed upgrade, got %d", rr.Code)
+	}
+
+	// Builds without a default upgrader cannot serve subscriptions.
+	orig := upgrader
+	defer func() { upgrader = orig }()
+	upgrader = nil
+	rr = httptest.NewRecorder()
+	SubscriptionHandler(rr, httptest.NewRequest("GET", "/graphql/ws", nil))
+	if rr.Code != http.StatusInternalServerError {
+		t.Errorf("expected status 500 without an upgrader, got %d", rr.Code)
+	}
+}
+
+// togglingConn records the compression setting of each message written.
+type togglingConn struct {
+	closingConn
+	compress   bool
+	compressed []bool
+}
+
+func (c *togglingConn) EnableWriteCompression(enable bool) { c.compress = enable }
+func (c *togglingConn) WriteMessage(messageType int, data []byte) error {
+	c.compressed = append(c.compressed, c.compress)
+	return c.closingConn.WriteMessage(messageType, data)
+}
+
+func TestThresholdConn(t *testing.T) {
+	conn := &togglingConn{}
+	tc := &thresholdConn{WebSocketConn: conn, toggler: conn, threshold: Compression{Threshold: 8}.threshold()}
+	tc.WriteMessage(TextMessage, []byte("short"))
+	tc.WriteJSON("long enough")
+	tc.WriteJSON(1)
+	if want := []bool{false, true, false}; !reflect.DeepEqual(conn.compressed, want) {
+		t.Errorf("got compression %v, want %v", conn.compressed, want)
+	}
+	if conn.messages[1] != "\"long enough\"\n" {
+		t.Errorf("unexpected JSON message %q", conn.messages[1])
+	}
+	if got := (Compression{}).threshold(); got != DefaultCompressionThreshold {
+		t.Errorf("expected the default threshold, got %d", got)
+	}
+}
+
+func TestWithCompression(t *testing.T) {
+	large := strings.Repeat("event ", 100)
+	RegisterSubscriptionResolver("deflateEvents", func(source interface{}, args map[string]interface{}) (interface{}, error) {
+		ch := make(chan interface{}, 2)
+		ch <- "small"
+		ch <- large
+		close(ch)
+		return ch, nil
+	})
+	defer delete(SubscriptionResolvers, "deflateEvents")
+	srv := httptest.NewServer(NewSubscriptionHandler(WithCompression(Compression{Level: flate.BestSpeed, Threshold: 64})))
+	defer srv.Close()
+
+	for _, compress := range []bool{true, false} {
+		dialer := websocket.Dialer{EnableCompression: compress}
+		conn, resp, err := dialer.Dial("ws"+strings.TrimPrefix(srv.URL, "http"), nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if negotiated := strings.HasPrefix(resp.Header.Get("Sec-WebSocket-Extensions"), "permessage-deflate"); negotiated != compress {
+			t.Errorf("client compression %v: negotiated %v", compress, negotiated)
+		}
+		if err := conn.WriteJSON(SubscriptionRequest{Query: "subscription { deflateEvents }"}); err != nil {
+			t.Fatal(err)
+		}
+		for _, want := range []string{"small", large} {
+			var event string
+			if err := conn.ReadJSON(&event); err != nil || event != want {
+				t.Errorf("client compression %v: unexpected event %q, %v", compress, event, err)
+			}
+		}
+		conn.Close()
+	}
+
+	defer func() {
+		if recover() == nil {
+			t.Error("expected an invalid level to panic")
+		}
+	}()
+	WithCompression(Compression{Level: 10})
+}